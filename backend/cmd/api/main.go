@@ -9,7 +9,6 @@ import (
 	"freelance-monitor-system/internal/database"
 	"freelance-monitor-system/internal/handlers"
 	"freelance-monitor-system/internal/jobs"
-	"freelance-monitor-system/internal/models"
 	"freelance-monitor-system/internal/monitoring"
 	"freelance-monitor-system/internal/scheduler"
 	"freelance-monitor-system/internal/server"
@@ -19,9 +18,16 @@ import (
 
 // BuildAppWithDB constructs the Gin engine and returns it with port.
 // If db is nil, it initializes using the configured Postgres settings.
-// allow test stubbing of database.InitDB and AutoMigrate
+// allow test stubbing of database.InitDB and the schema check
 var initDBFunc = database.InitDB
-var autoMigrateFunc = database.AutoMigrate
+var migrateFunc = prepareSchema
+
+// prepareSchema applies pending migrations on SQLite (dev/test, in-memory) or when
+// MIGRATE_ON_START=true; otherwise startup is refused while migrations are pending.
+func prepareSchema(db *gorm.DB) error {
+	autoApply := os.Getenv("MIGRATE_ON_START") == "true" || db.Dialector.Name() == "sqlite"
+	return database.EnsureSchema(context.Background(), db, autoApply)
+}
 
 func BuildAppWithDB(db *gorm.DB) (*serverEngineWrapper, error) {
 	port := os.Getenv("PORT")
@@ -37,7 +43,7 @@ func BuildAppWithDB(db *gorm.DB) (*serverEngineWrapper, error) {
 		database.DB = db
 	}
 
-    if err := autoMigrateFunc(&models.Client{}, &models.Service{}, &models.Offer{}, &models.UptimeLog{}, &models.Alert{}, &models.User{}, &models.PasswordReset{}, &models.MonthlyReport{}, &models.DailyReport{}, &models.HeartbeatJob{}, &models.SLOTarget{}, &models.ReportTemplate{}); err != nil {
+    if err := migrateFunc(database.DB); err != nil {
         return nil, err
     }
 
@@ -55,9 +61,10 @@ func BuildAppWithDB(db *gorm.DB) (*serverEngineWrapper, error) {
 	// Initialize scheduler and register periodic jobs
 	go func() {
 		ctx := context.Background()
-		s := scheduler.NewScheduler(ctx)
+		s := scheduler.NewScheduler(ctx, database.DB)
 		scheduler.SetDefault(s)
 		jr := jobs.NewJobRunner(database.DB)
+		s.SetNotifier(jr)
 
 		// Regular monitoring sweep every 30s
 		s.Register("monitoring_sweep", 30*time.Second, true, jr.RunMonitoring)
@@ -65,14 +72,8 @@ func BuildAppWithDB(db *gorm.DB) (*serverEngineWrapper, error) {
 		// Expiry refresh daily
 		s.Register("refresh_expiries", 24*time.Hour, true, jr.RefreshExpiries)
 
-		// Daily aggregation shortly after midnight
-		s.Register("daily_report", 24*time.Hour, true, func(c context.Context) error {
-			// align to local midnight before first run
-			now := time.Now()
-			next := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 1, 0, 0, now.Location())
-			time.Sleep(time.Until(next))
-			return jr.GenerateDailyReport(c)
-		})
+		// Daily aggregation of the previous day shortly after midnight
+		registerCron(s, "daily_report", "DAILY_REPORT_SCHEDULE", "1 0 * * *", jr.GenerateDailyReport)
 
 		// Background expiry warning evaluation every hour
 		s.Register("expiry_warnings", time.Hour, true, func(c context.Context) error {
@@ -99,25 +100,46 @@ func BuildAppWithDB(db *gorm.DB) (*serverEngineWrapper, error) {
 		// Nightly backups
 		s.Register("backups", 24*time.Hour, true, jr.RunBackups)
 
-		// Offer expiry reminders daily at 09:00 local time
-		s.Register("offer_reminders", 24*time.Hour, true, func(c context.Context) error {
-			// align first run to 09:00 today or tomorrow if past
-			now := time.Now()
-			first := time.Date(now.Year(), now.Month(), now.Day(), 9, 0, 0, 0, now.Location())
-			if now.After(first) {
-				first = first.Add(24 * time.Hour)
-			}
-			time.Sleep(time.Until(first))
-			return jr.SendOfferReminders(c)
-		})
+		// Offer expiry reminders daily at 09:00
+		registerCron(s, "offer_reminders", "OFFER_REMINDER_SCHEDULE", "0 9 * * *", jr.SendOfferReminders)
+
+		// Offer expiry once valid_until passes, hourly
+		s.Register("offer_expiry", time.Hour, true, jr.ExpireOffers)
+
+		// Auto-renewing offers cloned into drafts hourly
+		s.Register("renew_offers", time.Hour, true, jr.RenewOffers)
 
 		// Missed heartbeat detection every 2 minutes
 		s.Register("heartbeat_check", 2*time.Minute, true, jr.CheckHeartbeats)
+
+		// Overdue invoice detection and payment reminders hourly
+		s.Register("invoice_overdue", time.Hour, true, jr.ProcessInvoices)
+
+		// Retainer subscription invoicing hourly
+		s.Register("subscription_billing", time.Hour, true, jr.BillSubscriptions)
+
+		// Monthly report emails to opted-in clients, hourly from MONTHLY_REPORT_DAY
+		s.Register("monthly_report_delivery", time.Hour, true, jr.DeliverMonthlyReports)
 	}()
 
 	return &serverEngineWrapper{engine: r, port: port}, nil
 }
 
+// registerCron schedules a task on the cron expression in env, falling back
+// to def when it is unset or invalid.
+func registerCron(s *scheduler.Scheduler, name, env, def string, runner func(context.Context) error) {
+	if spec := os.Getenv(env); spec != "" {
+		err := s.RegisterCron(name, spec, true, runner)
+		if err == nil {
+			return
+		}
+		log.Printf("%s: %v; using %q", env, err, def)
+	}
+	if err := s.RegisterCron(name, def, true, runner); err != nil {
+		log.Printf("scheduler: %v", err)
+	}
+}
+
 type serverEngineWrapper struct {
 	engine interface{ Run(...string) error }
 	port   string
@@ -39,9 +39,9 @@ func TestBuildAppWithDBMigrateError(t *testing.T) {
 	initDBFunc = func() error { return nil }
 	t.Cleanup(func() { initDBFunc = oldInit })
 
-	oldMig := autoMigrateFunc
-	autoMigrateFunc = func(...interface{}) error { return errors.New("migrate fail") }
-	t.Cleanup(func() { autoMigrateFunc = oldMig })
+	oldMig := migrateFunc
+	migrateFunc = func(*gorm.DB) error { return errors.New("migrate fail") }
+	t.Cleanup(func() { migrateFunc = oldMig })
 
 	_, err := BuildAppWithDB(nil)
 	if err == nil {
@@ -1,11 +1,12 @@
 package main
 
 import (
-	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"freelance-monitor-system/internal/models"
@@ -13,9 +14,10 @@ import (
 )
 
 func main() {
-	// Ensure template path is set explicitly
-	tpl := filepath.Clean(filepath.Join("..", "PENAWARAN PT EMICO MITRA SAMUDERA_25-09-2025.pdf"))
-	os.Setenv("PDF_TEMPLATE_PATH", tpl)
+	tpl := flag.String("template", os.Getenv("PDF_TEMPLATE_PATH"), "JSON letterhead/font template (see README)")
+	items := flag.Int("items", 3, "number of line items, to exercise pagination")
+	flag.Parse()
+	os.Setenv("PDF_TEMPLATE_PATH", *tpl)
 
 	// Sample offer data for visual verification
 	offer := models.Offer{
@@ -24,16 +26,20 @@ func main() {
 		ClientID:    1,
 		Date:        time.Now(),
 		Subject:     "Penawaran Layanan Pemeliharaan",
-		TotalPrice:  7000000,
 		Notes:       "Harga sudah termasuk PPN. Masa berlaku penawaran 30 hari.",
 	}
-	items := []map[string]any{
-		{"description": "Jasa Pemeliharaan Sistem", "qty": 3, "unit_price": 1000000, "total": 3000000},
-		{"description": "Penggantian Komponen", "qty": 2, "unit_price": 1500000, "total": 3000000},
-		{"description": "Biaya Transportasi", "qty": 1, "unit_price": 1000000, "total": 1000000},
+	offer.ProposalDetails = strings.Repeat("Pekerjaan meliputi pemeriksaan berkala, pembaruan perangkat lunak dan laporan bulanan. ", 8)
+	samples := models.OfferItems{
+		{Description: "Jasa Pemeliharaan Sistem", Qty: 3, UnitPrice: 1000000},
+		{Description: "Penggantian Komponen", Qty: 2, UnitPrice: 1500000},
+		{Description: "Biaya Transportasi", Qty: 1, UnitPrice: 1000000},
+	}
+	for i := 0; i < *items; i++ {
+		offer.Items = append(offer.Items, samples[i%len(samples)])
+	}
+	if err := services.PriceOffer(&offer); err != nil {
+		log.Fatalf("invalid items: %v", err)
 	}
-	b, _ := json.Marshal(items)
-	offer.Items = string(b)
 
 	pdfSvc := services.NewPDFService()
 	url, err := pdfSvc.GenerateOfferPDFForOffer(&offer)
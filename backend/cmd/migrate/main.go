@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"freelance-monitor-system/internal/database"
+)
+
+const usage = `usage: migrate <command>
+
+commands:
+  status        show applied and pending migrations
+  up            apply all pending migrations
+  down          roll back the most recent migration
+  to <version>  migrate up or down to the given version (0 rolls back everything)`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err := database.InitDB(); err != nil {
+		log.Fatalf("init db: %v", err)
+	}
+	m, err := database.NewMigrator(database.DB)
+	if err != nil {
+		log.Fatalf("migrator: %v", err)
+	}
+	ctx := context.Background()
+
+	switch os.Args[1] {
+	case "status":
+		applied, err := m.Applied(ctx)
+		if err != nil {
+			log.Fatalf("status: %v", err)
+		}
+		for _, mg := range m.Migrations() {
+			state := "pending"
+			if a, ok := applied[mg.Version]; ok {
+				state = "applied " + a.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-28s %s\n", mg.Version, mg.Name, state)
+		}
+	case "up":
+		done, err := m.Up(ctx)
+		report("applied", done)
+		if err != nil {
+			log.Fatalf("up: %v", err)
+		}
+	case "down":
+		mg, err := m.Down(ctx)
+		if err != nil {
+			log.Fatalf("down: %v", err)
+		}
+		if mg == nil {
+			fmt.Println("nothing to roll back")
+			return
+		}
+		report("rolled back", []database.Migration{*mg})
+	case "to":
+		if len(os.Args) < 3 {
+			log.Fatal("to: version required")
+		}
+		v, err := strconv.Atoi(os.Args[2])
+		if err != nil {
+			log.Fatalf("to: invalid version %q", os.Args[2])
+		}
+		done, err := m.To(ctx, v)
+		report("migrated", done)
+		if err != nil {
+			log.Fatalf("to: %v", err)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func report(verb string, ms []database.Migration) {
+	if len(ms) == 0 {
+		fmt.Println("schema is up to date")
+		return
+	}
+	for _, mg := range ms {
+		fmt.Printf("%s %04d_%s\n", verb, mg.Version, mg.Name)
+	}
+}
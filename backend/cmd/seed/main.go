@@ -1,19 +1,22 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"time"
 
 	"freelance-monitor-system/internal/database"
 	"freelance-monitor-system/internal/models"
+	"freelance-monitor-system/internal/services"
 )
 
 func main() {
 	if err := database.InitDB(); err != nil {
 		log.Fatalf("init db: %v", err)
 	}
-	if err := database.AutoMigrate(&models.Client{}, &models.Service{}, &models.Offer{}, &models.UptimeLog{}, &models.Alert{}); err != nil {
+	// Seeding requires a current schema; SQLite (in-memory) is migrated here.
+	if err := database.EnsureSchema(context.Background(), database.DB, database.DB.Dialector.Name() == "sqlite"); err != nil {
 		log.Fatalf("migrate: %v", err)
 	}
 
@@ -35,8 +38,10 @@ func main() {
 
 	// Seed a sample offer
 	if first.ID != 0 {
-		off := models.Offer{ClientID: first.ID, Subject: "Website Redesign", Items: "[]", TotalPrice: 10000000, Date: time.Now()}
-		database.DB.Create(&off)
+		off := models.Offer{ClientID: first.ID, Subject: "Website Redesign", Items: models.OfferItems{{Description: "Website Redesign", Qty: 1, UnitPrice: 10000000}}, Date: time.Now()}
+		if err := services.NewOfferService(database.DB).CreateOffer(&off); err != nil {
+			log.Printf("seed offer: %v", err)
+		}
 	}
 
 	log.Println("Seed completed")
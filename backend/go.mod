@@ -5,11 +5,13 @@ go 1.23.0
 toolchain go1.24.5
 
 require (
-    github.com/gin-gonic/gin v1.11.0
-    github.com/golang-jwt/jwt/v5 v5.3.0
-    gorm.io/driver/postgres v1.6.0
-    gorm.io/driver/sqlite v1.6.0
-    gorm.io/gorm v1.30.0
+	github.com/gin-gonic/gin v1.11.0
+	github.com/golang-jwt/jwt/v5 v5.3.0
+	golang.org/x/crypto v0.40.0
+	golang.org/x/net v0.42.0
+	gorm.io/driver/postgres v1.6.0
+	gorm.io/driver/sqlite v1.6.0
+	gorm.io/gorm v1.30.0
 )
 
 require (
@@ -50,10 +52,8 @@ require (
 	github.com/ugorji/go/codec v1.3.0 // indirect
 	go.uber.org/mock v0.5.0 // indirect
 	golang.org/x/arch v0.20.0 // indirect
-	golang.org/x/crypto v0.40.0 // indirect
 	golang.org/x/image v0.21.0 // indirect
 	golang.org/x/mod v0.25.0 // indirect
-	golang.org/x/net v0.42.0 // indirect
 	golang.org/x/sync v0.16.0 // indirect
 	golang.org/x/sys v0.35.0 // indirect
 	golang.org/x/text v0.27.0 // indirect
@@ -0,0 +1,397 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migrations live in migrations/<dialect>/<version>_<name>.(up|down).sql.
+// Every version must exist for both postgres and sqlite.
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+var (
+	// ErrSchemaBehind is returned by EnsureSchema when migrations are pending.
+	ErrSchemaBehind = errors.New("database schema is behind")
+	// ErrIrreversible is returned when rolling back a migration whose down
+	// file has no statements, such as the baseline.
+	ErrIrreversible = errors.New("migration cannot be rolled back")
+	// ErrSchemaDrift is returned when a table a migration would create
+	// already exists without all of its columns.
+	ErrSchemaDrift = errors.New("existing table does not match the migration")
+)
+
+// Migration is one versioned schema change.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// SchemaMigration records an applied migration.
+type SchemaMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (SchemaMigration) TableName() string { return "schema_migrations" }
+
+// schemaMigrationsDDL is valid for both postgres and sqlite.
+const schemaMigrationsDDL = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version integer PRIMARY KEY,
+    name text NOT NULL,
+    applied_at timestamp NOT NULL
+)`
+
+// Migrator applies embedded migrations for the database's dialect.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// NewMigrator loads the migrations matching db's dialect (postgres or sqlite).
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	ms, err := LoadMigrations(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: ms}, nil
+}
+
+// LoadMigrations returns the embedded migrations for a dialect, ordered by version.
+func LoadMigrations(dialect string) ([]Migration, error) {
+	dir := path.Join("migrations", dialect)
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for dialect %q", dialect)
+	}
+	byVersion := map[int]*Migration{}
+	for _, e := range entries {
+		name := e.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+		base := strings.TrimSuffix(name, "."+direction+".sql")
+		verStr, label, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s: expected <version>_<name>", name)
+		}
+		ver, err := strconv.Atoi(verStr)
+		if err != nil || ver <= 0 {
+			return nil, fmt.Errorf("migration %s: invalid version", name)
+		}
+		body, err := migrationFiles.ReadFile(path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		m := byVersion[ver]
+		if m == nil {
+			m = &Migration{Version: ver, Name: label}
+			byVersion[ver] = m
+		} else if m.Name != label {
+			return nil, fmt.Errorf("migration version %d used by %q and %q", ver, m.Name, label)
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+	out := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s: both up and down files are required", m.Version, m.Name)
+		}
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+// Latest returns the highest known migration version.
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Migrations returns all known migrations in order.
+func (m *Migrator) Migrations() []Migration { return m.migrations }
+
+// Applied returns the applied migrations keyed by version.
+func (m *Migrator) Applied(ctx context.Context) (map[int]SchemaMigration, error) {
+	if err := m.db.WithContext(ctx).Exec(schemaMigrationsDDL).Error; err != nil {
+		return nil, err
+	}
+	var rows []SchemaMigration
+	if err := m.db.WithContext(ctx).Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[int]SchemaMigration, len(rows))
+	for _, r := range rows {
+		out[r.Version] = r
+	}
+	return out, nil
+}
+
+// Current returns the highest applied version (0 when none).
+func (m *Migrator) Current(ctx context.Context) (int, error) {
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	cur := 0
+	for v := range applied {
+		if v > cur {
+			cur = v
+		}
+	}
+	return cur, nil
+}
+
+// Pending returns migrations that have not been applied yet.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []Migration
+	for _, mg := range m.migrations {
+		if _, ok := applied[mg.Version]; !ok {
+			out = append(out, mg)
+		}
+	}
+	return out, nil
+}
+
+// Up applies all pending migrations.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	return m.To(ctx, m.Latest())
+}
+
+// Down rolls back the most recently applied migration.
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	cur, err := m.Current(ctx)
+	if err != nil || cur == 0 {
+		return nil, err
+	}
+	target := 0
+	for _, mg := range m.migrations {
+		if mg.Version < cur {
+			target = mg.Version
+		}
+	}
+	done, err := m.To(ctx, target)
+	if err != nil || len(done) == 0 {
+		return nil, err
+	}
+	return &done[0], nil
+}
+
+// To migrates up or down so that exactly the migrations <= version are applied.
+// It returns the migrations that were applied or rolled back, in execution order.
+// Nothing is rolled back when the range includes an irreversible migration.
+func (m *Migrator) To(ctx context.Context, version int) ([]Migration, error) {
+	if version < 0 || (version > 0 && m.find(version) == nil) {
+		return nil, fmt.Errorf("unknown migration version %d", version)
+	}
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, mg := range m.migrations {
+		if _, ok := applied[mg.Version]; ok && mg.Version > version && len(splitStatements(mg.Down)) == 0 {
+			return nil, fmt.Errorf("migration %04d_%s: %w", mg.Version, mg.Name, ErrIrreversible)
+		}
+	}
+	var done []Migration
+	for _, mg := range m.migrations {
+		if _, ok := applied[mg.Version]; ok || mg.Version > version {
+			continue
+		}
+		if err := m.verifyExisting(ctx, mg); err != nil {
+			return done, err
+		}
+		if err := m.apply(ctx, mg, true); err != nil {
+			return done, err
+		}
+		done = append(done, mg)
+	}
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mg := m.migrations[i]
+		if _, ok := applied[mg.Version]; !ok || mg.Version <= version {
+			continue
+		}
+		if err := m.apply(ctx, mg, false); err != nil {
+			return done, err
+		}
+		done = append(done, mg)
+	}
+	return done, nil
+}
+
+// EnsureSchema fails with ErrSchemaBehind if migrations are pending, unless
+// autoApply is set, in which case they are applied. Tables that already exist,
+// such as those of a database adopted by the baseline, must have every column
+// the migration would create; otherwise it fails with ErrSchemaDrift.
+func EnsureSchema(ctx context.Context, db *gorm.DB, autoApply bool) error {
+	m, err := NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	if autoApply {
+		_, err := m.Up(ctx)
+		return err
+	}
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		cur, _ := m.Current(ctx)
+		return fmt.Errorf("%w: at version %d, latest is %d; run `go run ./cmd/migrate up`", ErrSchemaBehind, cur, m.Latest())
+	}
+	return nil
+}
+
+// verifyExisting checks that every table mg creates with IF NOT EXISTS and
+// that is already there has all of the migration's columns, since the
+// migration would otherwise keep the drifted table as is.
+func (m *Migrator) verifyExisting(ctx context.Context, mg Migration) error {
+	mig := m.db.WithContext(ctx).Migrator()
+	var missing []string
+	for _, t := range createdTables(mg.Up) {
+		if !mig.HasTable(t.name) {
+			continue
+		}
+		for _, col := range t.columns {
+			if !mig.HasColumn(t.name, col) {
+				missing = append(missing, t.name+"."+col)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("migration %04d_%s: %w: missing %s", mg.Version, mg.Name, ErrSchemaDrift, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+type createdTable struct {
+	name    string
+	columns []string
+}
+
+// createdTables lists the tables and columns of the CREATE TABLE IF NOT
+// EXISTS statements in a migration, one column definition per line.
+func createdTables(sql string) []createdTable {
+	var out []createdTable
+	for _, stmt := range splitStatements(sql) {
+		lines := strings.Split(stmt, "\n")
+		head := strings.Fields(lines[0])
+		if len(head) < 6 || !strings.EqualFold(strings.Join(head[:5], " "), "CREATE TABLE IF NOT EXISTS") {
+			continue
+		}
+		t := createdTable{name: unquoteIdent(head[5])}
+		for _, line := range lines[1:] {
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				continue
+			}
+			switch strings.ToUpper(fields[0]) {
+			case "CONSTRAINT", "PRIMARY", "UNIQUE", "FOREIGN", "CHECK":
+				continue
+			}
+			t.columns = append(t.columns, unquoteIdent(fields[0]))
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+func unquoteIdent(s string) string {
+	return strings.Trim(s, "`\"(")
+}
+
+func (m *Migrator) find(version int) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+// apply runs one migration in a transaction together with its bookkeeping row.
+func (m *Migrator) apply(ctx context.Context, mg Migration, up bool) error {
+	sql := mg.Down
+	if up {
+		sql = mg.Up
+	}
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range splitStatements(sql) {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		if up {
+			return tx.Create(&SchemaMigration{Version: mg.Version, Name: mg.Name, AppliedAt: time.Now()}).Error
+		}
+		return tx.Where("version = ?", mg.Version).Delete(&SchemaMigration{}).Error
+	})
+	if err != nil {
+		dir := "down"
+		if up {
+			dir = "up"
+		}
+		return fmt.Errorf("migration %04d_%s %s: %w", mg.Version, mg.Name, dir, err)
+	}
+	return nil
+}
+
+// splitStatements splits a migration file on semicolons that end a line and
+// drops full-line "--" comments.
+func splitStatements(sql string) []string {
+	var out []string
+	var cur strings.Builder
+	for _, line := range strings.Split(sql, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		cur.WriteString(line)
+		cur.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			if s := strings.TrimSpace(cur.String()); s != ";" {
+				out = append(out, strings.TrimSuffix(s, ";"))
+			}
+			cur.Reset()
+		}
+	}
+	if s := strings.TrimSpace(cur.String()); s != "" {
+		out = append(out, s)
+	}
+	return out
+}
@@ -0,0 +1,353 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// migratedModels must cover every persisted model; the test below checks that
+// the migrations create a column for each of their fields.
+var migratedModels = []interface{}{
+	&models.Client{}, &models.Service{}, &models.Offer{}, &models.UptimeLog{}, &models.Alert{},
+	&models.User{}, &models.PasswordReset{}, &models.MonthlyReport{}, &models.DailyReport{},
+	&models.HeartbeatJob{}, &models.SLOTarget{}, &models.ReportTemplate{}, &models.APIKey{},
+	&models.AuthEvent{}, &models.LoginThrottle{}, &models.AuditLog{}, &models.RateLimitCounter{},
+	&models.Invoice{}, &models.InvoiceItem{}, &models.InvoiceTax{}, &models.InvoicePayment{},
+	&models.Subscription{}, &models.OfferItem{}, &models.OfferStatusChange{}, &models.OfferRevision{}, &models.OfferLink{}, &models.OfferSignature{},
+	&models.NumberSequence{}, &models.ExchangeRate{},
+	&models.CatalogItem{}, &models.OfferTemplate{}, &models.OfferTemplateItem{},
+	&models.ReportDelivery{}, &models.ClientMonthlyReport{}, &models.TimeEntry{}, &models.TaskRun{}, &models.TaskLease{}, &models.TaskSetting{},
+}
+
+func TestLoadMigrations_DialectsInSync(t *testing.T) {
+	pg, err := LoadMigrations("postgres")
+	if err != nil {
+		t.Fatalf("postgres: %v", err)
+	}
+	lite, err := LoadMigrations("sqlite")
+	if err != nil {
+		t.Fatalf("sqlite: %v", err)
+	}
+	if len(pg) == 0 || len(pg) != len(lite) {
+		t.Fatalf("dialects out of sync: %d postgres vs %d sqlite", len(pg), len(lite))
+	}
+	for i := range pg {
+		if pg[i].Version != lite[i].Version || pg[i].Name != lite[i].Name {
+			t.Fatalf("migration %d differs: %04d_%s vs %04d_%s", i, pg[i].Version, pg[i].Name, lite[i].Version, lite[i].Name)
+		}
+		if len(splitStatements(pg[i].Up)) == 0 || (pg[i].Version > 1 && len(splitStatements(pg[i].Down)) == 0) {
+			t.Fatalf("postgres migration %04d has no statements", pg[i].Version)
+		}
+		if (len(splitStatements(pg[i].Down)) == 0) != (len(splitStatements(lite[i].Down)) == 0) {
+			t.Fatalf("migration %04d is irreversible in only one dialect", pg[i].Version)
+		}
+	}
+}
+
+func TestMigrator_UpDownAndSchemaCheck(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	ctx := context.Background()
+	if err := EnsureSchema(ctx, db, false); !errors.Is(err, ErrSchemaBehind) {
+		t.Fatalf("expected ErrSchemaBehind on empty db, got %v", err)
+	}
+	m, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("migrator: %v", err)
+	}
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if cur, _ := m.Current(ctx); cur != m.Latest() {
+		t.Fatalf("expected version %d, got %d", m.Latest(), cur)
+	}
+	if err := EnsureSchema(ctx, db, false); err != nil {
+		t.Fatalf("schema should be current: %v", err)
+	}
+
+	for _, mdl := range migratedModels {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(mdl); err != nil {
+			t.Fatalf("parse %T: %v", mdl, err)
+		}
+		if !db.Migrator().HasTable(mdl) {
+			t.Fatalf("table %s missing", stmt.Schema.Table)
+		}
+		for _, f := range stmt.Schema.Fields {
+			if f.DBName == "" || f.IgnoreMigration {
+				continue
+			}
+			if !db.Migrator().HasColumn(mdl, f.DBName) {
+				t.Fatalf("column %s.%s missing from migrations", stmt.Schema.Table, f.DBName)
+			}
+		}
+		for _, idx := range stmt.Schema.ParseIndexes() {
+			if !db.Migrator().HasIndex(mdl, idx.Name) {
+				t.Fatalf("index %s on %s missing from migrations", idx.Name, stmt.Schema.Table)
+			}
+		}
+	}
+
+	if mg, err := m.Down(ctx); err != nil || mg == nil || mg.Version != m.Latest() {
+		t.Fatalf("down: %+v %v", mg, err)
+	}
+	if _, err := m.To(ctx, 0); !errors.Is(err, ErrIrreversible) {
+		t.Fatalf("expected the baseline to be irreversible, got %v", err)
+	}
+	if cur, _ := m.Current(ctx); cur != m.Latest()-1 {
+		t.Fatalf("expected nothing rolled back by a refused migration, at %d", cur)
+	}
+	if _, err := m.To(ctx, 1); err != nil {
+		t.Fatalf("to 1: %v", err)
+	}
+	if db.Migrator().HasTable("invoices") || !db.Migrator().HasTable("clients") {
+		t.Fatalf("expected only the baseline tables left at version 1")
+	}
+	if _, err := m.To(ctx, 99999); err == nil {
+		t.Fatalf("expected unknown version error")
+	}
+	if err := EnsureSchema(ctx, db, true); err != nil {
+		t.Fatalf("auto apply: %v", err)
+	}
+}
+
+func TestMigrator_OfferItemsFromLegacyJSON(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	ctx := context.Background()
+	m, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("migrator: %v", err)
+	}
+	if _, err := m.To(ctx, 7); err != nil {
+		t.Fatalf("to 7: %v", err)
+	}
+	legacy := []struct {
+		items string
+		total float64
+	}{
+		{`[{"description":"Setup","qty":2,"unit_price":1000,"total":2000},{"description":"Support","qty":1,"total":500}]`, 1},
+		{`[{"name":"Hosting","description":"","quantity":3,"unitPrice":100,"total":300}]`, 300},
+		{`[]`, 750},
+		{`not json`, 0},
+	}
+	for i, l := range legacy {
+		if err := db.Exec("INSERT INTO offers (offer_number, client_id, date, subject, items, total_price) VALUES (?, 1, ?, ?, ?, ?)",
+			fmt.Sprintf("%03d/T", i+1), time.Now(), fmt.Sprintf("Offer %d", i+1), l.items, l.total).Error; err != nil {
+			t.Fatalf("insert legacy offer: %v", err)
+		}
+	}
+	if _, err := m.To(ctx, 8); err != nil {
+		t.Fatalf("to 8: %v", err)
+	}
+
+	var offers []models.Offer
+	if err := db.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).Order("id").Find(&offers).Error; err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(offers[0].Items) != 2 || offers[0].Items[1].UnitPrice != 500 || offers[0].TotalPrice != 2500 {
+		t.Fatalf("api-style items not migrated: %+v", offers[0])
+	}
+	if it := offers[1].Items; len(it) != 1 || it[0].Description != "Hosting" || it[0].Qty != 3 || it[0].Total != 300 {
+		t.Fatalf("form-style items not migrated: %+v", it)
+	}
+	if it := offers[2].Items; len(it) != 1 || it[0].Description != "Offer 3" || offers[2].TotalPrice != 750 {
+		t.Fatalf("expected a single line for an offer without items: %+v", offers[2])
+	}
+	if len(offers[3].Items) != 0 || offers[3].TotalPrice != 0 {
+		t.Fatalf("expected invalid JSON to migrate to no items: %+v", offers[3])
+	}
+
+	if _, err := m.To(ctx, 7); err != nil {
+		t.Fatalf("down to 7: %v", err)
+	}
+	var items string
+	db.Raw("SELECT items FROM offers WHERE id = ?", offers[1].ID).Scan(&items)
+	if !strings.Contains(items, `"description":"Hosting"`) || !strings.Contains(items, `"qty":3`) {
+		t.Fatalf("expected items JSON restored on rollback, got %s", items)
+	}
+}
+
+func TestMigrator_MonthlyReportDuplicatesMerged(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	ctx := context.Background()
+	m, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("migrator: %v", err)
+	}
+	if _, err := m.To(ctx, 15); err != nil {
+		t.Fatalf("to 15: %v", err)
+	}
+	feb := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	for _, svc := range []int{1, 1, 1, 2} {
+		if err := db.Exec("INSERT INTO monthly_reports (service_id, report_month) VALUES (?, ?)", svc, feb).Error; err != nil {
+			t.Fatalf("insert report: %v", err)
+		}
+	}
+	if err := db.Exec("INSERT INTO invoices (invoice_number, client_id, issue_date, due_date, monthly_report_id) VALUES ('INV-1', 1, ?, ?, 1)", feb, feb).Error; err != nil {
+		t.Fatalf("insert invoice: %v", err)
+	}
+	if _, err := m.To(ctx, 16); err != nil {
+		t.Fatalf("to 16: %v", err)
+	}
+
+	var ids []int
+	db.Raw("SELECT id FROM monthly_reports ORDER BY id").Scan(&ids)
+	if len(ids) != 2 || ids[0] != 3 || ids[1] != 4 {
+		t.Fatalf("expected the newest report per service and month to remain, got %v", ids)
+	}
+	var ref int
+	db.Raw("SELECT monthly_report_id FROM invoices").Scan(&ref)
+	if ref != 3 {
+		t.Fatalf("expected the invoice to point at the kept report, got %d", ref)
+	}
+	if err := db.Exec("INSERT INTO monthly_reports (service_id, report_month) VALUES (2, ?)", feb).Error; err == nil {
+		t.Fatal("expected a duplicate report to be rejected")
+	}
+}
+
+func TestMigrator_InvoiceSequencesSeeded(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	ctx := context.Background()
+	m, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("migrator: %v", err)
+	}
+	if _, err := m.To(ctx, 22); err != nil {
+		t.Fatalf("to 22: %v", err)
+	}
+	now := time.Now()
+	for _, n := range []string{"INV/2024/0007", "INV/2025/0002", "INV/2025/0011", "INV/2025/0003", "INV/2025/X1", "manual-9"} {
+		if err := db.Exec("INSERT INTO invoices (invoice_number, client_id, issue_date, due_date) VALUES (?, 1, ?, ?)", n, now, now).Error; err != nil {
+			t.Fatalf("insert invoice: %v", err)
+		}
+	}
+	if _, err := m.To(ctx, 23); err != nil {
+		t.Fatalf("to 23: %v", err)
+	}
+	var seqs []models.NumberSequence
+	db.Where("name LIKE ?", "invoice/%").Order("name").Find(&seqs)
+	if len(seqs) != 2 || seqs[0].Name != "invoice/2024" || seqs[0].Value != 7 || seqs[1].Name != "invoice/2025" || seqs[1].Value != 11 ||
+		seqs[1].Format != models.DefaultInvoiceNumberFormat {
+		t.Fatalf("unexpected invoice sequences: %+v", seqs)
+	}
+}
+
+func TestMigrator_LegacyOfferStatusesMapped(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	ctx := context.Background()
+	m, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("migrator: %v", err)
+	}
+	if _, err := m.To(ctx, 23); err != nil {
+		t.Fatalf("to 23: %v", err)
+	}
+	legacy := map[string]string{"Approved": "accepted", "declined": "rejected", "pending": "sent", " Sent ": "sent", "whatever": "draft", "viewed": "viewed"}
+	for from := range legacy {
+		if err := db.Exec("INSERT INTO offers (offer_number, client_id, date, subject, status, total_price) VALUES (?, 1, ?, 'x', ?, 0)", "N-"+from, time.Now(), from).Error; err != nil {
+			t.Fatalf("insert offer: %v", err)
+		}
+		if err := db.Exec("INSERT INTO offer_status_changes (offer_id, from_status, to_status) VALUES (1, '', ?)", from).Error; err != nil {
+			t.Fatalf("insert history: %v", err)
+		}
+	}
+	if _, err := m.To(ctx, 24); err != nil {
+		t.Fatalf("to 24: %v", err)
+	}
+	for from, want := range legacy {
+		var got string
+		db.Raw("SELECT status FROM offers WHERE offer_number = ?", "N-"+from).Scan(&got)
+		if got != want {
+			t.Errorf("status %q: got %q, want %q", from, got, want)
+		}
+	}
+	var invalid int64
+	db.Raw("SELECT COUNT(*) FROM offer_status_changes WHERE to_status NOT IN ('draft', 'sent', 'viewed', 'accepted', 'rejected')").Scan(&invalid)
+	if invalid != 0 {
+		t.Fatalf("expected the status history to be mapped too, %d rows left", invalid)
+	}
+}
+
+func TestMigrator_InvoiceOfferUnique(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	ctx := context.Background()
+	m, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("migrator: %v", err)
+	}
+	if _, err := m.To(ctx, 24); err != nil {
+		t.Fatalf("to 24: %v", err)
+	}
+	now := time.Now()
+	for _, inv := range []struct{ number, status string }{{"INV/2025/0001", "sent"}, {"INV/2025/0002", "draft"}, {"INV/2025/0003", "void"}} {
+		if err := db.Exec("INSERT INTO invoices (invoice_number, offer_id, client_id, status, issue_date, due_date) VALUES (?, 7, 1, ?, ?, ?)", inv.number, inv.status, now, now).Error; err != nil {
+			t.Fatalf("insert invoice: %v", err)
+		}
+	}
+	if _, err := m.To(ctx, 25); err != nil {
+		t.Fatalf("to 25: %v", err)
+	}
+	var status string
+	db.Raw("SELECT status FROM invoices WHERE invoice_number = 'INV/2025/0002'").Scan(&status)
+	if status != "void" {
+		t.Fatalf("expected the duplicate draft to be voided, got %q", status)
+	}
+	if err := db.Exec("INSERT INTO invoices (invoice_number, offer_id, client_id, status, issue_date, due_date) VALUES ('INV/2025/0004', 7, 1, 'draft', ?, ?)", now, now).Error; err == nil {
+		t.Fatal("expected a second active invoice for the offer to be rejected")
+	}
+	if err := db.Exec("INSERT INTO invoices (invoice_number, offer_id, client_id, status, issue_date, due_date) VALUES ('INV/2025/0005', 7, 1, 'void', ?, ?)", now, now).Error; err != nil {
+		t.Fatalf("expected void invoices to be exempt: %v", err)
+	}
+}
+
+func TestEnsureSchema_AdoptedTablesVerified(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	ctx := context.Background()
+	// An older AutoMigrate schema that never got the clients.address column.
+	if err := db.Exec("CREATE TABLE clients (id integer PRIMARY KEY AUTOINCREMENT, name text NOT NULL, contact_person text, email text, phone text, created_at datetime, updated_at datetime)").Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	err = EnsureSchema(ctx, db, true)
+	if !errors.Is(err, ErrSchemaDrift) || !strings.Contains(err.Error(), "clients.address") {
+		t.Fatalf("expected drift on clients.address, got %v", err)
+	}
+	m, _ := NewMigrator(db)
+	if cur, _ := m.Current(ctx); cur != 0 {
+		t.Fatalf("expected nothing applied, at %d", cur)
+	}
+
+	if err := db.Exec("ALTER TABLE clients ADD COLUMN address text").Error; err != nil {
+		t.Fatalf("alter: %v", err)
+	}
+	if err := EnsureSchema(ctx, db, true); err != nil {
+		t.Fatalf("expected the repaired schema to be adopted: %v", err)
+	}
+}
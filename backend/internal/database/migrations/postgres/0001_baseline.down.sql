@@ -0,0 +1,2 @@
+-- Irreversible: rolling the baseline back would drop every production table,
+-- so the migrator refuses to go below version 1.
@@ -0,0 +1,183 @@
+-- Baseline schema: tables previously created by AutoMigrate.
+
+CREATE TABLE IF NOT EXISTS clients (
+    id bigserial PRIMARY KEY,
+    name text NOT NULL,
+    contact_person text,
+    email text,
+    phone text,
+    address text,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS services (
+    id bigserial PRIMARY KEY,
+    user_id bigint,
+    client_id bigint NOT NULL,
+    domain text NOT NULL,
+    url text,
+    service_type text NOT NULL,
+    status text DEFAULT 'active',
+    last_check timestamptz,
+    ssl_expiry timestamptz,
+    domain_expiry timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_services_user_id ON services(user_id);
+
+CREATE TABLE IF NOT EXISTS offers (
+    id bigserial PRIMARY KEY,
+    offer_number text NOT NULL,
+    client_id bigint NOT NULL,
+    date timestamptz NOT NULL,
+    subject text NOT NULL,
+    items jsonb NOT NULL,
+    total_price decimal NOT NULL,
+    notes text,
+    status text DEFAULT 'draft',
+    pdf_url text,
+    signed_doc_url text,
+    approved_at timestamptz,
+    currency text DEFAULT 'IDR',
+    valid_until timestamptz,
+    issuer_name text,
+    issuer_company text,
+    issuer_address text,
+    issuer_city text,
+    issuer_phone text,
+    issuer_email text,
+    client_attention text,
+    offer_title text,
+    proposal_summary text,
+    proposal_details text,
+    payment_terms text,
+    closing_text text,
+    signature_title text,
+    signature_city text,
+    signature_company text,
+    auto_renew boolean DEFAULT false,
+    renew_every_days bigint DEFAULT 30,
+    next_renewal timestamptz,
+    last_reminder_at timestamptz,
+    created_at timestamptz,
+    CONSTRAINT uni_offers_offer_number UNIQUE (offer_number)
+);
+
+CREATE TABLE IF NOT EXISTS uptime_logs (
+    id bigserial PRIMARY KEY,
+    service_id bigint NOT NULL,
+    status text NOT NULL,
+    response_time bigint,
+    status_code bigint,
+    error_message text,
+    checked_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_uptime_logs_service_id ON uptime_logs(service_id);
+
+CREATE TABLE IF NOT EXISTS alerts (
+    id bigserial PRIMARY KEY,
+    service_id bigint,
+    alert_type text NOT NULL,
+    level text NOT NULL,
+    title text NOT NULL,
+    message text,
+    sent_via text,
+    is_resolved boolean DEFAULT false,
+    created_at timestamptz,
+    resolved_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_alerts_service_id ON alerts(service_id);
+
+CREATE TABLE IF NOT EXISTS users (
+    id bigserial PRIMARY KEY,
+    email text NOT NULL,
+    password_hash text NOT NULL,
+    avatar_url text,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users(email);
+
+CREATE TABLE IF NOT EXISTS password_resets (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    token text NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used_at timestamptz,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_password_resets_expires_at ON password_resets(expires_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_password_resets_token ON password_resets(token);
+CREATE INDEX IF NOT EXISTS idx_password_resets_user_id ON password_resets(user_id);
+
+CREATE TABLE IF NOT EXISTS monthly_reports (
+    id bigserial PRIMARY KEY,
+    report_month date,
+    service_id bigint NOT NULL,
+    user_id bigint,
+    avg_uptime_percent decimal,
+    avg_response_ms bigint,
+    total_downtime bigint,
+    alerts_opened bigint,
+    alerts_resolved bigint,
+    maintenance_hours decimal,
+    created_at timestamptz,
+    activities text,
+    summary text
+);
+CREATE INDEX IF NOT EXISTS idx_monthly_reports_user_id ON monthly_reports(user_id);
+CREATE INDEX IF NOT EXISTS idx_monthly_reports_service_id ON monthly_reports(service_id);
+CREATE INDEX IF NOT EXISTS idx_monthly_reports_report_month ON monthly_reports(report_month);
+
+CREATE TABLE IF NOT EXISTS daily_reports (
+    id bigserial PRIMARY KEY,
+    report_date date,
+    service_id bigint NOT NULL,
+    uptime_percent decimal,
+    avg_response_ms bigint,
+    downtime_count bigint,
+    alerts_opened bigint,
+    alerts_unresolved bigint,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_daily_reports_service_id ON daily_reports(service_id);
+CREATE INDEX IF NOT EXISTS idx_daily_reports_report_date ON daily_reports(report_date);
+
+CREATE TABLE IF NOT EXISTS heartbeat_jobs (
+    id bigserial PRIMARY KEY,
+    service_id bigint NOT NULL,
+    name text NOT NULL,
+    expected_interval_seconds bigint NOT NULL,
+    grace_seconds bigint DEFAULT 60,
+    token varchar(64),
+    last_heartbeat_at timestamptz,
+    is_paused boolean DEFAULT false,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS slo_targets (
+    id bigserial PRIMARY KEY,
+    service_id bigint NOT NULL,
+    objective text NOT NULL,
+    target decimal NOT NULL,
+    window_days bigint DEFAULT 30,
+    is_paused boolean DEFAULT false,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS report_templates (
+    id bigserial PRIMARY KEY,
+    name text NOT NULL,
+    kind text NOT NULL,
+    content text,
+    user_id bigint,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_report_templates_user_id ON report_templates(user_id);
+CREATE INDEX IF NOT EXISTS idx_report_templates_kind ON report_templates(kind);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_report_templates_name ON report_templates(name);
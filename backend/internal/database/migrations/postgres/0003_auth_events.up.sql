@@ -0,0 +1,25 @@
+-- Authentication audit trail and login throttling.
+
+CREATE TABLE IF NOT EXISTS auth_events (
+    id bigserial PRIMARY KEY,
+    user_id bigint,
+    email text,
+    ip text,
+    event text NOT NULL,
+    detail text,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_auth_events_created_at ON auth_events(created_at);
+CREATE INDEX IF NOT EXISTS idx_auth_events_event ON auth_events(event);
+CREATE INDEX IF NOT EXISTS idx_auth_events_email ON auth_events(email);
+CREATE INDEX IF NOT EXISTS idx_auth_events_user_id ON auth_events(user_id);
+
+CREATE TABLE IF NOT EXISTS login_throttles (
+    id bigserial PRIMARY KEY,
+    "key" text NOT NULL,
+    failures bigint,
+    last_failure_at timestamptz,
+    locked_until timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_login_throttles_key ON login_throttles("key");
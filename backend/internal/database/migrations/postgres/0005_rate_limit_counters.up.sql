@@ -0,0 +1,11 @@
+-- Shared counters for the database-backed rate limit store.
+
+CREATE TABLE IF NOT EXISTS rate_limit_counters (
+    id bigserial PRIMARY KEY,
+    "key" text NOT NULL,
+    window_start timestamptz NOT NULL,
+    "count" bigint,
+    expires_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_rate_limit_counters_expires_at ON rate_limit_counters(expires_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_rate_limit_key_window ON rate_limit_counters("key",window_start);
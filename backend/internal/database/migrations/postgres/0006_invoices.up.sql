@@ -0,0 +1,65 @@
+-- Invoices generated from accepted offers, with lines, taxes and payments.
+
+CREATE TABLE IF NOT EXISTS invoices (
+    id bigserial PRIMARY KEY,
+    invoice_number text NOT NULL,
+    offer_id bigint,
+    client_id bigint NOT NULL,
+    status text DEFAULT 'draft',
+    issue_date timestamptz NOT NULL,
+    due_date timestamptz NOT NULL,
+    currency text DEFAULT 'IDR',
+    subtotal decimal,
+    tax_total decimal,
+    total decimal,
+    amount_paid decimal,
+    payment_terms text,
+    notes text,
+    pdf_url text,
+    sent_at timestamptz,
+    paid_at timestamptz,
+    voided_at timestamptz,
+    last_reminder_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_invoices_due_date ON invoices(due_date);
+CREATE INDEX IF NOT EXISTS idx_invoices_status ON invoices(status);
+CREATE INDEX IF NOT EXISTS idx_invoices_client_id ON invoices(client_id);
+CREATE INDEX IF NOT EXISTS idx_invoices_offer_id ON invoices(offer_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invoices_invoice_number ON invoices(invoice_number);
+
+CREATE TABLE IF NOT EXISTS invoice_items (
+    id bigserial PRIMARY KEY,
+    invoice_id bigint NOT NULL,
+    "position" bigint,
+    description text NOT NULL,
+    qty decimal,
+    unit_price decimal,
+    total decimal,
+    CONSTRAINT fk_invoices_items FOREIGN KEY (invoice_id) REFERENCES invoices(id)
+);
+CREATE INDEX IF NOT EXISTS idx_invoice_items_invoice_id ON invoice_items(invoice_id);
+
+CREATE TABLE IF NOT EXISTS invoice_taxes (
+    id bigserial PRIMARY KEY,
+    invoice_id bigint NOT NULL,
+    name text NOT NULL,
+    rate decimal,
+    amount decimal,
+    CONSTRAINT fk_invoices_taxes FOREIGN KEY (invoice_id) REFERENCES invoices(id)
+);
+CREATE INDEX IF NOT EXISTS idx_invoice_taxes_invoice_id ON invoice_taxes(invoice_id);
+
+CREATE TABLE IF NOT EXISTS invoice_payments (
+    id bigserial PRIMARY KEY,
+    invoice_id bigint NOT NULL,
+    amount decimal NOT NULL,
+    paid_at timestamptz NOT NULL,
+    method text,
+    reference text,
+    note text,
+    created_at timestamptz,
+    CONSTRAINT fk_invoices_payments FOREIGN KEY (invoice_id) REFERENCES invoices(id)
+);
+CREATE INDEX IF NOT EXISTS idx_invoice_payments_invoice_id ON invoice_payments(invoice_id);
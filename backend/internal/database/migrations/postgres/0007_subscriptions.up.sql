@@ -0,0 +1,32 @@
+-- Recurring retainer subscriptions and their cycle invoices.
+
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id bigserial PRIMARY KEY,
+    client_id bigint NOT NULL,
+    service_id bigint,
+    name text NOT NULL,
+    amount decimal NOT NULL,
+    currency text DEFAULT 'IDR',
+    cycle text DEFAULT 'monthly',
+    start_date timestamptz NOT NULL,
+    end_date timestamptz,
+    status text DEFAULT 'active',
+    payment_terms text,
+    tax_name text,
+    tax_rate decimal,
+    next_period_start timestamptz,
+    proration_adjustment decimal,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_next_period_start ON subscriptions(next_period_start);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_status ON subscriptions(status);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_service_id ON subscriptions(service_id);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_client_id ON subscriptions(client_id);
+
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS subscription_id bigint;
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS period_start timestamptz;
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS period_end timestamptz;
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS monthly_report_id bigint;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invoice_subscription_period ON invoices(subscription_id, period_start);
+CREATE INDEX IF NOT EXISTS idx_invoices_monthly_report_id ON invoices(monthly_report_id);
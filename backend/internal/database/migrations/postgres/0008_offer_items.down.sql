@@ -0,0 +1,9 @@
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS items jsonb NOT NULL DEFAULT '[]'::jsonb;
+UPDATE offers SET items = COALESCE((
+    SELECT jsonb_agg(jsonb_build_object('description', i.description, 'qty', i.qty, 'unit_price', i.unit_price, 'total', i.total) ORDER BY i."position")
+    FROM offer_items i WHERE i.offer_id = offers.id
+), '[]'::jsonb);
+ALTER TABLE offers DROP COLUMN IF EXISTS tax_total;
+ALTER TABLE offers DROP COLUMN IF EXISTS discount_total;
+ALTER TABLE offers DROP COLUMN IF EXISTS subtotal;
+DROP TABLE IF EXISTS offer_items;
@@ -0,0 +1,61 @@
+-- Line items move from offers.items JSON into offer_items; totals are computed server-side.
+
+CREATE TABLE IF NOT EXISTS offer_items (
+    id bigserial PRIMARY KEY,
+    offer_id bigint NOT NULL,
+    "position" bigint,
+    name text,
+    description text NOT NULL,
+    qty decimal NOT NULL,
+    unit text,
+    unit_price decimal NOT NULL,
+    discount_percent decimal,
+    tax_rate decimal,
+    subtotal decimal,
+    discount_amount decimal,
+    tax_amount decimal,
+    total decimal,
+    CONSTRAINT fk_offers_items FOREIGN KEY (offer_id) REFERENCES offers(id)
+);
+CREATE INDEX IF NOT EXISTS idx_offer_items_offer_id ON offer_items(offer_id);
+
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS subtotal decimal;
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS discount_total decimal;
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS tax_total decimal;
+
+-- Legacy items used either {description, qty, unit_price, total} (API) or
+-- {name, description, quantity, unitPrice, total} (web form).
+INSERT INTO offer_items (offer_id, "position", name, description, qty, unit, unit_price, discount_percent, tax_rate, subtotal, discount_amount, tax_amount, total)
+SELECT offer_id, "position", name, description, qty, unit, price, 0, 0, round(qty * price, 2), 0, 0, round(qty * price, 2)
+FROM (
+    SELECT offer_id, "position", name, description, unit, qty,
+        COALESCE(unit_price, line_total / NULLIF(qty, 0), 0) AS price
+    FROM (
+        SELECT o.id AS offer_id,
+            e.ord AS "position",
+            COALESCE(e.value->>'name', '') AS name,
+            COALESCE(NULLIF(TRIM(e.value->>'description'), ''), NULLIF(TRIM(e.value->>'name'), ''), 'Item') AS description,
+            COALESCE(e.value->>'unit', '') AS unit,
+            COALESCE((CASE WHEN e.value->>'qty' ~ '^\s*-?[0-9]+(\.[0-9]+)?\s*$' THEN (e.value->>'qty')::decimal END), (CASE WHEN e.value->>'quantity' ~ '^\s*-?[0-9]+(\.[0-9]+)?\s*$' THEN (e.value->>'quantity')::decimal END), 1) AS qty,
+            COALESCE((CASE WHEN e.value->>'unit_price' ~ '^\s*-?[0-9]+(\.[0-9]+)?\s*$' THEN (e.value->>'unit_price')::decimal END), (CASE WHEN e.value->>'unitPrice' ~ '^\s*-?[0-9]+(\.[0-9]+)?\s*$' THEN (e.value->>'unitPrice')::decimal END)) AS unit_price,
+            (CASE WHEN e.value->>'total' ~ '^\s*-?[0-9]+(\.[0-9]+)?\s*$' THEN (e.value->>'total')::decimal END) AS line_total
+        FROM offers o
+        CROSS JOIN LATERAL jsonb_array_elements(CASE WHEN jsonb_typeof(o.items) = 'array' THEN o.items ELSE '[]'::jsonb END) WITH ORDINALITY AS e(value, ord)
+        WHERE jsonb_typeof(e.value) = 'object'
+            AND NOT EXISTS (SELECT 1 FROM offer_items i WHERE i.offer_id = o.id)
+    ) parsed
+) legacy;
+
+-- Offers without items become a single line for their previous total.
+INSERT INTO offer_items (offer_id, "position", name, description, qty, unit, unit_price, discount_percent, tax_rate, subtotal, discount_amount, tax_amount, total)
+SELECT o.id, 1, '', COALESCE(NULLIF(TRIM(o.subject), ''), 'Item'), 1, '', o.total_price, 0, 0, o.total_price, 0, 0, o.total_price
+FROM offers o
+WHERE o.total_price > 0 AND NOT EXISTS (SELECT 1 FROM offer_items i WHERE i.offer_id = o.id);
+
+UPDATE offers SET
+    subtotal = COALESCE((SELECT SUM(i.subtotal) FROM offer_items i WHERE i.offer_id = offers.id), 0),
+    discount_total = 0,
+    tax_total = 0,
+    total_price = COALESCE((SELECT SUM(i.total) FROM offer_items i WHERE i.offer_id = offers.id), 0);
+
+ALTER TABLE offers DROP COLUMN IF EXISTS items;
@@ -0,0 +1,19 @@
+-- Offer status history; statuses follow a fixed state machine.
+
+CREATE TABLE IF NOT EXISTS offer_status_changes (
+    id bigserial PRIMARY KEY,
+    offer_id bigint NOT NULL,
+    from_status text,
+    to_status text NOT NULL,
+    actor_id bigint,
+    note text,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_offer_status_changes_created_at ON offer_status_changes(created_at);
+CREATE INDEX IF NOT EXISTS idx_offer_status_changes_offer_id ON offer_status_changes(offer_id);
+
+-- Seed history with each existing offer's current status.
+INSERT INTO offer_status_changes (offer_id, from_status, to_status, note, created_at)
+SELECT o.id, '', o.status, 'backfilled', COALESCE(o.created_at, NOW())
+FROM offers o
+WHERE NOT EXISTS (SELECT 1 FROM offer_status_changes h WHERE h.offer_id = o.id);
@@ -0,0 +1,3 @@
+ALTER TABLE offers DROP COLUMN IF EXISTS accepted_revision;
+ALTER TABLE offers DROP COLUMN IF EXISTS revision;
+DROP TABLE IF EXISTS offer_revisions;
@@ -0,0 +1,19 @@
+-- Immutable offer revisions snapshotted on each send.
+
+CREATE TABLE IF NOT EXISTS offer_revisions (
+    id bigserial PRIMARY KEY,
+    offer_id bigint NOT NULL,
+    revision bigint NOT NULL,
+    offer_number text NOT NULL,
+    total_price decimal,
+    snapshot text NOT NULL,
+    pdf_url text,
+    actor_id bigint,
+    sent_at timestamptz,
+    accepted_at timestamptz,
+    created_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_offer_revision ON offer_revisions(offer_id,revision);
+
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS revision bigint DEFAULT 0;
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS accepted_revision bigint;
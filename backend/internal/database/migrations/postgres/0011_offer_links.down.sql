@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS offer_signatures;
+DROP TABLE IF EXISTS offer_links;
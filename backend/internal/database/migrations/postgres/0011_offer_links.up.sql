@@ -0,0 +1,31 @@
+-- Public offer links emailed to clients and their online decisions.
+
+CREATE TABLE IF NOT EXISTS offer_links (
+    id bigserial PRIMARY KEY,
+    offer_id bigint NOT NULL,
+    revision bigint,
+    token_hash text NOT NULL,
+    email text,
+    expires_at timestamptz NOT NULL,
+    views bigint,
+    first_viewed_at timestamptz,
+    last_viewed_at timestamptz,
+    created_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_offer_links_token_hash ON offer_links(token_hash);
+CREATE INDEX IF NOT EXISTS idx_offer_links_offer_id ON offer_links(offer_id);
+
+CREATE TABLE IF NOT EXISTS offer_signatures (
+    id bigserial PRIMARY KEY,
+    offer_id bigint NOT NULL,
+    link_id bigint NOT NULL,
+    revision bigint,
+    decision text NOT NULL,
+    signer_name text,
+    reason text,
+    ip text,
+    user_agent text,
+    signed_at timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_offer_signatures_link_id ON offer_signatures(link_id);
+CREATE INDEX IF NOT EXISTS idx_offer_signatures_offer_id ON offer_signatures(offer_id);
@@ -0,0 +1,2 @@
+ALTER TABLE clients DROP COLUMN IF EXISTS code;
+DROP TABLE IF EXISTS number_sequences;
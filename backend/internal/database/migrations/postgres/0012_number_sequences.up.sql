@@ -0,0 +1,23 @@
+-- Per-workspace document number sequences; offers draw numbers from them.
+
+CREATE TABLE IF NOT EXISTS number_sequences (
+    id bigserial PRIMARY KEY,
+    workspace text NOT NULL,
+    name text NOT NULL,
+    format text NOT NULL,
+    reset_yearly boolean,
+    period bigint,
+    value bigint,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_number_sequence ON number_sequences(workspace,name);
+
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS code text;
+
+-- Continue after the highest existing offer number prefix (e.g. 038/MSI-...).
+INSERT INTO number_sequences (workspace, name, format, reset_yearly, period, value, updated_at)
+SELECT 'default', 'offer', '{seq:3}/MSI-{dd}/{mm}/{yyyy}', false, 0,
+       COALESCE(MAX(CAST(split_part(offer_number, '/', 1) AS bigint)), 0), NOW()
+FROM offers
+WHERE split_part(offer_number, '/', 1) ~ '^[0-9]{1,9}$'
+ON CONFLICT (workspace, name) DO NOTHING;
@@ -0,0 +1,4 @@
+ALTER TABLE invoices DROP COLUMN IF EXISTS exchange_rate;
+ALTER TABLE offers DROP COLUMN IF EXISTS exchange_rate_date;
+ALTER TABLE offers DROP COLUMN IF EXISTS exchange_rate;
+DROP TABLE IF EXISTS exchange_rates;
@@ -0,0 +1,20 @@
+-- Exchange rate table and per-offer/invoice rate snapshots.
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id bigserial PRIMARY KEY,
+    currency text NOT NULL,
+    date timestamptz NOT NULL,
+    rate decimal NOT NULL,
+    source text,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_exchange_rate_day ON exchange_rates(currency,date);
+
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS exchange_rate double precision;
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS exchange_rate_date timestamptz;
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS exchange_rate double precision;
+
+-- Existing documents were all in IDR, the default base currency.
+UPDATE offers SET exchange_rate = 1 WHERE COALESCE(currency, 'IDR') = 'IDR' AND status <> 'draft';
+UPDATE invoices SET exchange_rate = 1 WHERE COALESCE(currency, 'IDR') = 'IDR';
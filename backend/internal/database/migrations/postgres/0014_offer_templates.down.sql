@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_offer_items_catalog_item_id;
+ALTER TABLE offer_items DROP COLUMN IF EXISTS catalog_item_id;
+DROP TABLE IF EXISTS offer_template_items;
+DROP TABLE IF EXISTS offer_templates;
+DROP TABLE IF EXISTS catalog_items;
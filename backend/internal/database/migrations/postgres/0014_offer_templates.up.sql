@@ -0,0 +1,63 @@
+-- Product/service catalog and offer templates.
+
+CREATE TABLE IF NOT EXISTS catalog_items (
+    id bigserial PRIMARY KEY,
+    sku text,
+    name text NOT NULL,
+    description text,
+    unit text,
+    unit_price decimal NOT NULL,
+    currency text DEFAULT 'IDR',
+    tax_rate decimal,
+    active boolean,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_catalog_items_name ON catalog_items(name);
+CREATE INDEX IF NOT EXISTS idx_catalog_items_sku ON catalog_items(sku);
+
+CREATE TABLE IF NOT EXISTS offer_templates (
+    id bigserial PRIMARY KEY,
+    name text NOT NULL,
+    subject text,
+    offer_title text,
+    currency text,
+    valid_days bigint,
+    issuer_name text,
+    issuer_company text,
+    issuer_address text,
+    issuer_city text,
+    issuer_phone text,
+    issuer_email text,
+    proposal_summary text,
+    proposal_details text,
+    payment_terms text,
+    closing_text text,
+    notes text,
+    signature_title text,
+    signature_company text,
+    signature_city text,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_offer_templates_name ON offer_templates(name);
+
+CREATE TABLE IF NOT EXISTS offer_template_items (
+    id bigserial PRIMARY KEY,
+    template_id bigint NOT NULL,
+    "position" bigint,
+    catalog_item_id bigint,
+    name text,
+    description text,
+    qty decimal,
+    unit text,
+    unit_price decimal,
+    discount_percent decimal,
+    tax_rate decimal,
+    CONSTRAINT fk_offer_templates_items FOREIGN KEY (template_id) REFERENCES offer_templates(id)
+);
+CREATE INDEX IF NOT EXISTS idx_offer_template_items_catalog_item_id ON offer_template_items(catalog_item_id);
+CREATE INDEX IF NOT EXISTS idx_offer_template_items_template_id ON offer_template_items(template_id);
+
+ALTER TABLE offer_items ADD COLUMN IF NOT EXISTS catalog_item_id bigint;
+CREATE INDEX IF NOT EXISTS idx_offer_items_catalog_item_id ON offer_items(catalog_item_id);
@@ -0,0 +1,24 @@
+-- Automatic monthly report delivery log and per-client delivery settings.
+
+CREATE TABLE IF NOT EXISTS report_deliveries (
+    id bigserial PRIMARY KEY,
+    service_id bigint NOT NULL,
+    report_month date NOT NULL,
+    client_id bigint,
+    monthly_report_id bigint,
+    recipient text,
+    cc text,
+    status text NOT NULL,
+    attempts bigint,
+    last_error text,
+    last_attempt_at timestamptz,
+    sent_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_report_deliveries_status ON report_deliveries(status);
+CREATE INDEX IF NOT EXISTS idx_report_deliveries_client_id ON report_deliveries(client_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_report_delivery_month ON report_deliveries(service_id,report_month);
+
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS report_opt_in boolean DEFAULT false;
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS report_cc text;
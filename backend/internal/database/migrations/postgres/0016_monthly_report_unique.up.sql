@@ -0,0 +1,20 @@
+-- One monthly report per service and month, and a lock for sent reports.
+
+ALTER TABLE monthly_reports ADD COLUMN IF NOT EXISTS finalized_at timestamptz;
+
+-- Keep the newest of any duplicate reports and point references at it.
+UPDATE invoices SET monthly_report_id = (
+    SELECT MAX(m2.id) FROM monthly_reports m1
+    JOIN monthly_reports m2 ON m2.service_id = m1.service_id AND m2.report_month = m1.report_month
+    WHERE m1.id = invoices.monthly_report_id
+) WHERE monthly_report_id IS NOT NULL;
+UPDATE report_deliveries SET monthly_report_id = (
+    SELECT MAX(m2.id) FROM monthly_reports m1
+    JOIN monthly_reports m2 ON m2.service_id = m1.service_id AND m2.report_month = m1.report_month
+    WHERE m1.id = report_deliveries.monthly_report_id
+) WHERE monthly_report_id IS NOT NULL;
+DELETE FROM monthly_reports WHERE id NOT IN (
+    SELECT MAX(id) FROM monthly_reports GROUP BY service_id, report_month
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_monthly_report_month ON monthly_reports(service_id,report_month);
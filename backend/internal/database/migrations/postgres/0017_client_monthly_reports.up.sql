@@ -0,0 +1,23 @@
+-- Consolidated monthly reports per client.
+
+CREATE TABLE IF NOT EXISTS client_monthly_reports (
+    id bigserial PRIMARY KEY,
+    client_id bigint NOT NULL,
+    report_month date NOT NULL,
+    user_id bigint,
+    service_count bigint,
+    avg_uptime_percent decimal,
+    total_downtime bigint,
+    incidents bigint,
+    alerts_resolved bigint,
+    maintenance_hours decimal,
+    slos_met bigint,
+    slos_total bigint,
+    summary text,
+    services text,
+    finalized_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_client_monthly_reports_user_id ON client_monthly_reports(user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_client_report_month ON client_monthly_reports(client_id,report_month);
@@ -0,0 +1,22 @@
+-- time entries logged against clients and services
+
+CREATE TABLE IF NOT EXISTS time_entries (
+    id bigserial PRIMARY KEY,
+    user_id bigint,
+    client_id bigint NOT NULL,
+    service_id bigint,
+    date date NOT NULL,
+    minutes bigint NOT NULL,
+    description text NOT NULL,
+    billable boolean DEFAULT false,
+    rate decimal,
+    currency text DEFAULT 'IDR',
+    invoice_id bigint,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_time_entries_invoice_id ON time_entries(invoice_id);
+CREATE INDEX IF NOT EXISTS idx_time_entries_date ON time_entries(date);
+CREATE INDEX IF NOT EXISTS idx_time_entries_service_id ON time_entries(service_id);
+CREATE INDEX IF NOT EXISTS idx_time_entries_client_id ON time_entries(client_id);
+CREATE INDEX IF NOT EXISTS idx_time_entries_user_id ON time_entries(user_id);
@@ -0,0 +1,13 @@
+-- scheduler task run history
+
+CREATE TABLE IF NOT EXISTS task_runs (
+    id bigserial PRIMARY KEY,
+    task text NOT NULL,
+    triggered_by text NOT NULL,
+    status text NOT NULL,
+    started_at timestamptz NOT NULL,
+    finished_at timestamptz,
+    duration_ms bigint,
+    error text
+);
+CREATE INDEX IF NOT EXISTS idx_task_runs_task_started ON task_runs(task,started_at);
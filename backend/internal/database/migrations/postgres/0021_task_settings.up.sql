@@ -0,0 +1,12 @@
+-- scheduler task settings
+
+CREATE TABLE IF NOT EXISTS task_settings (
+    task text PRIMARY KEY,
+    enabled boolean,
+    schedule text NOT NULL,
+    timeout_seconds bigint,
+    max_retries bigint,
+    retry_backoff_seconds bigint,
+    alert_after_failures bigint,
+    updated_at timestamptz
+);
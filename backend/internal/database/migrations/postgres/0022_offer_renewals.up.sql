@@ -0,0 +1,4 @@
+-- Link auto-renewed offers to the offer they were cloned from.
+
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS renewed_from_id bigint;
+CREATE INDEX IF NOT EXISTS idx_offers_renewed_from_id ON offers(renewed_from_id);
@@ -0,0 +1 @@
+DELETE FROM number_sequences WHERE name LIKE 'invoice/%';
@@ -0,0 +1,10 @@
+-- Per-year invoice sequences (invoice/YYYY) continuing after the highest
+-- existing INV/YYYY/NNNN number of each year.
+
+INSERT INTO number_sequences (workspace, name, format, reset_yearly, period, value, updated_at)
+SELECT 'default', 'invoice/' || split_part(invoice_number, '/', 2), 'INV/{yyyy}/{seq:4}', false, CAST(split_part(invoice_number, '/', 2) AS bigint),
+       MAX(CAST(split_part(invoice_number, '/', 3) AS bigint)), NOW()
+FROM invoices
+WHERE invoice_number ~ '^INV/[0-9]{4}/[0-9]{1,9}$'
+GROUP BY split_part(invoice_number, '/', 2)
+ON CONFLICT (workspace, name) DO NOTHING;
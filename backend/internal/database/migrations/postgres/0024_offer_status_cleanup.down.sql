@@ -0,0 +1,2 @@
+-- The legacy statuses are not kept, so there is nothing to restore.
+SELECT 1;
@@ -0,0 +1,33 @@
+-- Map the free-form statuses offers had before the state machine (0009)
+-- onto valid ones: approved/signed become accepted, declined/cancelled
+-- rejected, pending/submitted sent, and anything unknown draft.
+
+UPDATE offers SET status = CASE
+    WHEN LOWER(TRIM(status)) IN ('draft', 'sent', 'viewed', 'accepted', 'rejected', 'expired', 'superseded') THEN LOWER(TRIM(status))
+    WHEN LOWER(TRIM(status)) IN ('approved', 'accept', 'signed', 'won', 'deal') THEN 'accepted'
+    WHEN LOWER(TRIM(status)) IN ('declined', 'reject', 'cancelled', 'canceled', 'lost') THEN 'rejected'
+    WHEN LOWER(TRIM(status)) IN ('pending', 'submitted', 'waiting', 'open') THEN 'sent'
+    WHEN LOWER(TRIM(status)) = 'expire' THEN 'expired'
+    ELSE 'draft'
+END
+WHERE status IS NULL OR status NOT IN ('draft', 'sent', 'viewed', 'accepted', 'rejected', 'expired', 'superseded');
+
+UPDATE offer_status_changes SET to_status = CASE
+    WHEN LOWER(TRIM(to_status)) IN ('draft', 'sent', 'viewed', 'accepted', 'rejected', 'expired', 'superseded') THEN LOWER(TRIM(to_status))
+    WHEN LOWER(TRIM(to_status)) IN ('approved', 'accept', 'signed', 'won', 'deal') THEN 'accepted'
+    WHEN LOWER(TRIM(to_status)) IN ('declined', 'reject', 'cancelled', 'canceled', 'lost') THEN 'rejected'
+    WHEN LOWER(TRIM(to_status)) IN ('pending', 'submitted', 'waiting', 'open') THEN 'sent'
+    WHEN LOWER(TRIM(to_status)) = 'expire' THEN 'expired'
+    ELSE 'draft'
+END
+WHERE to_status NOT IN ('draft', 'sent', 'viewed', 'accepted', 'rejected', 'expired', 'superseded');
+
+UPDATE offer_status_changes SET from_status = CASE
+    WHEN LOWER(TRIM(from_status)) IN ('draft', 'sent', 'viewed', 'accepted', 'rejected', 'expired', 'superseded') THEN LOWER(TRIM(from_status))
+    WHEN LOWER(TRIM(from_status)) IN ('approved', 'accept', 'signed', 'won', 'deal') THEN 'accepted'
+    WHEN LOWER(TRIM(from_status)) IN ('declined', 'reject', 'cancelled', 'canceled', 'lost') THEN 'rejected'
+    WHEN LOWER(TRIM(from_status)) IN ('pending', 'submitted', 'waiting', 'open') THEN 'sent'
+    WHEN LOWER(TRIM(from_status)) = 'expire' THEN 'expired'
+    ELSE 'draft'
+END
+WHERE from_status <> '' AND from_status NOT IN ('draft', 'sent', 'viewed', 'accepted', 'rejected', 'expired', 'superseded');
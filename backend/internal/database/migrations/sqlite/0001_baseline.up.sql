@@ -0,0 +1,183 @@
+-- Baseline schema: tables previously created by AutoMigrate.
+
+CREATE TABLE IF NOT EXISTS `clients` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `name` text NOT NULL,
+    `contact_person` text,
+    `email` text,
+    `phone` text,
+    `address` text,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+
+CREATE TABLE IF NOT EXISTS `services` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer,
+    `client_id` integer NOT NULL,
+    `domain` text NOT NULL,
+    `url` text,
+    `service_type` text NOT NULL,
+    `status` text DEFAULT 'active',
+    `last_check` datetime,
+    `ssl_expiry` datetime,
+    `domain_expiry` datetime,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_services_user_id` ON `services`(`user_id`);
+
+CREATE TABLE IF NOT EXISTS `offers` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `offer_number` text NOT NULL,
+    `client_id` integer NOT NULL,
+    `date` datetime NOT NULL,
+    `subject` text NOT NULL,
+    `items` text NOT NULL,
+    `total_price` real NOT NULL,
+    `notes` text,
+    `status` text DEFAULT 'draft',
+    `pdf_url` text,
+    `signed_doc_url` text,
+    `approved_at` datetime,
+    `currency` text DEFAULT 'IDR',
+    `valid_until` datetime,
+    `issuer_name` text,
+    `issuer_company` text,
+    `issuer_address` text,
+    `issuer_city` text,
+    `issuer_phone` text,
+    `issuer_email` text,
+    `client_attention` text,
+    `offer_title` text,
+    `proposal_summary` text,
+    `proposal_details` text,
+    `payment_terms` text,
+    `closing_text` text,
+    `signature_title` text,
+    `signature_city` text,
+    `signature_company` text,
+    `auto_renew` numeric DEFAULT false,
+    `renew_every_days` integer DEFAULT 30,
+    `next_renewal` datetime,
+    `last_reminder_at` datetime,
+    `created_at` datetime,
+    CONSTRAINT `uni_offers_offer_number` UNIQUE (`offer_number`)
+);
+
+CREATE TABLE IF NOT EXISTS `uptime_logs` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `service_id` integer NOT NULL,
+    `status` text NOT NULL,
+    `response_time` integer,
+    `status_code` integer,
+    `error_message` text,
+    `checked_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_uptime_logs_service_id` ON `uptime_logs`(`service_id`);
+
+CREATE TABLE IF NOT EXISTS `alerts` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `service_id` integer,
+    `alert_type` text NOT NULL,
+    `level` text NOT NULL,
+    `title` text NOT NULL,
+    `message` text,
+    `sent_via` text,
+    `is_resolved` numeric DEFAULT false,
+    `created_at` datetime,
+    `resolved_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_alerts_service_id` ON `alerts`(`service_id`);
+
+CREATE TABLE IF NOT EXISTS `users` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `email` text NOT NULL,
+    `password_hash` text NOT NULL,
+    `avatar_url` text,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_users_email` ON `users`(`email`);
+
+CREATE TABLE IF NOT EXISTS `password_resets` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer NOT NULL,
+    `token` text NOT NULL,
+    `expires_at` datetime NOT NULL,
+    `used_at` datetime,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_password_resets_expires_at` ON `password_resets`(`expires_at`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_password_resets_token` ON `password_resets`(`token`);
+CREATE INDEX IF NOT EXISTS `idx_password_resets_user_id` ON `password_resets`(`user_id`);
+
+CREATE TABLE IF NOT EXISTS `monthly_reports` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `report_month` date,
+    `service_id` integer NOT NULL,
+    `user_id` integer,
+    `avg_uptime_percent` real,
+    `avg_response_ms` integer,
+    `total_downtime` integer,
+    `alerts_opened` integer,
+    `alerts_resolved` integer,
+    `maintenance_hours` real,
+    `created_at` datetime,
+    `activities` text,
+    `summary` text
+);
+CREATE INDEX IF NOT EXISTS `idx_monthly_reports_user_id` ON `monthly_reports`(`user_id`);
+CREATE INDEX IF NOT EXISTS `idx_monthly_reports_service_id` ON `monthly_reports`(`service_id`);
+CREATE INDEX IF NOT EXISTS `idx_monthly_reports_report_month` ON `monthly_reports`(`report_month`);
+
+CREATE TABLE IF NOT EXISTS `daily_reports` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `report_date` date,
+    `service_id` integer NOT NULL,
+    `uptime_percent` real,
+    `avg_response_ms` integer,
+    `downtime_count` integer,
+    `alerts_opened` integer,
+    `alerts_unresolved` integer,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_daily_reports_service_id` ON `daily_reports`(`service_id`);
+CREATE INDEX IF NOT EXISTS `idx_daily_reports_report_date` ON `daily_reports`(`report_date`);
+
+CREATE TABLE IF NOT EXISTS `heartbeat_jobs` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `service_id` integer NOT NULL,
+    `name` text NOT NULL,
+    `expected_interval_seconds` integer NOT NULL,
+    `grace_seconds` integer DEFAULT 60,
+    `token` text,
+    `last_heartbeat_at` datetime,
+    `is_paused` numeric DEFAULT false,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+
+CREATE TABLE IF NOT EXISTS `slo_targets` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `service_id` integer NOT NULL,
+    `objective` text NOT NULL,
+    `target` real NOT NULL,
+    `window_days` integer DEFAULT 30,
+    `is_paused` numeric DEFAULT false,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+
+CREATE TABLE IF NOT EXISTS `report_templates` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `name` text NOT NULL,
+    `kind` text NOT NULL,
+    `content` text,
+    `user_id` integer,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_report_templates_user_id` ON `report_templates`(`user_id`);
+CREATE INDEX IF NOT EXISTS `idx_report_templates_kind` ON `report_templates`(`kind`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_report_templates_name` ON `report_templates`(`name`);
@@ -0,0 +1,17 @@
+-- API keys for automation and CI.
+
+CREATE TABLE IF NOT EXISTS `api_keys` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer NOT NULL,
+    `name` text NOT NULL,
+    `prefix` text NOT NULL,
+    `key_hash` text NOT NULL,
+    `scopes` text,
+    `expires_at` datetime,
+    `last_used_at` datetime,
+    `revoked_at` datetime,
+    `created_at` datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_api_keys_key_hash` ON `api_keys`(`key_hash`);
+CREATE INDEX IF NOT EXISTS `idx_api_keys_prefix` ON `api_keys`(`prefix`);
+CREATE INDEX IF NOT EXISTS `idx_api_keys_user_id` ON `api_keys`(`user_id`);
@@ -0,0 +1,25 @@
+-- Authentication audit trail and login throttling.
+
+CREATE TABLE IF NOT EXISTS `auth_events` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer,
+    `email` text,
+    `ip` text,
+    `event` text NOT NULL,
+    `detail` text,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_auth_events_created_at` ON `auth_events`(`created_at`);
+CREATE INDEX IF NOT EXISTS `idx_auth_events_event` ON `auth_events`(`event`);
+CREATE INDEX IF NOT EXISTS `idx_auth_events_email` ON `auth_events`(`email`);
+CREATE INDEX IF NOT EXISTS `idx_auth_events_user_id` ON `auth_events`(`user_id`);
+
+CREATE TABLE IF NOT EXISTS `login_throttles` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `key` text NOT NULL,
+    `failures` integer,
+    `last_failure_at` datetime,
+    `locked_until` datetime,
+    `updated_at` datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_login_throttles_key` ON `login_throttles`(`key`);
@@ -0,0 +1,19 @@
+-- Append-only audit log of mutating actions.
+
+CREATE TABLE IF NOT EXISTS `audit_logs` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `actor_id` integer,
+    `auth_method` text,
+    `action` text NOT NULL,
+    `entity_type` text NOT NULL,
+    `entity_id` integer,
+    `before` text,
+    `after` text,
+    `changes` text,
+    `ip` text,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_audit_logs_created_at` ON `audit_logs`(`created_at`);
+CREATE INDEX IF NOT EXISTS `idx_audit_entity` ON `audit_logs`(`entity_type`,`entity_id`);
+CREATE INDEX IF NOT EXISTS `idx_audit_logs_action` ON `audit_logs`(`action`);
+CREATE INDEX IF NOT EXISTS `idx_audit_logs_actor_id` ON `audit_logs`(`actor_id`);
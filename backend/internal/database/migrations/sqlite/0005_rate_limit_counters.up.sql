@@ -0,0 +1,11 @@
+-- Shared counters for the database-backed rate limit store.
+
+CREATE TABLE IF NOT EXISTS `rate_limit_counters` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `key` text NOT NULL,
+    `window_start` datetime NOT NULL,
+    `count` integer,
+    `expires_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_rate_limit_counters_expires_at` ON `rate_limit_counters`(`expires_at`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_rate_limit_key_window` ON `rate_limit_counters`(`key`,`window_start`);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS invoice_payments;
+DROP TABLE IF EXISTS invoice_taxes;
+DROP TABLE IF EXISTS invoice_items;
+DROP TABLE IF EXISTS invoices;
@@ -0,0 +1,65 @@
+-- Invoices generated from accepted offers, with lines, taxes and payments.
+
+CREATE TABLE IF NOT EXISTS `invoices` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `invoice_number` text NOT NULL,
+    `offer_id` integer,
+    `client_id` integer NOT NULL,
+    `status` text DEFAULT 'draft',
+    `issue_date` datetime NOT NULL,
+    `due_date` datetime NOT NULL,
+    `currency` text DEFAULT 'IDR',
+    `subtotal` real,
+    `tax_total` real,
+    `total` real,
+    `amount_paid` real,
+    `payment_terms` text,
+    `notes` text,
+    `pdf_url` text,
+    `sent_at` datetime,
+    `paid_at` datetime,
+    `voided_at` datetime,
+    `last_reminder_at` datetime,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_invoices_due_date` ON `invoices`(`due_date`);
+CREATE INDEX IF NOT EXISTS `idx_invoices_status` ON `invoices`(`status`);
+CREATE INDEX IF NOT EXISTS `idx_invoices_client_id` ON `invoices`(`client_id`);
+CREATE INDEX IF NOT EXISTS `idx_invoices_offer_id` ON `invoices`(`offer_id`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_invoices_invoice_number` ON `invoices`(`invoice_number`);
+
+CREATE TABLE IF NOT EXISTS `invoice_items` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `invoice_id` integer NOT NULL,
+    `position` integer,
+    `description` text NOT NULL,
+    `qty` real,
+    `unit_price` real,
+    `total` real,
+    CONSTRAINT `fk_invoices_items` FOREIGN KEY (`invoice_id`) REFERENCES `invoices`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_invoice_items_invoice_id` ON `invoice_items`(`invoice_id`);
+
+CREATE TABLE IF NOT EXISTS `invoice_taxes` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `invoice_id` integer NOT NULL,
+    `name` text NOT NULL,
+    `rate` real,
+    `amount` real,
+    CONSTRAINT `fk_invoices_taxes` FOREIGN KEY (`invoice_id`) REFERENCES `invoices`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_invoice_taxes_invoice_id` ON `invoice_taxes`(`invoice_id`);
+
+CREATE TABLE IF NOT EXISTS `invoice_payments` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `invoice_id` integer NOT NULL,
+    `amount` real NOT NULL,
+    `paid_at` datetime NOT NULL,
+    `method` text,
+    `reference` text,
+    `note` text,
+    `created_at` datetime,
+    CONSTRAINT `fk_invoices_payments` FOREIGN KEY (`invoice_id`) REFERENCES `invoices`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_invoice_payments_invoice_id` ON `invoice_payments`(`invoice_id`);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS `idx_invoices_monthly_report_id`;
+DROP INDEX IF EXISTS `idx_invoice_subscription_period`;
+ALTER TABLE `invoices` DROP COLUMN `monthly_report_id`;
+ALTER TABLE `invoices` DROP COLUMN `period_end`;
+ALTER TABLE `invoices` DROP COLUMN `period_start`;
+ALTER TABLE `invoices` DROP COLUMN `subscription_id`;
+DROP TABLE IF EXISTS subscriptions;
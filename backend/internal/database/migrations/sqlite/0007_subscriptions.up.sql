@@ -0,0 +1,32 @@
+-- Recurring retainer subscriptions and their cycle invoices.
+
+CREATE TABLE IF NOT EXISTS `subscriptions` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `client_id` integer NOT NULL,
+    `service_id` integer,
+    `name` text NOT NULL,
+    `amount` real NOT NULL,
+    `currency` text DEFAULT 'IDR',
+    `cycle` text DEFAULT 'monthly',
+    `start_date` datetime NOT NULL,
+    `end_date` datetime,
+    `status` text DEFAULT 'active',
+    `payment_terms` text,
+    `tax_name` text,
+    `tax_rate` real,
+    `next_period_start` datetime,
+    `proration_adjustment` real,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_subscriptions_next_period_start` ON `subscriptions`(`next_period_start`);
+CREATE INDEX IF NOT EXISTS `idx_subscriptions_status` ON `subscriptions`(`status`);
+CREATE INDEX IF NOT EXISTS `idx_subscriptions_service_id` ON `subscriptions`(`service_id`);
+CREATE INDEX IF NOT EXISTS `idx_subscriptions_client_id` ON `subscriptions`(`client_id`);
+
+ALTER TABLE `invoices` ADD COLUMN `subscription_id` integer;
+ALTER TABLE `invoices` ADD COLUMN `period_start` datetime;
+ALTER TABLE `invoices` ADD COLUMN `period_end` datetime;
+ALTER TABLE `invoices` ADD COLUMN `monthly_report_id` integer;
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_invoice_subscription_period` ON `invoices`(`subscription_id`,`period_start`);
+CREATE INDEX IF NOT EXISTS `idx_invoices_monthly_report_id` ON `invoices`(`monthly_report_id`);
@@ -0,0 +1,9 @@
+ALTER TABLE `offers` ADD COLUMN `items` text NOT NULL DEFAULT '[]';
+UPDATE `offers` SET `items` = COALESCE((
+    SELECT json_group_array(json_object('description', description, 'qty', qty, 'unit_price', unit_price, 'total', total))
+    FROM (SELECT * FROM `offer_items` i WHERE i.offer_id = offers.id ORDER BY position)
+), '[]');
+ALTER TABLE `offers` DROP COLUMN `tax_total`;
+ALTER TABLE `offers` DROP COLUMN `discount_total`;
+ALTER TABLE `offers` DROP COLUMN `subtotal`;
+DROP TABLE IF EXISTS offer_items;
@@ -0,0 +1,60 @@
+-- Line items move from offers.items JSON into offer_items; totals are computed server-side.
+
+CREATE TABLE IF NOT EXISTS `offer_items` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `offer_id` integer NOT NULL,
+    `position` integer,
+    `name` text,
+    `description` text NOT NULL,
+    `qty` real NOT NULL,
+    `unit` text,
+    `unit_price` real NOT NULL,
+    `discount_percent` real,
+    `tax_rate` real,
+    `subtotal` real,
+    `discount_amount` real,
+    `tax_amount` real,
+    `total` real,
+    CONSTRAINT `fk_offers_items` FOREIGN KEY (`offer_id`) REFERENCES `offers`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_offer_items_offer_id` ON `offer_items`(`offer_id`);
+
+ALTER TABLE `offers` ADD COLUMN `subtotal` real;
+ALTER TABLE `offers` ADD COLUMN `discount_total` real;
+ALTER TABLE `offers` ADD COLUMN `tax_total` real;
+
+-- Legacy items used either {description, qty, unit_price, total} (API) or
+-- {name, description, quantity, unitPrice, total} (web form).
+INSERT INTO `offer_items` (`offer_id`, `position`, `name`, `description`, `qty`, `unit`, `unit_price`, `discount_percent`, `tax_rate`, `subtotal`, `discount_amount`, `tax_amount`, `total`)
+SELECT offer_id, position, name, description, qty, unit, price, 0, 0, round(qty * price, 2), 0, 0, round(qty * price, 2)
+FROM (
+    SELECT offer_id, position, name, description, unit, qty,
+        COALESCE(unit_price, line_total / NULLIF(qty, 0), 0) AS price
+    FROM (
+        SELECT o.id AS offer_id,
+            CAST(e.key AS integer) + 1 AS position,
+            COALESCE(json_extract(e.value, '$.name'), '') AS name,
+            COALESCE(NULLIF(TRIM(json_extract(e.value, '$.description')), ''), NULLIF(TRIM(json_extract(e.value, '$.name')), ''), 'Item') AS description,
+            COALESCE(json_extract(e.value, '$.unit'), '') AS unit,
+            CAST(COALESCE(NULLIF(json_extract(e.value, '$.qty'), ''), NULLIF(json_extract(e.value, '$.quantity'), ''), 1) AS real) AS qty,
+            CAST(COALESCE(NULLIF(json_extract(e.value, '$.unit_price'), ''), NULLIF(json_extract(e.value, '$.unitPrice'), '')) AS real) AS unit_price,
+            CAST(NULLIF(json_extract(e.value, '$.total'), '') AS real) AS line_total
+        FROM `offers` o, json_each(CASE WHEN json_valid(o.items) AND json_type(o.items) = 'array' THEN o.items ELSE '[]' END) e
+        WHERE e.type = 'object'
+            AND NOT EXISTS (SELECT 1 FROM `offer_items` i WHERE i.offer_id = o.id)
+    ) parsed
+) legacy;
+
+-- Offers without items become a single line for their previous total.
+INSERT INTO `offer_items` (`offer_id`, `position`, `name`, `description`, `qty`, `unit`, `unit_price`, `discount_percent`, `tax_rate`, `subtotal`, `discount_amount`, `tax_amount`, `total`)
+SELECT o.id, 1, '', COALESCE(NULLIF(TRIM(o.subject), ''), 'Item'), 1, '', o.total_price, 0, 0, o.total_price, 0, 0, o.total_price
+FROM `offers` o
+WHERE o.total_price > 0 AND NOT EXISTS (SELECT 1 FROM `offer_items` i WHERE i.offer_id = o.id);
+
+UPDATE `offers` SET
+    `subtotal` = COALESCE((SELECT SUM(i.subtotal) FROM `offer_items` i WHERE i.offer_id = offers.id), 0),
+    `discount_total` = 0,
+    `tax_total` = 0,
+    `total_price` = COALESCE((SELECT SUM(i.total) FROM `offer_items` i WHERE i.offer_id = offers.id), 0);
+
+ALTER TABLE `offers` DROP COLUMN `items`;
@@ -0,0 +1,3 @@
+ALTER TABLE `offers` DROP COLUMN `accepted_revision`;
+ALTER TABLE `offers` DROP COLUMN `revision`;
+DROP TABLE IF EXISTS offer_revisions;
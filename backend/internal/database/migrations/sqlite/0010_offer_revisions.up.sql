@@ -0,0 +1,19 @@
+-- Immutable offer revisions snapshotted on each send.
+
+CREATE TABLE IF NOT EXISTS `offer_revisions` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `offer_id` integer NOT NULL,
+    `revision` integer NOT NULL,
+    `offer_number` text NOT NULL,
+    `total_price` real,
+    `snapshot` text NOT NULL,
+    `pdf_url` text,
+    `actor_id` integer,
+    `sent_at` datetime,
+    `accepted_at` datetime,
+    `created_at` datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_offer_revision` ON `offer_revisions`(`offer_id`,`revision`);
+
+ALTER TABLE `offers` ADD COLUMN `revision` integer DEFAULT 0;
+ALTER TABLE `offers` ADD COLUMN `accepted_revision` integer;
@@ -0,0 +1,31 @@
+-- Public offer links emailed to clients and their online decisions.
+
+CREATE TABLE IF NOT EXISTS `offer_links` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `offer_id` integer NOT NULL,
+    `revision` integer,
+    `token_hash` text NOT NULL,
+    `email` text,
+    `expires_at` datetime NOT NULL,
+    `views` integer,
+    `first_viewed_at` datetime,
+    `last_viewed_at` datetime,
+    `created_at` datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_offer_links_token_hash` ON `offer_links`(`token_hash`);
+CREATE INDEX IF NOT EXISTS `idx_offer_links_offer_id` ON `offer_links`(`offer_id`);
+
+CREATE TABLE IF NOT EXISTS `offer_signatures` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `offer_id` integer NOT NULL,
+    `link_id` integer NOT NULL,
+    `revision` integer,
+    `decision` text NOT NULL,
+    `signer_name` text,
+    `reason` text,
+    `ip` text,
+    `user_agent` text,
+    `signed_at` datetime NOT NULL
+);
+CREATE INDEX IF NOT EXISTS `idx_offer_signatures_link_id` ON `offer_signatures`(`link_id`);
+CREATE INDEX IF NOT EXISTS `idx_offer_signatures_offer_id` ON `offer_signatures`(`offer_id`);
@@ -0,0 +1,2 @@
+ALTER TABLE `clients` DROP COLUMN `code`;
+DROP TABLE IF EXISTS number_sequences;
@@ -0,0 +1,24 @@
+-- Per-workspace document number sequences; offers draw numbers from them.
+
+CREATE TABLE IF NOT EXISTS `number_sequences` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `workspace` text NOT NULL,
+    `name` text NOT NULL,
+    `format` text NOT NULL,
+    `reset_yearly` numeric,
+    `period` integer,
+    `value` integer,
+    `updated_at` datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_number_sequence` ON `number_sequences`(`workspace`,`name`);
+
+ALTER TABLE `clients` ADD COLUMN `code` text;
+
+-- Continue after the highest existing offer number prefix (e.g. 038/MSI-...).
+INSERT INTO `number_sequences` (`workspace`, `name`, `format`, `reset_yearly`, `period`, `value`, `updated_at`)
+SELECT 'default', 'offer', '{seq:3}/MSI-{dd}/{mm}/{yyyy}', 0, 0,
+       COALESCE(MAX(CAST(substr(`offer_number`, 1, instr(`offer_number`, '/') - 1) AS integer)), 0), CURRENT_TIMESTAMP
+FROM `offers`
+WHERE instr(`offer_number`, '/') > 1
+  AND substr(`offer_number`, 1, instr(`offer_number`, '/') - 1) NOT GLOB '*[^0-9]*'
+ON CONFLICT (`workspace`, `name`) DO NOTHING;
@@ -0,0 +1,20 @@
+-- Exchange rate table and per-offer/invoice rate snapshots.
+
+CREATE TABLE IF NOT EXISTS `exchange_rates` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `currency` text NOT NULL,
+    `date` datetime NOT NULL,
+    `rate` real NOT NULL,
+    `source` text,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_exchange_rate_day` ON `exchange_rates`(`currency`,`date`);
+
+ALTER TABLE `offers` ADD COLUMN `exchange_rate` real;
+ALTER TABLE `offers` ADD COLUMN `exchange_rate_date` datetime;
+ALTER TABLE `invoices` ADD COLUMN `exchange_rate` real;
+
+-- Existing documents were all in IDR, the default base currency.
+UPDATE `offers` SET `exchange_rate` = 1 WHERE COALESCE(`currency`, 'IDR') = 'IDR' AND `status` <> 'draft';
+UPDATE `invoices` SET `exchange_rate` = 1 WHERE COALESCE(`currency`, 'IDR') = 'IDR';
@@ -0,0 +1,63 @@
+-- Product/service catalog and offer templates.
+
+CREATE TABLE IF NOT EXISTS `catalog_items` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `sku` text,
+    `name` text NOT NULL,
+    `description` text,
+    `unit` text,
+    `unit_price` real NOT NULL,
+    `currency` text DEFAULT 'IDR',
+    `tax_rate` real,
+    `active` numeric,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_catalog_items_name` ON `catalog_items`(`name`);
+CREATE INDEX IF NOT EXISTS `idx_catalog_items_sku` ON `catalog_items`(`sku`);
+
+CREATE TABLE IF NOT EXISTS `offer_templates` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `name` text NOT NULL,
+    `subject` text,
+    `offer_title` text,
+    `currency` text,
+    `valid_days` integer,
+    `issuer_name` text,
+    `issuer_company` text,
+    `issuer_address` text,
+    `issuer_city` text,
+    `issuer_phone` text,
+    `issuer_email` text,
+    `proposal_summary` text,
+    `proposal_details` text,
+    `payment_terms` text,
+    `closing_text` text,
+    `notes` text,
+    `signature_title` text,
+    `signature_company` text,
+    `signature_city` text,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_offer_templates_name` ON `offer_templates`(`name`);
+
+CREATE TABLE IF NOT EXISTS `offer_template_items` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `template_id` integer NOT NULL,
+    `position` integer,
+    `catalog_item_id` integer,
+    `name` text,
+    `description` text,
+    `qty` real,
+    `unit` text,
+    `unit_price` real,
+    `discount_percent` real,
+    `tax_rate` real,
+    CONSTRAINT `fk_offer_templates_items` FOREIGN KEY (`template_id`) REFERENCES `offer_templates`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_offer_template_items_catalog_item_id` ON `offer_template_items`(`catalog_item_id`);
+CREATE INDEX IF NOT EXISTS `idx_offer_template_items_template_id` ON `offer_template_items`(`template_id`);
+
+ALTER TABLE `offer_items` ADD COLUMN `catalog_item_id` integer;
+CREATE INDEX IF NOT EXISTS `idx_offer_items_catalog_item_id` ON `offer_items`(`catalog_item_id`);
@@ -0,0 +1,3 @@
+ALTER TABLE `clients` DROP COLUMN `report_cc`;
+ALTER TABLE `clients` DROP COLUMN `report_opt_in`;
+DROP TABLE IF EXISTS report_deliveries;
@@ -0,0 +1,24 @@
+-- Automatic monthly report delivery log and per-client delivery settings.
+
+CREATE TABLE IF NOT EXISTS `report_deliveries` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `service_id` integer NOT NULL,
+    `report_month` date NOT NULL,
+    `client_id` integer,
+    `monthly_report_id` integer,
+    `recipient` text,
+    `cc` text,
+    `status` text NOT NULL,
+    `attempts` integer,
+    `last_error` text,
+    `last_attempt_at` datetime,
+    `sent_at` datetime,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_report_deliveries_status` ON `report_deliveries`(`status`);
+CREATE INDEX IF NOT EXISTS `idx_report_deliveries_client_id` ON `report_deliveries`(`client_id`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_report_delivery_month` ON `report_deliveries`(`service_id`,`report_month`);
+
+ALTER TABLE `clients` ADD COLUMN `report_opt_in` numeric DEFAULT false;
+ALTER TABLE `clients` ADD COLUMN `report_cc` text;
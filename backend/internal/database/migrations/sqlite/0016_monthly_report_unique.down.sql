@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS `idx_monthly_report_month`;
+ALTER TABLE `monthly_reports` DROP COLUMN `finalized_at`;
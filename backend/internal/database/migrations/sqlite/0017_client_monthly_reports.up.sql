@@ -0,0 +1,23 @@
+-- Consolidated monthly reports per client.
+
+CREATE TABLE IF NOT EXISTS `client_monthly_reports` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `client_id` integer NOT NULL,
+    `report_month` date NOT NULL,
+    `user_id` integer,
+    `service_count` integer,
+    `avg_uptime_percent` real,
+    `total_downtime` integer,
+    `incidents` integer,
+    `alerts_resolved` integer,
+    `maintenance_hours` real,
+    `slos_met` integer,
+    `slos_total` integer,
+    `summary` text,
+    `services` text,
+    `finalized_at` datetime,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_client_monthly_reports_user_id` ON `client_monthly_reports`(`user_id`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_client_report_month` ON `client_monthly_reports`(`client_id`,`report_month`);
@@ -0,0 +1,22 @@
+-- time entries logged against clients and services
+
+CREATE TABLE IF NOT EXISTS `time_entries` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer,
+    `client_id` integer NOT NULL,
+    `service_id` integer,
+    `date` date NOT NULL,
+    `minutes` integer NOT NULL,
+    `description` text NOT NULL,
+    `billable` numeric DEFAULT false,
+    `rate` real,
+    `currency` text DEFAULT 'IDR',
+    `invoice_id` integer,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_time_entries_invoice_id` ON `time_entries`(`invoice_id`);
+CREATE INDEX IF NOT EXISTS `idx_time_entries_date` ON `time_entries`(`date`);
+CREATE INDEX IF NOT EXISTS `idx_time_entries_service_id` ON `time_entries`(`service_id`);
+CREATE INDEX IF NOT EXISTS `idx_time_entries_client_id` ON `time_entries`(`client_id`);
+CREATE INDEX IF NOT EXISTS `idx_time_entries_user_id` ON `time_entries`(`user_id`);
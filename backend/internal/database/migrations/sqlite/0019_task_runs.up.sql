@@ -0,0 +1,13 @@
+-- scheduler task run history
+
+CREATE TABLE IF NOT EXISTS `task_runs` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `task` text NOT NULL,
+    `triggered_by` text NOT NULL,
+    `status` text NOT NULL,
+    `started_at` datetime NOT NULL,
+    `finished_at` datetime,
+    `duration_ms` integer,
+    `error` text
+);
+CREATE INDEX IF NOT EXISTS `idx_task_runs_task_started` ON `task_runs`(`task`,`started_at`);
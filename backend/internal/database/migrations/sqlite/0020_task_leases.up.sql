@@ -0,0 +1,8 @@
+-- scheduler task leases
+
+CREATE TABLE IF NOT EXISTS `task_leases` (
+    `task` text PRIMARY KEY,
+    `holder` text,
+    `acquired_at` datetime,
+    `expires_at` datetime NOT NULL
+);
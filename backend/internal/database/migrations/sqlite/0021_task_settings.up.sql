@@ -0,0 +1,12 @@
+-- scheduler task settings
+
+CREATE TABLE IF NOT EXISTS `task_settings` (
+    `task` text PRIMARY KEY,
+    `enabled` numeric,
+    `schedule` text NOT NULL,
+    `timeout_seconds` integer,
+    `max_retries` integer,
+    `retry_backoff_seconds` integer,
+    `alert_after_failures` integer,
+    `updated_at` datetime
+);
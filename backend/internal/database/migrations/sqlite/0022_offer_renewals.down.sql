@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS `idx_offers_renewed_from_id`;
+ALTER TABLE `offers` DROP COLUMN `renewed_from_id`;
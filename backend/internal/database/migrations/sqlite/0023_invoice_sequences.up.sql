@@ -0,0 +1,11 @@
+-- Per-year invoice sequences (invoice/YYYY) continuing after the highest
+-- existing INV/YYYY/NNNN number of each year.
+
+INSERT INTO `number_sequences` (`workspace`, `name`, `format`, `reset_yearly`, `period`, `value`, `updated_at`)
+SELECT 'default', 'invoice/' || substr(`invoice_number`, 5, 4), 'INV/{yyyy}/{seq:4}', 0, CAST(substr(`invoice_number`, 5, 4) AS integer),
+       MAX(CAST(substr(`invoice_number`, 10) AS integer)), CURRENT_TIMESTAMP
+FROM `invoices`
+WHERE `invoice_number` GLOB 'INV/[0-9][0-9][0-9][0-9]/[0-9]*'
+  AND substr(`invoice_number`, 10) NOT GLOB '*[^0-9]*'
+GROUP BY substr(`invoice_number`, 5, 4)
+ON CONFLICT (`workspace`, `name`) DO NOTHING;
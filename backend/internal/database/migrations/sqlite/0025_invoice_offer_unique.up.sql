@@ -0,0 +1,10 @@
+-- At most one invoice per offer that is not void.
+
+-- Void newer duplicate drafts so the index can be built; duplicates that were
+-- already sent or paid must be resolved by hand.
+UPDATE `invoices` SET `status` = 'void', `voided_at` = CURRENT_TIMESTAMP
+WHERE `status` = 'draft' AND `offer_id` IS NOT NULL AND `id` > (
+    SELECT MIN(i2.`id`) FROM `invoices` i2 WHERE i2.`offer_id` = `invoices`.`offer_id` AND i2.`status` <> 'void'
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_invoice_offer_active` ON `invoices`(`offer_id`) WHERE `status` <> 'void';
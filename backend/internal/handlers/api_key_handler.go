@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"freelance-monitor-system/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type APIKeyHandler struct{ svc *services.APIKeyService }
+
+func NewAPIKeyHandler(s *services.APIKeyService) *APIKeyHandler { return &APIKeyHandler{svc: s} }
+
+// List returns the current user's API keys (without secrets).
+func (h *APIKeyHandler) List(c *gin.Context) {
+	uid := c.GetInt("user_id")
+	if uid <= 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	items, err := h.svc.ListForUser(c.Request.Context(), uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": len(items), "available_scopes": services.APIKeyScopes})
+}
+
+type createAPIKeyInput struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	// ExpiresInDays is a convenience alternative to ExpiresAt.
+	ExpiresInDays int `json:"expires_in_days"`
+}
+
+// Create issues a new API key. The plaintext key is only returned in this response.
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	uid := c.GetInt("user_id")
+	if uid <= 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var in createAPIKeyInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	exp := in.ExpiresAt
+	if exp == nil && in.ExpiresInDays > 0 {
+		t := time.Now().Add(time.Duration(in.ExpiresInDays) * 24 * time.Hour)
+		exp = &t
+	}
+	key, raw, err := h.svc.Create(c.Request.Context(), uid, in.Name, in.Scopes, exp)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"key": raw, "api_key": key})
+}
+
+// Revoke disables an API key owned by the current user.
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	uid := c.GetInt("user_id")
+	if uid <= 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := h.svc.Revoke(c.Request.Context(), uid, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
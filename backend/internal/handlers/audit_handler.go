@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"freelance-monitor-system/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// auditSvc receives audit entries from mutating handlers; nil disables auditing.
+var auditSvc *services.AuditService
+
+// SetAuditService enables audit logging for mutating handlers.
+func SetAuditService(s *services.AuditService) { auditSvc = s }
+
+// recordAudit appends an audit entry for the current request. Errors are ignored
+// so that auditing never fails the user's request.
+func recordAudit(c *gin.Context, action, entityType string, entityID int, before, after interface{}) {
+	if auditSvc == nil {
+		return
+	}
+	_ = auditSvc.Record(c.Request.Context(), services.AuditEntry{
+		ActorID:    c.GetInt("user_id"),
+		AuthMethod: c.GetString("auth_method"),
+		IP:         c.ClientIP(),
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     before,
+		After:      after,
+	})
+}
+
+type AuditHandler struct{ svc *services.AuditService }
+
+func NewAuditHandler(s *services.AuditService) *AuditHandler { return &AuditHandler{svc: s} }
+
+// List returns audit entries filtered by entity_type, entity_id, actor_id, action, from, to.
+func (h *AuditHandler) List(c *gin.Context) {
+	f, ok := parseAuditFilter(c)
+	if !ok {
+		return
+	}
+	f.Limit = parseIntQuery(c, "limit")
+	if f.Limit <= 0 {
+		f.Limit = 50
+	}
+	f.Offset = parseIntQuery(c, "offset")
+	items, total, err := h.svc.List(c.Request.Context(), f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": total})
+}
+
+// Export streams matching audit entries as CSV.
+func (h *AuditHandler) Export(c *gin.Context) {
+	f, ok := parseAuditFilter(c)
+	if !ok {
+		return
+	}
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", "attachment; filename=\"audit-"+time.Now().Format("20060102")+".csv\"")
+	if err := h.svc.ExportCSV(c.Request.Context(), f, c.Writer); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+}
+
+// parseAuditFilter reads filters from the query; from/to accept YYYY-MM-DD or RFC3339.
+func parseAuditFilter(c *gin.Context) (services.AuditFilter, bool) {
+	f := services.AuditFilter{
+		EntityType: strings.TrimSpace(c.Query("entity_type")),
+		EntityID:   parseIntQuery(c, "entity_id"),
+		ActorID:    parseIntQuery(c, "actor_id"),
+		Action:     strings.TrimSpace(c.Query("action")),
+	}
+	for key, dst := range map[string]*time.Time{"from": &f.From, "to": &f.To} {
+		v := strings.TrimSpace(c.Query(key))
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			d, derr := time.Parse("2006-01-02", v)
+			if derr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + key + " (use YYYY-MM-DD or RFC3339)"})
+				return f, false
+			}
+			t = d
+			if key == "to" {
+				t = t.AddDate(0, 0, 1) // inclusive end date
+			}
+		}
+		*dst = t
+	}
+	return f, true
+}
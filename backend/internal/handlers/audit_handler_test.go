@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"freelance-monitor-system/internal/models"
+	"freelance-monitor-system/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestAuditRecordsClientMutations(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Client{}, &models.AuditLog{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	audit := services.NewAuditService(db)
+	SetAuditService(audit)
+	t.Cleanup(func() { SetAuditService(nil) })
+
+	h := NewClientHandler(services.NewClientService(db))
+	ah := NewAuditHandler(audit)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("user_id", 42); c.Next() })
+	r.POST("/api/clients", h.CreateClient)
+	r.PUT("/api/clients/:id", h.UpdateClient)
+	r.DELETE("/api/clients/:id", h.DeleteClient)
+	r.GET("/api/audit", ah.List)
+	r.GET("/api/audit/export", ah.Export)
+
+	for _, step := range []struct{ method, path, body string }{
+		{"POST", "/api/clients", `{"name":"Acme"}`},
+		{"PUT", "/api/clients/1", `{"name":"Acme Corp"}`},
+		{"DELETE", "/api/clients/1", ``},
+	} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(step.method, step.path, strings.NewReader(step.body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		if w.Code >= 300 {
+			t.Fatalf("%s %s: got %d", step.method, step.path, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/audit?entity_type=client&entity_id=1&actor_id=42", nil))
+	if w.Code != 200 || !strings.Contains(w.Body.String(), `"total":3`) {
+		t.Fatalf("expected 3 audit entries, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `Acme Corp`) {
+		t.Fatalf("expected update diff in audit entries: %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/audit/export?action=delete", nil))
+	if w.Code != 200 || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") || strings.Count(w.Body.String(), "\n") != 2 {
+		t.Fatalf("unexpected csv export %d: %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/audit?from=yesterday", nil))
+	if w.Code != 400 {
+		t.Fatalf("expected 400 for invalid from, got %d", w.Code)
+	}
+}
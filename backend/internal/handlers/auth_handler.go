@@ -10,7 +10,7 @@ import (
 )
 
 // DevTokenHandler issues a simple JWT for local development.
-// Not for production use.
+// Not for production use; only routed when DEV_TOKEN_ENABLED=true.
 func DevTokenHandler(c *gin.Context) {
 	secret := os.Getenv("JWT_SECRET")
 	if secret == "" {
@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"freelance-monitor-system/internal/services"
@@ -44,8 +46,11 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "email and password required"})
 		return
 	}
-	token, exp, err := h.svc.Login(c.Request.Context(), in.Email, in.Password)
+	token, exp, err := h.svc.LoginFrom(c.Request.Context(), in.Email, in.Password, c.ClientIP())
 	if err != nil {
+		if writeThrottled(c, err) {
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
@@ -73,8 +78,11 @@ func (h *AuthHandler) RequestReset(c *gin.Context) {
 		return
 	}
 	ttl := 30 // minutes
-	pr, err := h.svc.RequestPasswordReset(c.Request.Context(), in.Email, ttl)
+	pr, err := h.svc.RequestPasswordResetFrom(c.Request.Context(), in.Email, ttl, c.ClientIP())
 	if err != nil {
+		if writeThrottled(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -212,3 +220,42 @@ func (h *AuthHandler) UpdateAvatar(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"id": u.ID, "email": u.Email, "avatar_url": u.AvatarURL})
 }
+
+// writeThrottled responds 429 with Retry-After when err is a login/reset throttle.
+func writeThrottled(c *gin.Context, err error) bool {
+	var te *services.ThrottleError
+	if !errors.As(err, &te) {
+		return false
+	}
+	secs := int(te.RetryAfter.Round(time.Second) / time.Second)
+	if secs < 1 {
+		secs = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(secs))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": te.Error(), "retry_after": secs, "locked": te.Locked})
+	return true
+}
+
+// Events returns the authenticated user's auth audit trail (logins, lockouts, resets).
+func (h *AuthHandler) Events(c *gin.Context) {
+	id := c.GetInt("user_id")
+	if id <= 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	g := h.svc.Guard()
+	if g == nil {
+		c.JSON(http.StatusOK, gin.H{"items": []interface{}{}, "total": 0})
+		return
+	}
+	limit := parseIntQuery(c, "limit")
+	if limit <= 0 {
+		limit = 50
+	}
+	items, err := g.ListEvents(c.Request.Context(), id, limit, parseIntQuery(c, "offset"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": len(items)})
+}
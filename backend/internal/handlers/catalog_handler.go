@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"freelance-monitor-system/internal/models"
+	"freelance-monitor-system/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CatalogHandler struct {
+	catalog   *services.CatalogService
+	templates *services.OfferTemplateService
+}
+
+func NewCatalogHandler(catalog *services.CatalogService, templates *services.OfferTemplateService) *CatalogHandler {
+	return &CatalogHandler{catalog: catalog, templates: templates}
+}
+
+// ListItems returns catalog items filtered by q; inactive ones only with
+// include_inactive=true.
+func (h *CatalogHandler) ListItems(c *gin.Context) {
+	items, err := h.catalog.List(c.Request.Context(), services.CatalogFilter{
+		Q:               c.Query("q"),
+		IncludeInactive: c.Query("include_inactive") == "true",
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": len(items)})
+}
+
+// CreateItem adds a catalog item:
+// { sku, name, description, unit, unit_price, currency, tax_rate }.
+func (h *CatalogHandler) CreateItem(c *gin.Context) {
+	var body services.CatalogItemInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	item, err := h.catalog.Create(c.Request.Context(), body)
+	if err != nil {
+		writeCatalogError(c, err)
+		return
+	}
+	recordAudit(c, "create", "catalog_item", item.ID, nil, item)
+	c.JSON(http.StatusCreated, item)
+}
+
+// UpdateItem replaces a catalog item; { active: false } retires it.
+func (h *CatalogHandler) UpdateItem(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var body services.CatalogItemInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	before, _ := h.catalog.Get(c.Request.Context(), id)
+	item, err := h.catalog.Update(c.Request.Context(), id, body)
+	if err != nil {
+		writeCatalogError(c, err)
+		return
+	}
+	recordAudit(c, "update", "catalog_item", id, before, item)
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *CatalogHandler) DeleteItem(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	before, _ := h.catalog.Get(c.Request.Context(), id)
+	if err := h.catalog.Delete(c.Request.Context(), id); err != nil {
+		writeCatalogError(c, err)
+		return
+	}
+	recordAudit(c, "delete", "catalog_item", id, before, nil)
+	c.Status(http.StatusNoContent)
+}
+
+// ListTemplates returns offer templates with their lines.
+func (h *CatalogHandler) ListTemplates(c *gin.Context) {
+	rows, err := h.templates.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": rows, "total": len(rows)})
+}
+
+func (h *CatalogHandler) GetTemplate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	tpl, err := h.templates.Get(c.Request.Context(), id)
+	if err != nil {
+		writeCatalogError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tpl)
+}
+
+// CreateTemplate stores an offer template with the issuer, signature,
+// proposal and terms fields and default items.
+func (h *CatalogHandler) CreateTemplate(c *gin.Context) {
+	var body models.OfferTemplate
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.templates.Create(c.Request.Context(), &body); err != nil {
+		writeCatalogError(c, err)
+		return
+	}
+	recordAudit(c, "create", "offer_template", body.ID, nil, body)
+	c.JSON(http.StatusCreated, body)
+}
+
+// UpdateTemplate replaces a template, including its items.
+func (h *CatalogHandler) UpdateTemplate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var body models.OfferTemplate
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	before, _ := h.templates.Get(c.Request.Context(), id)
+	tpl, err := h.templates.Update(c.Request.Context(), id, &body)
+	if err != nil {
+		writeCatalogError(c, err)
+		return
+	}
+	recordAudit(c, "update", "offer_template", id, before, tpl)
+	c.JSON(http.StatusOK, tpl)
+}
+
+func (h *CatalogHandler) DeleteTemplate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	before, _ := h.templates.Get(c.Request.Context(), id)
+	if err := h.templates.Delete(c.Request.Context(), id); err != nil {
+		writeCatalogError(c, err)
+		return
+	}
+	recordAudit(c, "delete", "offer_template", id, before, nil)
+	c.Status(http.StatusNoContent)
+}
+
+// CreateOffer creates a draft offer from a template:
+// { client_id, subject, date, client_attention, currency, items, add_items }.
+func (h *CatalogHandler) CreateOffer(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var body services.OfferFromTemplateInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	offer, err := h.templates.CreateOffer(c.Request.Context(), id, body)
+	if err != nil {
+		writeCatalogError(c, err)
+		return
+	}
+	recordAudit(c, "create", "offer", offer.ID, nil, offer)
+	c.JSON(http.StatusCreated, offer)
+}
+
+func writeCatalogError(c *gin.Context, err error) {
+	var fe services.FieldErrors
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.As(err, &fe):
+		writeFieldErrors(c, fe)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
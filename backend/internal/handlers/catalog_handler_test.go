@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"freelance-monitor-system/internal/models"
+	"freelance-monitor-system/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestCatalogAndTemplateHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Offer{}, &models.OfferItem{}, &models.NumberSequence{}, &models.OfferStatusChange{},
+		&models.CatalogItem{}, &models.OfferTemplate{}, &models.OfferTemplateItem{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	h := NewCatalogHandler(services.NewCatalogService(db), services.NewOfferTemplateService(db))
+	r := gin.New()
+	r.GET("/api/catalog", h.ListItems)
+	r.POST("/api/catalog", h.CreateItem)
+	r.POST("/api/offer-templates", h.CreateTemplate)
+	r.GET("/api/offer-templates/:id", h.GetTemplate)
+	r.POST("/api/offer-templates/:id/offers", h.CreateOffer)
+
+	for _, step := range []struct {
+		method, path, body string
+		code               int
+		want               string
+	}{
+		{"POST", "/api/catalog", `{"name":"Domain renewal","unit":"year","unit_price":150000}`, 201, `"active":true`},
+		{"POST", "/api/catalog", `{"unit_price":1}`, 400, `"name":"is required"`},
+		{"GET", "/api/catalog?q=domain", ``, 200, `"total":1`},
+		{"POST", "/api/offer-templates", `{"name":"Domains","issuer_company":"MSI","payment_terms":"Net 7","items":[{"catalog_item_id":1,"qty":2}]}`, 201, `"name":"Domains"`},
+		{"GET", "/api/offer-templates/9", ``, 404, `not found`},
+		{"POST", "/api/offer-templates/1/offers", `{}`, 400, `"client_id":"is required"`},
+		{"POST", "/api/offer-templates/1/offers", `{"client_id":3,"add_items":[{"description":"Transfer","qty":1,"unit_price":50000}]}`, 201, `"total_price":350000`},
+	} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(step.method, step.path, strings.NewReader(step.body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		if w.Code != step.code || !strings.Contains(w.Body.String(), step.want) {
+			t.Fatalf("%s %s: got %d %s", step.method, step.path, w.Code, w.Body.String())
+		}
+	}
+}
@@ -61,9 +61,15 @@ func (h *ClientHandler) CreateClient(c *gin.Context) {
 		return
 	}
 	if err := h.service.CreateClient(&input); err != nil {
+		var fe services.FieldErrors
+		if errors.As(err, &fe) {
+			writeFieldErrors(c, fe)
+			return
+		}
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
+	recordAudit(c, "create", "client", input.ID, nil, input)
 	c.JSON(201, input)
 }
 
@@ -79,15 +85,22 @@ func (h *ClientHandler) UpdateClient(c *gin.Context) {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
+	before, _ := h.service.GetClientByID(c.Request.Context(), clientID)
 	client, err := h.service.UpdateClient(clientID, &updates)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.JSON(404, gin.H{"error": "Client not found"})
 			return
 		}
+		var fe services.FieldErrors
+		if errors.As(err, &fe) {
+			writeFieldErrors(c, fe)
+			return
+		}
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
+	recordAudit(c, "update", "client", clientID, before, client)
 	c.JSON(200, client)
 }
 
@@ -98,6 +111,7 @@ func (h *ClientHandler) DeleteClient(c *gin.Context) {
 		c.JSON(400, gin.H{"error": "Invalid client ID"})
 		return
 	}
+	before, _ := h.service.GetClientByID(c.Request.Context(), clientID)
 	if err := h.service.DeleteClient(clientID); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.JSON(404, gin.H{"error": "Client not found"})
@@ -106,5 +120,6 @@ func (h *ClientHandler) DeleteClient(c *gin.Context) {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
+	recordAudit(c, "delete", "client", clientID, before, nil)
 	c.Status(204)
 }
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"freelance-monitor-system/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ClientReportHandler serves consolidated per-client monthly reports.
+type ClientReportHandler struct {
+	svc *services.ClientReportService
+}
+
+func NewClientReportHandler(s *services.ClientReportService) *ClientReportHandler {
+	return &ClientReportHandler{svc: s}
+}
+
+// Generate builds (or returns the existing) report for a client and month:
+// { month: "YYYY-MM", summary }.
+func (h *ClientReportHandler) Generate(c *gin.Context) {
+	clientID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid client ID"})
+		return
+	}
+	var req struct {
+		Month   string `json:"month"`
+		Summary string `json:"summary"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Month) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "month is required"})
+		return
+	}
+	month, err := time.Parse("2006-01", strings.TrimSpace(req.Month))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month format (YYYY-MM)"})
+		return
+	}
+	ctx := c.Request.Context()
+	report, err := h.svc.Generate(ctx, clientID, month, c.GetInt("user_id"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Client not found"})
+			return
+		}
+		writeClientReportError(c, err)
+		return
+	}
+	if strings.TrimSpace(req.Summary) != "" {
+		if report, err = h.svc.UpdateSummary(ctx, report.ID, req.Summary); err != nil {
+			writeClientReportError(c, err)
+			return
+		}
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// List returns a client's consolidated reports, newest month first.
+func (h *ClientReportHandler) List(c *gin.Context) {
+	clientID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid client ID"})
+		return
+	}
+	items, err := h.svc.ListForUser(c.Request.Context(), c.GetInt("user_id"), clientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": len(items)})
+}
+
+func (h *ClientReportHandler) Get(c *gin.Context) {
+	if report, ok := h.owned(c); ok {
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+// Regenerate rebuilds the report from current data, keeping its summary.
+func (h *ClientReportHandler) Regenerate(c *gin.Context) {
+	report, ok := h.owned(c)
+	if !ok {
+		return
+	}
+	updated, err := h.svc.Regenerate(c.Request.Context(), report.ID)
+	if err != nil {
+		writeClientReportError(c, err)
+		return
+	}
+	recordAudit(c, "regenerate", "client_report", report.ID, report, updated)
+	c.JSON(http.StatusOK, updated)
+}
+
+// Finalize locks the report against regeneration and edits.
+func (h *ClientReportHandler) Finalize(c *gin.Context) {
+	report, ok := h.owned(c)
+	if !ok {
+		return
+	}
+	updated, err := h.svc.Finalize(c.Request.Context(), report.ID, time.Now())
+	if err != nil {
+		writeClientReportError(c, err)
+		return
+	}
+	recordAudit(c, "finalize", "client_report", report.ID, report, updated)
+	c.JSON(http.StatusOK, updated)
+}
+
+// PDF renders the report through ?template_id= or the owner's latest
+// "client_monthly" template.
+func (h *ClientReportHandler) PDF(c *gin.Context) {
+	templateID := 0
+	if v := strings.TrimSpace(c.Query("template_id")); v != "" {
+		var err error
+		if templateID, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+			return
+		}
+	}
+	report, ok := h.owned(c)
+	if !ok {
+		return
+	}
+	pdf, err := h.svc.RenderPDF(c.Request.Context(), report, templateID)
+	if err != nil {
+		writeClientReportError(c, err)
+		return
+	}
+	filename := fmt.Sprintf("client_report_%d_%s.pdf", report.ClientID, report.ReportMonth.Format("2006-01"))
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", filename))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+func (h *ClientReportHandler) owned(c *gin.Context) (*models.ClientMonthlyReport, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report ID"})
+		return nil, false
+	}
+	report, err := h.svc.GetForUser(c.Request.Context(), c.GetInt("user_id"), id)
+	if err != nil {
+		writeClientReportError(c, err)
+		return nil, false
+	}
+	return report, true
+}
+
+func writeClientReportError(c *gin.Context, err error) {
+	var fe services.FieldErrors
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Client report not found"})
+	case errors.Is(err, services.ErrReportFinalized), errors.Is(err, services.ErrClientReportOwned):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &fe):
+		writeFieldErrors(c, fe)
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"freelance-monitor-system/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ExchangeRateHandler struct {
+	rates   *services.ExchangeRateService
+	revenue *services.RevenueService
+}
+
+func NewExchangeRateHandler(rates *services.ExchangeRateService, revenue *services.RevenueService) *ExchangeRateHandler {
+	return &ExchangeRateHandler{rates: rates, revenue: revenue}
+}
+
+// List returns exchange rates, newest first, optionally filtered by currency.
+func (h *ExchangeRateHandler) List(c *gin.Context) {
+	rows, err := h.rates.List(c.Request.Context(), c.Query("currency"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": rows, "total": len(rows), "base_currency": services.BaseCurrency()})
+}
+
+// Set stores a rate. Body: { currency, date, rate } where rate is the base
+// currency value of one unit.
+func (h *ExchangeRateHandler) Set(c *gin.Context) {
+	var body services.ExchangeRateInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rate, err := h.rates.Set(c.Request.Context(), body)
+	if err != nil {
+		writeRateError(c, err)
+		return
+	}
+	recordAudit(c, "set", "exchange_rate", rate.ID, nil, rate)
+	c.JSON(http.StatusOK, rate)
+}
+
+// Import loads a CSV (currency,date,rate) sent as the "file" form field or as
+// the raw request body.
+func (h *ExchangeRateHandler) Import(c *gin.Context) {
+	var r io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fh, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+			return
+		}
+		f, err := fh.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer f.Close()
+		r = f
+	}
+	n, err := h.rates.Import(c.Request.Context(), r)
+	if err != nil {
+		writeRateError(c, err)
+		return
+	}
+	recordAudit(c, "import", "exchange_rate", 0, nil, gin.H{"imported": n})
+	c.JSON(http.StatusOK, gin.H{"imported": n})
+}
+
+func (h *ExchangeRateHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := h.rates.Delete(c.Request.Context(), id); err != nil {
+		writeRateError(c, err)
+		return
+	}
+	recordAudit(c, "delete", "exchange_rate", id, nil, nil)
+	c.Status(http.StatusNoContent)
+}
+
+// Revenue reports accepted, invoiced and paid amounts converted to the base
+// currency. Query: from, to (YYYY-MM-DD, inclusive); defaults to this month.
+func (h *ExchangeRateHandler) Revenue(c *gin.Context) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	to := from.AddDate(0, 1, 0)
+	if v := strings.TrimSpace(c.Query("from")); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from (use YYYY-MM-DD)"})
+			return
+		}
+		from = t
+	}
+	if v := strings.TrimSpace(c.Query("to")); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to (use YYYY-MM-DD)"})
+			return
+		}
+		to = t.AddDate(0, 0, 1) // inclusive end date
+	}
+	if !to.After(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+		return
+	}
+	report, err := h.revenue.Revenue(c.Request.Context(), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+func writeRateError(c *gin.Context, err error) {
+	var fe services.FieldErrors
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Exchange rate not found"})
+	case errors.As(err, &fe):
+		writeFieldErrors(c, fe)
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -43,6 +43,7 @@ func (h *HeartbeatHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	recordAudit(c, "create", "heartbeat", body.ID, nil, body)
 	c.JSON(http.StatusCreated, body)
 }
 
@@ -57,11 +58,13 @@ func (h *HeartbeatHandler) Update(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	before, _ := h.svc.Get(c, id)
 	item, err := h.svc.Update(c, id, &body)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
+	recordAudit(c, "update", "heartbeat", id, before, item)
 	c.JSON(http.StatusOK, item)
 }
 
@@ -71,10 +74,14 @@ func (h *HeartbeatHandler) Delete(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 		return
 	}
+	before, _ := h.svc.Get(c, id)
 	if err := h.svc.Delete(c, id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if before != nil {
+		recordAudit(c, "delete", "heartbeat", id, before, nil)
+	}
 	c.Status(http.StatusNoContent)
 }
 
@@ -136,5 +143,6 @@ func (h *HeartbeatHandler) RotateToken(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	recordAudit(c, "rotate_token", "heartbeat", id, nil, nil)
 	c.JSON(http.StatusOK, gin.H{"token": tok})
 }
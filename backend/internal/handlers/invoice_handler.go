@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"freelance-monitor-system/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type InvoiceHandler struct {
+	svc       *services.InvoiceService
+	clientSvc *services.ClientService
+}
+
+func NewInvoiceHandler(s *services.InvoiceService, clients *services.ClientService) *InvoiceHandler {
+	return &InvoiceHandler{svc: s, clientSvc: clients}
+}
+
+// List returns invoices filtered by status, client_id, offer_id and subscription_id.
+func (h *InvoiceHandler) List(c *gin.Context) {
+	f := services.InvoiceFilter{
+		Status:         strings.TrimSpace(c.Query("status")),
+		ClientID:       parseIntQuery(c, "client_id"),
+		OfferID:        parseIntQuery(c, "offer_id"),
+		SubscriptionID: parseIntQuery(c, "subscription_id"),
+		Limit:          parseIntQuery(c, "limit"),
+		Offset:         parseIntQuery(c, "offset"),
+	}
+	items, total, err := h.svc.List(c.Request.Context(), f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": total})
+}
+
+func (h *InvoiceHandler) Get(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	inv, err := h.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invoice not found"})
+		return
+	}
+	c.JSON(http.StatusOK, inv)
+}
+
+// CreateFromOffer generates an invoice from the accepted offer in the path.
+// Body (optional): { issue_date, due_date, taxes: [{name, rate}], notes }.
+func (h *InvoiceHandler) CreateFromOffer(c *gin.Context) {
+	offerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offer ID"})
+		return
+	}
+	var body struct {
+		IssueDate *time.Time                 `json:"issue_date"`
+		DueDate   *time.Time                 `json:"due_date"`
+		Taxes     []services.InvoiceTaxInput `json:"taxes"`
+		Notes     string                     `json:"notes"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	opts := services.InvoiceOptions{DueDate: body.DueDate, Taxes: body.Taxes, Notes: body.Notes}
+	if body.IssueDate != nil {
+		opts.IssueDate = *body.IssueDate
+	}
+	inv, err := h.svc.CreateFromOffer(c.Request.Context(), offerID, opts)
+	if err != nil {
+		writeInvoiceError(c, err, "Offer not found")
+		return
+	}
+	recordAudit(c, "create", "invoice", inv.ID, nil, inv)
+	c.JSON(http.StatusCreated, inv)
+}
+
+// Send marks a draft invoice as sent.
+func (h *InvoiceHandler) Send(c *gin.Context) {
+	h.transition(c, "send", h.svc.MarkSent)
+}
+
+// Void cancels an unpaid invoice.
+func (h *InvoiceHandler) Void(c *gin.Context) {
+	h.transition(c, "void", h.svc.Void)
+}
+
+func (h *InvoiceHandler) transition(c *gin.Context, action string, fn func(ctx context.Context, id int, at time.Time) (*models.Invoice, error)) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	before, _ := h.svc.Get(c.Request.Context(), id)
+	inv, err := fn(c.Request.Context(), id, time.Now())
+	if err != nil {
+		writeInvoiceError(c, err, "Invoice not found")
+		return
+	}
+	recordAudit(c, action, "invoice", id, before, inv)
+	c.JSON(http.StatusOK, inv)
+}
+
+// AddPayment records a full or partial payment: { amount, paid_at, method, reference, note }.
+func (h *InvoiceHandler) AddPayment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var body services.PaymentInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	before, _ := h.svc.Get(c.Request.Context(), id)
+	inv, err := h.svc.AddPayment(c.Request.Context(), id, body)
+	if err != nil {
+		writeInvoiceError(c, err, "Invoice not found")
+		return
+	}
+	recordAudit(c, "payment", "invoice", id, before, inv)
+	c.JSON(http.StatusOK, inv)
+}
+
+// GeneratePDF renders the invoice PDF and stores pdf_url.
+func (h *InvoiceHandler) GeneratePDF(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	inv, err := h.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invoice not found"})
+		return
+	}
+	var client *models.Client
+	if h.clientSvc != nil {
+		if cobj, e := h.clientSvc.GetClientByID(c.Request.Context(), inv.ClientID); e == nil {
+			client = cobj
+		}
+	}
+	url, err := services.NewPDFService().GenerateInvoicePDF(inv, client)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	_ = h.svc.SetPDFURL(c.Request.Context(), id, url)
+	c.JSON(http.StatusOK, gin.H{"pdf_url": url})
+}
+
+func writeInvoiceError(c *gin.Context, err error, notFound string) {
+	var fe services.FieldErrors
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+	case errors.Is(err, services.ErrInvoiceExists), errors.Is(err, services.ErrInvoiceState), errors.Is(err, services.ErrOfferNotAccepted),
+		errors.Is(err, services.ErrTimeEntryInvoiced):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &fe):
+		writeFieldErrors(c, fe)
+	case errors.Is(err, services.ErrInvalidPayment):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
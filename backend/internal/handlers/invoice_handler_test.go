@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"freelance-monitor-system/internal/models"
+	"freelance-monitor-system/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestInvoiceHandlersLifecycle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Client{}, &models.Offer{}, &models.OfferItem{}, &models.NumberSequence{}, &models.OfferStatusChange{}, &models.OfferRevision{}, &models.OfferLink{}, &models.OfferSignature{}, &models.Invoice{}, &models.InvoiceItem{}, &models.InvoiceTax{}, &models.InvoicePayment{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	db.Create(&models.Offer{ClientID: 1, Subject: "Retainer", Status: "draft", TotalPrice: 1000})
+
+	h := NewInvoiceHandler(services.NewInvoiceService(db), services.NewClientService(db))
+	r := gin.New()
+	r.GET("/api/invoices", h.List)
+	r.POST("/api/offers/:id/invoice", h.CreateFromOffer)
+	r.POST("/api/invoices/:id/send", h.Send)
+	r.POST("/api/invoices/:id/payments", h.AddPayment)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do("POST", "/api/offers/1/invoice", ""); w.Code != 409 {
+		t.Fatalf("expected 409 for unaccepted offer, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do("POST", "/api/offers/99/invoice", ""); w.Code != 404 {
+		t.Fatalf("expected 404 for missing offer, got %d", w.Code)
+	}
+	db.Model(&models.Offer{}).Where("id = 1").Update("status", "accepted")
+	w := do("POST", "/api/offers/1/invoice", `{"taxes":[{"name":"PPN","rate":11}]}`)
+	if w.Code != 201 || !strings.Contains(w.Body.String(), `"total":1110`) {
+		t.Fatalf("create: %d %s", w.Code, w.Body.String())
+	}
+	if w := do("POST", "/api/invoices/1/send", ""); w.Code != 200 || !strings.Contains(w.Body.String(), `"status":"sent"`) {
+		t.Fatalf("send: %d %s", w.Code, w.Body.String())
+	}
+	if w := do("POST", "/api/invoices/1/send", ""); w.Code != 409 {
+		t.Fatalf("expected 409 resending, got %d", w.Code)
+	}
+	if w := do("POST", "/api/invoices/1/payments", `{"amount":5000}`); w.Code != 400 {
+		t.Fatalf("expected 400 for overpayment, got %d", w.Code)
+	}
+	if w := do("POST", "/api/invoices/1/payments", `{"amount":1110,"method":"transfer"}`); w.Code != 200 || !strings.Contains(w.Body.String(), `"status":"paid"`) {
+		t.Fatalf("payment: %d %s", w.Code, w.Body.String())
+	}
+	if w := do("GET", "/api/invoices?status=paid", ""); w.Code != 200 || !strings.HasSuffix(w.Body.String(), `"total":1}`) {
+		t.Fatalf("list: %d %s", w.Code, w.Body.String())
+	}
+}
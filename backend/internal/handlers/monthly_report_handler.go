@@ -2,13 +2,17 @@ package handlers
 
 import (
     "encoding/json"
+    "errors"
+    "fmt"
     "net/http"
     "strconv"
     "strings"
     "time"
 
+    "freelance-monitor-system/internal/models"
     "freelance-monitor-system/internal/services"
     "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
 )
 
 type MonthlyReportHandler struct {
@@ -55,7 +59,9 @@ type monthlyReportRequest struct {
     Summary string `json:"summary"`
 }
 
-// GenerateMonthlyReportFromBody accepts JSON payload { service_id, month }
+// GenerateMonthlyReportFromBody accepts JSON payload { service_id, month }.
+// An existing report for the service and month is returned (and updated with
+// any details given) instead of creating a duplicate.
 func (h *MonthlyReportHandler) GenerateMonthlyReportFromBody(c *gin.Context) {
     var req monthlyReportRequest
     if err := c.ShouldBindJSON(&req); err != nil || req.ServiceID <= 0 || req.Month == "" {
@@ -75,7 +81,20 @@ func (h *MonthlyReportHandler) GenerateMonthlyReportFromBody(c *gin.Context) {
         c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
-    if uid, ok := c.Get("user_id"); ok { _ = h.reportService.SetMonthlyReportUser(ctx, report.ID, uid.(int)) }
+    // Adopt an unowned report; another user's report must not be returned
+    // or edited.
+    if report, err = h.reportService.ClaimMonthlyReport(ctx, report.ID, c.GetInt("user_id")); err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            c.JSON(http.StatusNotFound, gin.H{"error": "monthly report not found"})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+    if report.FinalizedAt != nil && (len(req.ActivityItems) > 0 || len(req.Activities) > 0 || req.MaintenanceHours > 0 || strings.TrimSpace(req.Summary) != "") {
+        c.JSON(http.StatusConflict, gin.H{"error": services.ErrReportFinalized.Error()})
+        return
+    }
 
     // Optionally update details if provided
     if len(req.ActivityItems) > 0 || len(req.Activities) > 0 || req.MaintenanceHours > 0 {
@@ -100,8 +119,11 @@ func (h *MonthlyReportHandler) GenerateMonthlyReportFromBody(c *gin.Context) {
         if req.MaintenanceHours > 0 { report.MaintenanceHours = req.MaintenanceHours }
     }
     if strings.TrimSpace(req.Summary) != "" {
-        _ = h.reportService.UpdateMonthlySummary(ctx, report.ID, req.Summary)
-        report.Summary = req.Summary
+        if err := h.reportService.UpdateMonthlySummary(ctx, report.ID, req.Summary); err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            return
+        }
+        report.Summary = strings.TrimSpace(req.Summary)
     }
 
     c.JSON(http.StatusOK, report)
@@ -124,6 +146,14 @@ func (h *MonthlyReportHandler) generateMonthlyReportFor(c *gin.Context, serviceI
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if report, err = h.reportService.ClaimMonthlyReport(ctx, report.ID, c.GetInt("user_id")); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "monthly report not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
 	c.JSON(http.StatusOK, report)
 }
@@ -172,4 +202,107 @@ func (h *MonthlyReportHandler) GetMonthlyReport(c *gin.Context) {
 	c.JSON(http.StatusOK, report)
 }
 
-// Backend PDF generation removed; PDFs are rendered client-side.
+// GetMonthlyReportPDF renders a monthly report through a report template.
+// ?template_id= picks a saved "monthly" template; otherwise the owner's
+// latest one (or the built-in default) is used.
+func (h *MonthlyReportHandler) GetMonthlyReportPDF(c *gin.Context) {
+	reportID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report ID"})
+		return
+	}
+	templateID := 0
+	if v := strings.TrimSpace(c.Query("template_id")); v != "" {
+		if templateID, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	var uid int
+	if v, ok := c.Get("user_id"); ok {
+		uid = v.(int)
+	}
+	report, err := h.reportService.GetMonthlyReportByIDForUser(ctx, uid, reportID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Monthly report not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	pdf, err := h.reportService.RenderPDF(ctx, report, templateID)
+	if err != nil {
+		var fe services.FieldErrors
+		if errors.As(err, &fe) {
+			writeFieldErrors(c, fe)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	filename := fmt.Sprintf("monthly_report_%d_%s.pdf", report.ServiceID, report.ReportMonth.Format("2006-01"))
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", filename))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// RegenerateMonthlyReport recomputes a report's metrics from current
+// monitoring data, keeping its summary and activities.
+func (h *MonthlyReportHandler) RegenerateMonthlyReport(c *gin.Context) {
+	report, ok := h.ownedReport(c)
+	if !ok {
+		return
+	}
+	updated, err := h.reportService.RegenerateMonthlyReport(c.Request.Context(), report.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrReportFinalized):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrNoReportData):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	recordAudit(c, "regenerate", "monthly_report", report.ID, report, updated)
+	c.JSON(http.StatusOK, updated)
+}
+
+// FinalizeMonthlyReport locks a report against regeneration and edits.
+// Reports emailed by the delivery task are finalized automatically.
+func (h *MonthlyReportHandler) FinalizeMonthlyReport(c *gin.Context) {
+	report, ok := h.ownedReport(c)
+	if !ok {
+		return
+	}
+	updated, err := h.reportService.FinalizeMonthlyReport(c.Request.Context(), report.ID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(c, "finalize", "monthly_report", report.ID, report, updated)
+	c.JSON(http.StatusOK, updated)
+}
+
+// ownedReport loads the :id report owned by the current user, writing the
+// error response when it cannot.
+func (h *MonthlyReportHandler) ownedReport(c *gin.Context) (*models.MonthlyReport, bool) {
+	reportID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report ID"})
+		return nil, false
+	}
+	report, err := h.reportService.GetMonthlyReportByIDForUser(c.Request.Context(), c.GetInt("user_id"), reportID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Monthly report not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return report, true
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"freelance-monitor-system/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMonthlyReportPDFEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("PDF_TEMPLATE_PATH", "")
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Client{}, &models.Service{}, &models.MonthlyReport{}, &models.ReportTemplate{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	db.Create(&models.Client{Name: "Acme"})
+	db.Create(&models.Service{ClientID: 1, Domain: "acme.test", ServiceType: "website"})
+	db.Create(&models.MonthlyReport{ServiceID: 1, ReportMonth: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), AvgUptimePercent: 100})
+
+	h := NewMonthlyReportHandler(services.NewMonthlyReportService(db), services.NewClientService(db), services.NewServiceService(db))
+	tpl := NewTemplateHandler(db)
+	r := gin.New()
+	r.GET("/api/reports/monthly/:id/pdf", h.GetMonthlyReportPDF)
+	r.POST("/api/templates", tpl.Upsert)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/reports/monthly/1/pdf", nil))
+	if w.Code != 200 || w.Header().Get("Content-Type") != "application/pdf" || !strings.HasPrefix(w.Body.String(), "%PDF") {
+		t.Fatalf("pdf: %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(w.Header().Get("Content-Disposition"), "monthly_report_1_2025-01.pdf") {
+		t.Fatalf("unexpected disposition %q", w.Header().Get("Content-Disposition"))
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/reports/monthly/9/pdf", nil))
+	if w.Code != 404 {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/reports/monthly/1/pdf?template_id=5", nil))
+	if w.Code != 400 || !strings.Contains(w.Body.String(), "template_id") {
+		t.Fatalf("expected 400 for unknown template, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/templates", strings.NewReader(`{"name":"bad","kind":"monthly","content":"{{range}}"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	if w.Code != 400 || !strings.Contains(w.Body.String(), `"content"`) {
+		t.Fatalf("expected invalid template to be rejected, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestMonthlyReportRegenerateAndFinalize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Client{}, &models.Service{}, &models.MonthlyReport{}, &models.DailyReport{}, &models.UptimeLog{}, &models.Alert{}, &models.TimeEntry{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	feb := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	db.Create(&models.UptimeLog{ServiceID: 1, Status: "up", ResponseTime: 120, CheckedAt: feb.AddDate(0, 0, 1)})
+	db.Create(&models.MonthlyReport{ServiceID: 1, ReportMonth: feb, Summary: "Hand written"})
+
+	h := NewMonthlyReportHandler(services.NewMonthlyReportService(db), services.NewClientService(db), services.NewServiceService(db))
+	r := gin.New()
+	r.POST("/api/reports/monthly", h.GenerateMonthlyReportFromBody)
+	r.POST("/api/reports/monthly/:id/regenerate", h.RegenerateMonthlyReport)
+	r.POST("/api/reports/monthly/:id/finalize", h.FinalizeMonthlyReport)
+	post := func(path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := post("/api/reports/monthly/1/regenerate", ""); w.Code != 200 || !strings.Contains(w.Body.String(), `"avg_response_ms":120`) || !strings.Contains(w.Body.String(), "Hand written") {
+		t.Fatalf("regenerate: %d %s", w.Code, w.Body.String())
+	}
+	if w := post("/api/reports/monthly", `{"service_id":1,"month":"2025-02"}`); w.Code != 200 || !strings.Contains(w.Body.String(), `"id":1,`) {
+		t.Fatalf("expected the existing report back: %d %s", w.Code, w.Body.String())
+	}
+	if w := post("/api/reports/monthly/1/finalize", ""); w.Code != 200 || strings.Contains(w.Body.String(), `"finalized_at":null`) {
+		t.Fatalf("finalize: %d %s", w.Code, w.Body.String())
+	}
+	if w := post("/api/reports/monthly/1/regenerate", ""); w.Code != 409 {
+		t.Fatalf("expected 409 for a finalized report, got %d", w.Code)
+	}
+	if w := post("/api/reports/monthly", `{"service_id":1,"month":"2025-02","summary":"changed"}`); w.Code != 409 {
+		t.Fatalf("expected 409 when editing a finalized report, got %d", w.Code)
+	}
+	if w := post("/api/reports/monthly/7/finalize", ""); w.Code != 404 {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestMonthlyReportUpsertScopedToOwner(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Client{}, &models.Service{}, &models.MonthlyReport{}, &models.DailyReport{}, &models.UptimeLog{}, &models.Alert{}, &models.TimeEntry{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	feb := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	db.Create(&models.MonthlyReport{ServiceID: 1, ReportMonth: feb, Summary: "A's notes"})
+
+	h := NewMonthlyReportHandler(services.NewMonthlyReportService(db), services.NewClientService(db), services.NewServiceService(db))
+	post := func(uid int, body string) *httptest.ResponseRecorder {
+		r := gin.New()
+		r.POST("/api/reports/monthly", func(c *gin.Context) { c.Set("user_id", uid); c.Next() }, h.GenerateMonthlyReportFromBody)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/reports/monthly", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+	// The first caller adopts the unowned report.
+	if w := post(1, `{"service_id":1,"month":"2025-02"}`); w.Code != 200 || !strings.Contains(w.Body.String(), `"user_id":1`) {
+		t.Fatalf("expected the report to be adopted, got %d %s", w.Code, w.Body.String())
+	}
+	if w := post(2, `{"service_id":1,"month":"2025-02","summary":"B was here"}`); w.Code != 404 {
+		t.Fatalf("expected another user's report to be hidden, got %d %s", w.Code, w.Body.String())
+	}
+	var got models.MonthlyReport
+	db.First(&got)
+	if got.UserID != 1 || got.Summary != "A's notes" {
+		t.Fatalf("expected A's report untouched: %+v", got)
+	}
+}
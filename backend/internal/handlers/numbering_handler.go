@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"freelance-monitor-system/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type NumberingHandler struct {
+	svc *services.NumberingService
+}
+
+func NewNumberingHandler(s *services.NumberingService) *NumberingHandler {
+	return &NumberingHandler{svc: s}
+}
+
+// Get returns the offer numbering format with a preview of the next number.
+func (h *NumberingHandler) Get(c *gin.Context) {
+	out, err := h.svc.Get(c.Request.Context(), time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// Update sets the offer numbering. Body: { format, reset_yearly }.
+func (h *NumberingHandler) Update(c *gin.Context) {
+	var body services.NumberingInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	before, _ := h.svc.Get(c.Request.Context(), time.Now())
+	out, err := h.svc.Update(c.Request.Context(), body, time.Now())
+	if err != nil {
+		writeOfferError(c, err)
+		return
+	}
+	recordAudit(c, "update", "offer_numbering", 0, before, out)
+	c.JSON(http.StatusOK, out)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"freelance-monitor-system/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type OfferAnalyticsHandler struct {
+	svc *services.OfferAnalyticsService
+}
+
+func NewOfferAnalyticsHandler(s *services.OfferAnalyticsService) *OfferAnalyticsHandler {
+	return &OfferAnalyticsHandler{svc: s}
+}
+
+// Get returns pipeline analytics filtered by from, to (YYYY-MM-DD, inclusive),
+// client_id and expiring_days.
+func (h *OfferAnalyticsHandler) Get(c *gin.Context) {
+	f, ok := parseOfferAnalyticsFilter(c)
+	if !ok {
+		return
+	}
+	out, err := h.svc.Analytics(c.Request.Context(), f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// Export returns the same analytics as CSV.
+func (h *OfferAnalyticsHandler) Export(c *gin.Context) {
+	f, ok := parseOfferAnalyticsFilter(c)
+	if !ok {
+		return
+	}
+	out, err := h.svc.Analytics(c.Request.Context(), f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", "attachment; filename=\"offer-analytics-"+time.Now().Format("20060102")+".csv\"")
+	if err := out.WriteCSV(c.Writer); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func parseOfferAnalyticsFilter(c *gin.Context) (services.OfferAnalyticsFilter, bool) {
+	f := services.OfferAnalyticsFilter{
+		ClientID:     parseIntQuery(c, "client_id"),
+		ExpiringDays: parseIntQuery(c, "expiring_days"),
+	}
+	for key, dst := range map[string]*time.Time{"from": &f.From, "to": &f.To} {
+		v := strings.TrimSpace(c.Query(key))
+		if v == "" {
+			continue
+		}
+		t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + key + " (use YYYY-MM-DD)"})
+			return f, false
+		}
+		if key == "to" {
+			t = t.AddDate(0, 0, 1) // inclusive end date
+		}
+		*dst = t
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.To.After(f.From) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+		return f, false
+	}
+	return f, true
+}
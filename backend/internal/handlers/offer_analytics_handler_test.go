@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"freelance-monitor-system/internal/models"
+	"freelance-monitor-system/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestOfferAnalyticsHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Client{}, &models.Offer{}, &models.OfferItem{}, &models.NumberSequence{}, &models.OfferStatusChange{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := services.NewOfferService(db).CreateOffer(&models.Offer{ClientID: 1, Subject: "S",
+		Items: models.OfferItems{{Description: "Work", Qty: 1, UnitPrice: 250}}}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	h := NewOfferAnalyticsHandler(services.NewOfferAnalyticsService(db))
+	r := gin.New()
+	r.GET("/api/analytics/offers", h.Get)
+	r.GET("/api/analytics/offers/export", h.Export)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/analytics/offers?client_id=1", nil))
+	if w.Code != 200 || !strings.Contains(w.Body.String(), `"status":"draft","count":1,"value":250`) {
+		t.Fatalf("get: %d %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/analytics/offers/export", nil))
+	if w.Code != 200 || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") || !strings.Contains(w.Body.String(), "pipeline,draft") {
+		t.Fatalf("export: %d %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/analytics/offers?from=01-01-2026", nil))
+	if w.Code != 400 {
+		t.Fatalf("expected 400 for bad date, got %d", w.Code)
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"freelance-monitor-system/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type OfferDeliveryHandler struct {
+	svc *services.OfferDeliveryService
+}
+
+func NewOfferDeliveryHandler(s *services.OfferDeliveryService) *OfferDeliveryHandler {
+	return &OfferDeliveryHandler{svc: s}
+}
+
+// Send emails the offer PDF and a public accept link to the client.
+// Body (optional): { to, message, expires_in_days }.
+func (h *OfferDeliveryHandler) Send(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offer ID"})
+		return
+	}
+	var in services.SendOfferInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&in); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	out, err := h.svc.SendOffer(c.Request.Context(), id, in, actorID(c), time.Now())
+	if err != nil {
+		writeOfferError(c, err)
+		return
+	}
+	recordAudit(c, "email", "offer", id, nil, out.Link)
+	c.JSON(http.StatusOK, out)
+}
+
+// PublicView shows the offer behind a public link and records the view.
+func (h *OfferDeliveryHandler) PublicView(c *gin.Context) {
+	view, err := h.svc.OpenLink(c.Request.Context(), c.Param("token"), time.Now())
+	if err != nil {
+		writeOfferLinkError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, view)
+}
+
+// PublicPDF streams the PDF of the revision behind a public link.
+func (h *OfferDeliveryHandler) PublicPDF(c *gin.Context) {
+	path, err := h.svc.LinkPDF(c.Request.Context(), c.Param("token"), time.Now())
+	if err != nil {
+		writeOfferLinkError(c, err)
+		return
+	}
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", "inline")
+	c.File(path)
+}
+
+// PublicAccept accepts the offer. Body: { name } — the typed name is stored
+// with the time and IP as the e-signature.
+func (h *OfferDeliveryHandler) PublicAccept(c *gin.Context) {
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.respond(c, services.OfferResponseInput{Decision: models.OfferDecisionAccept, Name: body.Name})
+}
+
+// PublicReject rejects the offer. Body: { reason }.
+func (h *OfferDeliveryHandler) PublicReject(c *gin.Context) {
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.respond(c, services.OfferResponseInput{Decision: models.OfferDecisionReject, Reason: body.Reason})
+}
+
+func (h *OfferDeliveryHandler) respond(c *gin.Context, in services.OfferResponseInput) {
+	in.IP = c.ClientIP()
+	in.UA = c.Request.UserAgent()
+	view, err := h.svc.Respond(c.Request.Context(), c.Param("token"), in, time.Now())
+	if err != nil {
+		writeOfferLinkError(c, err)
+		return
+	}
+	recordAudit(c, in.Decision+"_online", "offer", view.Offer.ID, nil, view.Signature)
+	c.JSON(http.StatusOK, view)
+}
+
+func writeOfferLinkError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrOfferLinkNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrOfferLinkExpired):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrOfferLinkClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		writeOfferError(c, err)
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"freelance-monitor-system/internal/models"
+	"freelance-monitor-system/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestOfferDeliveryHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	chdirTemp(t)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Client{}, &models.Offer{}, &models.OfferItem{}, &models.NumberSequence{}, &models.OfferStatusChange{},
+		&models.OfferRevision{}, &models.OfferLink{}, &models.OfferSignature{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	offer := &models.Offer{ClientID: 1, Subject: "S", Items: models.OfferItems{{Description: "Setup", Qty: 1, UnitPrice: 10}}}
+	if err := services.NewOfferService(db).CreateOffer(offer); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	h := NewOfferDeliveryHandler(services.NewOfferDeliveryService(db))
+	r := gin.Default()
+	r.POST("/api/offers/:id/email", h.Send)
+	r.GET("/api/public/offers/:token", h.PublicView)
+	r.GET("/api/public/offers/:token/pdf", h.PublicPDF)
+	r.POST("/api/public/offers/:token/accept", h.PublicAccept)
+	r.POST("/api/public/offers/:token/reject", h.PublicReject)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do("POST", "/api/offers/1/email", ``); w.Code != 400 || !strings.Contains(w.Body.String(), `"to"`) {
+		t.Fatalf("expected 400 without recipient, got %d: %s", w.Code, w.Body.String())
+	}
+	w := do("POST", "/api/offers/1/email", `{"to":"client@example.com"}`)
+	if w.Code != 200 {
+		t.Fatalf("expected 200 on email, got %d: %s", w.Code, w.Body.String())
+	}
+	var out struct {
+		URL string `json:"url"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &out)
+	token := out.URL[strings.LastIndex(out.URL, "/")+1:]
+
+	if w := do("GET", "/api/public/offers/"+token, ``); w.Code != 200 || !strings.Contains(w.Body.String(), `"status":"viewed"`) || strings.Contains(w.Body.String(), "token_hash") {
+		t.Fatalf("unexpected public view: %d %s", w.Code, w.Body.String())
+	}
+	if w := do("GET", "/api/public/offers/"+token+"/pdf", ``); w.Code != 200 || !strings.HasPrefix(w.Body.String(), "%PDF") {
+		t.Fatalf("expected revision pdf, got %d", w.Code)
+	}
+	if w := do("POST", "/api/public/offers/"+token+"/accept", `{"name":""}`); w.Code != 400 {
+		t.Fatalf("expected 400 without name, got %d", w.Code)
+	}
+	if w := do("POST", "/api/public/offers/"+token+"/accept", `{"name":"Jane Client"}`); w.Code != 200 || !strings.Contains(w.Body.String(), `"signer_name":"Jane Client"`) {
+		t.Fatalf("expected 200 on accept, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do("POST", "/api/public/offers/"+token+"/reject", `{"reason":"changed my mind"}`); w.Code != 409 {
+		t.Fatalf("expected 409 after decision, got %d", w.Code)
+	}
+	if w := do("GET", "/api/public/offers/unknown", ``); w.Code != 404 {
+		t.Fatalf("expected 404 for unknown token, got %d", w.Code)
+	}
+}
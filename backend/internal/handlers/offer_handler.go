@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"freelance-monitor-system/internal/database"
+	"log"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -55,31 +56,36 @@ func (h *OfferHandler) GetOffer(c *gin.Context) {
 	c.JSON(200, offer)
 }
 
+// CreateOffer stores an offer with its line items. Totals are computed by the
+// server; total_price in the request is ignored.
 func (h *OfferHandler) CreateOffer(c *gin.Context) {
 	var input models.Offer
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
-	// Ensure items is a JSON array string to satisfy jsonb column
-	if input.Items == "" {
-		input.Items = "[]"
-	}
 	if input.ClientID == 0 || input.Subject == "" {
 		c.JSON(400, gin.H{"error": "client_id and subject are required"})
 		return
 	}
-	if input.TotalPrice <= 0 {
-		c.JSON(400, gin.H{"error": "total_price must be positive"})
+	if len(input.Items) == 0 {
+		writeFieldErrors(c, services.FieldErrors{"items": "at least one line item is required"})
 		return
 	}
 	if err := h.service.CreateOffer(&input); err != nil {
+		var fe services.FieldErrors
+		if errors.As(err, &fe) {
+			writeFieldErrors(c, fe)
+			return
+		}
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
+	recordAudit(c, "create", "offer", input.ID, nil, input)
 	c.JSON(201, input)
 }
 
+// UpdateOffer applies a partial update. Sending items replaces all line items.
 func (h *OfferHandler) UpdateOffer(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
@@ -92,18 +98,29 @@ func (h *OfferHandler) UpdateOffer(c *gin.Context) {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
-	if updates.TotalPrice < 0 {
-		c.JSON(400, gin.H{"error": "total_price must be non-negative"})
+	if updates.Status != "" {
+		c.JSON(400, gin.H{"error": "status cannot be edited; use the offer transition endpoints"})
 		return
 	}
+	if updates.Items != nil && len(updates.Items) == 0 {
+		writeFieldErrors(c, services.FieldErrors{"items": "at least one line item is required"})
+		return
+	}
+	before, _ := h.service.GetOfferByID(id)
 	offer, err := h.service.UpdateOffer(id, &updates)
 	if err != nil {
-		c.JSON(404, gin.H{"error": "Offer not found"})
+		writeOfferError(c, err)
 		return
 	}
+	recordAudit(c, "update", "offer", id, before, offer)
 	c.JSON(200, offer)
 }
 
+// writeFieldErrors responds 400 with per-field messages under "fields".
+func writeFieldErrors(c *gin.Context, fe services.FieldErrors) {
+	c.JSON(400, gin.H{"error": fe.Error(), "fields": fe})
+}
+
 func (h *OfferHandler) DeleteOffer(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
@@ -111,6 +128,7 @@ func (h *OfferHandler) DeleteOffer(c *gin.Context) {
 		c.JSON(400, gin.H{"error": "Invalid offer ID"})
 		return
 	}
+	before, _ := h.service.GetOfferByID(id)
 	if err := h.service.DeleteOffer(id); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.JSON(404, gin.H{"error": "Offer not found"})
@@ -119,6 +137,7 @@ func (h *OfferHandler) DeleteOffer(c *gin.Context) {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
+	recordAudit(c, "delete", "offer", id, before, nil)
 	c.Status(204)
 }
 
@@ -148,27 +167,208 @@ func (h *OfferHandler) GeneratePDF(c *gin.Context) {
 		return
 	}
 	// persist url on offer
-	_, _ = h.service.UpdateOffer(id, &models.Offer{PDFURL: url})
+	_ = h.service.SetPDFURL(id, url)
 	c.JSON(200, gin.H{"pdf_url": url})
 }
 
-// Approve marks the offer as accepted.
+// Approve marks the offer as accepted. Kept as an alias of Accept.
 func (h *OfferHandler) Approve(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
+	h.transition(c, models.OfferAccepted, "approve")
+}
+
+// Send marks a draft offer as sent to the client, snapshotting it as a new
+// revision with its own PDF.
+func (h *OfferHandler) Send(c *gin.Context) { h.transition(c, models.OfferSent, "send") }
+
+// Revise moves a sent, viewed or rejected offer back to draft for editing;
+// sending it again creates the next revision.
+func (h *OfferHandler) Revise(c *gin.Context) { h.transition(c, models.OfferDraft, "revise") }
+
+// MarkViewed records that the client opened a sent offer.
+func (h *OfferHandler) MarkViewed(c *gin.Context) { h.transition(c, models.OfferViewed, "view") }
+
+// Accept marks a sent or viewed offer as accepted.
+func (h *OfferHandler) Accept(c *gin.Context) { h.transition(c, models.OfferAccepted, "accept") }
+
+// Reject marks a sent or viewed offer as rejected.
+func (h *OfferHandler) Reject(c *gin.Context) { h.transition(c, models.OfferRejected, "reject") }
+
+// Expire marks a sent or viewed offer as expired before its valid_until.
+func (h *OfferHandler) Expire(c *gin.Context) { h.transition(c, models.OfferExpired, "expire") }
+
+// Supersede retires an offer that a newer one replaces.
+func (h *OfferHandler) Supersede(c *gin.Context) { h.transition(c, models.OfferSuperseded, "supersede") }
+
+// transition applies a status change. Body (optional): { note }.
+func (h *OfferHandler) transition(c *gin.Context, to, action string) {
+	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(400, gin.H{"error": "Invalid offer ID"})
 		return
 	}
-	now := time.Now()
-	offer, err := h.service.ApproveOffer(id, now)
+	var body struct {
+		Note string `json:"note"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	before, _ := h.service.GetOfferByID(id)
+	offer, err := h.service.TransitionOffer(id, to, actorID(c), body.Note, time.Now())
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		writeOfferError(c, err)
+		return
+	}
+	if to == models.OfferSent {
+		if _, err := h.service.RenderRevisionPDF(offer.ID, offer.Revision); err != nil {
+			log.Printf("offer %d revision %d pdf: %v", offer.ID, offer.Revision, err)
+		}
+	}
+	recordAudit(c, action, "offer", id, before, offer)
+	c.JSON(200, offer)
+}
+
+// AutoRenew turns auto-renewal on or off. Body: { enabled, renew_every_days }
+// (days default to the offer's cycle, else 30). Renewals are drafts cloned by
+// the renew_offers task.
+func (h *OfferHandler) AutoRenew(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid offer ID"})
+		return
+	}
+	var body struct {
+		Enabled        *bool `json:"enabled" binding:"required"`
+		RenewEveryDays int   `json:"renew_every_days"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	before, _ := h.service.GetOfferByID(id)
+	offer, err := h.service.ToggleAutoRenew(id, *body.Enabled, body.RenewEveryDays)
+	if err != nil {
+		writeOfferError(c, err)
 		return
 	}
+	recordAudit(c, "update", "offer", id, before, offer)
 	c.JSON(200, offer)
 }
 
+// Revisions lists the offer's sent revisions, oldest first.
+func (h *OfferHandler) Revisions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid offer ID"})
+		return
+	}
+	rows, err := h.service.ListRevisions(id)
+	if err != nil {
+		writeOfferError(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows, "total": len(rows)})
+}
+
+// Revision returns one revision including the offer as it was sent.
+func (h *OfferHandler) Revision(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid offer ID"})
+		return
+	}
+	rev, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid revision"})
+		return
+	}
+	row, err := h.service.GetRevision(id, rev)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(404, gin.H{"error": "Revision not found"})
+			return
+		}
+		writeOfferError(c, err)
+		return
+	}
+	c.JSON(200, row)
+}
+
+// DiffRevisions compares two revisions. Query: from, to (default: the latest
+// revision against the one before it).
+func (h *OfferHandler) DiffRevisions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid offer ID"})
+		return
+	}
+	offer, err := h.service.GetOfferByID(id)
+	if err != nil {
+		writeOfferError(c, err)
+		return
+	}
+	to := parseIntQuery(c, "to")
+	if to == 0 {
+		to = offer.Revision
+	}
+	from := parseIntQuery(c, "from")
+	if from == 0 {
+		from = to - 1
+	}
+	if from < 1 || to < 1 || from == to {
+		c.JSON(400, gin.H{"error": "from and to must be two different revisions"})
+		return
+	}
+	diff, err := h.service.DiffRevisions(id, from, to)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(404, gin.H{"error": "Revision not found"})
+			return
+		}
+		writeOfferError(c, err)
+		return
+	}
+	c.JSON(200, diff)
+}
+
+// History lists the offer's status changes, oldest first.
+func (h *OfferHandler) History(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid offer ID"})
+		return
+	}
+	rows, err := h.service.OfferHistory(id)
+	if err != nil {
+		writeOfferError(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows, "total": len(rows)})
+}
+
+func actorID(c *gin.Context) *int {
+	if uid := c.GetInt("user_id"); uid > 0 {
+		return &uid
+	}
+	return nil
+}
+
+func writeOfferError(c *gin.Context, err error) {
+	var fe services.FieldErrors
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(404, gin.H{"error": "Offer not found"})
+	case errors.Is(err, services.ErrOfferTransition), errors.Is(err, services.ErrOfferLocked):
+		c.JSON(409, gin.H{"error": err.Error()})
+	case errors.As(err, &fe):
+		writeFieldErrors(c, fe)
+	default:
+		c.JSON(400, gin.H{"error": err.Error()})
+	}
+}
+
 // UploadSigned handles a multipart upload for a signed offer document, saves it, and marks offer accepted.
 func (h *OfferHandler) UploadSigned(c *gin.Context) {
 	idStr := c.Param("id")
@@ -196,11 +396,14 @@ func (h *OfferHandler) UploadSigned(c *gin.Context) {
 	}
 	publicURL := "/static/uploads/signed_offers/" + filename
 	now := time.Now()
-	offer, err := h.service.SetSignedDocAndApprove(id, publicURL, now)
+	before, _ := h.service.GetOfferByID(id)
+	offer, err := h.service.SetSignedDocAndApprove(id, publicURL, now, actorID(c))
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		_ = os.Remove(path)
+		writeOfferError(c, err)
 		return
 	}
+	recordAudit(c, "approve", "offer", id, before, offer)
 	c.JSON(200, gin.H{"signed_doc_url": publicURL, "offer": offer})
 }
 
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 
@@ -18,7 +19,7 @@ func TestOfferHandlersCreateList(t *testing.T) {
 	if err != nil {
 		t.Fatalf("sqlite open: %v", err)
 	}
-	if err := db.AutoMigrate(&models.Offer{}); err != nil {
+	if err := db.AutoMigrate(&models.Offer{}, &models.OfferItem{}, &models.NumberSequence{}, &models.OfferStatusChange{}, &models.OfferRevision{}, &models.OfferLink{}, &models.OfferSignature{}); err != nil {
 		t.Fatalf("migrate: %v", err)
 	}
 	svc := services.NewOfferService(db)
@@ -32,7 +33,7 @@ func TestOfferHandlersCreateList(t *testing.T) {
 
 	// Create
 	w := httptest.NewRecorder()
-	body := `{"client_id":1,"subject":"Test","items":"[]","total_price":100}`
+	body := `{"client_id":1,"subject":"Test","items":[{"description":"Setup","qty":2,"unit_price":50}],"total_price":1}`
 	req := httptest.NewRequest("POST", "/api/offers", strings.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	r.ServeHTTP(w, req)
@@ -43,6 +44,39 @@ func TestOfferHandlersCreateList(t *testing.T) {
 	if !strings.Contains(w.Body.String(), "pdf_url") {
 		t.Fatalf("expected pdf_url in response")
 	}
+	if !strings.Contains(w.Body.String(), `"total_price":100`) {
+		t.Fatalf("expected server-computed total, got %s", w.Body.String())
+	}
+
+	// Legacy clients send items as a JSON string
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/api/offers", strings.NewReader(`{"client_id":1,"subject":"Legacy","items":"[{\"description\":\"Support\",\"qty\":1,\"unit_price\":75}]"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	if w.Code != 201 || !strings.Contains(w.Body.String(), `"total_price":75`) {
+		t.Fatalf("expected 201 for string items, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Malformed items are rejected per field
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/api/offers", strings.NewReader(`{"client_id":1,"subject":"Bad","items":[{"description":"","qty":0,"unit_price":-1,"discount_percent":120}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	if w.Code != 400 {
+		t.Fatalf("expected 400 for invalid items, got %d", w.Code)
+	}
+	for _, f := range []string{"items[0].description", "items[0].qty", "items[0].unit_price", "items[0].discount_percent"} {
+		if !strings.Contains(w.Body.String(), `"`+f+`"`) {
+			t.Fatalf("expected field error for %s, got %s", f, w.Body.String())
+		}
+	}
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/api/offers", strings.NewReader(`{"client_id":1,"subject":"Empty","items":[]}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	if w.Code != 400 || !strings.Contains(w.Body.String(), `"items"`) {
+		t.Fatalf("expected 400 for missing items, got %d: %s", w.Code, w.Body.String())
+	}
 
 	// List
 	w = httptest.NewRecorder()
@@ -88,11 +122,11 @@ func TestOfferHandlersCreateList(t *testing.T) {
 
 	// Update
 	w = httptest.NewRecorder()
-	req = httptest.NewRequest("PUT", "/api/offers/1", strings.NewReader(`{"subject":"Updated","total_price":200}`))
+	req = httptest.NewRequest("PUT", "/api/offers/1", strings.NewReader(`{"subject":"Updated","items":[{"description":"Setup","qty":4,"unit_price":50}]}`))
 	req.Header.Set("Content-Type", "application/json")
 	r.ServeHTTP(w, req)
-	if w.Code != 200 {
-		t.Fatalf("expected 200 on update, got %d", w.Code)
+	if w.Code != 200 || !strings.Contains(w.Body.String(), `"total_price":200`) {
+		t.Fatalf("expected 200 on update, got %d: %s", w.Code, w.Body.String())
 	}
 
 	// Delete
@@ -129,3 +163,108 @@ func TestOfferHandlersCreateList(t *testing.T) {
 		t.Fatalf("expected 400 for invalid json, got %d", w.Code)
 	}
 }
+
+// chdirTemp runs the test from a temp dir so generated PDFs stay out of the tree.
+func chdirTemp(t *testing.T) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+}
+
+func TestOfferHandlersTransitions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	chdirTemp(t)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Offer{}, &models.OfferItem{}, &models.NumberSequence{}, &models.OfferStatusChange{}, &models.OfferRevision{}, &models.OfferLink{}, &models.OfferSignature{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	svc := services.NewOfferService(db)
+	offer := &models.Offer{ClientID: 1, Subject: "S", Items: models.OfferItems{{Description: "Setup", Qty: 1, UnitPrice: 10}}}
+	if err := svc.CreateOffer(offer); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	h := NewOfferHandler(svc)
+	r := gin.Default()
+	r.PUT("/api/offers/:id", h.UpdateOffer)
+	r.POST("/api/offers/:id/send", h.Send)
+	r.POST("/api/offers/:id/accept", h.Accept)
+	r.POST("/api/offers/:id/reject", h.Reject)
+	r.POST("/api/offers/:id/supersede", h.Supersede)
+	r.GET("/api/offers/:id/history", h.History)
+	r.POST("/api/offers/:id/revise", h.Revise)
+	r.GET("/api/offers/:id/revisions", h.Revisions)
+	r.GET("/api/offers/:id/revisions/:rev", h.Revision)
+	r.GET("/api/offers/:id/diff", h.DiffRevisions)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do("PUT", "/api/offers/1", `{"status":"accepted"}`); w.Code != 400 {
+		t.Fatalf("expected 400 for raw status edit, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do("POST", "/api/offers/1/accept", ``); w.Code != 409 {
+		t.Fatalf("expected 409 accepting a draft, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do("POST", "/api/offers/1/send", `{"note":"emailed to client"}`); w.Code != 200 || !strings.Contains(w.Body.String(), `"status":"sent"`) {
+		t.Fatalf("expected 200 on send, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do("PUT", "/api/offers/1", `{"subject":"Changed"}`); w.Code != 409 {
+		t.Fatalf("expected 409 editing a sent offer, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do("POST", "/api/offers/1/reject", ``); w.Code != 200 || !strings.Contains(w.Body.String(), `"status":"rejected"`) {
+		t.Fatalf("expected 200 on reject, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do("POST", "/api/offers/1/revise", ``); w.Code != 200 || !strings.Contains(w.Body.String(), `"status":"draft"`) {
+		t.Fatalf("expected 200 on revise, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do("PUT", "/api/offers/1", `{"items":[{"description":"Setup","qty":1,"unit_price":8}]}`); w.Code != 200 {
+		t.Fatalf("expected 200 editing the revised draft, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do("POST", "/api/offers/1/send", ``); w.Code != 200 || !strings.Contains(w.Body.String(), `"revision":2`) {
+		t.Fatalf("expected revision 2 on resend, got %d: %s", w.Code, w.Body.String())
+	}
+	w := do("GET", "/api/offers/1/revisions", ``)
+	if w.Code != 200 || !strings.Contains(w.Body.String(), `"total":2`) || !strings.Contains(w.Body.String(), `/R2"`) || !strings.Contains(w.Body.String(), "offer_1_r2.pdf") {
+		t.Fatalf("unexpected revisions: %d %s", w.Code, w.Body.String())
+	}
+	if w := do("GET", "/api/offers/1/revisions/1", ``); w.Code != 200 || !strings.Contains(w.Body.String(), `"unit_price":10`) {
+		t.Fatalf("expected revision 1 snapshot, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do("GET", "/api/offers/1/revisions/9", ``); w.Code != 404 {
+		t.Fatalf("expected 404 for missing revision, got %d", w.Code)
+	}
+	if w := do("GET", "/api/offers/1/diff", ``); w.Code != 200 || !strings.Contains(w.Body.String(), `"total_price":{"from":10,"to":8}`) {
+		t.Fatalf("unexpected diff: %d %s", w.Code, w.Body.String())
+	}
+	if w := do("POST", "/api/offers/999/send", ``); w.Code != 404 {
+		t.Fatalf("expected 404 for unknown offer, got %d", w.Code)
+	}
+	w = do("GET", "/api/offers/1/history", ``)
+	if w.Code != 200 || !strings.Contains(w.Body.String(), `"total":5`) || !strings.Contains(w.Body.String(), "emailed to client") {
+		t.Fatalf("unexpected history: %d %s", w.Code, w.Body.String())
+	}
+	if w := do("POST", "/api/offers/1/supersede", `{"note":"replaced by offer 2"}`); w.Code != 200 || !strings.Contains(w.Body.String(), `"status":"superseded"`) {
+		t.Fatalf("expected 200 on supersede, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do("POST", "/api/offers/1/supersede", ``); w.Code != 409 {
+		t.Fatalf("expected 409 superseding twice, got %d: %s", w.Code, w.Body.String())
+	}
+	w = do("GET", "/api/offers/1/history", ``)
+	if w.Code != 200 || !strings.Contains(w.Body.String(), `"total":6`) || !strings.Contains(w.Body.String(), "replaced by offer 2") {
+		t.Fatalf("expected supersede in history: %d %s", w.Code, w.Body.String())
+	}
+}
@@ -18,12 +18,12 @@ func TestOfferListPaginationFiltering(t *testing.T) {
 	if err != nil {
 		t.Fatalf("sqlite: %v", err)
 	}
-	if err := db.AutoMigrate(&models.Offer{}); err != nil {
+	if err := db.AutoMigrate(&models.Offer{}, &models.OfferItem{}, &models.NumberSequence{}, &models.OfferStatusChange{}, &models.OfferRevision{}, &models.OfferLink{}, &models.OfferSignature{}); err != nil {
 		t.Fatalf("migrate: %v", err)
 	}
 	// Seed
-	_ = db.Create(&models.Offer{ClientID: 1, Subject: "Website", Items: "[]", TotalPrice: 10}).Error
-	_ = db.Create(&models.Offer{ClientID: 2, Subject: "Mobile App", Items: "[]", TotalPrice: 20}).Error
+	_ = db.Create(&models.Offer{ClientID: 1, Subject: "Website", TotalPrice: 10}).Error
+	_ = db.Create(&models.Offer{ClientID: 2, Subject: "Mobile App", TotalPrice: 20}).Error
 
 	svc := services.NewOfferService(db)
 	h := NewOfferHandler(svc)
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"freelance-monitor-system/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ReportDeliveryHandler struct {
+	svc *services.ReportDeliveryService
+}
+
+func NewReportDeliveryHandler(s *services.ReportDeliveryService) *ReportDeliveryHandler {
+	return &ReportDeliveryHandler{svc: s}
+}
+
+// List returns monthly report deliveries filtered by month (YYYY-MM), status
+// and client_id.
+func (h *ReportDeliveryHandler) List(c *gin.Context) {
+	f := services.ReportDeliveryFilter{
+		Month:    strings.TrimSpace(c.Query("month")),
+		Status:   strings.TrimSpace(c.Query("status")),
+		ClientID: parseIntQuery(c, "client_id"),
+		Limit:    parseIntQuery(c, "limit"),
+		Offset:   parseIntQuery(c, "offset"),
+	}
+	items, total, err := h.svc.ListDeliveries(c.Request.Context(), f)
+	if err != nil {
+		var fe services.FieldErrors
+		if errors.As(err, &fe) {
+			writeFieldErrors(c, fe)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": total})
+}
+
+// Retry attempts a failed or skipped delivery again immediately.
+func (h *ReportDeliveryHandler) Retry(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	d, err := h.svc.RetryDelivery(c.Request.Context(), id, time.Now())
+	if err != nil {
+		var fe services.FieldErrors
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Report delivery not found"})
+		case errors.As(err, &fe):
+			writeFieldErrors(c, fe)
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	recordAudit(c, "retry", "report_delivery", id, nil, d)
+	c.JSON(http.StatusOK, d)
+}
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"errors"
+	"net/http"
+
 	"freelance-monitor-system/internal/scheduler"
 	"github.com/gin-gonic/gin"
-	"net/http"
 )
 
 type SchedulerHandler struct{}
@@ -21,7 +23,7 @@ func (h *SchedulerHandler) List(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"items": items, "total": len(items)})
 }
 
-// Run executes a named task immediately.
+// Run executes a named task immediately; 409 while it is already running.
 func (h *SchedulerHandler) Run(c *gin.Context) {
 	name := c.Param("name")
 	s := scheduler.GetDefault()
@@ -30,8 +32,93 @@ func (h *SchedulerHandler) Run(c *gin.Context) {
 		return
 	}
 	if err := s.RunTask(name); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "error": err.Error()})
+		status := http.StatusInternalServerError
+		if errors.Is(err, scheduler.ErrTaskRunning) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"ok": false, "error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"ok": true})
 }
+
+// Runs returns a task's run history, newest first: ?limit= (default 50,
+// max 200) and ?offset=.
+func (h *SchedulerHandler) Runs(c *gin.Context) {
+	name := c.Param("name")
+	s := scheduler.GetDefault()
+	if s == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "scheduler not ready"})
+		return
+	}
+	if !s.HasTask(name) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+	runs, total, err := s.Runs(name, parseIntQuery(c, "limit"), parseIntQuery(c, "offset"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": runs, "total": total})
+}
+
+// Pause stops a task's schedule until it is resumed; manual runs still work.
+func (h *SchedulerHandler) Pause(c *gin.Context) { h.setEnabled(c, false) }
+
+// Resume restarts a paused task's schedule.
+func (h *SchedulerHandler) Resume(c *gin.Context) { h.setEnabled(c, true) }
+
+func (h *SchedulerHandler) setEnabled(c *gin.Context, enabled bool) {
+	s := scheduler.GetDefault()
+	if s == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "scheduler not ready"})
+		return
+	}
+	name := c.Param("name")
+	before, err := s.Task(name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+	if err := s.SetEnabled(name, enabled); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	after, _ := s.Task(name)
+	recordAudit(c, "update", "scheduler_task", 0, before, after)
+	c.JSON(http.StatusOK, after)
+}
+
+// Update changes a task's schedule ("@every 5m" or a cron expression),
+// timeout_seconds, max_retries, retry_backoff_seconds or
+// alert_after_failures. Omitted fields are unchanged.
+func (h *SchedulerHandler) Update(c *gin.Context) {
+	s := scheduler.GetDefault()
+	if s == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "scheduler not ready"})
+		return
+	}
+	var in scheduler.TaskConfig
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	name := c.Param("name")
+	before, err := s.Task(name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+	after, err := s.Configure(name, in)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, scheduler.ErrTaskNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(c, "update", "scheduler_task", 0, before, after)
+	c.JSON(http.StatusOK, after)
+}
@@ -72,6 +72,7 @@ func (h *ServiceHandler) CreateService(c *gin.Context) {
         c.JSON(500, gin.H{"error": err.Error()})
         return
     }
+    recordAudit(c, "create", "service", input.ID, nil, input)
     c.JSON(201, input)
 }
 
@@ -90,11 +91,13 @@ func (h *ServiceHandler) UpdateService(c *gin.Context) {
     // enforce ownership by user
     if v, ok := c.Get("user_id"); ok { updates.UserID = v.(int) }
     userID := updates.UserID
+    before, _ := h.service.GetServiceByIDForUser(c.Request.Context(), id, userID)
     svc, err := h.service.UpdateServiceForUser(id, &updates, userID)
     if err != nil {
         c.JSON(404, gin.H{"error": "Service not found"})
         return
     }
+    recordAudit(c, "update", "service", id, before, svc)
     c.JSON(200, svc)
 }
 
@@ -107,6 +110,7 @@ func (h *ServiceHandler) DeleteService(c *gin.Context) {
 	}
     userID := 0
     if v, ok := c.Get("user_id"); ok { userID = v.(int) }
+    before, _ := h.service.GetServiceByIDForUser(c.Request.Context(), id, userID)
     if err := h.service.DeleteServiceForUser(id, userID); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.JSON(404, gin.H{"error": "Service not found"})
@@ -115,5 +119,6 @@ func (h *ServiceHandler) DeleteService(c *gin.Context) {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
+	recordAudit(c, "delete", "service", id, before, nil)
 	c.Status(204)
 }
@@ -37,6 +37,7 @@ func (h *SLOHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	recordAudit(c, "create", "slo", body.ID, nil, body)
 	c.JSON(http.StatusCreated, body)
 }
 
@@ -51,11 +52,13 @@ func (h *SLOHandler) Update(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	before, _ := h.svc.Get(c, id)
 	item, err := h.svc.Update(c, id, &body)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
+	recordAudit(c, "update", "slo", id, before, item)
 	c.JSON(http.StatusOK, item)
 }
 
@@ -65,9 +68,13 @@ func (h *SLOHandler) Delete(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 		return
 	}
+	before, _ := h.svc.Get(c, id)
 	if err := h.svc.Delete(c, id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if before != nil {
+		recordAudit(c, "delete", "slo", id, before, nil)
+	}
 	c.Status(http.StatusNoContent)
 }
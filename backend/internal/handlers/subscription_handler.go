@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"freelance-monitor-system/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SubscriptionHandler struct {
+	svc      *services.SubscriptionService
+	invoices *services.InvoiceService
+}
+
+func NewSubscriptionHandler(s *services.SubscriptionService, invoices *services.InvoiceService) *SubscriptionHandler {
+	return &SubscriptionHandler{svc: s, invoices: invoices}
+}
+
+// List returns subscriptions filtered by client_id, service_id and status.
+func (h *SubscriptionHandler) List(c *gin.Context) {
+	f := services.SubscriptionFilter{
+		ClientID:  parseIntQuery(c, "client_id"),
+		ServiceID: parseIntQuery(c, "service_id"),
+		Status:    strings.TrimSpace(c.Query("status")),
+		Limit:     parseIntQuery(c, "limit"),
+		Offset:    parseIntQuery(c, "offset"),
+	}
+	items, total, err := h.svc.List(c.Request.Context(), f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": total})
+}
+
+func (h *SubscriptionHandler) Get(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	sub, err := h.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Subscription not found"})
+		return
+	}
+	c.JSON(http.StatusOK, sub)
+}
+
+// Create starts a retainer:
+// { client_id, service_id, name, amount, currency, cycle, start_date, payment_terms, tax_name, tax_rate }.
+func (h *SubscriptionHandler) Create(c *gin.Context) {
+	var body services.SubscriptionInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sub, err := h.svc.Create(c.Request.Context(), body)
+	if err != nil {
+		writeSubscriptionError(c, err, "Client or service not found")
+		return
+	}
+	recordAudit(c, "create", "subscription", sub.ID, nil, sub)
+	c.JSON(http.StatusCreated, sub)
+}
+
+// Update changes name, amount, payment terms or tax. An amount change is
+// prorated from effective_at (default now) within the open cycle.
+func (h *SubscriptionHandler) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var body struct {
+		services.SubscriptionUpdate
+		EffectiveAt *time.Time `json:"effective_at"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	before, _ := h.svc.Get(c.Request.Context(), id)
+	sub, err := h.svc.Update(c.Request.Context(), id, body.SubscriptionUpdate, effectiveAt(body.EffectiveAt))
+	if err != nil {
+		writeSubscriptionError(c, err, "Subscription not found")
+		return
+	}
+	recordAudit(c, "update", "subscription", id, before, sub)
+	c.JSON(http.StatusOK, sub)
+}
+
+// Cancel ends the subscription at effective_at (default now); the final cycle
+// is prorated. Body (optional): { effective_at }.
+func (h *SubscriptionHandler) Cancel(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var body struct {
+		EffectiveAt *time.Time `json:"effective_at"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	before, _ := h.svc.Get(c.Request.Context(), id)
+	sub, err := h.svc.Cancel(c.Request.Context(), id, effectiveAt(body.EffectiveAt))
+	if err != nil {
+		writeSubscriptionError(c, err, "Subscription not found")
+		return
+	}
+	recordAudit(c, "cancel", "subscription", id, before, sub)
+	c.JSON(http.StatusOK, sub)
+}
+
+// Invoices lists the invoices generated for a subscription.
+func (h *SubscriptionHandler) Invoices(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	items, total, err := h.invoices.List(c.Request.Context(), services.InvoiceFilter{
+		SubscriptionID: id,
+		Limit:          parseIntQuery(c, "limit"),
+		Offset:         parseIntQuery(c, "offset"),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": total})
+}
+
+func effectiveAt(t *time.Time) time.Time {
+	if t == nil {
+		return time.Now()
+	}
+	return *t
+}
+
+func writeSubscriptionError(c *gin.Context, err error, notFound string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+	case errors.Is(err, services.ErrSubscriptionState):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"freelance-monitor-system/internal/models"
+	"freelance-monitor-system/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestSubscriptionHandlersLifecycle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Client{}, &models.Service{}, &models.MonthlyReport{}, &models.TimeEntry{}, &models.Subscription{},
+		&models.Invoice{}, &models.InvoiceItem{}, &models.InvoiceTax{}, &models.InvoicePayment{}, &models.NumberSequence{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	db.Create(&models.Client{Name: "Acme"})
+
+	h := NewSubscriptionHandler(services.NewSubscriptionService(db), services.NewInvoiceService(db))
+	r := gin.New()
+	r.GET("/api/subscriptions", h.List)
+	r.POST("/api/subscriptions", h.Create)
+	r.PUT("/api/subscriptions/:id", h.Update)
+	r.POST("/api/subscriptions/:id/cancel", h.Cancel)
+	r.GET("/api/subscriptions/:id/invoices", h.Invoices)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do("POST", "/api/subscriptions", `{"client_id":99,"name":"Retainer","amount":1000}`); w.Code != 404 {
+		t.Fatalf("expected 404 for missing client, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do("POST", "/api/subscriptions", `{"client_id":1,"name":"Retainer","amount":1000,"cycle":"weekly"}`); w.Code != 400 {
+		t.Fatalf("expected 400 for unknown cycle, got %d", w.Code)
+	}
+	w := do("POST", "/api/subscriptions", `{"client_id":1,"name":"Retainer","amount":3000,"start_date":"2025-01-01T00:00:00Z"}`)
+	if w.Code != 201 || !strings.Contains(w.Body.String(), `"cycle":"monthly"`) {
+		t.Fatalf("create: %d %s", w.Code, w.Body.String())
+	}
+	if w := do("PUT", "/api/subscriptions/1", `{"amount":6000,"effective_at":"2025-02-15T00:00:00Z"}`); w.Code != 200 || !strings.Contains(w.Body.String(), `"amount":6000`) {
+		t.Fatalf("update: %d %s", w.Code, w.Body.String())
+	}
+	// The January cycle ended before the change and was billed at the old amount.
+	w = do("GET", "/api/subscriptions/1/invoices", "")
+	if w.Code != 200 || !strings.Contains(w.Body.String(), `"total":3000`) || !strings.HasSuffix(w.Body.String(), `"total":1}`) {
+		t.Fatalf("invoices: %d %s", w.Code, w.Body.String())
+	}
+	if w := do("POST", "/api/subscriptions/1/cancel", `{"effective_at":"2025-03-01T00:00:00Z"}`); w.Code != 200 || !strings.Contains(w.Body.String(), `"status":"cancelled"`) {
+		t.Fatalf("cancel: %d %s", w.Code, w.Body.String())
+	}
+	if w := do("POST", "/api/subscriptions/1/cancel", ""); w.Code != 409 {
+		t.Fatalf("expected 409 on second cancel, got %d", w.Code)
+	}
+	if w := do("GET", "/api/subscriptions?status=cancelled", ""); w.Code != 200 || !strings.HasSuffix(w.Body.String(), `"total":1}`) {
+		t.Fatalf("list: %d %s", w.Code, w.Body.String())
+	}
+}
@@ -6,6 +6,7 @@ import (
     "strings"
 
     "freelance-monitor-system/internal/models"
+    "freelance-monitor-system/internal/services"
     "github.com/gin-gonic/gin"
     "gorm.io/gorm"
 )
@@ -80,6 +81,13 @@ func (h *TemplateHandler) Upsert(c *gin.Context) {
     if kind == "" {
         kind = "monthly"
     }
+    // Report templates are rendered server-side, so reject broken syntax now.
+    if kind == "monthly" || kind == "client_monthly" {
+        if fe := services.ValidateReportTemplate(req.Content); fe != nil {
+            writeFieldErrors(c, fe)
+            return
+        }
+    }
     uid := 0
     if v, ok := c.Get("user_id"); ok {
         uid = v.(int)
@@ -92,15 +100,18 @@ func (h *TemplateHandler) Upsert(c *gin.Context) {
             c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
             return
         }
+        recordAudit(c, "create", "template", t.ID, nil, t)
         c.JSON(http.StatusOK, t)
         return
     }
     // Update
+    before := t
     t.Content = req.Content
     if err := h.db.Save(&t).Error; err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
+    recordAudit(c, "update", "template", t.ID, before, t)
     c.JSON(http.StatusOK, t)
 }
 
@@ -112,11 +123,14 @@ func (h *TemplateHandler) Delete(c *gin.Context) {
     if v, ok := c.Get("user_id"); ok { uid = v.(int) }
     q := h.db.Where("id = ?", id)
     if uid > 0 { q = q.Where("user_id = ?", uid) } else { q = q.Where("user_id = 0") }
+    var before models.ReportTemplate
+    _ = q.Session(&gorm.Session{}).First(&before).Error
     res := q.Delete(&models.ReportTemplate{})
     if res.Error != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": res.Error.Error()})
         return
     }
     if res.RowsAffected == 0 { c.JSON(http.StatusNotFound, gin.H{"error": "not found"}); return }
+    recordAudit(c, "delete", "template", id, before, nil)
     c.Status(http.StatusNoContent)
 }
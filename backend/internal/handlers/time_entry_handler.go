@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"freelance-monitor-system/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// TimeEntryHandler serves the work log and invoicing of billable time.
+type TimeEntryHandler struct {
+	svc      *services.TimeEntryService
+	invoices *services.InvoiceService
+}
+
+func NewTimeEntryHandler(s *services.TimeEntryService, invoices *services.InvoiceService) *TimeEntryHandler {
+	return &TimeEntryHandler{svc: s, invoices: invoices}
+}
+
+// List returns entries filtered by client_id, service_id, from and to
+// (YYYY-MM-DD, inclusive), billable=true|false and uninvoiced=true.
+func (h *TimeEntryHandler) List(c *gin.Context) {
+	f := services.TimeEntryFilter{
+		ClientID:   parseIntQuery(c, "client_id"),
+		ServiceID:  parseIntQuery(c, "service_id"),
+		Uninvoiced: c.Query("uninvoiced") == "true",
+		Limit:      parseIntQuery(c, "limit"),
+		Offset:     parseIntQuery(c, "offset"),
+	}
+	if v := c.Query("billable"); v != "" {
+		b := v == "true"
+		f.Billable = &b
+	}
+	var ok bool
+	if f.From, ok = parseDayQuery(c, "from"); !ok {
+		return
+	}
+	if f.To, ok = parseDayQuery(c, "to"); !ok {
+		return
+	}
+	items, total, err := h.svc.List(c.Request.Context(), f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": total})
+}
+
+func (h *TimeEntryHandler) Get(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	entry, err := h.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		writeTimeEntryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// Create logs work: { client_id, service_id, date, minutes | hours,
+// description, billable, rate, currency }.
+func (h *TimeEntryHandler) Create(c *gin.Context) {
+	var body services.TimeEntryInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	entry, err := h.svc.Create(c.Request.Context(), body, c.GetInt("user_id"))
+	if err != nil {
+		writeTimeEntryError(c, err)
+		return
+	}
+	recordAudit(c, "create", "time_entry", entry.ID, nil, entry)
+	c.JSON(http.StatusCreated, entry)
+}
+
+func (h *TimeEntryHandler) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var body services.TimeEntryInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	before, _ := h.svc.Get(c.Request.Context(), id)
+	entry, err := h.svc.Update(c.Request.Context(), id, body)
+	if err != nil {
+		writeTimeEntryError(c, err)
+		return
+	}
+	recordAudit(c, "update", "time_entry", id, before, entry)
+	c.JSON(http.StatusOK, entry)
+}
+
+func (h *TimeEntryHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	before, _ := h.svc.Get(c.Request.Context(), id)
+	if err := h.svc.Delete(c.Request.Context(), id); err != nil {
+		writeTimeEntryError(c, err)
+		return
+	}
+	recordAudit(c, "delete", "time_entry", id, before, nil)
+	c.Status(http.StatusNoContent)
+}
+
+// Invoice bills a client's uninvoiced billable entries as a draft invoice:
+// { client_id, entry_ids, from, to, issue_date, due_date, payment_terms,
+// taxes: [{name, rate}], notes }. Without entry_ids every entry dated
+// from..to is billed.
+func (h *TimeEntryHandler) Invoice(c *gin.Context) {
+	var body struct {
+		services.TimeEntryInvoiceInput
+		From      string     `json:"from"`
+		To        string     `json:"to"`
+		IssueDate *time.Time `json:"issue_date"`
+		DueDate   *time.Time `json:"due_date"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	in := body.TimeEntryInvoiceInput
+	in.DueDate = body.DueDate
+	if body.IssueDate != nil {
+		in.IssueDate = *body.IssueDate
+	}
+	for key, v := range map[string]string{"from": body.From, "to": body.To} {
+		if strings.TrimSpace(v) == "" {
+			continue
+		}
+		d, err := time.Parse("2006-01-02", strings.TrimSpace(v))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + key + " (use YYYY-MM-DD)"})
+			return
+		}
+		if key == "from" {
+			in.From = &d
+		} else {
+			in.To = &d
+		}
+	}
+	inv, err := h.invoices.CreateFromTimeEntries(c.Request.Context(), in)
+	if err != nil {
+		writeInvoiceError(c, err, "Client not found")
+		return
+	}
+	recordAudit(c, "create", "invoice", inv.ID, nil, inv)
+	c.JSON(http.StatusCreated, inv)
+}
+
+// parseDayQuery reads an optional YYYY-MM-DD query parameter.
+func parseDayQuery(c *gin.Context, key string) (*time.Time, bool) {
+	v := strings.TrimSpace(c.Query(key))
+	if v == "" {
+		return nil, true
+	}
+	d, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + key + " (use YYYY-MM-DD)"})
+		return nil, false
+	}
+	return &d, true
+}
+
+func writeTimeEntryError(c *gin.Context, err error) {
+	var fe services.FieldErrors
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Time entry not found"})
+	case errors.Is(err, services.ErrTimeEntryInvoiced):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &fe):
+		writeFieldErrors(c, fe)
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -64,10 +64,11 @@ func (jr *JobRunner) RefreshExpiries(ctx context.Context) error {
 	return nil
 }
 
-// GenerateDailyReport triggers daily aggregation using ReportService.
+// GenerateDailyReport aggregates the previous day using ReportService; it is
+// scheduled shortly after midnight.
 func (jr *JobRunner) GenerateDailyReport(ctx context.Context) error {
 	rs := services.NewReportService(jr.DB)
-	return rs.GenerateDailyReport(ctx, time.Now())
+	return rs.GenerateDailyReport(ctx, time.Now().AddDate(0, 0, -1))
 }
 
 // CheckHeartbeats looks for missed heartbeats and raises alerts.
@@ -119,6 +120,50 @@ func (jr *JobRunner) SendOfferReminders(ctx context.Context) error {
             days = n
         }
     }
-    _, _ = rs.SendOfferExpiryReminders(ctx, time.Duration(days)*24*time.Hour)
-    return nil
+    _, err := rs.SendOfferExpiryReminders(ctx, time.Duration(days)*24*time.Hour)
+    return err
+}
+
+// ProcessInvoices flags overdue invoices and emails payment reminders.
+func (jr *JobRunner) ProcessInvoices(ctx context.Context) error {
+	is := services.NewInvoiceService(jr.DB)
+	now := time.Now()
+	if _, err := is.MarkOverdue(ctx, now); err != nil {
+		return err
+	}
+	_, err := is.SendOverdueReminders(ctx, now)
+	return err
+}
+
+// BillSubscriptions generates invoices for retainer cycles that have ended.
+func (jr *JobRunner) BillSubscriptions(ctx context.Context) error {
+	_, err := services.NewSubscriptionService(jr.DB).GenerateDueInvoices(ctx, time.Now())
+	return err
+}
+
+// ExpireOffers marks sent or viewed offers past their valid_until as expired.
+func (jr *JobRunner) ExpireOffers(ctx context.Context) error {
+	_, err := services.NewOfferService(jr.DB).ExpireOffers(ctx, time.Now())
+	return err
+}
+
+// RenewOffers clones auto-renewing offers that are due into new drafts.
+func (jr *JobRunner) RenewOffers(ctx context.Context) error {
+	_, err := services.NewOfferService(jr.DB).RenewDueOffers(ctx, time.Now())
+	return err
+}
+
+// DeliverMonthlyReports emails last month's reports once the day of month
+// reaches MONTHLY_REPORT_DAY (default 1).
+func (jr *JobRunner) DeliverMonthlyReports(ctx context.Context) error {
+	now := time.Now()
+	day := 1
+	if v, err := strconv.Atoi(os.Getenv("MONTHLY_REPORT_DAY")); err == nil && v >= 1 && v <= 28 {
+		day = v
+	}
+	if now.Day() < day {
+		return nil
+	}
+	_, err := services.NewReportDeliveryService(jr.DB).DeliverMonthly(ctx, now)
+	return err
 }
@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"freelance-monitor-system/internal/services"
+)
+
+// taskFailedAlert is the alert type raised for failing scheduler tasks. They
+// are not tied to a service, so their service_id is 0.
+const taskFailedAlert = "task_failed"
+
+func taskAlertTitle(task string) string { return "Scheduled task failing: " + task }
+
+// TaskFailing raises a critical alert for a task that keeps failing and, when
+// SCHEDULER_ALERT_EMAIL is set, emails it there.
+func (jr *JobRunner) TaskFailing(ctx context.Context, task string, failures int, err error) {
+	title := taskAlertTitle(task)
+	msg := fmt.Sprintf("%s failed %d times in a row; last error: %v", task, failures, err)
+	var open int64
+	jr.DB.WithContext(ctx).Model(&models.Alert{}).
+		Where("alert_type = ? AND title = ? AND is_resolved = ?", taskFailedAlert, title, false).Count(&open)
+	if open == 0 {
+		alert := models.Alert{AlertType: taskFailedAlert, Level: "critical", Title: title, Message: msg}
+		if err := jr.DB.WithContext(ctx).Create(&alert).Error; err != nil {
+			log.Printf("jobs: raising alert for %s: %v", task, err)
+		}
+	}
+	if to := os.Getenv("SCHEDULER_ALERT_EMAIL"); to != "" {
+		_ = services.NewMailer().SendGenericEmail(to, title, msg)
+	}
+}
+
+// TaskRecovered resolves the task's open failure alerts.
+func (jr *JobRunner) TaskRecovered(ctx context.Context, task string) {
+	now := time.Now()
+	if err := jr.DB.WithContext(ctx).Model(&models.Alert{}).
+		Where("alert_type = ? AND title = ? AND is_resolved = ?", taskFailedAlert, taskAlertTitle(task), false).
+		Updates(map[string]any{"is_resolved": true, "resolved_at": &now}).Error; err != nil {
+		log.Printf("jobs: resolving alerts of %s: %v", task, err)
+	}
+}
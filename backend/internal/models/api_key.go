@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// APIKey is a long-lived, user-owned credential for automation and CI.
+// Only a SHA-256 hash of the key is stored; the plaintext is shown once on creation.
+type APIKey struct {
+	ID         int        `json:"id" gorm:"primaryKey"`
+	UserID     int        `json:"user_id" gorm:"index;not null"`
+	Name       string     `json:"name" gorm:"not null"`
+	Prefix     string     `json:"prefix" gorm:"index;size:16;not null"` // first characters of the key, for display
+	KeyHash    string     `json:"-" gorm:"uniqueIndex;size:64;not null"`
+	Scopes     string     `json:"scopes"` // comma-separated, e.g. "services:read,checks:run"
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (APIKey) TableName() string { return "api_keys" }
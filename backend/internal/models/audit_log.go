@@ -0,0 +1,32 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrAuditAppendOnly is returned when code attempts to modify or delete an audit entry.
+var ErrAuditAppendOnly = errors.New("audit log is append-only")
+
+// AuditLog records who changed what for create, update and delete actions.
+type AuditLog struct {
+	ID         int       `json:"id" gorm:"primaryKey"`
+	ActorID    int       `json:"actor_id" gorm:"index"` // user_id from the JWT/API key; 0 when unauthenticated
+	AuthMethod string    `json:"auth_method"`           // jwt, api_key, or empty
+	Action     string    `json:"action" gorm:"index;not null"`
+	EntityType string    `json:"entity_type" gorm:"index:idx_audit_entity;not null"` // client, offer, service, heartbeat, slo, template
+	EntityID   int       `json:"entity_id" gorm:"index:idx_audit_entity"`
+	Before     string    `json:"before" gorm:"type:text"`  // JSON snapshot, empty on create
+	After      string    `json:"after" gorm:"type:text"`   // JSON snapshot, empty on delete
+	Changes    string    `json:"changes" gorm:"type:text"` // JSON object of field -> {from, to}
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+func (AuditLog) TableName() string { return "audit_logs" }
+
+func (AuditLog) BeforeUpdate(tx *gorm.DB) error { return ErrAuditAppendOnly }
+
+func (AuditLog) BeforeDelete(tx *gorm.DB) error { return ErrAuditAppendOnly }
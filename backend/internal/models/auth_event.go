@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// AuthEvent is an append-only audit record of authentication activity.
+type AuthEvent struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	UserID    int       `json:"user_id" gorm:"index"` // 0 when the account is unknown
+	Email     string    `json:"email" gorm:"index"`
+	IP        string    `json:"ip"`
+	Event     string    `json:"event" gorm:"index;not null"` // login_success, login_failure, login_throttled, account_locked, reset_requested, reset_throttled, reset_completed
+	Detail    string    `json:"detail"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+func (AuthEvent) TableName() string { return "auth_events" }
+
+// LoginThrottle tracks consecutive failures for an account, IP or reset target.
+type LoginThrottle struct {
+	ID            int        `json:"id" gorm:"primaryKey"`
+	Key           string     `json:"key" gorm:"uniqueIndex;not null"` // e.g. "account:a@b.com", "ip:1.2.3.4"
+	Failures      int        `json:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until"`
+	UpdatedAt     time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (LoginThrottle) TableName() string { return "login_throttles" }
@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// CatalogItem is a product or service with a default price, picked into offer
+// lines and offer templates. Inactive items are kept for history but hidden
+// from pickers.
+type CatalogItem struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	SKU         string    `json:"sku" gorm:"index"`
+	Name        string    `json:"name" gorm:"uniqueIndex;not null"`
+	Description string    `json:"description"`
+	Unit        string    `json:"unit"`
+	UnitPrice   float64   `json:"unit_price" gorm:"not null"`
+	Currency    string    `json:"currency" gorm:"default:'IDR'"`
+	TaxRate     float64   `json:"tax_rate"` // percent
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (CatalogItem) TableName() string { return "catalog_items" }
+
+// OfferTemplate prefills the issuer, signature, proposal and terms fields of
+// new offers and their default line items.
+type OfferTemplate struct {
+	ID               int                 `json:"id" gorm:"primaryKey"`
+	Name             string              `json:"name" gorm:"uniqueIndex;not null"`
+	Subject          string              `json:"subject"`
+	OfferTitle       string              `json:"offer_title"`
+	Currency         string              `json:"currency"`
+	ValidDays        int                 `json:"valid_days"` // valid_until = offer date + ValidDays when > 0
+	IssuerName       string              `json:"issuer_name"`
+	IssuerCompany    string              `json:"issuer_company"`
+	IssuerAddress    string              `json:"issuer_address"`
+	IssuerCity       string              `json:"issuer_city"`
+	IssuerPhone      string              `json:"issuer_phone"`
+	IssuerEmail      string              `json:"issuer_email"`
+	ProposalSummary  string              `json:"proposal_summary"`
+	ProposalDetails  string              `json:"proposal_details"`
+	PaymentTerms     string              `json:"payment_terms"`
+	ClosingText      string              `json:"closing_text"`
+	Notes            string              `json:"notes"`
+	SignatureTitle   string              `json:"signature_title"`
+	SignatureCompany string              `json:"signature_company"`
+	SignatureCity    string              `json:"signature_city"`
+	CreatedAt        time.Time           `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time           `json:"updated_at" gorm:"autoUpdateTime"`
+	Items            []OfferTemplateItem `json:"items" gorm:"foreignKey:TemplateID"`
+}
+
+func (OfferTemplate) TableName() string { return "offer_templates" }
+
+// OfferTemplateItem is a default line of an offer template. With a
+// CatalogItemID, empty fields are filled from the catalog when the offer is
+// created.
+type OfferTemplateItem struct {
+	ID              int     `json:"id" gorm:"primaryKey"`
+	TemplateID      int     `json:"template_id" gorm:"index;not null"`
+	Position        int     `json:"position"`
+	CatalogItemID   *int    `json:"catalog_item_id" gorm:"index"`
+	Name            string  `json:"name"`
+	Description     string  `json:"description"`
+	Qty             float64 `json:"qty"`
+	Unit            string  `json:"unit"`
+	UnitPrice       float64 `json:"unit_price"`
+	DiscountPercent float64 `json:"discount_percent"`
+	TaxRate         float64 `json:"tax_rate"`
+}
+
+func (OfferTemplateItem) TableName() string { return "offer_template_items" }
@@ -7,10 +7,13 @@ import (
 type Client struct {
 	ID            int       `json:"id" gorm:"primaryKey"`
 	Name          string    `json:"name" gorm:"not null"`
+	Code          string    `json:"code"` // short code for document numbers, e.g. ACME
 	ContactPerson string    `json:"contact_person"`
 	Email         string    `json:"email"`
 	Phone         string    `json:"phone"`
 	Address       string    `json:"address"`
+	ReportOptIn   bool      `json:"report_opt_in" gorm:"default:false"` // email the monthly report PDF automatically
+	ReportCC      string    `json:"report_cc"`                          // comma-separated CC addresses for report emails
 	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
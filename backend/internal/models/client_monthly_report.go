@@ -0,0 +1,96 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ClientMonthlyReport consolidates one month of every service a client has,
+// built from the per-service MonthlyReport rows. There is one per client and
+// month.
+type ClientMonthlyReport struct {
+	ID               int       `json:"id" gorm:"primaryKey"`
+	ClientID         int       `json:"client_id" gorm:"not null;uniqueIndex:idx_client_report_month,priority:1"`
+	ReportMonth      time.Time `json:"report_month" gorm:"type:date;not null;uniqueIndex:idx_client_report_month,priority:2"`
+	UserID           int       `json:"user_id" gorm:"index"`
+	ServiceCount     int       `json:"service_count"`
+	AvgUptimePercent float64   `json:"avg_uptime_percent"` // mean over services with data
+	TotalDowntime    int       `json:"total_downtime"`
+	Incidents        int       `json:"incidents"`
+	AlertsResolved   int       `json:"alerts_resolved"`
+	MaintenanceHours float64   `json:"maintenance_hours"`
+	SLOsMet          int       `json:"slos_met" gorm:"column:slos_met"`
+	SLOsTotal        int       `json:"slos_total" gorm:"column:slos_total"`
+	Summary          string    `json:"summary" gorm:"type:text"`
+	// Services is stored as JSON in the services column.
+	Services     []ClientServiceSummary `json:"services" gorm:"-"`
+	ServicesJSON string                 `json:"-" gorm:"column:services;type:text"`
+	FinalizedAt  *time.Time             `json:"finalized_at"`
+	CreatedAt    time.Time              `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time              `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (ClientMonthlyReport) TableName() string { return "client_monthly_reports" }
+
+func (r *ClientMonthlyReport) BeforeSave(tx *gorm.DB) error {
+	b, err := json.Marshal(r.Services)
+	if err != nil {
+		return err
+	}
+	r.ServicesJSON = string(b)
+	return nil
+}
+
+func (r *ClientMonthlyReport) AfterFind(tx *gorm.DB) error {
+	r.Services = nil
+	if r.ServicesJSON == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(r.ServicesJSON), &r.Services)
+}
+
+// ClientServiceSummary is one service's section of a client report.
+type ClientServiceSummary struct {
+	ServiceID        int                `json:"service_id"`
+	Domain           string             `json:"domain"`
+	ServiceType      string             `json:"service_type"`
+	MonthlyReportID  *int               `json:"monthly_report_id"` // nil when the service had no data
+	UptimePercent    float64            `json:"uptime_percent"`
+	AvgResponseMs    int                `json:"avg_response_ms"`
+	TotalDowntime    int                `json:"total_downtime"`
+	Incidents        int                `json:"incidents"` // uptime and missed heartbeat alerts raised
+	AlertsResolved   int                `json:"alerts_resolved"`
+	SSLExpiry        *time.Time         `json:"ssl_expiry"`
+	SSLStatus        string             `json:"ssl_status"` // ok, expiring, expired, unknown
+	DomainExpiry     *time.Time         `json:"domain_expiry"`
+	DomainStatus     string             `json:"domain_status"`
+	Heartbeats       []HeartbeatSummary `json:"heartbeats"`
+	MaintenanceHours float64            `json:"maintenance_hours"`
+	Activities       []ReportActivity   `json:"activities"`
+	SLOs             []SLOAttainment    `json:"slos"`
+}
+
+// HeartbeatSummary is a heartbeat job's state as of the end of the month.
+type HeartbeatSummary struct {
+	Name            string     `json:"name"`
+	Status          string     `json:"status"` // healthy, late, paused, never
+	LastHeartbeatAt *time.Time `json:"last_heartbeat_at"`
+}
+
+// SLOAttainment compares an SLO target with the month's result: uptime
+// percent for availability, average response ms for latency.
+type SLOAttainment struct {
+	Objective string  `json:"objective"`
+	Target    float64 `json:"target"`
+	Achieved  float64 `json:"achieved"`
+	Met       bool    `json:"met"`
+}
+
+// ReportActivity is a dated maintenance activity, as listed in
+// MonthlyReport.Activities.
+type ReportActivity struct {
+	Date        string `json:"date"`
+	Description string `json:"description"`
+}
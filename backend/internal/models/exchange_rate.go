@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// ExchangeRate is the value of one unit of Currency in the base currency,
+// effective from Date until the next rate for the same currency.
+type ExchangeRate struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	Currency  string    `json:"currency" gorm:"uniqueIndex:idx_exchange_rate_day;not null"`
+	Date      time.Time `json:"date" gorm:"uniqueIndex:idx_exchange_rate_day;not null"`
+	Rate      float64   `json:"rate" gorm:"not null"`
+	Source    string    `json:"source"` // manual or import
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (ExchangeRate) TableName() string { return "exchange_rates" }
@@ -0,0 +1,88 @@
+package models
+
+import "time"
+
+// Invoice statuses.
+const (
+	InvoiceDraft   = "draft"
+	InvoiceSent    = "sent"
+	InvoicePaid    = "paid"
+	InvoiceOverdue = "overdue"
+	InvoiceVoid    = "void"
+)
+
+// Invoice bills a client, typically generated from an accepted offer.
+type Invoice struct {
+	ID              int              `json:"id" gorm:"primaryKey"`
+	InvoiceNumber   string           `json:"invoice_number" gorm:"uniqueIndex;not null"` // INV/YYYY/NNNN
+	OfferID         *int             `json:"offer_id" gorm:"index;uniqueIndex:idx_invoice_offer_active,where:status <> 'void'"`
+	SubscriptionID  *int             `json:"subscription_id" gorm:"uniqueIndex:idx_invoice_subscription_period"`
+	PeriodStart     *time.Time       `json:"period_start" gorm:"uniqueIndex:idx_invoice_subscription_period"`
+	PeriodEnd       *time.Time       `json:"period_end"`
+	MonthlyReportID *int             `json:"monthly_report_id" gorm:"index"`
+	ClientID        int              `json:"client_id" gorm:"index;not null"`
+	Status          string           `json:"status" gorm:"index;default:'draft'"`
+	IssueDate       time.Time        `json:"issue_date" gorm:"not null"`
+	DueDate         time.Time        `json:"due_date" gorm:"index;not null"`
+	Currency        string           `json:"currency" gorm:"default:'IDR'"`
+	ExchangeRate    float64          `json:"exchange_rate"` // to the base currency; 0 if unknown
+	Subtotal        float64          `json:"subtotal"`
+	TaxTotal        float64          `json:"tax_total"`
+	Total           float64          `json:"total"`
+	AmountPaid      float64          `json:"amount_paid"`
+	PaymentTerms    string           `json:"payment_terms"`
+	Notes           string           `json:"notes"`
+	PDFURL          string           `json:"pdf_url"`
+	SentAt          *time.Time       `json:"sent_at"`
+	PaidAt          *time.Time       `json:"paid_at"`
+	VoidedAt        *time.Time       `json:"voided_at"`
+	LastReminderAt  *time.Time       `json:"last_reminder_at"`
+	CreatedAt       time.Time        `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
+	Items           []InvoiceItem    `json:"items,omitempty" gorm:"foreignKey:InvoiceID"`
+	Taxes           []InvoiceTax     `json:"taxes,omitempty" gorm:"foreignKey:InvoiceID"`
+	Payments        []InvoicePayment `json:"payments,omitempty" gorm:"foreignKey:InvoiceID"`
+}
+
+func (Invoice) TableName() string { return "invoices" }
+
+// Balance is the amount still owed.
+func (i Invoice) Balance() float64 { return i.Total - i.AmountPaid }
+
+// InvoiceItem is a billed line, copied from the offer at generation time.
+type InvoiceItem struct {
+	ID          int     `json:"id" gorm:"primaryKey"`
+	InvoiceID   int     `json:"invoice_id" gorm:"index;not null"`
+	Position    int     `json:"position"`
+	Description string  `json:"description" gorm:"not null"`
+	Qty         float64 `json:"qty"`
+	UnitPrice   float64 `json:"unit_price"`
+	Total       float64 `json:"total"`
+}
+
+func (InvoiceItem) TableName() string { return "invoice_items" }
+
+// InvoiceTax is a tax applied to the invoice subtotal, e.g. PPN 11%.
+type InvoiceTax struct {
+	ID        int     `json:"id" gorm:"primaryKey"`
+	InvoiceID int     `json:"invoice_id" gorm:"index;not null"`
+	Name      string  `json:"name" gorm:"not null"`
+	Rate      float64 `json:"rate"` // percent
+	Amount    float64 `json:"amount"`
+}
+
+func (InvoiceTax) TableName() string { return "invoice_taxes" }
+
+// InvoicePayment records a full or partial payment.
+type InvoicePayment struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	InvoiceID int       `json:"invoice_id" gorm:"index;not null"`
+	Amount    float64   `json:"amount" gorm:"not null"`
+	PaidAt    time.Time `json:"paid_at" gorm:"not null"`
+	Method    string    `json:"method"`
+	Reference string    `json:"reference"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (InvoicePayment) TableName() string { return "invoice_payments" }
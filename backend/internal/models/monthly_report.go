@@ -7,8 +7,8 @@ import (
 // MonthlyReport aggregates daily reports into a monthly summary
 type MonthlyReport struct {
     ID                int       `json:"id" gorm:"primaryKey"`
-    ReportMonth       time.Time `json:"report_month" gorm:"index;type:date"`
-    ServiceID         int       `json:"service_id" gorm:"index;not null"`
+    ReportMonth       time.Time `json:"report_month" gorm:"index;uniqueIndex:idx_monthly_report_month,priority:2;type:date"`
+    ServiceID         int       `json:"service_id" gorm:"index;uniqueIndex:idx_monthly_report_month,priority:1;not null"`
     UserID            int       `json:"user_id" gorm:"index"`
     AvgUptimePercent  float64   `json:"avg_uptime_percent"`
     AvgResponseMs     int       `json:"avg_response_ms"`
@@ -19,6 +19,9 @@ type MonthlyReport struct {
     CreatedAt         time.Time `json:"created_at" gorm:"autoCreateTime"`
     Activities        string    `json:"activities" gorm:"type:text"` // JSON array of maintenance activities
     Summary           string    `json:"summary" gorm:"type:text"`
+    // FinalizedAt is set once the report has been sent; finalized reports
+    // can no longer be regenerated or edited.
+    FinalizedAt       *time.Time `json:"finalized_at"`
 }
 
 func (MonthlyReport) TableName() string { return "monthly_reports" }
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultWorkspace is the workspace used until the app is multi-tenant.
+const DefaultWorkspace = "default"
+
+// SequenceOffer names the offer number sequence.
+const SequenceOffer = "offer"
+
+// DefaultOfferNumberFormat reproduces the historical 038/MSI-DD/MM/YYYY numbers.
+const DefaultOfferNumberFormat = "{seq:3}/MSI-{dd}/{mm}/{yyyy}"
+
+// SequenceInvoice prefixes the per-year invoice sequences (invoice/2025).
+const SequenceInvoice = "invoice"
+
+// DefaultInvoiceNumberFormat renders INV/YYYY/NNNN.
+const DefaultInvoiceNumberFormat = "INV/{yyyy}/{seq:4}"
+
+// NumberSequence is a per-workspace counter used to number documents. Value
+// is the last number handed out; with ResetYearly it restarts at 1 when the
+// year (Period) changes.
+type NumberSequence struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	Workspace   string    `json:"workspace" gorm:"uniqueIndex:idx_number_sequence;not null"`
+	Name        string    `json:"name" gorm:"uniqueIndex:idx_number_sequence;not null"`
+	Format      string    `json:"format" gorm:"not null"`
+	ResetYearly bool      `json:"reset_yearly"`
+	Period      int       `json:"period"`
+	Value       int       `json:"value"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (NumberSequence) TableName() string { return "number_sequences" }
+
+// NextSequence increments the named sequence inside tx and returns the new
+// value with the sequence's format. The increment is a single UPDATE, so
+// concurrent transactions serialize on the row instead of reading the same
+// maximum. A missing sequence is created with defaultFormat.
+func NextSequence(tx *gorm.DB, workspace, name, defaultFormat string, at time.Time) (int, string, error) {
+	tx = tx.Session(&gorm.Session{NewDB: true})
+	seed := NumberSequence{Workspace: workspace, Name: name, Format: defaultFormat}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&seed).Error; err != nil {
+		return 0, "", err
+	}
+	year := at.Year()
+	res := tx.Model(&NumberSequence{}).
+		Where("workspace = ? AND name = ?", workspace, name).
+		Updates(map[string]interface{}{
+			"value":  gorm.Expr("CASE WHEN reset_yearly AND period <> ? THEN 1 ELSE value + 1 END", year),
+			"period": gorm.Expr("CASE WHEN reset_yearly THEN ? ELSE period END", year),
+		})
+	if res.Error != nil {
+		return 0, "", res.Error
+	}
+	var seq NumberSequence
+	if err := tx.Where("workspace = ? AND name = ?", workspace, name).First(&seq).Error; err != nil {
+		return 0, "", err
+	}
+	return seq.Value, seq.Format, nil
+}
+
+var numberToken = regexp.MustCompile(`\{(seq(?::\d+)?|dd|mm|yyyy|yy|client)\}`)
+
+// FormatNumber expands a number template. Tokens: {seq} or {seq:N} (zero
+// padded to N digits), {dd}, {mm}, {yyyy}, {yy} and {client}.
+func FormatNumber(format string, seq int, date time.Time, clientCode string) string {
+	return numberToken.ReplaceAllStringFunc(format, func(tok string) string {
+		tok = strings.Trim(tok, "{}")
+		switch {
+		case strings.HasPrefix(tok, "seq"):
+			width := 0
+			if i := strings.IndexByte(tok, ':'); i > 0 {
+				width, _ = strconv.Atoi(tok[i+1:])
+			}
+			return fmt.Sprintf("%0*d", width, seq)
+		case tok == "dd":
+			return fmt.Sprintf("%02d", date.Day())
+		case tok == "mm":
+			return fmt.Sprintf("%02d", int(date.Month()))
+		case tok == "yyyy":
+			return fmt.Sprintf("%04d", date.Year())
+		case tok == "yy":
+			return fmt.Sprintf("%02d", date.Year()%100)
+		default:
+			return clientCode
+		}
+	})
+}
+
+// ValidateNumberFormat checks that a template contains {seq} and only known tokens.
+func ValidateNumberFormat(format string) error {
+	if strings.TrimSpace(format) == "" {
+		return fmt.Errorf("format is required")
+	}
+	stripped := numberToken.ReplaceAllString(format, "")
+	if strings.ContainsAny(stripped, "{}") {
+		return fmt.Errorf("unknown token in %q; use {seq}, {seq:N}, {dd}, {mm}, {yyyy}, {yy} or {client}", format)
+	}
+	if !strings.Contains(format, "{seq") {
+		return fmt.Errorf("format must contain {seq}")
+	}
+	return nil
+}
+
+// ClientCode is the client's Code, or up to three letters of its name.
+func ClientCode(c *Client) string {
+	if c == nil {
+		return ""
+	}
+	if code := strings.TrimSpace(c.Code); code != "" {
+		return strings.ToUpper(code)
+	}
+	code := make([]rune, 0, 3)
+	for _, r := range c.Name {
+		if len(code) < 3 && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			code = append(code, unicode.ToUpper(r))
+		}
+	}
+	return string(code)
+}
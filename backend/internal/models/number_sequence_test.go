@@ -0,0 +1,65 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestFormatNumber(t *testing.T) {
+	date := time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC)
+	cases := map[string]string{
+		DefaultOfferNumberFormat: "007/MSI-07/03/2026",
+		"OF-{yy}{mm}-{seq:4}":    "OF-2603-0007",
+		"{client}/{yyyy}/{seq}":  "ACM/2026/7",
+		"literal {seq:2} {dd}.":  "literal 07 07.",
+	}
+	for format, want := range cases {
+		if got := FormatNumber(format, 7, date, "ACM"); got != want {
+			t.Errorf("FormatNumber(%q) = %q, want %q", format, got, want)
+		}
+	}
+	if err := ValidateNumberFormat("{seq}-{foo}"); err == nil {
+		t.Errorf("expected unknown token to be rejected")
+	}
+	if err := ValidateNumberFormat("{yyyy}"); err == nil {
+		t.Errorf("expected format without {seq} to be rejected")
+	}
+	if got := ClientCode(&Client{Name: "pt. acme"}); got != "PTA" {
+		t.Errorf("ClientCode = %q, want PTA", got)
+	}
+}
+
+func TestNextSequenceResetsYearly(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&NumberSequence{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	next := func(year int) int {
+		v, _, err := NextSequence(db, DefaultWorkspace, "test", "{seq}", time.Date(year, 6, 1, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatalf("next: %v", err)
+		}
+		return v
+	}
+	if a, b := next(2025), next(2026); a != 1 || b != 2 {
+		t.Fatalf("expected 1,2 without reset, got %d,%d", a, b)
+	}
+	if err := db.Model(&NumberSequence{}).Where("name = ?", "test").Updates(map[string]interface{}{"reset_yearly": true, "period": 2026}).Error; err != nil {
+		t.Fatalf("enable reset: %v", err)
+	}
+	if got := next(2026); got != 3 {
+		t.Fatalf("expected 3 within the same year, got %d", got)
+	}
+	if got := next(2027); got != 1 {
+		t.Fatalf("expected reset to 1 in a new year, got %d", got)
+	}
+	if got := next(2027); got != 2 {
+		t.Fatalf("expected 2 after reset, got %d", got)
+	}
+}
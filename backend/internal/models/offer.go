@@ -1,29 +1,74 @@
 package models
 
 import (
+	"encoding/json"
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// Offer statuses. Status changes only through the transitions below.
+const (
+	OfferDraft      = "draft"
+	OfferSent       = "sent"
+	OfferViewed     = "viewed"
+	OfferAccepted   = "accepted"
+	OfferRejected   = "rejected"
+	OfferExpired    = "expired"
+	OfferSuperseded = "superseded"
+)
+
+// OfferTransitions lists the statuses each status may move to. Moving a sent,
+// viewed or rejected offer back to draft starts a new revision.
+var OfferTransitions = map[string][]string{
+	OfferDraft:      {OfferSent, OfferSuperseded},
+	OfferSent:       {OfferDraft, OfferViewed, OfferAccepted, OfferRejected, OfferExpired, OfferSuperseded},
+	OfferViewed:     {OfferDraft, OfferAccepted, OfferRejected, OfferExpired, OfferSuperseded},
+	OfferAccepted:   {OfferSuperseded},
+	OfferRejected:   {OfferDraft, OfferSuperseded},
+	OfferExpired:    {OfferSuperseded},
+	OfferSuperseded: {},
+}
+
+// CanTransitionOffer reports whether an offer may move from one status to another.
+func CanTransitionOffer(from, to string) bool {
+	for _, s := range OfferTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
 type Offer struct {
-	ID           int        `json:"id" gorm:"primaryKey"`
-	OfferNumber  string     `json:"offer_number" gorm:"unique;not null"`
-	ClientID     int        `json:"client_id" gorm:"not null"`
-	Date         time.Time  `json:"date" gorm:"not null"`
-	Subject      string     `json:"subject" gorm:"not null"`
-	Items        string     `json:"items" gorm:"type:jsonb;not null"`
-	TotalPrice   float64    `json:"total_price" gorm:"not null"`
-	Notes        string     `json:"notes"`
-	Status       string     `json:"status" gorm:"default:'draft'"`
-	PDFURL       string     `json:"pdf_url"`
-	SignedDocURL string     `json:"signed_doc_url"`
-	ApprovedAt   *time.Time `json:"approved_at"`
+	ID          int        `json:"id" gorm:"primaryKey"`
+	OfferNumber string     `json:"offer_number" gorm:"unique;not null"`
+	ClientID    int        `json:"client_id" gorm:"not null"`
+	Date        time.Time  `json:"date" gorm:"not null"`
+	Subject     string     `json:"subject" gorm:"not null"`
+	Items       OfferItems `json:"items" gorm:"foreignKey:OfferID"`
+	// Totals are computed from Items by the server; see services.PriceOffer.
+	Subtotal      float64    `json:"subtotal"`
+	DiscountTotal float64    `json:"discount_total"`
+	TaxTotal      float64    `json:"tax_total"`
+	TotalPrice    float64    `json:"total_price" gorm:"not null"`
+	Notes         string     `json:"notes"`
+	Status        string     `json:"status" gorm:"default:'draft'"`
+	PDFURL        string     `json:"pdf_url"`
+	SignedDocURL  string     `json:"signed_doc_url"`
+	ApprovedAt    *time.Time `json:"approved_at"`
+	// Revision is the number of the latest sent revision (0 until first sent);
+	// AcceptedRevision is the revision the client accepted.
+	Revision         int  `json:"revision" gorm:"default:0"`
+	AcceptedRevision *int `json:"accepted_revision"`
 	// Additional fields for detailed PDF and form
-	Currency         string     `json:"currency" gorm:"default:'IDR'"`
+	Currency string `json:"currency" gorm:"default:'IDR'"`
+	// ExchangeRate converts Currency to the base currency. It is snapshotted
+	// when the offer is sent and again when accepted; 0 means not yet known.
+	ExchangeRate     float64    `json:"exchange_rate"`
+	ExchangeRateDate *time.Time `json:"exchange_rate_date"`
 	ValidUntil       *time.Time `json:"valid_until"`
 	IssuerName       string     `json:"issuer_name"`
 	IssuerCompany    string     `json:"issuer_company"`
@@ -44,44 +89,147 @@ type Offer struct {
 	AutoRenew      bool      `json:"auto_renew" gorm:"default:false"`
 	RenewEveryDays int       `json:"renew_every_days" gorm:"default:30"`
 	NextRenewal    time.Time `json:"next_renewal"`
+	// RenewedFromID is the offer this one was auto-renewed from.
+	RenewedFromID *int `json:"renewed_from_id" gorm:"index"`
 	// Reminders
 	LastReminderAt *time.Time `json:"last_reminder_at"`
-	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
 }
 
 func (Offer) TableName() string {
 	return "offers"
 }
 
-// BeforeCreate generates an offer number if not provided.
+// BeforeCreate numbers the offer from the workspace's offer sequence unless a
+// number is provided. See NextSequence and FormatNumber.
 func (o *Offer) BeforeCreate(tx *gorm.DB) (err error) {
+	if o.Date.IsZero() {
+		o.Date = time.Now()
+	}
 	if o.OfferNumber != "" {
-		// Ensure date default even when offer number is provided externally
-		if o.Date.IsZero() {
-			o.Date = time.Now()
-		}
 		return nil
 	}
-	var offers []Offer
-	if err := tx.Model(&Offer{}).Select("offer_number").Find(&offers).Error; err != nil {
+	seq, format, err := NextSequence(tx, DefaultWorkspace, SequenceOffer, DefaultOfferNumberFormat, o.Date)
+	if err != nil {
 		return err
 	}
-	maxSeq := 0
-	for _, of := range offers {
-		parts := strings.SplitN(of.OfferNumber, "/", 2)
-		if len(parts) > 0 {
-			if n, err := strconv.Atoi(parts[0]); err == nil {
-				if n > maxSeq {
-					maxSeq = n
-				}
-			}
+	code := ""
+	if strings.Contains(format, "{client}") {
+		var client Client
+		if err := tx.Session(&gorm.Session{NewDB: true}).First(&client, o.ClientID).Error; err == nil {
+			code = ClientCode(&client)
 		}
 	}
-	seq := maxSeq + 1
-	now := time.Now()
-	if o.Date.IsZero() {
-		o.Date = now
+	o.OfferNumber = FormatNumber(format, seq, o.Date, code)
+	return nil
+}
+
+// OfferItem is one priced line of an offer. Subtotal, DiscountAmount,
+// TaxAmount and Total are computed server-side.
+type OfferItem struct {
+	ID              int     `json:"id" gorm:"primaryKey"`
+	OfferID         int     `json:"offer_id" gorm:"index;not null"`
+	Position        int     `json:"position"`
+	CatalogItemID   *int    `json:"catalog_item_id" gorm:"index"`
+	Name            string  `json:"name"`
+	Description     string  `json:"description" gorm:"not null"`
+	Qty             float64 `json:"qty" gorm:"not null"`
+	Unit            string  `json:"unit"`
+	UnitPrice       float64 `json:"unit_price" gorm:"not null"`
+	DiscountPercent float64 `json:"discount_percent"`
+	TaxRate         float64 `json:"tax_rate"` // percent
+	Subtotal        float64 `json:"subtotal"` // qty * unit_price
+	DiscountAmount  float64 `json:"discount_amount"`
+	TaxAmount       float64 `json:"tax_amount"`
+	Total           float64 `json:"total"` // subtotal - discount + tax
+	// UnitPriceSet and TaxRateSet record that the JSON sent the field, so an
+	// explicit 0 (a free or tax-exempt line) is not filled from the catalog.
+	UnitPriceSet bool `json:"-" gorm:"-"`
+	TaxRateSet   bool `json:"-" gorm:"-"`
+}
+
+func (OfferItem) TableName() string { return "offer_items" }
+
+func (it *OfferItem) UnmarshalJSON(b []byte) error {
+	type plain OfferItem
+	var sent struct {
+		UnitPrice *float64 `json:"unit_price"`
+		TaxRate   *float64 `json:"tax_rate"`
+	}
+	if err := json.Unmarshal(b, (*plain)(it)); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &sent); err != nil {
+		return err
+	}
+	it.UnitPriceSet = sent.UnitPrice != nil
+	it.TaxRateSet = sent.TaxRate != nil
+	return nil
+}
+
+// OfferStatusChange records one status transition. FromStatus is empty for
+// the initial draft; ActorID is nil for system changes such as expiry.
+type OfferStatusChange struct {
+	ID         int       `json:"id" gorm:"primaryKey"`
+	OfferID    int       `json:"offer_id" gorm:"index;not null"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status" gorm:"not null"`
+	ActorID    *int      `json:"actor_id"`
+	Note       string    `json:"note"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
+
+func (OfferStatusChange) TableName() string { return "offer_status_changes" }
+
+// OfferRevision is an immutable snapshot of an offer taken each time it is
+// sent. Snapshot holds the offer and its items as JSON.
+type OfferRevision struct {
+	ID          int        `json:"id" gorm:"primaryKey"`
+	OfferID     int        `json:"offer_id" gorm:"uniqueIndex:idx_offer_revision;not null"`
+	Revision    int        `json:"revision" gorm:"uniqueIndex:idx_offer_revision;not null"`
+	OfferNumber string     `json:"offer_number" gorm:"not null"`
+	TotalPrice  float64    `json:"total_price"`
+	Snapshot    string     `json:"-" gorm:"type:text;not null"`
+	PDFURL      string     `json:"pdf_url"`
+	ActorID     *int       `json:"actor_id"`
+	SentAt      time.Time  `json:"sent_at"`
+	AcceptedAt  *time.Time `json:"accepted_at"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	// Offer is the decoded Snapshot; filled by the service, not stored.
+	Offer *Offer `json:"offer,omitempty" gorm:"-"`
+}
+
+func (OfferRevision) TableName() string { return "offer_revisions" }
+
+// RevisionNumber is the offer number shown on a revision: the first revision
+// keeps the base number, later ones get an "/R<n>" suffix.
+func RevisionNumber(base string, revision int) string {
+	if revision <= 1 {
+		return base
+	}
+	return fmt.Sprintf("%s/R%d", base, revision)
+}
+
+// OfferItems is the line item list. For older API clients it also accepts a
+// JSON string holding the array, e.g. "items": "[{...}]".
+type OfferItems []OfferItem
+
+func (l *OfferItems) UnmarshalJSON(b []byte) error {
+	var raw string
+	if err := json.Unmarshal(b, &raw); err == nil {
+		if strings.TrimSpace(raw) == "" {
+			*l = OfferItems{}
+			return nil
+		}
+		b = []byte(raw)
+	}
+	var items []OfferItem
+	if err := json.Unmarshal(b, &items); err != nil {
+		return fmt.Errorf("items: %w", err)
+	}
+	if items == nil {
+		items = []OfferItem{}
 	}
-	o.OfferNumber = fmt.Sprintf("%03d/MSI-%02d/%02d/%04d", seq, now.Day(), int(now.Month()), now.Year())
+	*l = items
 	return nil
 }
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Client decisions recorded through a public offer link.
+const (
+	OfferDecisionAccept = "accept"
+	OfferDecisionReject = "reject"
+)
+
+// OfferLink is an expiring public link emailed to the client for one offer
+// revision. Only the SHA-256 of the token is stored.
+type OfferLink struct {
+	ID            int        `json:"id" gorm:"primaryKey"`
+	OfferID       int        `json:"offer_id" gorm:"index;not null"`
+	Revision      int        `json:"revision"`
+	TokenHash     string     `json:"-" gorm:"uniqueIndex;not null"`
+	Email         string     `json:"email"`
+	ExpiresAt     time.Time  `json:"expires_at" gorm:"not null"`
+	Views         int        `json:"views"`
+	FirstViewedAt *time.Time `json:"first_viewed_at"`
+	LastViewedAt  *time.Time `json:"last_viewed_at"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (OfferLink) TableName() string { return "offer_links" }
+
+// OfferSignature is the client's online decision on an offer: for acceptance
+// the typed name, time and IP serve as the e-signature record.
+type OfferSignature struct {
+	ID         int       `json:"id" gorm:"primaryKey"`
+	OfferID    int       `json:"offer_id" gorm:"index;not null"`
+	LinkID     int       `json:"link_id" gorm:"index;not null"`
+	Revision   int       `json:"revision"`
+	Decision   string    `json:"decision" gorm:"not null"`
+	SignerName string    `json:"signer_name"`
+	Reason     string    `json:"reason"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	SignedAt   time.Time `json:"signed_at" gorm:"not null"`
+}
+
+func (OfferSignature) TableName() string { return "offer_signatures" }
@@ -12,11 +12,11 @@ func TestOfferBeforeCreateGeneratesSequentialNumber(t *testing.T) {
 	if err != nil {
 		t.Fatalf("sqlite open: %v", err)
 	}
-	if err := db.AutoMigrate(&Offer{}); err != nil {
+	if err := db.AutoMigrate(&Offer{}, &NumberSequence{}); err != nil {
 		t.Fatalf("migrate: %v", err)
 	}
 
-	o1 := &Offer{ClientID: 1, Subject: "A", Items: "[]", TotalPrice: 1}
+	o1 := &Offer{ClientID: 1, Subject: "A", TotalPrice: 1}
 	if err := db.Create(o1).Error; err != nil {
 		t.Fatalf("create o1: %v", err)
 	}
@@ -24,7 +24,7 @@ func TestOfferBeforeCreateGeneratesSequentialNumber(t *testing.T) {
 		t.Fatalf("o1 number empty")
 	}
 
-	o2 := &Offer{ClientID: 1, Subject: "B", Items: "[]", TotalPrice: 2}
+	o2 := &Offer{ClientID: 1, Subject: "B", TotalPrice: 2}
 	if err := db.Create(o2).Error; err != nil {
 		t.Fatalf("create o2: %v", err)
 	}
@@ -38,10 +38,10 @@ func TestOfferBeforeCreateKeepsProvidedNumber(t *testing.T) {
 	if err != nil {
 		t.Fatalf("sqlite open: %v", err)
 	}
-	if err := db.AutoMigrate(&Offer{}); err != nil {
+	if err := db.AutoMigrate(&Offer{}, &NumberSequence{}); err != nil {
 		t.Fatalf("migrate: %v", err)
 	}
-	o := &Offer{ClientID: 1, Subject: "A", Items: "[]", TotalPrice: 1, OfferNumber: "123/MSI-01/01/2024"}
+	o := &Offer{ClientID: 1, Subject: "A", TotalPrice: 1, OfferNumber: "123/MSI-01/01/2024"}
 	if err := db.Create(o).Error; err != nil {
 		t.Fatalf("create: %v", err)
 	}
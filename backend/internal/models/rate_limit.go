@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// RateLimitCounter is a fixed-window request counter shared by all replicas
+// when the database-backed rate limit store is enabled.
+type RateLimitCounter struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	Key         string    `json:"key" gorm:"uniqueIndex:idx_rate_limit_key_window;not null"` // e.g. "default:ip:1.2.3.4"
+	WindowStart time.Time `json:"window_start" gorm:"uniqueIndex:idx_rate_limit_key_window;not null"`
+	Count       int       `json:"count"`
+	ExpiresAt   time.Time `json:"expires_at" gorm:"index"`
+}
+
+func (RateLimitCounter) TableName() string { return "rate_limit_counters" }
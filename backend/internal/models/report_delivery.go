@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Report delivery statuses.
+const (
+	ReportDeliverySent    = "sent"
+	ReportDeliveryFailed  = "failed"
+	ReportDeliverySkipped = "skipped" // client not opted in, no email or no data for the month
+)
+
+// ReportDelivery records the automatic emailing of one service's monthly
+// report. There is at most one row per service and month; failed rows are
+// retried by the scheduler until the attempt limit is reached.
+type ReportDelivery struct {
+	ID              int        `json:"id" gorm:"primaryKey"`
+	ServiceID       int        `json:"service_id" gorm:"not null;uniqueIndex:idx_report_delivery_month"`
+	ReportMonth     time.Time  `json:"report_month" gorm:"type:date;not null;uniqueIndex:idx_report_delivery_month"`
+	ClientID        int        `json:"client_id" gorm:"index"`
+	MonthlyReportID *int       `json:"monthly_report_id"`
+	Recipient       string     `json:"recipient"`
+	CC              string     `json:"cc"`
+	Status          string     `json:"status" gorm:"index;not null"`
+	Attempts        int        `json:"attempts"`
+	LastError       string     `json:"last_error"`
+	LastAttemptAt   *time.Time `json:"last_attempt_at"`
+	SentAt          *time.Time `json:"sent_at"`
+	CreatedAt       time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (ReportDelivery) TableName() string { return "report_deliveries" }
@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// Subscription statuses.
+const (
+	SubscriptionActive    = "active"
+	SubscriptionCancelled = "cancelled"
+)
+
+// Billing cycles.
+const (
+	CycleMonthly   = "monthly"
+	CycleQuarterly = "quarterly"
+	CycleYearly    = "yearly"
+)
+
+// Subscription is a recurring retainer billed to a client, optionally for one
+// monitored service. Each cycle is invoiced in arrears once the period ends.
+type Subscription struct {
+	ID           int        `json:"id" gorm:"primaryKey"`
+	ClientID     int        `json:"client_id" gorm:"index;not null"`
+	ServiceID    *int       `json:"service_id" gorm:"index"`
+	Name         string     `json:"name" gorm:"not null"`
+	Amount       float64    `json:"amount" gorm:"not null"`
+	Currency     string     `json:"currency" gorm:"default:'IDR'"`
+	Cycle        string     `json:"cycle" gorm:"default:'monthly'"`
+	StartDate    time.Time  `json:"start_date" gorm:"not null"`
+	EndDate      *time.Time `json:"end_date"` // set on cancellation; the final period is prorated to it
+	Status       string     `json:"status" gorm:"index;default:'active'"`
+	PaymentTerms string     `json:"payment_terms"`
+	TaxName      string     `json:"tax_name"`
+	TaxRate      float64    `json:"tax_rate"` // percent
+	// NextPeriodStart is the start of the first period that has not been invoiced.
+	NextPeriodStart time.Time `json:"next_period_start" gorm:"index"`
+	// ProrationAdjustment accumulates mid-cycle amount changes for the open period.
+	ProrationAdjustment float64   `json:"proration_adjustment"`
+	CreatedAt           time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt           time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (Subscription) TableName() string { return "subscriptions" }
+
+// PeriodEnd returns the exclusive end of the cycle starting at start. Cycles
+// end on the start date's day of the month, clamped to the last day of
+// shorter months, so a retainer started on Jan 31 runs to Feb 28 and then to
+// Mar 31 instead of drifting.
+func (s Subscription) PeriodEnd(start time.Time) time.Time {
+	anchor := start.Day()
+	if !s.StartDate.IsZero() {
+		anchor = s.StartDate.Day()
+	}
+	switch s.Cycle {
+	case CycleQuarterly:
+		return addMonths(start, 3, anchor)
+	case CycleYearly:
+		return addMonths(start, 12, anchor)
+	default:
+		return addMonths(start, 1, anchor)
+	}
+}
+
+// addMonths moves t n months ahead to day, or the month's last day when the
+// month is shorter, keeping the time of day.
+func addMonths(t time.Time, n, day int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return time.Date(first.Year(), first.Month(), day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
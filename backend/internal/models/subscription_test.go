@@ -0,0 +1,29 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSubscriptionPeriodEndClampsToMonthEnd(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	cases := []struct {
+		cycle       string
+		start, from time.Time
+		want        time.Time
+	}{
+		{CycleMonthly, day(2025, 1, 31), day(2025, 1, 31), day(2025, 2, 28)},
+		{CycleMonthly, day(2025, 1, 31), day(2025, 2, 28), day(2025, 3, 31)},
+		{CycleMonthly, day(2024, 1, 30), day(2024, 1, 30), day(2024, 2, 29)},
+		{CycleMonthly, day(2025, 1, 15), day(2025, 12, 15), day(2026, 1, 15)},
+		{CycleQuarterly, day(2025, 11, 30), day(2025, 11, 30), day(2026, 2, 28)},
+		{CycleYearly, day(2024, 2, 29), day(2024, 2, 29), day(2025, 2, 28)},
+	}
+	for _, c := range cases {
+		sub := Subscription{Cycle: c.cycle, StartDate: c.start}
+		if end := sub.PeriodEnd(c.from); !end.Equal(c.want) {
+			t.Errorf("%s from %s (started %s): got %s, want %s", c.cycle, c.from.Format("2006-01-02"), c.start.Format("2006-01-02"),
+				end.Format("2006-01-02"), c.want.Format("2006-01-02"))
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// TaskLease is the lock a scheduler instance holds while running a task on
+// databases without advisory locks. The lease is free once ExpiresAt has
+// passed; its holder renews it while the task runs.
+type TaskLease struct {
+	Task       string    `json:"task" gorm:"primaryKey;size:100"`
+	Holder     string    `json:"holder" gorm:"size:200"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at" gorm:"not null"`
+}
+
+func (TaskLease) TableName() string { return "task_leases" }
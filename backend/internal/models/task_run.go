@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Task run statuses.
+const (
+	TaskRunRunning = "running"
+	TaskRunSuccess = "success"
+	TaskRunFailed  = "failed"
+)
+
+// TaskRun records one execution of a scheduler task. TriggeredBy is "schedule"
+// or "manual".
+type TaskRun struct {
+	ID          int        `json:"id" gorm:"primaryKey"`
+	Task        string     `json:"task" gorm:"size:100;not null;index:idx_task_runs_task_started,priority:1"`
+	TriggeredBy string     `json:"triggered_by" gorm:"size:20;not null"`
+	Status      string     `json:"status" gorm:"size:20;not null"`
+	StartedAt   time.Time  `json:"started_at" gorm:"not null;index:idx_task_runs_task_started,priority:2"`
+	FinishedAt  *time.Time `json:"finished_at"`
+	DurationMs  int64      `json:"duration_ms"`
+	Error       string     `json:"error" gorm:"type:text"`
+}
+
+func (TaskRun) TableName() string { return "task_runs" }
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// TaskSetting overrides a scheduler task's registered configuration. A row
+// is written the first time a task is paused, resumed or reconfigured and
+// holds its full configuration from then on.
+type TaskSetting struct {
+	Task                string    `json:"task" gorm:"primaryKey;size:100"`
+	Enabled             bool      `json:"enabled"`
+	Schedule            string    `json:"schedule" gorm:"not null"`
+	TimeoutSeconds      int       `json:"timeout_seconds"`
+	MaxRetries          int       `json:"max_retries"`
+	RetryBackoffSeconds int       `json:"retry_backoff_seconds"`
+	AlertAfterFailures  int       `json:"alert_after_failures"`
+	UpdatedAt           time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (TaskSetting) TableName() string { return "task_settings" }
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// TimeEntry is work logged for a client, optionally on one of its services.
+// Entries on a service feed that service's monthly report; billable entries
+// can be invoiced once, after which they are locked until the invoice is
+// voided.
+type TimeEntry struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	UserID      int       `json:"user_id" gorm:"index"`
+	ClientID    int       `json:"client_id" gorm:"index;not null"`
+	ServiceID   *int      `json:"service_id" gorm:"index"`
+	Date        time.Time `json:"date" gorm:"type:date;index;not null"`
+	Minutes     int       `json:"minutes" gorm:"not null"`
+	Description string    `json:"description" gorm:"not null"`
+	Billable    bool      `json:"billable" gorm:"default:false"`
+	Rate        float64   `json:"rate"` // per hour
+	Currency    string    `json:"currency" gorm:"default:'IDR'"`
+	InvoiceID   *int      `json:"invoice_id" gorm:"index"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (TimeEntry) TableName() string { return "time_entries" }
+
+// Hours returns the logged duration in hours.
+func (e TimeEntry) Hours() float64 { return float64(e.Minutes) / 60 }
@@ -0,0 +1,65 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Skipf("no tzdata: %v", err)
+	}
+	at := func(s string) time.Time {
+		v, err := time.ParseInLocation("2006-01-02 15:04", s, jakarta)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	cases := []struct {
+		spec, after, want string
+	}{
+		{"1 0 * * *", "2025-03-10 00:01", "2025-03-11 00:01"},
+		{"0 9 * * *", "2025-03-10 08:59", "2025-03-10 09:00"},
+		{"*/15 * * * *", "2025-03-10 10:07", "2025-03-10 10:15"},
+		{"0 9 * * MON-FRI", "2025-03-14 09:00", "2025-03-17 09:00"},
+		{"30 8 1,15 * *", "2025-03-02 00:00", "2025-03-15 08:30"},
+		{"0 0 31 * *", "2025-04-01 00:00", "2025-05-31 00:00"},
+		{"0 12 1 * 0", "2025-03-01 13:00", "2025-03-02 12:00"}, // day of month OR Sunday
+		{"@monthly", "2025-12-15 00:00", "2026-01-01 00:00"},
+		{"0 0 * * 7", "2025-03-10 00:00", "2025-03-16 00:00"},
+	}
+	for _, tc := range cases {
+		c, err := ParseCron(tc.spec, jakarta)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.spec, err)
+		}
+		if got := c.Next(at(tc.after)); !got.Equal(at(tc.want)) {
+			t.Errorf("%s after %s: got %s, want %s", tc.spec, tc.after, got, tc.want)
+		}
+	}
+
+	c, err := ParseCron("CRON_TZ=UTC 0 2 * * *", jakarta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Next(at("2025-03-10 08:00")); !got.Equal(at("2025-03-10 09:00")) {
+		t.Errorf("CRON_TZ: got %s", got)
+	}
+	if c, _ := ParseCron("0 0 30 2 *", jakarta); !c.Next(at("2025-01-01 00:00")).IsZero() {
+		t.Error("expected 30 February never to fire")
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "* * * FOO *", "@every 10ms", "CRON_TZ=Nowhere/Land * * * * *"} {
+		if _, err := ParseSchedule(spec, time.UTC); err == nil {
+			t.Errorf("expected %q to be rejected", spec)
+		}
+	}
+	s, err := ParseSchedule("@every 90s", time.UTC)
+	if err != nil || s.String() != "@every 1m30s" {
+		t.Fatalf("@every: %v %v", s, err)
+	}
+}
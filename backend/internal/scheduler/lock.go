@@ -0,0 +1,150 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// locker serializes runs of a task across every process sharing the
+// database. tryLock returns ok=false when another run holds the lock.
+type locker interface {
+	tryLock(ctx context.Context, task string) (unlock func(), ok bool, err error)
+}
+
+// newLocker picks Postgres advisory locks, a task_leases row on other
+// databases, or nothing beyond the in-process guard without a database.
+func newLocker(db *gorm.DB) locker {
+	switch {
+	case db == nil:
+		return localLocker{}
+	case db.Dialector.Name() == "postgres":
+		return &advisoryLocker{db: db}
+	}
+	ttl := 2 * time.Minute
+	if n, err := strconv.Atoi(os.Getenv("SCHEDULER_LEASE_SECONDS")); err == nil && n >= 10 {
+		ttl = time.Duration(n) * time.Second
+	}
+	return &leaseLocker{db: db, ttl: ttl}
+}
+
+type localLocker struct{}
+
+func (localLocker) tryLock(context.Context, string) (func(), bool, error) {
+	return func() {}, true, nil
+}
+
+// advisoryLocker takes a session-level pg_try_advisory_lock on a connection
+// pinned for the run, so the lock is released if the process dies.
+type advisoryLocker struct {
+	db *gorm.DB
+}
+
+func (l *advisoryLocker) tryLock(ctx context.Context, task string) (func(), bool, error) {
+	sqlDB, err := l.db.DB()
+	if err != nil {
+		return nil, false, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	key := advisoryKey(task)
+	var ok bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&ok); err != nil {
+		conn.Close()
+		return nil, false, err
+	}
+	if !ok {
+		conn.Close()
+		return nil, false, nil
+	}
+	return func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key); err != nil {
+			log.Printf("scheduler: releasing lock of %s: %v", task, err)
+		}
+		conn.Close()
+	}, true, nil
+}
+
+func advisoryKey(task string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("freelance-monitor:task:" + task))
+	return int64(h.Sum64())
+}
+
+// leaseLocker claims the task's task_leases row when its lease has expired
+// and renews it every ttl/3 until unlocked. A crashed holder blocks the task
+// for at most ttl.
+type leaseLocker struct {
+	db  *gorm.DB
+	ttl time.Duration
+}
+
+func (l *leaseLocker) tryLock(ctx context.Context, task string) (func(), bool, error) {
+	holder, err := leaseHolder()
+	if err != nil {
+		return nil, false, err
+	}
+	now := time.Now().UTC()
+	if err := l.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.TaskLease{Task: task, ExpiresAt: time.Unix(0, 0).UTC()}).Error; err != nil {
+		return nil, false, err
+	}
+	res := l.db.WithContext(ctx).Model(&models.TaskLease{}).
+		Where("task = ? AND expires_at < ?", task, now).
+		Updates(map[string]interface{}{"holder": holder, "acquired_at": now, "expires_at": now.Add(l.ttl)})
+	if res.Error != nil {
+		return nil, false, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, false, nil
+	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tick := time.NewTicker(l.ttl / 3)
+		defer tick.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-tick.C:
+				if err := l.db.Model(&models.TaskLease{}).Where("task = ? AND holder = ?", task, holder).
+					Update("expires_at", time.Now().UTC().Add(l.ttl)).Error; err != nil {
+					log.Printf("scheduler: renewing lease of %s: %v", task, err)
+				}
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-done
+		if err := l.db.Model(&models.TaskLease{}).Where("task = ? AND holder = ?", task, holder).
+			Update("expires_at", time.Unix(0, 0).UTC()).Error; err != nil {
+			log.Printf("scheduler: releasing lease of %s: %v", task, err)
+		}
+	}, true, nil
+}
+
+// leaseHolder identifies one lock attempt: host, process and a random suffix,
+// so two runs in the same process never share a lease.
+func leaseHolder() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s:%d:%s", host, os.Getpid(), hex.EncodeToString(b)), nil
+}
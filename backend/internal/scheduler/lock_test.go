@@ -0,0 +1,134 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// newSharedDB is a file database two schedulers can use like two replicas.
+func newSharedDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "sched.db")+"?_busy_timeout=5000"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	if err := db.AutoMigrate(&models.TaskRun{}, &models.TaskLease{}, &models.TaskSetting{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func TestReplicasRunScheduledSlotOnce(t *testing.T) {
+	db := newSharedDB(t)
+	var runs int32
+	runner := func(context.Context) error { atomic.AddInt32(&runs, 1); return nil }
+	a := NewScheduler(context.Background(), db)
+	b := NewScheduler(context.Background(), db)
+	defer a.Close()
+	defer b.Close()
+	a.Register("billing", time.Hour, false, runner)
+	b.Register("billing", time.Hour, false, runner)
+
+	if err := a.execute("billing", TriggerSchedule); err != nil {
+		t.Fatal(err)
+	}
+	// b's timer fires a moment later for the same slot.
+	if err := b.execute("billing", TriggerSchedule); !errors.Is(err, errNotDue) {
+		t.Fatalf("expected the slot to be skipped, got %v", err)
+	}
+	if runs != 1 {
+		t.Fatalf("expected one run, got %d", runs)
+	}
+	if info := b.ListTasks()[0]; info.LastRunAt.IsZero() {
+		t.Fatal("expected b to adopt a's run as its last run")
+	}
+	// Manual runs are not deduplicated.
+	if err := b.RunTask("billing"); err != nil || runs != 2 {
+		t.Fatalf("manual run: %v, runs %d", err, runs)
+	}
+}
+
+func TestLockPreventsConcurrentRuns(t *testing.T) {
+	db := newSharedDB(t)
+	started, release := make(chan struct{}), make(chan struct{})
+	a := NewScheduler(context.Background(), db)
+	b := NewScheduler(context.Background(), db)
+	defer a.Close()
+	defer b.Close()
+	a.Register("backup", time.Hour, false, func(context.Context) error {
+		close(started)
+		<-release
+		return nil
+	})
+	b.Register("backup", time.Hour, false, func(context.Context) error { return nil })
+
+	done := make(chan error)
+	go func() { done <- a.RunTask("backup") }()
+	<-started
+	if err := a.RunTask("backup"); !errors.Is(err, ErrTaskRunning) {
+		t.Fatalf("expected the same process to be refused, got %v", err)
+	}
+	if err := b.RunTask("backup"); !errors.Is(err, ErrTaskRunning) {
+		t.Fatalf("expected another replica to be refused, got %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if err := b.RunTask("backup"); err != nil {
+		t.Fatalf("expected the lock to be released, got %v", err)
+	}
+}
+
+func TestLeaseExpiresAfterCrash(t *testing.T) {
+	db := newSharedDB(t)
+	// A holder that died mid-run left a live-looking lease and a running row.
+	db.Create(&models.TaskLease{Task: "sweep", Holder: "dead", ExpiresAt: time.Now().UTC().Add(time.Minute)})
+	db.Create(&models.TaskRun{Task: "sweep", TriggeredBy: TriggerSchedule, Status: models.TaskRunRunning, StartedAt: time.Now().Add(-time.Hour)})
+	s := NewScheduler(context.Background(), db)
+	defer s.Close()
+	s.Register("sweep", time.Hour, false, func(context.Context) error { return nil })
+	if err := s.RunTask("sweep"); !errors.Is(err, ErrTaskRunning) {
+		t.Fatalf("expected the lease to block, got %v", err)
+	}
+	db.Model(&models.TaskLease{}).Where("task = ?", "sweep").Update("expires_at", time.Now().UTC().Add(-time.Second))
+	if err := s.RunTask("sweep"); err != nil {
+		t.Fatalf("expected the expired lease to be taken over, got %v", err)
+	}
+	var stale models.TaskRun
+	db.Where("task = ? AND triggered_by = ?", "sweep", TriggerSchedule).First(&stale)
+	if stale.Status != models.TaskRunFailed || stale.Error != "interrupted" {
+		t.Fatalf("expected the orphaned run to be marked interrupted: %+v", stale)
+	}
+}
+
+type failingLocker struct{ calls int32 }
+
+func (l *failingLocker) tryLock(context.Context, string) (func(), bool, error) {
+	atomic.AddInt32(&l.calls, 1)
+	return nil, false, errors.New("database is unreachable")
+}
+
+func TestLockErrorsBackOff(t *testing.T) {
+	s := NewScheduler(context.Background(), nil)
+	defer s.Close()
+	l := &failingLocker{}
+	s.locker = l
+	s.Register("sweep", time.Minute, true, func(context.Context) error { return nil })
+
+	time.Sleep(200 * time.Millisecond)
+	if n := atomic.LoadInt32(&l.calls); n != 1 {
+		t.Fatalf("expected one lock attempt before backing off, got %d", n)
+	}
+	if info := s.ListTasks()[0]; info.NextRunAt == nil || time.Until(*info.NextRunAt) < lockRetryDelay-time.Second {
+		t.Fatalf("expected the next attempt after lockRetryDelay: %+v", info)
+	}
+}
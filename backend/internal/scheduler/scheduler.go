@@ -2,152 +2,619 @@ package scheduler
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
+
+	"gorm.io/gorm"
+)
+
+// Run triggers recorded in task_runs.
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+	TriggerRetry    = "retry"
 )
 
+// ErrTaskRunning is returned by RunTask while the task is running, here or
+// in another process sharing the database.
+var ErrTaskRunning = errors.New("task is already running")
+
+// ErrTaskNotFound is returned when configuring a task that is not registered.
+var ErrTaskNotFound = errors.New("task not found")
+
+// lockRetryDelay is how long a scheduled run waits before retrying when the
+// task was locked or the lock could not be taken.
+var lockRetryDelay = 30 * time.Second
+
+// errNotDue means another process already ran the scheduled slot.
+var errNotDue = errors.New("task not due")
+
+// maxRetriesLimit caps TaskConfig.MaxRetries so backoff stays bounded.
+const maxRetriesLimit = 10
+
+// Notifier is told when a task has failed AlertAfterFailures times in a row,
+// and when it next succeeds.
+type Notifier interface {
+	TaskFailing(ctx context.Context, task string, failures int, err error)
+	TaskRecovered(ctx context.Context, task string)
+}
+
 // TaskInfo describes a scheduled task for introspection.
 type TaskInfo struct {
-	Name      string    `json:"name"`
-	Interval  int64     `json:"interval_seconds"`
-	Enabled   bool      `json:"enabled"`
-	LastRunAt time.Time `json:"last_run_at"`
+	Name                string     `json:"name"`
+	Schedule            string     `json:"schedule"`
+	Timezone            string     `json:"timezone,omitempty"` // cron schedules only
+	Interval            int64      `json:"interval_seconds"`   // interval schedules only
+	Enabled             bool       `json:"enabled"`
+	Running             bool       `json:"running"`
+	TimeoutSeconds      int        `json:"timeout_seconds"`
+	MaxRetries          int        `json:"max_retries"`
+	RetryBackoffSeconds int        `json:"retry_backoff_seconds"`
+	AlertAfterFailures  int        `json:"alert_after_failures"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastRunAt           time.Time  `json:"last_run_at"`
+	LastStatus          string     `json:"last_status,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastDurationMs      int64      `json:"last_duration_ms"`
+	NextRunAt           *time.Time `json:"next_run_at"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"`
 }
 
-// Scheduler manages named tasks and runs them at fixed intervals.
+// TaskConfig changes a task's configuration; nil fields are left as they
+// are. Zero TimeoutSeconds means no timeout and zero AlertAfterFailures no
+// alerts.
+type TaskConfig struct {
+	Schedule            *string `json:"schedule"`
+	TimeoutSeconds      *int    `json:"timeout_seconds"`
+	MaxRetries          *int    `json:"max_retries"`
+	RetryBackoffSeconds *int    `json:"retry_backoff_seconds"`
+	AlertAfterFailures  *int    `json:"alert_after_failures"`
+}
+
+// Scheduler manages named tasks and runs them on interval or cron schedules.
+// With a database, every run is recorded in task_runs and the last run is
+// restored on restart, so schedules keep their alignment. Runs take a
+// database lock, so replicas sharing the database run each slot once and
+// never run a task concurrently. Pausing and reconfiguring a task is stored
+// in task_settings and survives restarts.
 type Scheduler struct {
-	ctx     context.Context
-	cancel  context.CancelFunc
-	mu      sync.RWMutex
-	runners map[string]func(context.Context) error
-	tasks   map[string]*taskState
+	ctx      context.Context
+	cancel   context.CancelFunc
+	db       *gorm.DB
+	locker   locker
+	loc      *time.Location
+	defaults taskOptions
+	notifier Notifier
+	mu       sync.RWMutex
+	runners  map[string]func(context.Context) error
+	tasks    map[string]*taskState
+}
+
+// taskOptions control how a task's runs are executed.
+type taskOptions struct {
+	timeout    time.Duration // 0 = none
+	maxRetries int
+	backoff    time.Duration // doubled after every failed retry
+	alertAfter int           // 0 = never
 }
 
 type taskState struct {
-	name     string
-	interval time.Duration
-	enabled  bool
-	lastRun  time.Time
-	stopCh   chan struct{}
+	name         string
+	schedule     Schedule
+	opts         taskOptions
+	enabled      bool
+	running      bool
+	lastRun      time.Time
+	lastStatus   string
+	lastError    string
+	lastDuration time.Duration
+	failures     int // consecutive failed runs
+	attempt      int // retries made since the last scheduled run
+	retryAt      time.Time
+	nextRun      time.Time
+	notBefore    time.Time // retry time after the lock was unavailable
+	stopCh       chan struct{}
 }
 
-// NewScheduler constructs a scheduler bound to the given context.
-func NewScheduler(parent context.Context) *Scheduler {
+// NewScheduler constructs a scheduler bound to the given context. db may be
+// nil to keep run history and settings in memory only. Cron schedules
+// default to the SCHEDULER_TIMEZONE zone (default local time). Each task
+// starts with SCHEDULER_TASK_TIMEOUT_SECONDS (3600), SCHEDULER_MAX_RETRIES
+// (2), SCHEDULER_RETRY_BACKOFF_SECONDS (60) and
+// SCHEDULER_ALERT_AFTER_FAILURES (3).
+func NewScheduler(parent context.Context, db *gorm.DB) *Scheduler {
 	ctx, cancel := context.WithCancel(parent)
+	loc := time.Local
+	if tz := os.Getenv("SCHEDULER_TIMEZONE"); tz != "" {
+		if l, err := time.LoadLocation(tz); err == nil {
+			loc = l
+		} else {
+			log.Printf("scheduler: ignoring SCHEDULER_TIMEZONE: %v", err)
+		}
+	}
 	return &Scheduler{
-		ctx:     ctx,
-		cancel:  cancel,
+		ctx:    ctx,
+		cancel: cancel,
+		db:     db,
+		locker: newLocker(db),
+		loc:    loc,
+		defaults: taskOptions{
+			timeout:    time.Duration(envInt("SCHEDULER_TASK_TIMEOUT_SECONDS", 3600)) * time.Second,
+			maxRetries: min(envInt("SCHEDULER_MAX_RETRIES", 2), maxRetriesLimit),
+			backoff:    time.Duration(envInt("SCHEDULER_RETRY_BACKOFF_SECONDS", 60)) * time.Second,
+			alertAfter: envInt("SCHEDULER_ALERT_AFTER_FAILURES", 3),
+		},
 		runners: make(map[string]func(context.Context) error),
 		tasks:   make(map[string]*taskState),
 	}
 }
 
+func envInt(key string, def int) int {
+	if n, err := strconv.Atoi(os.Getenv(key)); err == nil && n >= 0 {
+		return n
+	}
+	return def
+}
+
 // Close stops all tasks.
 func (s *Scheduler) Close() { s.cancel() }
 
-// Register adds a new periodic task. If enabled, it starts the loop.
+// SetNotifier installs the failure notifier. Call it before registering tasks.
+func (s *Scheduler) SetNotifier(n Notifier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifier = n
+}
+
+// Register adds a task run every interval. If enabled, it starts the loop;
+// the first run is immediate unless the last recorded run is more recent
+// than interval.
 func (s *Scheduler) Register(name string, interval time.Duration, enabled bool, runner func(context.Context) error) {
+	s.RegisterSchedule(name, Every(interval), enabled, runner)
+}
+
+// RegisterCron adds a task run on a cron expression (see ParseCron),
+// evaluated in the scheduler's time zone unless it sets CRON_TZ.
+func (s *Scheduler) RegisterCron(name, spec string, enabled bool, runner func(context.Context) error) error {
+	sched, err := ParseSchedule(spec, s.loc)
+	if err != nil {
+		return fmt.Errorf("task %s: %w", name, err)
+	}
+	s.RegisterSchedule(name, sched, enabled, runner)
+	return nil
+}
+
+// RegisterSchedule adds a task with any Schedule. Stored settings for the
+// task take precedence over sched, enabled and the default options.
+func (s *Scheduler) RegisterSchedule(name string, sched Schedule, enabled bool, runner func(context.Context) error) {
+	ts := &taskState{name: name, schedule: sched, opts: s.defaults, enabled: enabled, stopCh: make(chan struct{})}
+	if err := s.applySettings(ts); err != nil {
+		log.Printf("scheduler: loading settings of %s: %v", name, err)
+	}
+	if last, err := s.lastRecordedRun(name); err != nil {
+		log.Printf("scheduler: loading last run of %s: %v", name, err)
+	} else if last != nil {
+		ts.lastRun = last.StartedAt
+		ts.lastStatus = last.Status
+		ts.lastError = last.Error
+		ts.lastDuration = time.Duration(last.DurationMs) * time.Millisecond
+	}
+	if n, err := s.consecutiveFailures(name); err != nil {
+		log.Printf("scheduler: loading failures of %s: %v", name, err)
+	} else {
+		ts.failures = n
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.runners[name] = runner
-	ts := &taskState{name: name, interval: interval, enabled: enabled, stopCh: make(chan struct{})}
 	s.tasks[name] = ts
-	if enabled {
-		go s.runLoop(ts)
+	if ts.enabled {
+		go s.runLoop(ts, ts.stopCh)
 	}
 }
 
-// SetEnabled toggles a task on/off.
-func (s *Scheduler) SetEnabled(name string, enabled bool) {
+// SetEnabled pauses or resumes a task and stores the choice.
+func (s *Scheduler) SetEnabled(name string, enabled bool) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
 	ts, ok := s.tasks[name]
 	if !ok {
-		return
-	}
-	if ts.enabled == enabled {
-		return
+		s.mu.Unlock()
+		return ErrTaskNotFound
 	}
-	ts.enabled = enabled
-	if enabled {
-		ts.stopCh = make(chan struct{})
-		go s.runLoop(ts)
-	} else {
-		close(ts.stopCh)
+	if ts.enabled != enabled {
+		ts.enabled = enabled
+		if enabled {
+			ts.stopCh = make(chan struct{})
+			go s.runLoop(ts, ts.stopCh)
+		} else {
+			close(ts.stopCh)
+			ts.nextRun = time.Time{}
+			ts.retryAt, ts.attempt = time.Time{}, 0
+		}
 	}
+	setting := settingOf(ts)
+	s.mu.Unlock()
+	return s.saveSetting(setting)
 }
 
-// RunTask executes a task immediately, outside its interval loop.
-func (s *Scheduler) RunTask(name string) error {
-	s.mu.RLock()
-	r := s.runners[name]
-	s.mu.RUnlock()
-	if r == nil {
-		return nil
+// Configure changes a task's schedule and run options, stores them and
+// restarts its loop so the next run follows the new schedule.
+func (s *Scheduler) Configure(name string, c TaskConfig) (TaskInfo, error) {
+	var sched Schedule
+	if c.Schedule != nil {
+		var err error
+		if sched, err = ParseSchedule(*c.Schedule, s.loc); err != nil {
+			return TaskInfo{}, err
+		}
 	}
-	err := r(s.ctx)
-	if err == nil {
-		s.mu.Lock()
-		if ts, ok := s.tasks[name]; ok {
-			ts.lastRun = time.Now()
+	for field, v := range map[string]*int{"timeout_seconds": c.TimeoutSeconds, "retry_backoff_seconds": c.RetryBackoffSeconds,
+		"alert_after_failures": c.AlertAfterFailures, "max_retries": c.MaxRetries} {
+		if v != nil && *v < 0 {
+			return TaskInfo{}, fmt.Errorf("%s must not be negative", field)
 		}
+	}
+	if c.MaxRetries != nil && *c.MaxRetries > maxRetriesLimit {
+		return TaskInfo{}, fmt.Errorf("max_retries must be at most %d", maxRetriesLimit)
+	}
+	if c.RetryBackoffSeconds != nil && *c.RetryBackoffSeconds < 1 {
+		return TaskInfo{}, errors.New("retry_backoff_seconds must be at least 1")
+	}
+
+	s.mu.Lock()
+	ts, ok := s.tasks[name]
+	if !ok {
 		s.mu.Unlock()
+		return TaskInfo{}, ErrTaskNotFound
 	}
-	return err
+	if sched != nil {
+		ts.schedule = sched
+	}
+	if c.TimeoutSeconds != nil {
+		ts.opts.timeout = time.Duration(*c.TimeoutSeconds) * time.Second
+	}
+	if c.MaxRetries != nil {
+		ts.opts.maxRetries = *c.MaxRetries
+	}
+	if c.RetryBackoffSeconds != nil {
+		ts.opts.backoff = time.Duration(*c.RetryBackoffSeconds) * time.Second
+	}
+	if c.AlertAfterFailures != nil {
+		ts.opts.alertAfter = *c.AlertAfterFailures
+	}
+	if ts.enabled {
+		close(ts.stopCh)
+		ts.stopCh = make(chan struct{})
+		go s.runLoop(ts, ts.stopCh)
+	}
+	setting := settingOf(ts)
+	info := s.infoOf(ts)
+	s.mu.Unlock()
+	return info, s.saveSetting(setting)
+}
+
+// RunTask executes a task immediately, outside its schedule, and records the
+// run. It returns ErrTaskRunning instead when the task is already running
+// anywhere. Unknown tasks are ignored.
+func (s *Scheduler) RunTask(name string) error {
+	return s.execute(name, TriggerManual)
 }
 
-// ListTasks returns current tasks for display.
+// ListTasks returns current tasks for display, sorted by name.
 func (s *Scheduler) ListTasks() []TaskInfo {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	items := make([]TaskInfo, 0, len(s.tasks))
 	for _, ts := range s.tasks {
-		items = append(items, TaskInfo{
-			Name:      ts.name,
-			Interval:  int64(ts.interval / time.Second),
-			Enabled:   ts.enabled,
-			LastRunAt: ts.lastRun,
-		})
+		items = append(items, s.infoOf(ts))
 	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
 	return items
 }
 
-func (s *Scheduler) runLoop(ts *taskState) {
-	// First tick happens immediately
-	tick := time.NewTicker(ts.interval)
-	defer tick.Stop()
-	// Initial run
+// Task returns one task's details.
+func (s *Scheduler) Task(name string) (TaskInfo, error) {
 	s.mu.RLock()
-	r := s.runners[ts.name]
-	s.mu.RUnlock()
-	if r != nil {
-		if err := r(s.ctx); err == nil {
-			s.mu.Lock()
-			ts.lastRun = time.Now()
-			s.mu.Unlock()
-		}
+	defer s.mu.RUnlock()
+	ts, ok := s.tasks[name]
+	if !ok {
+		return TaskInfo{}, ErrTaskNotFound
 	}
+	return s.infoOf(ts), nil
+}
+
+// HasTask reports whether name is registered.
+func (s *Scheduler) HasTask(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.tasks[name]
+	return ok
+}
+
+// infoOf describes ts. Callers hold s.mu.
+func (s *Scheduler) infoOf(ts *taskState) TaskInfo {
+	info := TaskInfo{
+		Name:                ts.name,
+		Schedule:            ts.schedule.String(),
+		Enabled:             ts.enabled,
+		Running:             ts.running,
+		TimeoutSeconds:      int(ts.opts.timeout / time.Second),
+		MaxRetries:          ts.opts.maxRetries,
+		RetryBackoffSeconds: int(ts.opts.backoff / time.Second),
+		AlertAfterFailures:  ts.opts.alertAfter,
+		ConsecutiveFailures: ts.failures,
+		LastRunAt:           ts.lastRun,
+		LastStatus:          ts.lastStatus,
+		LastError:           ts.lastError,
+		LastDurationMs:      ts.lastDuration.Milliseconds(),
+	}
+	switch sc := ts.schedule.(type) {
+	case Every:
+		info.Interval = int64(time.Duration(sc) / time.Second)
+	case *CronSchedule:
+		info.Timezone = sc.Location().String()
+	}
+	if !ts.nextRun.IsZero() {
+		next := ts.nextRun
+		info.NextRunAt = &next
+	}
+	if !ts.retryAt.IsZero() {
+		retry := ts.retryAt
+		info.RetryAt = &retry
+	}
+	return info
+}
+
+func (s *Scheduler) runLoop(ts *taskState, stop chan struct{}) {
 	for {
+		s.mu.Lock()
+		next, retry := s.nextRunTime(ts, time.Now())
+		ts.nextRun = next
+		s.mu.Unlock()
+		if next.IsZero() {
+			return // the schedule never fires
+		}
+		timer := time.NewTimer(time.Until(next))
 		select {
 		case <-s.ctx.Done():
+			timer.Stop()
 			return
-		case <-ts.stopCh:
+		case <-stop:
+			timer.Stop()
 			return
-		case <-tick.C:
+		case <-timer.C:
+			trigger := TriggerSchedule
+			if retry {
+				trigger = TriggerRetry
+			}
 			s.mu.RLock()
-			r := s.runners[ts.name]
+			before := ts.lastRun
 			s.mu.RUnlock()
-			if r != nil {
-				if err := r(s.ctx); err == nil {
-					s.mu.Lock()
-					ts.lastRun = time.Now()
-					s.mu.Unlock()
+			// A run that failed has its own retry; any other error (the task
+			// locked elsewhere, the database unreachable) left the slot due,
+			// so wait before trying it again.
+			if err := s.execute(ts.name, trigger); err != nil {
+				s.mu.Lock()
+				if ts.lastRun.Equal(before) {
+					ts.notBefore = time.Now().Add(lockRetryDelay)
 				}
+				s.mu.Unlock()
+			}
+		}
+	}
+}
+
+// nextRunTime is the schedule's next time after the last run, or now when
+// that has already passed (a run missed while the process was down). Tasks
+// that never ran start immediately on an interval and at the next cron time
+// otherwise. A pending retry that comes first wins, reported by retry. A
+// task found locked waits until notBefore. Callers hold s.mu.
+func (s *Scheduler) nextRunTime(ts *taskState, now time.Time) (next time.Time, retry bool) {
+	if now.Before(ts.notBefore) {
+		now = ts.notBefore
+	}
+	switch {
+	case ts.lastRun.IsZero():
+		if _, ok := ts.schedule.(Every); ok {
+			next = now
+		} else {
+			next = ts.schedule.Next(now)
+		}
+	default:
+		next = ts.schedule.Next(ts.lastRun)
+		if !next.IsZero() && next.Before(now) {
+			next = now
+		}
+	}
+	if !ts.retryAt.IsZero() && (next.IsZero() || ts.retryAt.Before(next)) {
+		if ts.retryAt.Before(now) {
+			return now, true
+		}
+		return ts.retryAt, true
+	}
+	return next, false
+}
+
+// execute runs a task once under its lock and records the run, whatever its
+// outcome. Scheduled runs are skipped when the lock shows another process
+// has already run the slot, and retries when another process ran the task
+// since. Failed scheduled runs are retried with backoff up to maxRetries.
+func (s *Scheduler) execute(name, trigger string) error {
+	s.mu.Lock()
+	r := s.runners[name]
+	ts := s.tasks[name]
+	if r == nil || ts == nil {
+		s.mu.Unlock()
+		return nil
+	}
+	if ts.running {
+		s.mu.Unlock()
+		return ErrTaskRunning
+	}
+	ts.running = true
+	timeout := ts.opts.timeout
+	s.mu.Unlock()
+	// release clears running and, once taken, drops the lock. It is handed
+	// off when a run is abandoned, so neither is released before the runner
+	// has really returned.
+	release := func() {
+		s.mu.Lock()
+		ts.running = false
+		s.mu.Unlock()
+	}
+	defer func() {
+		if release != nil {
+			release()
+		}
+	}()
+
+	unlock, ok, err := s.locker.tryLock(s.ctx, name)
+	if err != nil {
+		log.Printf("scheduler: locking %s: %v", name, err)
+		return err
+	}
+	if !ok {
+		return ErrTaskRunning
+	}
+	clearRunning := release
+	release = func() {
+		unlock()
+		clearRunning()
+	}
+	s.markInterrupted(name)
+	if trigger != TriggerManual {
+		if err := s.refreshLastRun(ts, trigger == TriggerRetry); err != nil {
+			return err
+		}
+	}
+
+	start := time.Now()
+	runID := s.recordStart(name, trigger, start)
+	abandoned, err := runWithTimeout(s.ctx, r, timeout)
+	end := time.Now()
+	if abandoned != nil {
+		finish := release
+		release = nil
+		go func() {
+			<-abandoned
+			finish()
+		}()
+	}
+	s.recordFinish(runID, start, end, err)
+
+	s.mu.Lock()
+	ts.lastRun = start
+	ts.lastDuration = end.Sub(start)
+	ts.lastStatus, ts.lastError = runStatus(err)
+	failing, recovered := false, false
+	if err != nil {
+		ts.failures++
+		failing = ts.opts.alertAfter > 0 && ts.failures == ts.opts.alertAfter
+		if trigger != TriggerManual {
+			if ts.attempt < ts.opts.maxRetries {
+				ts.retryAt = end.Add(ts.opts.backoff << ts.attempt)
+				ts.attempt++
+			} else {
+				ts.retryAt, ts.attempt = time.Time{}, 0
 			}
 		}
+	} else {
+		recovered = ts.opts.alertAfter > 0 && ts.failures >= ts.opts.alertAfter
+		ts.failures = 0
+		ts.retryAt, ts.attempt = time.Time{}, 0
+	}
+	failures, notifier := ts.failures, s.notifier
+	s.mu.Unlock()
+
+	if err != nil {
+		log.Printf("scheduler: task %s failed: %v", name, err)
+	}
+	if notifier != nil && failing {
+		notifier.TaskFailing(s.ctx, name, failures, err)
+	}
+	if notifier != nil && recovered {
+		notifier.TaskRecovered(s.ctx, name)
+	}
+	return err
+}
+
+// refreshLastRun loads the task's last recorded run, which another process
+// may have made. It returns errNotDue when the schedule has no slot left to
+// run, or for a retry when another process has run the task since. Called
+// with the task's lock held.
+func (s *Scheduler) refreshLastRun(ts *taskState, retry bool) error {
+	last, err := s.lastRecordedRun(ts.name)
+	if err != nil || last == nil {
+		return nil
+	}
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !last.StartedAt.After(ts.lastRun) {
+		return nil
+	}
+	ts.lastRun = last.StartedAt
+	ts.lastStatus = last.Status
+	ts.lastError = last.Error
+	ts.lastDuration = time.Duration(last.DurationMs) * time.Millisecond
+	if retry {
+		ts.retryAt, ts.attempt = time.Time{}, 0
+		return errNotDue
+	}
+	if next := ts.schedule.Next(ts.lastRun); next.IsZero() || next.After(now) {
+		return errNotDue
+	}
+	return nil
+}
+
+// runWithTimeout runs r, giving up after timeout (when non-zero) or when
+// ctx ends. A run given up on keeps going in the background until the
+// runner returns, which closes abandoned; the caller must keep the task
+// locked until then.
+func runWithTimeout(ctx context.Context, r func(context.Context) error, timeout time.Duration) (abandoned <-chan struct{}, err error) {
+	if timeout <= 0 {
+		return nil, callRunner(ctx, r)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	result := make(chan error, 1)
+	returned := make(chan struct{})
+	go func() {
+		defer close(returned)
+		defer cancel()
+		result <- callRunner(ctx, r)
+	}()
+	select {
+	case err := <-result:
+		return nil, err
+	case <-ctx.Done():
+		select {
+		case err := <-result: // finished just as the deadline passed
+			return nil, err
+		default:
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return returned, fmt.Errorf("timed out after %s", timeout)
+		}
+		return returned, ctx.Err()
 	}
 }
 
+// callRunner runs r, turning a panic into an error so one task cannot take
+// down its loop.
+func callRunner(ctx context.Context, r func(context.Context) error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return r(ctx)
+}
+
 // Default scheduler singleton for easy wiring.
 var defaultSched *Scheduler
 
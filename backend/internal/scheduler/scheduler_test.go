@@ -0,0 +1,269 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	if err := db.AutoMigrate(&models.TaskRun{}, &models.TaskLease{}, &models.TaskSetting{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func TestRunTaskRecordsRuns(t *testing.T) {
+	db := newTestDB(t)
+	s := NewScheduler(context.Background(), db)
+	defer s.Close()
+	fail := true
+	s.Register("flaky", time.Hour, false, func(context.Context) error {
+		if fail {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	s.Register("panics", time.Hour, false, func(context.Context) error { panic("oops") })
+
+	if err := s.RunTask("flaky"); err == nil {
+		t.Fatal("expected the error to be returned")
+	}
+	info := s.ListTasks()[0]
+	if info.Name != "flaky" || info.LastRunAt.IsZero() || info.LastStatus != models.TaskRunFailed || info.LastError != "boom" {
+		t.Fatalf("failed runs must update the task: %+v", info)
+	}
+	fail = false
+	if err := s.RunTask("flaky"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RunTask("panics"); err == nil || err.Error() != "panic: oops" {
+		t.Fatalf("expected the panic as an error, got %v", err)
+	}
+
+	runs, total, err := s.Runs("flaky", 0, 0)
+	if err != nil || total != 2 {
+		t.Fatalf("runs: %d %v", total, err)
+	}
+	if runs[0].Status != models.TaskRunSuccess || runs[1].Status != models.TaskRunFailed || runs[1].Error != "boom" ||
+		runs[1].FinishedAt == nil || runs[1].TriggeredBy != TriggerManual {
+		t.Fatalf("unexpected history: %+v", runs)
+	}
+
+	// A restarted scheduler picks up the last run.
+	again := NewScheduler(context.Background(), db)
+	defer again.Close()
+	again.Register("flaky", time.Hour, false, func(context.Context) error { return nil })
+	if info := again.ListTasks()[0]; info.LastStatus != models.TaskRunSuccess || info.LastRunAt.IsZero() {
+		t.Fatalf("expected the last run to be restored: %+v", info)
+	}
+}
+
+func TestNextRunTimeKeepsAlignment(t *testing.T) {
+	s := NewScheduler(context.Background(), nil)
+	defer s.Close()
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	daily, _ := ParseCron("1 0 * * *", time.UTC)
+
+	ts := &taskState{schedule: daily}
+	if got, _ := s.nextRunTime(ts, now); !got.Equal(time.Date(2025, 3, 11, 0, 1, 0, 0, time.UTC)) {
+		t.Errorf("new cron task: %s", got)
+	}
+	ts.lastRun = time.Date(2025, 3, 9, 0, 1, 0, 0, time.UTC)
+	if got, _ := s.nextRunTime(ts, now); !got.Equal(now) {
+		t.Errorf("missed cron run should run now: %s", got)
+	}
+
+	hourly := &taskState{schedule: Every(time.Hour)}
+	if got, _ := s.nextRunTime(hourly, now); !got.Equal(now) {
+		t.Errorf("new interval task should run now: %s", got)
+	}
+	hourly.lastRun = now.Add(-20 * time.Minute)
+	if got, _ := s.nextRunTime(hourly, now); !got.Equal(now.Add(40 * time.Minute)) {
+		t.Errorf("interval should continue from the last run: %s", got)
+	}
+}
+
+func TestScheduledLoopRuns(t *testing.T) {
+	s := NewScheduler(context.Background(), newTestDB(t))
+	defer s.Close()
+	ran := make(chan struct{}, 1)
+	s.Register("tick", time.Hour, true, func(context.Context) error {
+		select {
+		case ran <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+	select {
+	case <-ran:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the first interval run to start immediately")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		info := s.ListTasks()[0]
+		if info.NextRunAt != nil && info.LastStatus == models.TaskRunSuccess && info.NextRunAt.After(info.LastRunAt) {
+			if info.NextRunAt.Sub(info.LastRunAt) != time.Hour {
+				t.Fatalf("next run should be an interval after the last: %+v", info)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("task state not updated: %+v", s.ListTasks()[0])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSettingsSurviveRestart(t *testing.T) {
+	db := newTestDB(t)
+	noop := func(context.Context) error { return nil }
+	s := NewScheduler(context.Background(), db)
+	defer s.Close()
+	s.Register("sweep", time.Hour, true, noop)
+	if err := s.SetEnabled("sweep", false); err != nil {
+		t.Fatal(err)
+	}
+	spec, timeout, retries := "30 2 * * 1", 90, 0
+	if _, err := s.Configure("sweep", TaskConfig{Schedule: &spec, TimeoutSeconds: &timeout, MaxRetries: &retries}); err != nil {
+		t.Fatal(err)
+	}
+	bad := "61 * * * *"
+	if _, err := s.Configure("sweep", TaskConfig{Schedule: &bad}); err == nil {
+		t.Fatal("expected an invalid schedule to be rejected")
+	}
+	if err := s.SetEnabled("missing", true); !errors.Is(err, ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound, got %v", err)
+	}
+
+	again := NewScheduler(context.Background(), db)
+	defer again.Close()
+	again.Register("sweep", time.Hour, true, noop)
+	info := again.ListTasks()[0]
+	if info.Enabled || info.Schedule != spec || info.TimeoutSeconds != 90 || info.MaxRetries != 0 || info.NextRunAt != nil {
+		t.Fatalf("expected stored settings to win over the registration: %+v", info)
+	}
+}
+
+func TestRunTimesOut(t *testing.T) {
+	s := NewScheduler(context.Background(), newTestDB(t))
+	defer s.Close()
+	s.Register("hang", time.Hour, false, func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(200 * time.Millisecond) // ignores cancellation for a while
+		return nil
+	})
+	s.mu.Lock()
+	s.tasks["hang"].opts.timeout = 50 * time.Millisecond
+	s.mu.Unlock()
+
+	start := time.Now()
+	err := s.RunTask("hang")
+	if err == nil || err.Error() != "timed out after 50ms" || time.Since(start) > 500*time.Millisecond {
+		t.Fatalf("expected the run to give up after its timeout, got %v after %s", err, time.Since(start))
+	}
+	// The abandoned run still holds the task until the runner returns.
+	if err := s.RunTask("hang"); !errors.Is(err, ErrTaskRunning) {
+		t.Fatalf("expected the abandoned run to keep the task locked, got %v", err)
+	}
+	if info := s.ListTasks()[0]; !info.Running || info.LastStatus != models.TaskRunFailed {
+		t.Fatalf("expected the timeout recorded while the runner is still running: %+v", info)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for s.ListTasks()[0].Running {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the task to be released once the runner returned")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	var lease models.TaskLease
+	s.db.First(&lease, "task = ?", "hang")
+	if !lease.ExpiresAt.Before(time.Now()) {
+		t.Fatalf("expected the lease to be released: %+v", lease)
+	}
+}
+
+type recordingNotifier struct {
+	failing   []int
+	recovered int
+}
+
+func (n *recordingNotifier) TaskFailing(_ context.Context, _ string, failures int, _ error) {
+	n.failing = append(n.failing, failures)
+}
+
+func (n *recordingNotifier) TaskRecovered(context.Context, string) { n.recovered++ }
+
+func TestFailedRunsRetryAndAlert(t *testing.T) {
+	db := newTestDB(t)
+	s := NewScheduler(context.Background(), db)
+	defer s.Close()
+	n := &recordingNotifier{}
+	s.SetNotifier(n)
+	fail := true
+	s.Register("sync", time.Hour, false, func(context.Context) error {
+		if fail {
+			return errors.New("upstream down")
+		}
+		return nil
+	})
+	retries, backoff, alertAfter := 2, 10, 3
+	if _, err := s.Configure("sync", TaskConfig{MaxRetries: &retries, RetryBackoffSeconds: &backoff, AlertAfterFailures: &alertAfter}); err != nil {
+		t.Fatal(err)
+	}
+
+	s.execute("sync", TriggerSchedule)
+	info := s.ListTasks()[0]
+	if info.RetryAt == nil || info.RetryAt.Sub(info.LastRunAt) < 10*time.Second || info.ConsecutiveFailures != 1 {
+		t.Fatalf("expected a retry after the backoff: %+v", info)
+	}
+	s.mu.Lock()
+	next, retry := s.nextRunTime(s.tasks["sync"], time.Now())
+	s.mu.Unlock()
+	if !retry || !next.Equal(*info.RetryAt) {
+		t.Fatalf("expected the loop to wait for the retry, got %s %v", next, retry)
+	}
+
+	s.execute("sync", TriggerRetry)
+	if info := s.ListTasks()[0]; info.RetryAt == nil || info.RetryAt.Sub(info.LastRunAt) < 20*time.Second {
+		t.Fatalf("expected the backoff to double: %+v", info)
+	}
+	s.execute("sync", TriggerRetry)
+	if info := s.ListTasks()[0]; info.RetryAt != nil || info.ConsecutiveFailures != 3 {
+		t.Fatalf("expected retries to stop after max_retries: %+v", info)
+	}
+	if len(n.failing) != 1 || n.failing[0] != 3 {
+		t.Fatalf("expected one alert at the third failure, got %v", n.failing)
+	}
+	s.RunTask("sync")
+	if len(n.failing) != 1 {
+		t.Fatalf("expected no repeated alert while still failing, got %v", n.failing)
+	}
+
+	// A restart keeps the streak without alerting again.
+	again := NewScheduler(context.Background(), db)
+	defer again.Close()
+	again.SetNotifier(n)
+	again.Register("sync", time.Hour, false, func(context.Context) error { return nil })
+	if info := again.ListTasks()[0]; info.ConsecutiveFailures != 4 {
+		t.Fatalf("expected the failure streak to be restored: %+v", info)
+	}
+	fail = false
+	if err := again.RunTask("sync"); err != nil {
+		t.Fatal(err)
+	}
+	if n.recovered != 1 || again.ListTasks()[0].ConsecutiveFailures != 0 {
+		t.Fatalf("expected a recovery notice: %+v", again.ListTasks()[0])
+	}
+}
@@ -0,0 +1,182 @@
+package scheduler
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Runs returns a task's recorded runs, newest first, with the total count.
+// Without a database there is no history.
+func (s *Scheduler) Runs(name string, limit, offset int) ([]models.TaskRun, int64, error) {
+	if s.db == nil {
+		return []models.TaskRun{}, 0, nil
+	}
+	q := s.db.WithContext(s.ctx).Model(&models.TaskRun{}).Where("task = ?", name)
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	var runs []models.TaskRun
+	if err := q.Order("started_at DESC, id DESC").Limit(limit).Offset(offset).Find(&runs).Error; err != nil {
+		return nil, 0, err
+	}
+	return runs, total, nil
+}
+
+func (s *Scheduler) lastRecordedRun(name string) (*models.TaskRun, error) {
+	if s.db == nil {
+		return nil, nil
+	}
+	var run models.TaskRun
+	err := s.db.WithContext(s.ctx).Where("task = ? AND status <> ?", name, models.TaskRunRunning).
+		Order("started_at DESC, id DESC").First(&run).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// markInterrupted fails runs left "running" by a process that died. Called
+// with the task's lock held, so no such run can still be going.
+func (s *Scheduler) markInterrupted(name string) {
+	if s.db == nil {
+		return
+	}
+	if err := s.db.WithContext(s.ctx).Model(&models.TaskRun{}).
+		Where("task = ? AND status = ?", name, models.TaskRunRunning).
+		Updates(map[string]interface{}{"status": models.TaskRunFailed, "error": "interrupted"}).Error; err != nil {
+		log.Printf("scheduler: marking interrupted runs of %s: %v", name, err)
+	}
+}
+
+// recordStart inserts a running row and returns its id, or 0 when runs are
+// not persisted. Failing to record never stops the task itself.
+func (s *Scheduler) recordStart(name, trigger string, at time.Time) int {
+	if s.db == nil {
+		return 0
+	}
+	run := models.TaskRun{Task: name, TriggeredBy: trigger, Status: models.TaskRunRunning, StartedAt: at}
+	if err := s.db.WithContext(s.ctx).Create(&run).Error; err != nil {
+		log.Printf("scheduler: recording run of %s: %v", name, err)
+		return 0
+	}
+	return run.ID
+}
+
+func (s *Scheduler) recordFinish(id int, start, end time.Time, runErr error) {
+	if s.db == nil || id == 0 {
+		return
+	}
+	status, msg := runStatus(runErr)
+	if err := s.db.WithContext(s.ctx).Model(&models.TaskRun{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":      status,
+		"error":       msg,
+		"finished_at": end,
+		"duration_ms": end.Sub(start).Milliseconds(),
+	}).Error; err != nil {
+		log.Printf("scheduler: recording result of run %d: %v", id, err)
+	}
+}
+
+func runStatus(err error) (status, msg string) {
+	if err != nil {
+		return models.TaskRunFailed, err.Error()
+	}
+	return models.TaskRunSuccess, ""
+}
+
+// consecutiveFailures counts the task's failed runs since its last success.
+func (s *Scheduler) consecutiveFailures(name string) (int, error) {
+	if s.db == nil {
+		return 0, nil
+	}
+	q := s.db.WithContext(s.ctx).Model(&models.TaskRun{}).Where("task = ? AND status = ?", name, models.TaskRunFailed)
+	last, err := s.lastRunWithStatus(name, models.TaskRunSuccess)
+	if err != nil {
+		return 0, err
+	}
+	if last != nil {
+		q = q.Where("started_at > ?", last.StartedAt)
+	}
+	var n int64
+	err = q.Count(&n).Error
+	return int(n), err
+}
+
+func (s *Scheduler) lastRunWithStatus(name, status string) (*models.TaskRun, error) {
+	var run models.TaskRun
+	err := s.db.WithContext(s.ctx).Where("task = ? AND status = ?", name, status).
+		Order("started_at DESC, id DESC").First(&run).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// applySettings overrides ts with the task's stored settings, if any. A
+// stored schedule that no longer parses is logged and ignored.
+func (s *Scheduler) applySettings(ts *taskState) error {
+	if s.db == nil {
+		return nil
+	}
+	var set models.TaskSetting
+	err := s.db.WithContext(s.ctx).Where("task = ?", ts.name).First(&set).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	ts.enabled = set.Enabled
+	if sched, err := ParseSchedule(set.Schedule, s.loc); err != nil {
+		log.Printf("scheduler: ignoring stored schedule of %s: %v", ts.name, err)
+	} else {
+		ts.schedule = sched
+	}
+	ts.opts = taskOptions{
+		timeout:    time.Duration(set.TimeoutSeconds) * time.Second,
+		maxRetries: min(max(set.MaxRetries, 0), maxRetriesLimit),
+		backoff:    time.Duration(set.RetryBackoffSeconds) * time.Second,
+		alertAfter: set.AlertAfterFailures,
+	}
+	if ts.opts.backoff <= 0 {
+		ts.opts.backoff = s.defaults.backoff
+	}
+	return nil
+}
+
+// settingOf captures ts's configuration. Callers hold s.mu.
+func settingOf(ts *taskState) models.TaskSetting {
+	return models.TaskSetting{
+		Task:                ts.name,
+		Enabled:             ts.enabled,
+		Schedule:            ts.schedule.String(),
+		TimeoutSeconds:      int(ts.opts.timeout / time.Second),
+		MaxRetries:          ts.opts.maxRetries,
+		RetryBackoffSeconds: int(ts.opts.backoff / time.Second),
+		AlertAfterFailures:  ts.opts.alertAfter,
+	}
+}
+
+// saveSetting upserts a task's settings. Without a database they last until
+// the process exits.
+func (s *Scheduler) saveSetting(set models.TaskSetting) error {
+	if s.db == nil {
+		return nil
+	}
+	return s.db.WithContext(s.ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&set).Error
+}
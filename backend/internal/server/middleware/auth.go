@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"os"
 	"strings"
@@ -9,7 +10,47 @@ import (
 	jwt "github.com/golang-jwt/jwt/v5"
 )
 
+// apiKeyPrefix marks a bearer credential as an API key; kept in sync with services.APIKeyPrefix.
+const apiKeyPrefix = "fms_"
+
+// APIKeyAuthenticator resolves a plaintext API key to its owner and granted scopes.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, raw string) (userID int, scopes []string, err error)
+}
+
+var apiKeyAuth APIKeyAuthenticator
+
+// SetAPIKeyAuthenticator enables API key authentication in AuthMiddleware.
+func SetAPIKeyAuthenticator(a APIKeyAuthenticator) { apiKeyAuth = a }
+
+// apiKeyCredentialKey holds the request's apiKeyCredential in the gin context.
+const apiKeyCredentialKey = "api_key_credential"
+
+// apiKeyCredential is the outcome of authenticating one API key.
+type apiKeyCredential struct {
+	raw    string
+	userID int
+	scopes []string
+	err    error
+}
+
+// authenticateAPIKey looks raw up once per request: the rate limiter and
+// AuthMiddleware share the stored result, so a key is checked (and its
+// last_used_at written) a single time.
+func authenticateAPIKey(c *gin.Context, raw string) (int, []string, error) {
+	if v, ok := c.Get(apiKeyCredentialKey); ok {
+		if cred, ok := v.(apiKeyCredential); ok && cred.raw == raw {
+			return cred.userID, cred.scopes, cred.err
+		}
+	}
+	uid, scopes, err := apiKeyAuth.AuthenticateAPIKey(c.Request.Context(), raw)
+	c.Set(apiKeyCredentialKey, apiKeyCredential{raw: raw, userID: uid, scopes: scopes, err: err})
+	return uid, scopes, err
+}
+
 // AuthMiddleware validates Bearer JWT using HS256 and JWT_SECRET.
+// API keys are accepted via "Authorization: Bearer fms_..." or the X-API-Key header
+// once an authenticator has been registered with SetAPIKeyAuthenticator.
 func AuthMiddleware() gin.HandlerFunc {
 	secret := os.Getenv("JWT_SECRET")
 	return func(c *gin.Context) {
@@ -17,6 +58,8 @@ func AuthMiddleware() gin.HandlerFunc {
 		var tokenStr string
 		if strings.HasPrefix(auth, "Bearer ") {
 			tokenStr = strings.TrimPrefix(auth, "Bearer ")
+		} else if key := c.GetHeader("X-API-Key"); key != "" {
+			tokenStr = key
 		} else {
 			if cookieToken, err := c.Cookie("auth_token"); err == nil && cookieToken != "" {
 				tokenStr = cookieToken
@@ -26,6 +69,22 @@ func AuthMiddleware() gin.HandlerFunc {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
 			return
 		}
+		if strings.HasPrefix(tokenStr, apiKeyPrefix) {
+			if apiKeyAuth == nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "api keys not enabled"})
+				return
+			}
+			uid, scopes, err := authenticateAPIKey(c, tokenStr)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				return
+			}
+			c.Set("user_id", uid)
+			c.Set("auth_method", "api_key")
+			c.Set("scopes", scopes)
+			c.Next()
+			return
+		}
 		token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
 			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
 				return nil, jwt.ErrTokenUnverifiable
@@ -56,6 +115,38 @@ func AuthMiddleware() gin.HandlerFunc {
 				c.Set("user_email", email)
 			}
 		}
+		c.Set("auth_method", "jwt")
+		c.Next()
+	}
+}
+
+// RequireScope rejects API key requests lacking the given scope.
+// JWT sessions act as the user and are not scope-restricted.
+// Must run after AuthMiddleware.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("auth_method") != "api_key" {
+			c.Next()
+			return
+		}
+		if v, ok := c.Get("scopes"); ok {
+			if scopes, ok := v.([]string); ok && contains(scopes, scope) {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "api key missing scope " + scope})
+	}
+}
+
+// RequireSession rejects API key requests; used for routes that manage credentials.
+// Must run after AuthMiddleware.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("auth_method") == "api_key" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "api keys cannot access this endpoint"})
+			return
+		}
 		c.Next()
 	}
 }
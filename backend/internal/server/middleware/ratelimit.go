@@ -1,76 +1,175 @@
 package middleware
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
 	"net"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// RateLimitStore counts requests per key in fixed windows. Incr records one hit and
+// returns the count so far in the current window and when that window resets.
+type RateLimitStore interface {
+	Incr(ctx context.Context, key string, window time.Duration) (count int, resetAt time.Time, err error)
+}
+
+// RateLimitPolicy is a named limit together with the function that derives the
+// bucket key (client IP, credential, heartbeat token, ...) from a request.
+type RateLimitPolicy struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+	Key    func(c *gin.Context) string
+}
+
+// RateLimitRule applies Policy to requests for which Match returns true.
+type RateLimitRule struct {
+	Match  func(c *gin.Context) bool
+	Policy RateLimitPolicy
+}
+
+var (
+	rateLimitStoreMu sync.RWMutex
+	rateLimitStore   RateLimitStore
+)
+
+// SetRateLimitStore sets the store used by RateLimitFromEnv. Without one an
+// in-memory store is used, which only limits within a single process.
+func SetRateLimitStore(s RateLimitStore) {
+	rateLimitStoreMu.Lock()
+	rateLimitStore = s
+	rateLimitStoreMu.Unlock()
+}
+
+func currentRateLimitStore() RateLimitStore {
+	rateLimitStoreMu.RLock()
+	defer rateLimitStoreMu.RUnlock()
+	return rateLimitStore
+}
+
+// MemoryRateLimitStore is a per-process store. Expired windows are swept
+// periodically so memory stays proportional to recently active keys.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	entries   map[string]*limiterEntry
+	nextSweep time.Time
+	now       func() time.Time
+}
+
 type limiterEntry struct {
-	count      int
-	windowFrom time.Time
+	count   int
+	resetAt time.Time
 }
 
-type rateLimiter struct {
-	mu     sync.Mutex
-	limit  int
-	window time.Duration
-	store  map[string]*limiterEntry
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{entries: make(map[string]*limiterEntry), now: time.Now}
 }
 
-// RateLimitFromEnv creates a limiter using env vars RATE_LIMIT_REQUESTS and RATE_LIMIT_WINDOW_SECS.
-// Defaults: 100 req per 60 seconds.
-func RateLimitFromEnv() gin.HandlerFunc {
-	limit := 100
-	if v := os.Getenv("RATE_LIMIT_REQUESTS"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil && n > 0 {
-			limit = n
+func (s *MemoryRateLimitStore) Incr(_ context.Context, key string, window time.Duration) (int, time.Time, error) {
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.After(s.nextSweep) {
+		for k, e := range s.entries {
+			if !now.Before(e.resetAt) {
+				delete(s.entries, k)
+			}
 		}
+		s.nextSweep = now.Add(time.Minute)
 	}
-	window := 60 * time.Second
-	if v := os.Getenv("RATE_LIMIT_WINDOW_SECS"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil && n > 0 {
-			window = time.Duration(n) * time.Second
-		}
+	e, ok := s.entries[key]
+	if !ok || !now.Before(e.resetAt) {
+		e = &limiterEntry{resetAt: now.Add(window)}
+		s.entries[key] = e
+	}
+	e.count++
+	return e.count, e.resetAt, nil
+}
+
+// Len reports the number of tracked keys.
+func (s *MemoryRateLimitStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// RateLimitFromEnv builds the limiter with the default per-IP policy plus stricter
+// auth, higher heartbeat and per-API-key policies, each configurable via env:
+//
+//	RATE_LIMIT_REQUESTS / RATE_LIMIT_WINDOW_SECS                     (100 / 60)
+//	RATE_LIMIT_AUTH_REQUESTS / RATE_LIMIT_AUTH_WINDOW_SECS           (20 / 60)
+//	RATE_LIMIT_HEARTBEAT_REQUESTS / RATE_LIMIT_HEARTBEAT_WINDOW_SECS (600 / 60)
+//	RATE_LIMIT_API_KEY_REQUESTS / RATE_LIMIT_API_KEY_WINDOW_SECS     (300 / 60)
+func RateLimitFromEnv() gin.HandlerFunc {
+	store := currentRateLimitStore()
+	if store == nil {
+		store = NewMemoryRateLimitStore()
+	}
+	def := policyFromEnv("default", "RATE_LIMIT", 100, 60, ipKey)
+	rules := []RateLimitRule{
+		{Match: isAuthRoute, Policy: policyFromEnv("auth", "RATE_LIMIT_AUTH", 20, 60, ipKey)},
+		{Match: isHeartbeatPing, Policy: policyFromEnv("heartbeat", "RATE_LIMIT_HEARTBEAT", 600, 60, heartbeatKey)},
+		{Match: hasAPIKey, Policy: policyFromEnv("api_key", "RATE_LIMIT_API_KEY", 300, 60, apiKeyKey)},
 	}
-	return RateLimit(limit, window)
+	return RateLimitWithPolicies(store, def, rules...)
 }
 
-// RateLimit returns a middleware that limits requests per client IP.
+// RateLimit returns a middleware that limits requests per client IP using an
+// in-memory store.
 func RateLimit(limit int, window time.Duration) gin.HandlerFunc {
-	rl := &rateLimiter{limit: limit, window: window, store: make(map[string]*limiterEntry)}
+	return RateLimitWithPolicies(NewMemoryRateLimitStore(), RateLimitPolicy{Name: "default", Limit: limit, Window: window, Key: ipKey})
+}
+
+// RateLimitWithPolicies applies the first matching rule's policy, or def when none
+// match. Store errors fail open so that a database outage does not block traffic.
+func RateLimitWithPolicies(store RateLimitStore, def RateLimitPolicy, rules ...RateLimitRule) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ip := clientIP(c.Request)
-		now := time.Now()
-		rl.mu.Lock()
-		entry, ok := rl.store[ip]
-		if !ok || now.Sub(entry.windowFrom) > rl.window {
-			entry = &limiterEntry{count: 0, windowFrom: now}
-			rl.store[ip] = entry
+		p := def
+		for _, r := range rules {
+			if r.Match(c) {
+				p = r.Policy
+				break
+			}
 		}
-		entry.count++
-		used := entry.count
-		resetIn := rl.window - now.Sub(entry.windowFrom)
-		rl.mu.Unlock()
-
-		// Set basic rate limit headers
+		if p.Limit <= 0 {
+			c.Next()
+			return
+		}
+		used, resetAt, err := store.Incr(c.Request.Context(), p.Name+":"+p.Key(c), p.Window)
+		if err != nil {
+			log.Printf("rate limit store error (%s): %v", p.Name, err)
+			c.Next()
+			return
+		}
+		resetIn := time.Until(resetAt)
 		if resetIn < 0 {
 			resetIn = 0
 		}
-		remaining := limit - used
+		resetSecs := strconv.FormatInt(int64((resetIn+time.Second-1)/time.Second), 10)
+		remaining := p.Limit - used
 		if remaining < 0 {
 			remaining = 0
 		}
-		c.Header("X-RateLimit-Limit", strconv.Itoa(limit))
+		c.Header("RateLimit-Policy", strconv.Itoa(p.Limit)+";w="+strconv.Itoa(int(p.Window/time.Second)))
+		c.Header("RateLimit-Limit", strconv.Itoa(p.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Header("RateLimit-Reset", resetSecs)
+		// Legacy headers kept for existing clients
+		c.Header("X-RateLimit-Limit", strconv.Itoa(p.Limit))
 		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
-		c.Header("X-RateLimit-Reset", strconv.FormatInt(int64(resetIn/time.Second), 10))
+		c.Header("X-RateLimit-Reset", resetSecs)
 
-		if used > rl.limit {
+		if used > p.Limit {
+			c.Header("Retry-After", resetSecs)
 			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
 			return
 		}
@@ -78,6 +177,74 @@ func RateLimit(limit int, window time.Duration) gin.HandlerFunc {
 	}
 }
 
+func policyFromEnv(name, prefix string, defLimit, defWindowSecs int, key func(*gin.Context) string) RateLimitPolicy {
+	limit := defLimit
+	if v := os.Getenv(prefix + "_REQUESTS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			limit = n
+		}
+	}
+	window := time.Duration(defWindowSecs) * time.Second
+	if v := os.Getenv(prefix + "_WINDOW_SECS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			window = time.Duration(n) * time.Second
+		}
+	}
+	return RateLimitPolicy{Name: name, Limit: limit, Window: window, Key: key}
+}
+
+func isAuthRoute(c *gin.Context) bool {
+	return strings.HasPrefix(c.Request.URL.Path, "/api/auth/")
+}
+
+func isHeartbeatPing(c *gin.Context) bool {
+	switch c.FullPath() {
+	case "/api/heartbeats/ping/:token", "/api/heartbeats/:id/ping":
+		return true
+	}
+	return false
+}
+
+// hasAPIKey matches only valid keys; unknown keys fall through to the per-IP
+// policy so that random credentials cannot be used to dodge it. The lookup is
+// stored on the request for AuthMiddleware (see authenticateAPIKey).
+func hasAPIKey(c *gin.Context) bool {
+	raw := requestAPIKey(c)
+	if raw == "" || apiKeyAuth == nil {
+		return false
+	}
+	_, _, err := authenticateAPIKey(c, raw)
+	return err == nil
+}
+
+func ipKey(c *gin.Context) string { return "ip:" + clientIP(c.Request) }
+
+// heartbeatKey buckets pings by job so that many agents behind one NAT do not
+// share a limit.
+func heartbeatKey(c *gin.Context) string {
+	if t := c.Param("token"); t != "" {
+		return "hb:" + shortHash(t)
+	}
+	return "hb:id:" + c.Param("id")
+}
+
+func apiKeyKey(c *gin.Context) string { return "key:" + shortHash(requestAPIKey(c)) }
+
+func requestAPIKey(c *gin.Context) string {
+	if k := strings.TrimSpace(c.GetHeader("X-API-Key")); strings.HasPrefix(k, apiKeyPrefix) {
+		return k
+	}
+	if h := c.GetHeader("Authorization"); strings.HasPrefix(h, "Bearer "+apiKeyPrefix) {
+		return strings.TrimPrefix(h, "Bearer ")
+	}
+	return ""
+}
+
+func shortHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:8])
+}
+
 func clientIP(r *http.Request) string {
 	// Honor X-Forwarded-For if present (take first IP)
 	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type stubKeys struct{}
+
+func (stubKeys) AuthenticateAPIKey(_ context.Context, raw string) (int, []string, error) {
+	if raw == "fms_good" {
+		return 1, nil, nil
+	}
+	return 0, nil, errors.New("invalid")
+}
+
+func TestRateLimitFromEnv_PerRoutePolicies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("RATE_LIMIT_REQUESTS", "2")
+	t.Setenv("RATE_LIMIT_AUTH_REQUESTS", "1")
+	t.Setenv("RATE_LIMIT_HEARTBEAT_REQUESTS", "3")
+	t.Setenv("RATE_LIMIT_API_KEY_REQUESTS", "4")
+	SetRateLimitStore(nil)
+	SetAPIKeyAuthenticator(stubKeys{})
+	t.Cleanup(func() { SetAPIKeyAuthenticator(nil) })
+
+	r := gin.New()
+	r.Use(RateLimitFromEnv())
+	ok := func(c *gin.Context) { c.Status(200) }
+	r.GET("/api/clients", ok)
+	r.POST("/api/auth/login", ok)
+	r.POST("/api/heartbeats/ping/:token", ok)
+
+	hit := func(method, path string, hdr ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		for i := 0; i+1 < len(hdr); i += 2 {
+			req.Header.Set(hdr[i], hdr[i+1])
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+	allowed := func(n int, method, path string, hdr ...string) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			if w := hit(method, path, hdr...); w.Code != 200 {
+				t.Fatalf("%s request %d: got %d", path, i+1, w.Code)
+			}
+		}
+		w := hit(method, path, hdr...)
+		if w.Code != 429 || w.Header().Get("Retry-After") == "" {
+			t.Fatalf("%s: expected 429 after %d, got %d", path, n, w.Code)
+		}
+	}
+
+	w := hit("GET", "/api/clients")
+	if w.Header().Get("RateLimit-Limit") != "2" || w.Header().Get("RateLimit-Remaining") != "1" || w.Header().Get("RateLimit-Policy") != "2;w=60" {
+		t.Fatalf("unexpected headers %v", w.Header())
+	}
+	allowed(1, "GET", "/api/clients")
+	allowed(1, "POST", "/api/auth/login")
+	allowed(3, "POST", "/api/heartbeats/ping/abc")
+	allowed(3, "POST", "/api/heartbeats/ping/def")
+	allowed(4, "GET", "/api/clients", "X-API-Key", "fms_good")
+	// Unknown keys fall back to the (exhausted) per-IP policy
+	if w := hit("GET", "/api/clients", "X-API-Key", "fms_bogus"); w.Code != 429 {
+		t.Fatalf("expected bogus key to share IP limit, got %d", w.Code)
+	}
+}
+
+type countingKeys struct{ calls *int }
+
+func (k countingKeys) AuthenticateAPIKey(ctx context.Context, raw string) (int, []string, error) {
+	*k.calls++
+	return stubKeys{}.AuthenticateAPIKey(ctx, raw)
+}
+
+func TestRateLimitSharesAPIKeyLookupWithAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	SetRateLimitStore(nil)
+	calls := 0
+	SetAPIKeyAuthenticator(countingKeys{&calls})
+	t.Cleanup(func() { SetAPIKeyAuthenticator(nil) })
+
+	r := gin.New()
+	r.Use(RateLimitFromEnv())
+	r.GET("/api/clients", AuthMiddleware(), func(c *gin.Context) { c.JSON(200, gin.H{"user_id": c.GetInt("user_id")}) })
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/clients", nil)
+	req.Header.Set("Authorization", "Bearer fms_good")
+	r.ServeHTTP(w, req)
+	if w.Code != 200 || calls != 1 {
+		t.Fatalf("expected one key lookup for the request, got %d (status %d)", calls, w.Code)
+	}
+}
+
+func TestMemoryRateLimitStore_EvictsExpired(t *testing.T) {
+	s := NewMemoryRateLimitStore()
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	for _, k := range []string{"a", "b", "c"} {
+		_, _, _ = s.Incr(context.Background(), k, time.Second)
+	}
+	now = now.Add(2 * time.Minute)
+	_, _, _ = s.Incr(context.Background(), "d", time.Second)
+	if s.Len() != 1 {
+		t.Fatalf("expected expired keys evicted, have %d", s.Len())
+	}
+}
@@ -39,6 +39,10 @@ func NewServer(clientHandler *handlers.ClientHandler, offerHandler *handlers.Off
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
 	r.Use(middleware.CORSMiddleware())
+	// RATE_LIMIT_STORE=db shares counters across replicas; default is in-memory
+	if os.Getenv("RATE_LIMIT_STORE") == "db" && database.DB != nil {
+		middleware.SetRateLimitStore(services.NewRateLimitStore(database.DB))
+	}
 	r.Use(middleware.RateLimitFromEnv())
 	r.Static("/static", "static")
 
@@ -63,14 +67,27 @@ func NewServer(clientHandler *handlers.ClientHandler, offerHandler *handlers.Off
 
 	// Serve static PDFs (already mounted above)
 
+	// Record mutating actions in the audit log
+	handlers.SetAuditService(services.NewAuditService(database.DB))
+
 	// Client routes
 	api := r.Group("/api")
 	{
-		// Dev-only token issuance (consider guarding behind env flag)
-		api.POST("/auth/token", handlers.DevTokenHandler)
+		// Dev-only token issuance; never enable in production
+		if os.Getenv("DEV_TOKEN_ENABLED") == "true" {
+			api.POST("/auth/token", handlers.DevTokenHandler)
+		}
+
+		// API keys for automation/CI; accepted by AuthMiddleware alongside JWTs
+		apiKeySvc := services.NewAPIKeyService(database.DB)
+		middleware.SetAPIKeyAuthenticator(apiKeySvc)
+		apiKeyHandler := handlers.NewAPIKeyHandler(apiKeySvc)
+		api.GET("/api-keys", middleware.AuthMiddleware(), middleware.RequireSession(), apiKeyHandler.List)
+		api.POST("/api-keys", middleware.AuthMiddleware(), middleware.RequireSession(), apiKeyHandler.Create)
+		api.DELETE("/api-keys/:id", middleware.AuthMiddleware(), middleware.RequireSession(), apiKeyHandler.Revoke)
 
 		// Auth routes
-		authSvc := services.NewAuthService(database.DB)
+		authSvc := services.NewAuthService(database.DB).WithLoginGuard(services.NewLoginGuard(database.DB, services.LoginGuardConfigFromEnv()))
 		authHandler := handlers.NewAuthHandler(authSvc)
 		api.POST("/auth/register", authHandler.Register)
 		api.POST("/auth/login", authHandler.Login)
@@ -78,15 +95,16 @@ func NewServer(clientHandler *handlers.ClientHandler, offerHandler *handlers.Off
 		api.POST("/auth/reset/confirm", authHandler.ResetPassword)
 		api.POST("/auth/logout", authHandler.Logout)
 		api.GET("/me", middleware.AuthMiddleware(), authHandler.Me)
-		api.PUT("/me/email", middleware.AuthMiddleware(), authHandler.UpdateEmail)
-		api.PUT("/me/avatar", middleware.AuthMiddleware(), authHandler.UpdateAvatar)
+		api.GET("/me/auth-events", middleware.AuthMiddleware(), middleware.RequireSession(), authHandler.Events)
+		api.PUT("/me/email", middleware.AuthMiddleware(), middleware.RequireSession(), authHandler.UpdateEmail)
+		api.PUT("/me/avatar", middleware.AuthMiddleware(), middleware.RequireSession(), authHandler.UpdateAvatar)
 		api.GET("/clients", clientHandler.GetClients)
 		api.GET("/clients/:id", clientHandler.GetClient)
 		useAuth := os.Getenv("DEV_ALLOW_UNAUTH") != "true"
 		if useAuth {
-			api.POST("/clients", middleware.AuthMiddleware(), clientHandler.CreateClient)
-			api.PUT("/clients/:id", middleware.AuthMiddleware(), clientHandler.UpdateClient)
-			api.DELETE("/clients/:id", middleware.AuthMiddleware(), clientHandler.DeleteClient)
+			api.POST("/clients", middleware.AuthMiddleware(), middleware.RequireScope("clients:write"), clientHandler.CreateClient)
+			api.PUT("/clients/:id", middleware.AuthMiddleware(), middleware.RequireScope("clients:write"), clientHandler.UpdateClient)
+			api.DELETE("/clients/:id", middleware.AuthMiddleware(), middleware.RequireScope("clients:write"), clientHandler.DeleteClient)
 		} else {
 			api.POST("/clients", clientHandler.CreateClient)
 			api.PUT("/clients/:id", clientHandler.UpdateClient)
@@ -98,30 +116,214 @@ func NewServer(clientHandler *handlers.ClientHandler, offerHandler *handlers.Off
 		api.GET("/offers/:id", offerHandler.GetOffer)
 		api.GET("/offers/:id/pdf", offerHandler.ViewPDF)
 		if useAuth {
-			api.POST("/offers", middleware.AuthMiddleware(), offerHandler.CreateOffer)
-			api.PUT("/offers/:id", middleware.AuthMiddleware(), offerHandler.UpdateOffer)
-			api.DELETE("/offers/:id", middleware.AuthMiddleware(), offerHandler.DeleteOffer)
-			api.POST("/offers/:id/generate-pdf", middleware.AuthMiddleware(), offerHandler.GeneratePDF)
-			api.POST("/offers/:id/approve", middleware.AuthMiddleware(), offerHandler.Approve)
-			api.POST("/offers/:id/upload-signed", middleware.AuthMiddleware(), offerHandler.UploadSigned)
+			api.GET("/offers/:id/history", middleware.AuthMiddleware(), middleware.RequireScope("offers:read"), offerHandler.History)
+			api.GET("/offers/:id/revisions", middleware.AuthMiddleware(), middleware.RequireScope("offers:read"), offerHandler.Revisions)
+			api.GET("/offers/:id/revisions/:rev", middleware.AuthMiddleware(), middleware.RequireScope("offers:read"), offerHandler.Revision)
+			api.GET("/offers/:id/diff", middleware.AuthMiddleware(), middleware.RequireScope("offers:read"), offerHandler.DiffRevisions)
+			api.POST("/offers", middleware.AuthMiddleware(), middleware.RequireScope("offers:write"), offerHandler.CreateOffer)
+			api.PUT("/offers/:id", middleware.AuthMiddleware(), middleware.RequireScope("offers:write"), offerHandler.UpdateOffer)
+			api.DELETE("/offers/:id", middleware.AuthMiddleware(), middleware.RequireScope("offers:write"), offerHandler.DeleteOffer)
+			api.POST("/offers/:id/generate-pdf", middleware.AuthMiddleware(), middleware.RequireScope("offers:write"), offerHandler.GeneratePDF)
+			api.POST("/offers/:id/approve", middleware.AuthMiddleware(), middleware.RequireScope("offers:write"), offerHandler.Approve)
+			api.POST("/offers/:id/send", middleware.AuthMiddleware(), middleware.RequireScope("offers:write"), offerHandler.Send)
+			api.POST("/offers/:id/revise", middleware.AuthMiddleware(), middleware.RequireScope("offers:write"), offerHandler.Revise)
+			api.POST("/offers/:id/viewed", middleware.AuthMiddleware(), middleware.RequireScope("offers:write"), offerHandler.MarkViewed)
+			api.POST("/offers/:id/accept", middleware.AuthMiddleware(), middleware.RequireScope("offers:write"), offerHandler.Accept)
+			api.POST("/offers/:id/reject", middleware.AuthMiddleware(), middleware.RequireScope("offers:write"), offerHandler.Reject)
+			api.POST("/offers/:id/expire", middleware.AuthMiddleware(), middleware.RequireScope("offers:write"), offerHandler.Expire)
+			api.POST("/offers/:id/supersede", middleware.AuthMiddleware(), middleware.RequireScope("offers:write"), offerHandler.Supersede)
+			api.POST("/offers/:id/upload-signed", middleware.AuthMiddleware(), middleware.RequireScope("offers:write"), offerHandler.UploadSigned)
+			api.POST("/offers/:id/auto-renew", middleware.AuthMiddleware(), middleware.RequireScope("offers:write"), offerHandler.AutoRenew)
 		} else {
+			api.GET("/offers/:id/history", offerHandler.History)
+			api.GET("/offers/:id/revisions", offerHandler.Revisions)
+			api.GET("/offers/:id/revisions/:rev", offerHandler.Revision)
+			api.GET("/offers/:id/diff", offerHandler.DiffRevisions)
 			api.POST("/offers", offerHandler.CreateOffer)
 			api.PUT("/offers/:id", offerHandler.UpdateOffer)
 			api.DELETE("/offers/:id", offerHandler.DeleteOffer)
 			api.POST("/offers/:id/generate-pdf", offerHandler.GeneratePDF)
 			api.POST("/offers/:id/approve", offerHandler.Approve)
+			api.POST("/offers/:id/send", offerHandler.Send)
+			api.POST("/offers/:id/revise", offerHandler.Revise)
+			api.POST("/offers/:id/viewed", offerHandler.MarkViewed)
+			api.POST("/offers/:id/accept", offerHandler.Accept)
+			api.POST("/offers/:id/reject", offerHandler.Reject)
+			api.POST("/offers/:id/expire", offerHandler.Expire)
+			api.POST("/offers/:id/supersede", offerHandler.Supersede)
 			api.POST("/offers/:id/upload-signed", offerHandler.UploadSigned)
+			api.POST("/offers/:id/auto-renew", offerHandler.AutoRenew)
+		}
+
+		// Product/service catalog and offer templates
+		catalogHandler := handlers.NewCatalogHandler(services.NewCatalogService(database.DB), services.NewOfferTemplateService(database.DB))
+		if useAuth {
+			api.GET("/catalog", middleware.AuthMiddleware(), middleware.RequireScope("offers:read"), catalogHandler.ListItems)
+			api.POST("/catalog", middleware.AuthMiddleware(), middleware.RequireScope("offers:write"), catalogHandler.CreateItem)
+			api.PUT("/catalog/:id", middleware.AuthMiddleware(), middleware.RequireScope("offers:write"), catalogHandler.UpdateItem)
+			api.DELETE("/catalog/:id", middleware.AuthMiddleware(), middleware.RequireScope("offers:write"), catalogHandler.DeleteItem)
+			api.GET("/offer-templates", middleware.AuthMiddleware(), middleware.RequireScope("offers:read"), catalogHandler.ListTemplates)
+			api.GET("/offer-templates/:id", middleware.AuthMiddleware(), middleware.RequireScope("offers:read"), catalogHandler.GetTemplate)
+			api.POST("/offer-templates", middleware.AuthMiddleware(), middleware.RequireScope("offers:write"), catalogHandler.CreateTemplate)
+			api.PUT("/offer-templates/:id", middleware.AuthMiddleware(), middleware.RequireScope("offers:write"), catalogHandler.UpdateTemplate)
+			api.DELETE("/offer-templates/:id", middleware.AuthMiddleware(), middleware.RequireScope("offers:write"), catalogHandler.DeleteTemplate)
+			api.POST("/offer-templates/:id/offers", middleware.AuthMiddleware(), middleware.RequireScope("offers:write"), catalogHandler.CreateOffer)
+		} else {
+			api.GET("/catalog", catalogHandler.ListItems)
+			api.POST("/catalog", catalogHandler.CreateItem)
+			api.PUT("/catalog/:id", catalogHandler.UpdateItem)
+			api.DELETE("/catalog/:id", catalogHandler.DeleteItem)
+			api.GET("/offer-templates", catalogHandler.ListTemplates)
+			api.GET("/offer-templates/:id", catalogHandler.GetTemplate)
+			api.POST("/offer-templates", catalogHandler.CreateTemplate)
+			api.PUT("/offer-templates/:id", catalogHandler.UpdateTemplate)
+			api.DELETE("/offer-templates/:id", catalogHandler.DeleteTemplate)
+			api.POST("/offer-templates/:id/offers", catalogHandler.CreateOffer)
+		}
+
+		// Sales pipeline analytics
+		analyticsHandler := handlers.NewOfferAnalyticsHandler(services.NewOfferAnalyticsService(database.DB))
+		if useAuth {
+			api.GET("/analytics/offers", middleware.AuthMiddleware(), middleware.RequireScope("offers:read"), analyticsHandler.Get)
+			api.GET("/analytics/offers/export", middleware.AuthMiddleware(), middleware.RequireScope("offers:read"), analyticsHandler.Export)
+		} else {
+			api.GET("/analytics/offers", analyticsHandler.Get)
+			api.GET("/analytics/offers/export", analyticsHandler.Export)
+		}
+
+		// Offer numbering format and yearly reset
+		numberingHandler := handlers.NewNumberingHandler(services.NewNumberingService(database.DB))
+		if useAuth {
+			api.GET("/settings/offer-numbering", middleware.AuthMiddleware(), middleware.RequireScope("offers:read"), numberingHandler.Get)
+			api.PUT("/settings/offer-numbering", middleware.AuthMiddleware(), middleware.RequireScope("offers:write"), numberingHandler.Update)
+		} else {
+			api.GET("/settings/offer-numbering", numberingHandler.Get)
+			api.PUT("/settings/offer-numbering", numberingHandler.Update)
+		}
+
+		// Exchange rates and revenue in the base currency
+		rateHandler := handlers.NewExchangeRateHandler(services.NewExchangeRateService(database.DB), services.NewRevenueService(database.DB))
+		if useAuth {
+			api.GET("/exchange-rates", middleware.AuthMiddleware(), middleware.RequireScope("invoices:read"), rateHandler.List)
+			api.PUT("/exchange-rates", middleware.AuthMiddleware(), middleware.RequireScope("invoices:write"), rateHandler.Set)
+			api.POST("/exchange-rates/import", middleware.AuthMiddleware(), middleware.RequireScope("invoices:write"), rateHandler.Import)
+			api.DELETE("/exchange-rates/:id", middleware.AuthMiddleware(), middleware.RequireScope("invoices:write"), rateHandler.Delete)
+			api.GET("/reports/revenue", middleware.AuthMiddleware(), middleware.RequireScope("reports:read"), rateHandler.Revenue)
+		} else {
+			api.GET("/exchange-rates", rateHandler.List)
+			api.PUT("/exchange-rates", rateHandler.Set)
+			api.POST("/exchange-rates/import", rateHandler.Import)
+			api.DELETE("/exchange-rates/:id", rateHandler.Delete)
+			api.GET("/reports/revenue", rateHandler.Revenue)
+		}
+
+		// Emailed offers and the public accept/reject links (no auth; the token is the credential)
+		offerDeliveryHandler := handlers.NewOfferDeliveryHandler(services.NewOfferDeliveryService(database.DB))
+		if useAuth {
+			api.POST("/offers/:id/email", middleware.AuthMiddleware(), middleware.RequireScope("offers:write"), offerDeliveryHandler.Send)
+		} else {
+			api.POST("/offers/:id/email", offerDeliveryHandler.Send)
+		}
+		api.GET("/public/offers/:token", offerDeliveryHandler.PublicView)
+		api.GET("/public/offers/:token/pdf", offerDeliveryHandler.PublicPDF)
+		api.POST("/public/offers/:token/accept", offerDeliveryHandler.PublicAccept)
+		api.POST("/public/offers/:token/reject", offerDeliveryHandler.PublicReject)
+
+		// Invoices generated from accepted offers
+		invoiceHandler := handlers.NewInvoiceHandler(services.NewInvoiceService(database.DB), services.NewClientService(database.DB))
+		if useAuth {
+			api.GET("/invoices", middleware.AuthMiddleware(), middleware.RequireScope("invoices:read"), invoiceHandler.List)
+			api.GET("/invoices/:id", middleware.AuthMiddleware(), middleware.RequireScope("invoices:read"), invoiceHandler.Get)
+			api.POST("/offers/:id/invoice", middleware.AuthMiddleware(), middleware.RequireScope("invoices:write"), invoiceHandler.CreateFromOffer)
+			api.POST("/invoices/:id/send", middleware.AuthMiddleware(), middleware.RequireScope("invoices:write"), invoiceHandler.Send)
+			api.POST("/invoices/:id/void", middleware.AuthMiddleware(), middleware.RequireScope("invoices:write"), invoiceHandler.Void)
+			api.POST("/invoices/:id/payments", middleware.AuthMiddleware(), middleware.RequireScope("invoices:write"), invoiceHandler.AddPayment)
+			api.POST("/invoices/:id/pdf", middleware.AuthMiddleware(), middleware.RequireScope("invoices:write"), invoiceHandler.GeneratePDF)
+		} else {
+			api.GET("/invoices", invoiceHandler.List)
+			api.GET("/invoices/:id", invoiceHandler.Get)
+			api.POST("/offers/:id/invoice", invoiceHandler.CreateFromOffer)
+			api.POST("/invoices/:id/send", invoiceHandler.Send)
+			api.POST("/invoices/:id/void", invoiceHandler.Void)
+			api.POST("/invoices/:id/payments", invoiceHandler.AddPayment)
+			api.POST("/invoices/:id/pdf", invoiceHandler.GeneratePDF)
+		}
+
+		// Recurring retainers billed per cycle
+		subscriptionHandler := handlers.NewSubscriptionHandler(services.NewSubscriptionService(database.DB), services.NewInvoiceService(database.DB))
+		if useAuth {
+			api.GET("/subscriptions", middleware.AuthMiddleware(), middleware.RequireScope("subscriptions:read"), subscriptionHandler.List)
+			api.GET("/subscriptions/:id", middleware.AuthMiddleware(), middleware.RequireScope("subscriptions:read"), subscriptionHandler.Get)
+			api.GET("/subscriptions/:id/invoices", middleware.AuthMiddleware(), middleware.RequireScope("subscriptions:read"), subscriptionHandler.Invoices)
+			api.POST("/subscriptions", middleware.AuthMiddleware(), middleware.RequireScope("subscriptions:write"), subscriptionHandler.Create)
+			api.PUT("/subscriptions/:id", middleware.AuthMiddleware(), middleware.RequireScope("subscriptions:write"), subscriptionHandler.Update)
+			api.POST("/subscriptions/:id/cancel", middleware.AuthMiddleware(), middleware.RequireScope("subscriptions:write"), subscriptionHandler.Cancel)
+		} else {
+			api.GET("/subscriptions", subscriptionHandler.List)
+			api.GET("/subscriptions/:id", subscriptionHandler.Get)
+			api.GET("/subscriptions/:id/invoices", subscriptionHandler.Invoices)
+			api.POST("/subscriptions", subscriptionHandler.Create)
+			api.PUT("/subscriptions/:id", subscriptionHandler.Update)
+			api.POST("/subscriptions/:id/cancel", subscriptionHandler.Cancel)
+		}
+
+		// Consolidated monthly reports per client
+		clientReportHandler := handlers.NewClientReportHandler(services.NewClientReportService(database.DB))
+		if useAuth {
+			api.POST("/clients/:id/reports/monthly", middleware.AuthMiddleware(), middleware.RequireScope("reports:write"), clientReportHandler.Generate)
+			api.GET("/clients/:id/reports/monthly", middleware.AuthMiddleware(), middleware.RequireScope("reports:read"), clientReportHandler.List)
+			api.GET("/reports/client-monthly/:id", middleware.AuthMiddleware(), middleware.RequireScope("reports:read"), clientReportHandler.Get)
+			api.GET("/reports/client-monthly/:id/pdf", middleware.AuthMiddleware(), middleware.RequireScope("reports:read"), clientReportHandler.PDF)
+			api.POST("/reports/client-monthly/:id/regenerate", middleware.AuthMiddleware(), middleware.RequireScope("reports:write"), clientReportHandler.Regenerate)
+			api.POST("/reports/client-monthly/:id/finalize", middleware.AuthMiddleware(), middleware.RequireScope("reports:write"), clientReportHandler.Finalize)
+		} else {
+			api.POST("/clients/:id/reports/monthly", clientReportHandler.Generate)
+			api.GET("/clients/:id/reports/monthly", clientReportHandler.List)
+			api.GET("/reports/client-monthly/:id", clientReportHandler.Get)
+			api.GET("/reports/client-monthly/:id/pdf", clientReportHandler.PDF)
+			api.POST("/reports/client-monthly/:id/regenerate", clientReportHandler.Regenerate)
+			api.POST("/reports/client-monthly/:id/finalize", clientReportHandler.Finalize)
+		}
+
+		// Work log feeding monthly reports and invoices
+		timeEntryHandler := handlers.NewTimeEntryHandler(services.NewTimeEntryService(database.DB), services.NewInvoiceService(database.DB))
+		if useAuth {
+			api.GET("/time-entries", middleware.AuthMiddleware(), middleware.RequireScope("reports:read"), timeEntryHandler.List)
+			api.GET("/time-entries/:id", middleware.AuthMiddleware(), middleware.RequireScope("reports:read"), timeEntryHandler.Get)
+			api.POST("/time-entries", middleware.AuthMiddleware(), middleware.RequireScope("reports:write"), timeEntryHandler.Create)
+			api.PUT("/time-entries/:id", middleware.AuthMiddleware(), middleware.RequireScope("reports:write"), timeEntryHandler.Update)
+			api.DELETE("/time-entries/:id", middleware.AuthMiddleware(), middleware.RequireScope("reports:write"), timeEntryHandler.Delete)
+			api.POST("/time-entries/invoice", middleware.AuthMiddleware(), middleware.RequireScope("invoices:write"), timeEntryHandler.Invoice)
+		} else {
+			api.GET("/time-entries", timeEntryHandler.List)
+			api.GET("/time-entries/:id", timeEntryHandler.Get)
+			api.POST("/time-entries", timeEntryHandler.Create)
+			api.PUT("/time-entries/:id", timeEntryHandler.Update)
+			api.DELETE("/time-entries/:id", timeEntryHandler.Delete)
+			api.POST("/time-entries/invoice", timeEntryHandler.Invoice)
+		}
+
+		// Scheduled monthly report emails
+		deliveryHandler := handlers.NewReportDeliveryHandler(services.NewReportDeliveryService(database.DB))
+		if useAuth {
+			api.GET("/report-deliveries", middleware.AuthMiddleware(), middleware.RequireScope("reports:read"), deliveryHandler.List)
+			api.POST("/report-deliveries/:id/retry", middleware.AuthMiddleware(), middleware.RequireScope("reports:write"), deliveryHandler.Retry)
+		} else {
+			api.GET("/report-deliveries", deliveryHandler.List)
+			api.POST("/report-deliveries/:id/retry", deliveryHandler.Retry)
 		}
 
 		// Service routes
-		api.GET("/services", serviceHandler.ListServices)
-		api.GET("/services/:id", serviceHandler.GetService)
 		if useAuth {
-            api.POST("/services", middleware.AuthMiddleware(), serviceHandler.CreateService)
-            api.PUT("/services/:id", middleware.AuthMiddleware(), serviceHandler.UpdateService)
-            api.DELETE("/services/:id", middleware.AuthMiddleware(), serviceHandler.DeleteService)
-            api.POST("/services/:id/check", middleware.AuthMiddleware(), handlers.NewServiceCheckHandler().CheckNow)
+			api.GET("/services", middleware.AuthMiddleware(), middleware.RequireScope("services:read"), serviceHandler.ListServices)
+			api.GET("/services/:id", middleware.AuthMiddleware(), middleware.RequireScope("services:read"), serviceHandler.GetService)
+            api.POST("/services", middleware.AuthMiddleware(), middleware.RequireScope("services:write"), serviceHandler.CreateService)
+            api.PUT("/services/:id", middleware.AuthMiddleware(), middleware.RequireScope("services:write"), serviceHandler.UpdateService)
+            api.DELETE("/services/:id", middleware.AuthMiddleware(), middleware.RequireScope("services:write"), serviceHandler.DeleteService)
+            api.POST("/services/:id/check", middleware.AuthMiddleware(), middleware.RequireScope("checks:run"), handlers.NewServiceCheckHandler().CheckNow)
 		} else {
+			api.GET("/services", serviceHandler.ListServices)
+			api.GET("/services/:id", serviceHandler.GetService)
             api.POST("/services", serviceHandler.CreateService)
             api.PUT("/services/:id", serviceHandler.UpdateService)
             api.DELETE("/services/:id", serviceHandler.DeleteService)
@@ -145,7 +347,7 @@ func NewServer(clientHandler *handlers.ClientHandler, offerHandler *handlers.Off
 		api.GET("/services/:id/alerts", alertHandler.ListAlerts)
 		api.GET("/alerts", alertHandler.ListAlerts)
 		if useAuth {
-			api.POST("/alerts/:id/resolve", middleware.AuthMiddleware(), alertHandler.ResolveAlert)
+			api.POST("/alerts/:id/resolve", middleware.AuthMiddleware(), middleware.RequireScope("alerts:write"), alertHandler.ResolveAlert)
 		} else {
 			api.POST("/alerts/:id/resolve", alertHandler.ResolveAlert)
 		}
@@ -154,42 +356,60 @@ func NewServer(clientHandler *handlers.ClientHandler, offerHandler *handlers.Off
 		reportSvc := services.NewReportService(database.DB)
 		reportHandler := handlers.NewReportHandler(reportSvc)
 		if useAuth {
-			api.POST("/reports/daily", middleware.AuthMiddleware(), reportHandler.GenerateDaily)
+			api.POST("/reports/daily", middleware.AuthMiddleware(), middleware.RequireScope("reports:write"), reportHandler.GenerateDaily)
 		} else {
 			api.POST("/reports/daily", reportHandler.GenerateDaily)
 		}
 		reportReadHandler := handlers.NewReportReadHandler(database.DB)
 		api.GET("/reports/daily", reportReadHandler.ListDaily)
         if useAuth {
-            api.POST("/reports/monthly", middleware.AuthMiddleware(), monthlyHandler.GenerateMonthlyReportFromBody)
-            api.GET("/services/:id/reports/monthly", middleware.AuthMiddleware(), monthlyHandler.ListMonthlyReports)
-            api.GET("/reports/monthly/:id", middleware.AuthMiddleware(), monthlyHandler.GetMonthlyReport)
+            api.POST("/reports/monthly", middleware.AuthMiddleware(), middleware.RequireScope("reports:write"), monthlyHandler.GenerateMonthlyReportFromBody)
+            api.GET("/services/:id/reports/monthly", middleware.AuthMiddleware(), middleware.RequireScope("reports:read"), monthlyHandler.ListMonthlyReports)
+            api.GET("/reports/monthly/:id", middleware.AuthMiddleware(), middleware.RequireScope("reports:read"), monthlyHandler.GetMonthlyReport)
+            api.GET("/reports/monthly/:id/pdf", middleware.AuthMiddleware(), middleware.RequireScope("reports:read"), monthlyHandler.GetMonthlyReportPDF)
+            api.POST("/reports/monthly/:id/regenerate", middleware.AuthMiddleware(), middleware.RequireScope("reports:write"), monthlyHandler.RegenerateMonthlyReport)
+            api.POST("/reports/monthly/:id/finalize", middleware.AuthMiddleware(), middleware.RequireScope("reports:write"), monthlyHandler.FinalizeMonthlyReport)
         } else {
             api.POST("/reports/monthly", monthlyHandler.GenerateMonthlyReportFromBody)
             api.GET("/services/:id/reports/monthly", monthlyHandler.ListMonthlyReports)
             api.GET("/reports/monthly/:id", monthlyHandler.GetMonthlyReport)
+            api.GET("/reports/monthly/:id/pdf", monthlyHandler.GetMonthlyReportPDF)
+            api.POST("/reports/monthly/:id/regenerate", monthlyHandler.RegenerateMonthlyReport)
+            api.POST("/reports/monthly/:id/finalize", monthlyHandler.FinalizeMonthlyReport)
         }
-        // Removed backend monthly PDF generation; client-side PDF rendering used
 		if useAuth {
-			api.POST("/services/:id/reports/monthly", middleware.AuthMiddleware(), monthlyHandler.GenerateMonthlyReport)
+			api.POST("/services/:id/reports/monthly", middleware.AuthMiddleware(), middleware.RequireScope("reports:write"), monthlyHandler.GenerateMonthlyReport)
 		} else {
 			api.POST("/services/:id/reports/monthly", monthlyHandler.GenerateMonthlyReport)
 		}
 
 		// Scheduler (automation) endpoints
 		schedHandler := handlers.NewSchedulerHandler()
-		api.GET("/automation/tasks", schedHandler.List)
-		api.POST("/automation/tasks/:name/run", schedHandler.Run)
+		if useAuth {
+			api.GET("/automation/tasks", middleware.AuthMiddleware(), middleware.RequireScope("automation:read"), schedHandler.List)
+			api.GET("/automation/tasks/:name/runs", middleware.AuthMiddleware(), middleware.RequireScope("automation:read"), schedHandler.Runs)
+			api.POST("/automation/tasks/:name/run", middleware.AuthMiddleware(), middleware.RequireScope("automation:write"), schedHandler.Run)
+			api.POST("/automation/tasks/:name/pause", middleware.AuthMiddleware(), middleware.RequireScope("automation:write"), schedHandler.Pause)
+			api.POST("/automation/tasks/:name/resume", middleware.AuthMiddleware(), middleware.RequireScope("automation:write"), schedHandler.Resume)
+			api.PUT("/automation/tasks/:name", middleware.AuthMiddleware(), middleware.RequireScope("automation:write"), schedHandler.Update)
+		} else {
+			api.GET("/automation/tasks", schedHandler.List)
+			api.GET("/automation/tasks/:name/runs", schedHandler.Runs)
+			api.POST("/automation/tasks/:name/run", schedHandler.Run)
+			api.POST("/automation/tasks/:name/pause", schedHandler.Pause)
+			api.POST("/automation/tasks/:name/resume", schedHandler.Resume)
+			api.PUT("/automation/tasks/:name", schedHandler.Update)
+		}
 
 		// Heartbeats endpoints
 		hbHandler := handlers.NewHeartbeatHandler(services.NewHeartbeatService(database.DB))
 		api.GET("/heartbeats", hbHandler.List)
 		if useAuth {
-			api.POST("/heartbeats", middleware.AuthMiddleware(), hbHandler.Create)
-			api.PUT("/heartbeats/:id", middleware.AuthMiddleware(), hbHandler.Update)
-			api.DELETE("/heartbeats/:id", middleware.AuthMiddleware(), hbHandler.Delete)
+			api.POST("/heartbeats", middleware.AuthMiddleware(), middleware.RequireScope("heartbeats:write"), hbHandler.Create)
+			api.PUT("/heartbeats/:id", middleware.AuthMiddleware(), middleware.RequireScope("heartbeats:write"), hbHandler.Update)
+			api.DELETE("/heartbeats/:id", middleware.AuthMiddleware(), middleware.RequireScope("heartbeats:write"), hbHandler.Delete)
 			api.POST("/heartbeats/:id/ping", hbHandler.Ping) // allow pings unauth for agents
-			api.POST("/heartbeats/:id/rotate-token", middleware.AuthMiddleware(), hbHandler.RotateToken)
+			api.POST("/heartbeats/:id/rotate-token", middleware.AuthMiddleware(), middleware.RequireScope("heartbeats:write"), hbHandler.RotateToken)
 		} else {
 			api.POST("/heartbeats", hbHandler.Create)
 			api.PUT("/heartbeats/:id", hbHandler.Update)
@@ -204,15 +424,20 @@ func NewServer(clientHandler *handlers.ClientHandler, offerHandler *handlers.Off
         sloHandler := handlers.NewSLOHandler(services.NewSLOService(database.DB))
 		api.GET("/slos", sloHandler.List)
 		if useAuth {
-			api.POST("/slos", middleware.AuthMiddleware(), sloHandler.Create)
-			api.PUT("/slos/:id", middleware.AuthMiddleware(), sloHandler.Update)
-			api.DELETE("/slos/:id", middleware.AuthMiddleware(), sloHandler.Delete)
+			api.POST("/slos", middleware.AuthMiddleware(), middleware.RequireScope("slos:write"), sloHandler.Create)
+			api.PUT("/slos/:id", middleware.AuthMiddleware(), middleware.RequireScope("slos:write"), sloHandler.Update)
+			api.DELETE("/slos/:id", middleware.AuthMiddleware(), middleware.RequireScope("slos:write"), sloHandler.Delete)
 		} else {
 			api.POST("/slos", sloHandler.Create)
 			api.PUT("/slos/:id", sloHandler.Update)
 			api.DELETE("/slos/:id", sloHandler.Delete)
 		}
 
+        // Audit log of create/update/delete actions
+        auditHandler := handlers.NewAuditHandler(services.NewAuditService(database.DB))
+        api.GET("/audit", middleware.AuthMiddleware(), middleware.RequireScope("audit:read"), auditHandler.List)
+        api.GET("/audit/export", middleware.AuthMiddleware(), middleware.RequireScope("audit:read"), auditHandler.Export)
+
         // DNS (Cloudflare) dry-run plan endpoint
         dnsHandler := handlers.NewDNSHandler()
         api.POST("/dns/cloudflare/plan", dnsHandler.PlanCloudflare)
@@ -220,11 +445,11 @@ func NewServer(clientHandler *handlers.ClientHandler, offerHandler *handlers.Off
         // Report templates (client-side HTML/JSON templates)
         tplHandler := handlers.NewTemplateHandler(database.DB)
         if useAuth {
-            api.GET("/templates", middleware.AuthMiddleware(), tplHandler.Get)      // query by kind (default monthly)
-            api.GET("/templates/list", middleware.AuthMiddleware(), tplHandler.List)
-            api.GET("/templates/:id", middleware.AuthMiddleware(), tplHandler.Get)  // get by id
-            api.POST("/templates", middleware.AuthMiddleware(), tplHandler.Upsert)
-            api.DELETE("/templates/:id", middleware.AuthMiddleware(), tplHandler.Delete)
+            api.GET("/templates", middleware.AuthMiddleware(), middleware.RequireScope("templates:read"), tplHandler.Get)      // query by kind (default monthly)
+            api.GET("/templates/list", middleware.AuthMiddleware(), middleware.RequireScope("templates:read"), tplHandler.List)
+            api.GET("/templates/:id", middleware.AuthMiddleware(), middleware.RequireScope("templates:read"), tplHandler.Get)  // get by id
+            api.POST("/templates", middleware.AuthMiddleware(), middleware.RequireScope("templates:write"), tplHandler.Upsert)
+            api.DELETE("/templates/:id", middleware.AuthMiddleware(), middleware.RequireScope("templates:write"), tplHandler.Delete)
         } else {
             api.GET("/templates", tplHandler.Get)
             api.GET("/templates/list", tplHandler.List)
@@ -25,3 +25,15 @@ func TestNewServerRoutes(t *testing.T) {
 		t.Fatalf("health should return 200, got %d", w.Code)
 	}
 }
+
+func TestOfferHistoryRoutesRequireAuth(t *testing.T) {
+	t.Setenv("DEV_ALLOW_UNAUTH", "")
+	r := NewServer(&handlers.ClientHandler{}, &handlers.OfferHandler{}, &handlers.ServiceHandler{}, &handlers.MonthlyReportHandler{})
+	for _, path := range []string{"/api/offers/1/history", "/api/offers/1/revisions", "/api/offers/1/revisions/1", "/api/offers/1/diff"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != 401 {
+			t.Errorf("%s: expected 401 without credentials, got %d", path, w.Code)
+		}
+	}
+}
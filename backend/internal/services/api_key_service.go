@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/gorm"
+)
+
+// APIKeyPrefix marks a bearer credential as an API key rather than a JWT.
+const APIKeyPrefix = "fms_"
+
+// APIKeyScopes lists every scope an API key may be granted.
+var APIKeyScopes = []string{
+	"clients:write",
+	"services:read",
+	"services:write",
+	"checks:run",
+	"offers:read",
+	"offers:write",
+	"invoices:read",
+	"invoices:write",
+	"subscriptions:read",
+	"subscriptions:write",
+	"alerts:write",
+	"reports:read",
+	"reports:write",
+	"heartbeats:write",
+	"slos:write",
+	"templates:read",
+	"templates:write",
+	"audit:read",
+	"automation:read",
+	"automation:write",
+}
+
+var (
+	ErrAPIKeyInvalid = errors.New("invalid api key")
+	ErrAPIKeyExpired = errors.New("api key expired")
+	ErrAPIKeyRevoked = errors.New("api key revoked")
+)
+
+type APIKeyService struct{ db *gorm.DB }
+
+func NewAPIKeyService(db *gorm.DB) *APIKeyService { return &APIKeyService{db: db} }
+
+// Create issues a new key for the user and returns the stored record together with
+// the plaintext key. The plaintext is not recoverable afterwards.
+func (s *APIKeyService) Create(ctx context.Context, userID int, name string, scopes []string, expiresAt *time.Time) (*models.APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if userID <= 0 {
+		return nil, "", errors.New("user required")
+	}
+	if name == "" {
+		return nil, "", errors.New("name required")
+	}
+	normalized, err := normalizeScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, "", errors.New("expires_at must be in the future")
+	}
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, "", err
+	}
+	raw := APIKeyPrefix + hex.EncodeToString(buf)
+	key := models.APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    raw[:len(APIKeyPrefix)+8],
+		KeyHash:   hashAPIKey(raw),
+		Scopes:    strings.Join(normalized, ","),
+		ExpiresAt: expiresAt,
+	}
+	if err := s.db.WithContext(ctx).Create(&key).Error; err != nil {
+		return nil, "", err
+	}
+	return &key, raw, nil
+}
+
+// ListForUser returns all keys owned by the user, newest first.
+func (s *APIKeyService) ListForUser(ctx context.Context, userID int) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	if err := s.db.WithContext(ctx).Where("user_id = ?", userID).Order("id DESC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Revoke disables a key owned by the user.
+func (s *APIKeyService) Revoke(ctx context.Context, userID, id int) error {
+	now := time.Now()
+	res := s.db.WithContext(ctx).Model(&models.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", &now)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Authenticate resolves a plaintext key to its record, rejecting revoked or expired
+// keys, and stamps LastUsedAt.
+func (s *APIKeyService) Authenticate(ctx context.Context, raw string) (*models.APIKey, error) {
+	if !strings.HasPrefix(raw, APIKeyPrefix) {
+		return nil, ErrAPIKeyInvalid
+	}
+	var key models.APIKey
+	if err := s.db.WithContext(ctx).Where("key_hash = ?", hashAPIKey(raw)).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyInvalid
+		}
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return nil, ErrAPIKeyRevoked
+	}
+	now := time.Now()
+	if key.ExpiresAt != nil && now.After(*key.ExpiresAt) {
+		return nil, ErrAPIKeyExpired
+	}
+	_ = s.db.WithContext(ctx).Model(&models.APIKey{}).Where("id = ?", key.ID).Update("last_used_at", now).Error
+	key.LastUsedAt = &now
+	return &key, nil
+}
+
+// AuthenticateAPIKey adapts Authenticate to the middleware's authenticator interface.
+func (s *APIKeyService) AuthenticateAPIKey(ctx context.Context, raw string) (int, []string, error) {
+	key, err := s.Authenticate(ctx, raw)
+	if err != nil {
+		return 0, nil, err
+	}
+	return key.UserID, SplitScopes(key.Scopes), nil
+}
+
+// SplitScopes parses the stored comma-separated scope list.
+func SplitScopes(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func normalizeScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, errors.New("at least one scope required")
+	}
+	known := make(map[string]bool, len(APIKeyScopes))
+	for _, sc := range APIKeyScopes {
+		known[sc] = true
+	}
+	seen := make(map[string]bool, len(scopes))
+	out := make([]string, 0, len(scopes))
+	for _, sc := range scopes {
+		sc = strings.TrimSpace(sc)
+		if !known[sc] {
+			return nil, fmt.Errorf("unknown scope %q", sc)
+		}
+		if !seen[sc] {
+			seen[sc] = true
+			out = append(out, sc)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func hashAPIKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
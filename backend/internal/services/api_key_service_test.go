@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestAPIKeyService_CreateAndAuthenticate(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	if err := db.AutoMigrate(&models.APIKey{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	svc := NewAPIKeyService(db)
+	ctx := context.Background()
+
+	key, raw, err := svc.Create(ctx, 7, "ci", []string{"checks:run", "services:read", "checks:run"}, nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if !strings.HasPrefix(raw, APIKeyPrefix) || !strings.HasPrefix(raw, key.Prefix) {
+		t.Fatalf("unexpected key format %q (prefix %q)", raw, key.Prefix)
+	}
+	if key.KeyHash == raw || key.KeyHash == "" {
+		t.Fatalf("expected hashed storage")
+	}
+	if key.Scopes != "checks:run,services:read" {
+		t.Fatalf("expected normalized scopes, got %q", key.Scopes)
+	}
+
+	uid, scopes, err := svc.AuthenticateAPIKey(ctx, raw)
+	if err != nil {
+		t.Fatalf("authenticate: %v", err)
+	}
+	if uid != 7 || len(scopes) != 2 {
+		t.Fatalf("unexpected auth result uid=%d scopes=%v", uid, scopes)
+	}
+	var got models.APIKey
+	db.First(&got, key.ID)
+	if got.LastUsedAt == nil {
+		t.Fatalf("expected last_used_at stamped")
+	}
+
+	if _, err := svc.Authenticate(ctx, raw+"x"); !errors.Is(err, ErrAPIKeyInvalid) {
+		t.Fatalf("expected invalid key error, got %v", err)
+	}
+
+	// Revoked keys are rejected; revoking someone else's key is not found
+	if err := svc.Revoke(ctx, 8, key.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected not found for foreign revoke, got %v", err)
+	}
+	if err := svc.Revoke(ctx, 7, key.ID); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, raw); !errors.Is(err, ErrAPIKeyRevoked) {
+		t.Fatalf("expected revoked error, got %v", err)
+	}
+}
+
+func TestAPIKeyService_ValidationAndExpiry(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	if err := db.AutoMigrate(&models.APIKey{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	svc := NewAPIKeyService(db)
+	ctx := context.Background()
+
+	if _, _, err := svc.Create(ctx, 1, "bad", []string{"everything"}, nil); err == nil {
+		t.Fatalf("expected unknown scope error")
+	}
+	if _, _, err := svc.Create(ctx, 1, "none", nil, nil); err == nil {
+		t.Fatalf("expected missing scope error")
+	}
+	past := time.Now().Add(-time.Hour)
+	if _, _, err := svc.Create(ctx, 1, "past", []string{"offers:write"}, &past); err == nil {
+		t.Fatalf("expected expiry in the past to be rejected")
+	}
+
+	future := time.Now().Add(time.Hour)
+	key, raw, err := svc.Create(ctx, 1, "short", []string{"offers:write"}, &future)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	db.Model(key).Update("expires_at", time.Now().Add(-time.Minute))
+	if _, err := svc.Authenticate(ctx, raw); !errors.Is(err, ErrAPIKeyExpired) {
+		t.Fatalf("expected expired error, got %v", err)
+	}
+}
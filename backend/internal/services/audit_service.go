@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/gorm"
+)
+
+// AuditEntry is the input to AuditService.Record. Before and After are any
+// JSON-serializable snapshots (typically model structs); either may be nil.
+type AuditEntry struct {
+	ActorID    int
+	AuthMethod string
+	IP         string
+	Action     string
+	EntityType string
+	EntityID   int
+	Before     interface{}
+	After      interface{}
+}
+
+// AuditFilter narrows audit log queries. Zero values are ignored.
+type AuditFilter struct {
+	EntityType string
+	EntityID   int
+	ActorID    int
+	Action     string
+	From       time.Time
+	To         time.Time
+	Limit      int
+	Offset     int
+}
+
+// auditRedactedFields are never stored in snapshots.
+var auditRedactedFields = map[string]bool{"token": true, "password": true, "password_hash": true}
+
+// auditIgnoredFields are excluded from change diffs because they change on every save.
+var auditIgnoredFields = map[string]bool{"updated_at": true}
+
+type AuditService struct{ db *gorm.DB }
+
+func NewAuditService(db *gorm.DB) *AuditService { return &AuditService{db: db} }
+
+// Record appends an audit entry with before/after snapshots and a field-level diff.
+func (s *AuditService) Record(ctx context.Context, e AuditEntry) error {
+	before := auditSnapshot(e.Before)
+	after := auditSnapshot(e.After)
+	row := models.AuditLog{
+		ActorID:    e.ActorID,
+		AuthMethod: e.AuthMethod,
+		IP:         e.IP,
+		Action:     e.Action,
+		EntityType: e.EntityType,
+		EntityID:   e.EntityID,
+		Before:     marshalSnapshot(before),
+		After:      marshalSnapshot(after),
+		Changes:    marshalSnapshot(auditDiff(before, after)),
+	}
+	return s.db.WithContext(ctx).Create(&row).Error
+}
+
+// List returns audit entries matching the filter, newest first, with the total count.
+func (s *AuditService) List(ctx context.Context, f AuditFilter) ([]models.AuditLog, int64, error) {
+	q := s.filtered(ctx, f)
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if f.Limit > 0 {
+		q = q.Limit(f.Limit)
+	}
+	if f.Offset > 0 {
+		q = q.Offset(f.Offset)
+	}
+	var items []models.AuditLog
+	if err := q.Order("id DESC").Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// ExportCSV writes all entries matching the filter (ignoring limit/offset) as CSV.
+func (s *AuditService) ExportCSV(ctx context.Context, f AuditFilter, w io.Writer) error {
+	var items []models.AuditLog
+	if err := s.filtered(ctx, f).Order("id ASC").Find(&items).Error; err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"id", "created_at", "actor_id", "auth_method", "ip", "action", "entity_type", "entity_id", "changes", "before", "after"})
+	for _, it := range items {
+		_ = cw.Write([]string{
+			strconv.Itoa(it.ID),
+			it.CreatedAt.UTC().Format(time.RFC3339),
+			strconv.Itoa(it.ActorID),
+			it.AuthMethod,
+			it.IP,
+			it.Action,
+			it.EntityType,
+			strconv.Itoa(it.EntityID),
+			it.Changes,
+			it.Before,
+			it.After,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (s *AuditService) filtered(ctx context.Context, f AuditFilter) *gorm.DB {
+	q := s.db.WithContext(ctx).Model(&models.AuditLog{})
+	if f.EntityType != "" {
+		q = q.Where("entity_type = ?", f.EntityType)
+	}
+	if f.EntityID > 0 {
+		q = q.Where("entity_id = ?", f.EntityID)
+	}
+	if f.ActorID > 0 {
+		q = q.Where("actor_id = ?", f.ActorID)
+	}
+	if f.Action != "" {
+		q = q.Where("action = ?", f.Action)
+	}
+	if !f.From.IsZero() {
+		q = q.Where("created_at >= ?", f.From)
+	}
+	if !f.To.IsZero() {
+		q = q.Where("created_at < ?", f.To)
+	}
+	return q
+}
+
+// auditSnapshot converts v to a generic JSON object with sensitive fields removed.
+func auditSnapshot(v interface{}) map[string]interface{} {
+	if v == nil {
+		return nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil
+	}
+	for k := range m {
+		if auditRedactedFields[k] {
+			m[k] = "[redacted]"
+		}
+	}
+	return m
+}
+
+// auditDiff returns field -> {from, to} for every field that differs.
+func auditDiff(before, after map[string]interface{}) map[string]interface{} {
+	return fieldDiff(before, after, auditIgnoredFields)
+}
+
+// fieldDiff returns field -> {from, to} for every field outside ignored that
+// differs between before and after, or nil when nothing changed.
+func fieldDiff(before, after map[string]interface{}, ignored map[string]bool) map[string]interface{} {
+	keys := make(map[string]bool, len(before)+len(after))
+	for k := range before {
+		keys[k] = true
+	}
+	for k := range after {
+		keys[k] = true
+	}
+	names := make([]string, 0, len(keys))
+	for k := range keys {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	out := make(map[string]interface{})
+	for _, k := range names {
+		if ignored[k] {
+			continue
+		}
+		from, to := before[k], after[k]
+		if !reflect.DeepEqual(from, to) {
+			out[k] = map[string]interface{}{"from": from, "to": to}
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func marshalSnapshot(m map[string]interface{}) string {
+	if m == nil {
+		return ""
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestAuditService_RecordDiffAndFilter(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	if err := db.AutoMigrate(&models.AuditLog{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	svc := NewAuditService(db)
+	ctx := context.Background()
+
+	before := &models.Offer{ID: 3, Subject: "Old", TotalPrice: 100, Status: "draft"}
+	after := &models.Offer{ID: 3, Subject: "Old", TotalPrice: 250, Status: "draft"}
+	if err := svc.Record(ctx, AuditEntry{ActorID: 9, IP: "10.1.1.1", Action: "update", EntityType: "offer", EntityID: 3, Before: before, After: after}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	hb := &models.HeartbeatJob{ID: 1, Name: "cron", Token: "secret-token"}
+	if err := svc.Record(ctx, AuditEntry{ActorID: 2, Action: "create", EntityType: "heartbeat", EntityID: 1, After: hb}); err != nil {
+		t.Fatalf("record create: %v", err)
+	}
+
+	items, total, err := svc.List(ctx, AuditFilter{EntityType: "offer"})
+	if err != nil || total != 1 || len(items) != 1 {
+		t.Fatalf("expected one offer entry, got %d (%v)", total, err)
+	}
+	var changes map[string]map[string]interface{}
+	if err := json.Unmarshal([]byte(items[0].Changes), &changes); err != nil {
+		t.Fatalf("changes json: %v", err)
+	}
+	if len(changes) != 1 || changes["total_price"]["from"] != float64(100) || changes["total_price"]["to"] != float64(250) {
+		t.Fatalf("unexpected diff %v", changes)
+	}
+
+	hbItems, _, _ := svc.List(ctx, AuditFilter{ActorID: 2})
+	if len(hbItems) != 1 || bytes.Contains([]byte(hbItems[0].After), []byte("secret-token")) {
+		t.Fatalf("expected heartbeat token redacted, got %+v", hbItems)
+	}
+
+	// Append-only
+	if err := db.Model(&items[0]).Update("action", "tampered").Error; err == nil {
+		t.Fatalf("expected update to be rejected")
+	}
+	if err := db.Delete(&items[0]).Error; err == nil {
+		t.Fatalf("expected delete to be rejected")
+	}
+
+	var buf bytes.Buffer
+	if err := svc.ExportCSV(ctx, AuditFilter{}, &buf); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if len(rows) != 3 || rows[0][0] != "id" || rows[1][5] != "update" {
+		t.Fatalf("unexpected csv rows %v", rows)
+	}
+}
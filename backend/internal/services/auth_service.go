@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"log"
 	"os"
 	"time"
 
@@ -17,11 +18,24 @@ import (
 )
 
 type AuthService struct {
-	db *gorm.DB
+	db    *gorm.DB
+	guard *LoginGuard
 }
 
+// ErrInvalidCredentials is returned for unknown emails and wrong passwords alike.
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
 func NewAuthService(db *gorm.DB) *AuthService { return &AuthService{db: db} }
 
+// WithLoginGuard enables brute-force protection and auth event auditing.
+func (s *AuthService) WithLoginGuard(g *LoginGuard) *AuthService {
+	s.guard = g
+	return s
+}
+
+// Guard returns the configured login guard, if any.
+func (s *AuthService) Guard() *LoginGuard { return s.guard }
+
 func (s *AuthService) hashPassword(password string) (string, error) {
 	b, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -57,16 +71,79 @@ func (s *AuthService) Register(ctx context.Context, email, password string) (*mo
 }
 
 func (s *AuthService) Login(ctx context.Context, email, password string) (string, time.Time, error) {
+	return s.LoginFrom(ctx, email, password, "")
+}
+
+// LoginFrom authenticates like Login and, when a LoginGuard is configured, applies
+// per-account and per-IP backoff/lockout and records auth events for the client IP.
+func (s *AuthService) LoginFrom(ctx context.Context, email, password, ip string) (string, time.Time, error) {
+	if s.guard != nil {
+		if err := s.guard.Check(ctx, accountKey(email), ipKey(ip)); err != nil {
+			s.guard.RecordEvent(ctx, s.userIDByEmail(ctx, email), email, ip, "login_throttled", err.Error())
+			return "", time.Time{}, err
+		}
+	}
+	u, err := s.authenticate(ctx, email, password)
+	if s.guard != nil {
+		switch {
+		case errors.Is(err, ErrInvalidCredentials):
+			uid := 0
+			if u != nil {
+				uid = u.ID
+			}
+			s.guard.RecordEvent(ctx, uid, email, ip, "login_failure", "")
+			locked, _ := s.guard.RecordFailure(ctx, accountKey(email), s.guard.cfg.AccountLockThreshold)
+			if ipLocked, _ := s.guard.RecordFailure(ctx, ipKey(ip), s.guard.cfg.IPLockThreshold); ipLocked {
+				s.guard.RecordEvent(ctx, 0, "", ip, "ip_locked", "")
+			}
+			if locked {
+				s.guard.RecordEvent(ctx, uid, email, ip, "account_locked", "")
+				if u != nil {
+					// Mail is slow; don't hold the failed login's response on it.
+					go func(to string) {
+						if err := s.guard.NotifyLockout(to, ip); err != nil {
+							log.Printf("lockout notice to %s: %v", to, err)
+						}
+					}(u.Email)
+				}
+			}
+		case err == nil:
+			_ = s.guard.RecordSuccess(ctx, accountKey(email))
+			s.guard.RecordEvent(ctx, u.ID, email, ip, "login_success", "")
+		}
+	}
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return s.issueToken(u)
+}
+
+// authenticate verifies credentials. On a wrong password the user is returned
+// alongside ErrInvalidCredentials so callers can attribute the failure.
+func (s *AuthService) authenticate(ctx context.Context, email, password string) (*models.User, error) {
 	var u models.User
 	if err := s.db.WithContext(ctx).Where("email = ?", email).First(&u).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return "", time.Time{}, errors.New("invalid credentials")
+			return nil, ErrInvalidCredentials
 		}
-		return "", time.Time{}, err
+		return nil, err
 	}
 	if !s.checkPassword(u.PasswordHash, password) {
-		return "", time.Time{}, errors.New("invalid credentials")
+		return &u, ErrInvalidCredentials
 	}
+	return &u, nil
+}
+
+// userIDByEmail returns the user's ID or 0 when unknown.
+func (s *AuthService) userIDByEmail(ctx context.Context, email string) int {
+	var u models.User
+	if err := s.db.WithContext(ctx).Select("id").Where("email = ?", email).First(&u).Error; err != nil {
+		return 0
+	}
+	return u.ID
+}
+
+func (s *AuthService) issueToken(u *models.User) (string, time.Time, error) {
 	secret := os.Getenv("JWT_SECRET")
 	if secret == "" {
 		return "", time.Time{}, errors.New("JWT_SECRET not set")
@@ -96,6 +173,33 @@ func (s *AuthService) Login(ctx context.Context, email, password string) (string
 }
 
 func (s *AuthService) RequestPasswordReset(ctx context.Context, email string, ttlMinutes int) (*models.PasswordReset, error) {
+	return s.RequestPasswordResetFrom(ctx, email, ttlMinutes, "")
+}
+
+// RequestPasswordResetFrom is RequestPasswordReset with per-email and per-IP backoff
+// and auditing when a LoginGuard is configured.
+func (s *AuthService) RequestPasswordResetFrom(ctx context.Context, email string, ttlMinutes int, ip string) (*models.PasswordReset, error) {
+	if s.guard != nil {
+		if err := s.guard.Check(ctx, resetKey(email), resetIPKey(ip)); err != nil {
+			s.guard.RecordEvent(ctx, s.userIDByEmail(ctx, email), email, ip, "reset_throttled", err.Error())
+			return nil, err
+		}
+		// Every request counts toward backoff, whether or not the account exists
+		_, _ = s.guard.RecordFailure(ctx, resetKey(email), 0)
+		_, _ = s.guard.RecordFailure(ctx, resetIPKey(ip), 0)
+	}
+	pr, err := s.requestPasswordReset(ctx, email, ttlMinutes)
+	if s.guard != nil && err == nil {
+		uid := 0
+		if pr != nil {
+			uid = pr.UserID
+		}
+		s.guard.RecordEvent(ctx, uid, email, ip, "reset_requested", "")
+	}
+	return pr, err
+}
+
+func (s *AuthService) requestPasswordReset(ctx context.Context, email string, ttlMinutes int) (*models.PasswordReset, error) {
 	var u models.User
 	if err := s.db.WithContext(ctx).Where("email = ?", email).First(&u).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -152,6 +256,11 @@ func (s *AuthService) ResetPassword(ctx context.Context, token, newPassword stri
 	if err := s.db.WithContext(ctx).Model(&pr).Update("used_at", &now).Error; err != nil {
 		return err
 	}
+	if s.guard != nil {
+		// A successful reset lifts any lockout on the account
+		_ = s.guard.RecordSuccess(ctx, accountKey(u.Email))
+		s.guard.RecordEvent(ctx, u.ID, u.Email, "", "reset_completed", "")
+	}
 	return nil
 }
 
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/gorm"
+)
+
+// CatalogFilter narrows the catalog list. Q matches name, SKU or description.
+type CatalogFilter struct {
+	Q               string
+	IncludeInactive bool
+}
+
+// CatalogService maintains the product/service catalog.
+type CatalogService struct {
+	db *gorm.DB
+}
+
+func NewCatalogService(db *gorm.DB) *CatalogService {
+	return &CatalogService{db: db}
+}
+
+// List returns catalog items ordered by name.
+func (s *CatalogService) List(ctx context.Context, f CatalogFilter) ([]models.CatalogItem, error) {
+	q := s.db.WithContext(ctx).Order("name")
+	if !f.IncludeInactive {
+		q = q.Where("active = ?", true)
+	}
+	if term := strings.ToLower(strings.TrimSpace(f.Q)); term != "" {
+		like := "%" + term + "%"
+		q = q.Where("LOWER(name) LIKE ? OR LOWER(sku) LIKE ? OR LOWER(description) LIKE ?", like, like, like)
+	}
+	var items []models.CatalogItem
+	if err := q.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (s *CatalogService) Get(ctx context.Context, id int) (*models.CatalogItem, error) {
+	var item models.CatalogItem
+	if err := s.db.WithContext(ctx).First(&item, id).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// CatalogItemInput creates or updates a catalog item. Active defaults to
+// true on create and is left unchanged on update when omitted.
+type CatalogItemInput struct {
+	SKU         string  `json:"sku"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Unit        string  `json:"unit"`
+	UnitPrice   float64 `json:"unit_price"`
+	Currency    string  `json:"currency"`
+	TaxRate     float64 `json:"tax_rate"`
+	Active      *bool   `json:"active"`
+}
+
+// Create adds a catalog item.
+func (s *CatalogService) Create(ctx context.Context, in CatalogItemInput) (*models.CatalogItem, error) {
+	item := &models.CatalogItem{Active: true}
+	applyCatalogInput(item, in)
+	if err := validateCatalogItem(item); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Create(item).Error; err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// Update replaces the item's fields. Offers already priced from it keep
+// their prices.
+func (s *CatalogService) Update(ctx context.Context, id int, in CatalogItemInput) (*models.CatalogItem, error) {
+	item, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	applyCatalogInput(item, in)
+	if err := validateCatalogItem(item); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Save(item).Error; err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func applyCatalogInput(item *models.CatalogItem, in CatalogItemInput) {
+	item.SKU = in.SKU
+	item.Name = in.Name
+	item.Description = in.Description
+	item.Unit = in.Unit
+	item.UnitPrice = in.UnitPrice
+	item.Currency = in.Currency
+	item.TaxRate = in.TaxRate
+	if in.Active != nil {
+		item.Active = *in.Active
+	}
+}
+
+// Delete removes a catalog item. Offer lines keep their copied values;
+// template lines that relied on it get its current values copied in.
+func (s *CatalogService) Delete(ctx context.Context, id int) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var item models.CatalogItem
+		if err := tx.First(&item, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.OfferTemplateItem{}).Where("catalog_item_id = ?", id).Updates(map[string]interface{}{
+			"name":            gorm.Expr("COALESCE(NULLIF(name, ''), ?)", item.Name),
+			"description":     gorm.Expr("COALESCE(NULLIF(description, ''), ?)", nonEmpty(item.Description, item.Name)),
+			"unit":            gorm.Expr("COALESCE(NULLIF(unit, ''), ?)", item.Unit),
+			"unit_price":      gorm.Expr("CASE WHEN unit_price = 0 THEN ? ELSE unit_price END", item.UnitPrice),
+			"tax_rate":        gorm.Expr("CASE WHEN tax_rate = 0 THEN ? ELSE tax_rate END", item.TaxRate),
+			"catalog_item_id": nil,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.CatalogItem{}, id).Error
+	})
+}
+
+func validateCatalogItem(item *models.CatalogItem) error {
+	errs := FieldErrors{}
+	item.Name = strings.TrimSpace(item.Name)
+	item.SKU = strings.TrimSpace(item.SKU)
+	item.Unit = strings.TrimSpace(item.Unit)
+	item.Currency = nonEmpty(normalizeCurrency(item.Currency), BaseCurrency())
+	if item.Name == "" {
+		errs["name"] = "is required"
+	}
+	if item.UnitPrice < 0 {
+		errs["unit_price"] = "must not be negative"
+	}
+	if item.TaxRate < 0 || item.TaxRate > 100 {
+		errs["tax_rate"] = "must be between 0 and 100"
+	}
+	if !validCurrency(item.Currency) {
+		errs["currency"] = "must be a 3-letter ISO code"
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// fillFromCatalog completes offer lines that reference a catalog item: empty
+// name, description and unit, a unit price or tax rate that is zero and was
+// not sent explicitly, and a zero quantity (as 1) are taken from the catalog.
+// A catalog price in a currency
+// other than the offer's must be overridden with an explicit unit_price.
+func fillFromCatalog(db *gorm.DB, items []models.OfferItem, currency string) error {
+	ids := []int{}
+	for _, it := range items {
+		if it.CatalogItemID != nil {
+			ids = append(ids, *it.CatalogItemID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	var rows []models.CatalogItem
+	if err := db.Session(&gorm.Session{NewDB: true}).Where("id IN ?", ids).Find(&rows).Error; err != nil {
+		return err
+	}
+	catalog := map[int]models.CatalogItem{}
+	for _, r := range rows {
+		catalog[r.ID] = r
+	}
+	currency = nonEmpty(normalizeCurrency(currency), BaseCurrency())
+	errs := FieldErrors{}
+	for i := range items {
+		it := &items[i]
+		if it.CatalogItemID == nil {
+			continue
+		}
+		field := fmt.Sprintf("items[%d].catalog_item_id", i)
+		c, ok := catalog[*it.CatalogItemID]
+		if !ok {
+			errs[field] = "unknown catalog item"
+			continue
+		}
+		it.Name = nonEmpty(strings.TrimSpace(it.Name), c.Name)
+		it.Description = nonEmpty(strings.TrimSpace(it.Description), c.Description, c.Name)
+		it.Unit = nonEmpty(strings.TrimSpace(it.Unit), c.Unit)
+		if it.Qty == 0 {
+			it.Qty = 1
+		}
+		if it.TaxRate == 0 && !it.TaxRateSet {
+			it.TaxRate = c.TaxRate
+		}
+		if it.UnitPrice == 0 && !it.UnitPriceSet {
+			if c.Currency != currency {
+				errs[field] = fmt.Sprintf("priced in %s but the offer is in %s; set unit_price", c.Currency, currency)
+				continue
+			}
+			it.UnitPrice = c.UnitPrice
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,358 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/gorm"
+)
+
+// DefaultClientReportTemplate is used when the report owner has not saved a
+// "client_monthly" ReportTemplate of their own.
+const DefaultClientReportTemplate = `<h1>Laporan Bulanan - {{.Month}}</h1>
+<table>
+  <tr><th width="22%">Client</th><td>{{.Client.Name}}</td></tr>
+  <tr><th>Periode</th><td>{{date .Start}} – {{date .End}}</td></tr>
+  <tr><th>Jumlah layanan</th><td>{{.Report.ServiceCount}}</td></tr>
+</table>
+<h2>Ringkasan</h2>
+{{range lines .Summary}}<p>{{.}}</p>{{end}}
+<table>
+  <thead><tr><th>Layanan</th><th width="14%" align="right">Uptime</th><th width="14%" align="right">Respon</th><th width="12%" align="right">Insiden</th><th width="14%">SSL</th><th width="14%">Domain</th></tr></thead>
+  {{range .Report.Services}}<tr><td>{{.Domain}}</td><td>{{if .MonthlyReportID}}{{printf "%.2f" .UptimePercent}}%{{else}}-{{end}}</td><td>{{if .MonthlyReportID}}{{.AvgResponseMs}} ms{{else}}-{{end}}</td><td>{{.Incidents}}</td><td>{{.SSLStatus}}</td><td>{{.DomainStatus}}</td></tr>{{end}}
+</table>
+{{range .Report.Services}}
+<h3>{{.Domain}}</h3>
+{{if .SLOs}}<table>
+  <thead><tr><th>SLO</th><th width="20%" align="right">Target</th><th width="20%" align="right">Tercapai</th><th width="15%">Status</th></tr></thead>
+  {{range .SLOs}}<tr><td>{{.Objective}}</td><td>{{printf "%.2f" .Target}}</td><td>{{printf "%.2f" .Achieved}}</td><td>{{if .Met}}Tercapai{{else}}Tidak tercapai{{end}}</td></tr>{{end}}
+</table>{{end}}
+{{if .Heartbeats}}<table>
+  <thead><tr><th>Heartbeat</th><th width="20%">Status</th><th width="30%">Terakhir</th></tr></thead>
+  {{range .Heartbeats}}<tr><td>{{.Name}}</td><td>{{.Status}}</td><td>{{if .LastHeartbeatAt}}{{date .LastHeartbeatAt}}{{else}}-{{end}}</td></tr>{{end}}
+</table>{{end}}
+{{if .Activities}}<table>
+  <thead><tr><th width="22%">Tanggal</th><th>Aktivitas</th></tr></thead>
+  {{range .Activities}}<tr><td>{{.Date}}</td><td>{{.Description}}</td></tr>{{end}}
+</table>{{end}}
+{{end}}`
+
+// ClientReportData is what a client_monthly template is executed against.
+type ClientReportData struct {
+	Report *models.ClientMonthlyReport
+	Client *models.Client
+	Month  string
+	Start  time.Time
+	End    time.Time
+	// Summary is the stored summary, or one generated from the totals.
+	Summary string
+}
+
+// expiryWarning is how close to the end of the month an SSL certificate or
+// domain registration counts as expiring.
+const expiryWarning = 30 * 24 * time.Hour
+
+// ClientReportService builds consolidated monthly reports over all of a
+// client's services.
+type ClientReportService struct {
+	db      *gorm.DB
+	reports *MonthlyReportService
+}
+
+func NewClientReportService(db *gorm.DB) *ClientReportService {
+	return &ClientReportService{db: db, reports: NewMonthlyReportService(db)}
+}
+
+// ErrClientReportOwned is returned when generating a client month whose
+// report belongs to another user.
+var ErrClientReportOwned = errors.New("the client's report for this month belongs to another user")
+
+// Generate returns the client's report for month, building it (and any
+// missing per-service reports) when none exists. userID owns a new report and
+// adopts an unowned one; another user's report is ErrClientReportOwned.
+func (s *ClientReportService) Generate(ctx context.Context, clientID int, month time.Time, userID int) (*models.ClientMonthlyReport, error) {
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	existing, err := s.find(ctx, clientID, start)
+	if err == nil {
+		return s.claim(ctx, existing, userID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).First(&models.Client{}, clientID).Error; err != nil {
+		return nil, err
+	}
+	report := &models.ClientMonthlyReport{ClientID: clientID, ReportMonth: start, UserID: userID}
+	if err := s.build(ctx, report, false); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Create(report).Error; err != nil {
+		// A concurrent request may have created it first.
+		if existing, ferr := s.find(ctx, clientID, start); ferr == nil {
+			return s.claim(ctx, existing, userID)
+		}
+		return nil, err
+	}
+	return report, nil
+}
+
+// claim returns report when userID owns it, assigning it to userID first when
+// it has no owner.
+func (s *ClientReportService) claim(ctx context.Context, report *models.ClientMonthlyReport, userID int) (*models.ClientMonthlyReport, error) {
+	if report.UserID == userID {
+		return report, nil
+	}
+	if report.UserID == 0 && userID > 0 {
+		res := s.db.WithContext(ctx).Model(&models.ClientMonthlyReport{}).Where("id = ? AND user_id = 0", report.ID).Update("user_id", userID)
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		if res.RowsAffected == 1 {
+			report.UserID = userID
+			return report, nil
+		}
+	}
+	return nil, ErrClientReportOwned
+}
+
+// find returns the client's report for the month starting at start.
+func (s *ClientReportService) find(ctx context.Context, clientID int, start time.Time) (*models.ClientMonthlyReport, error) {
+	var report models.ClientMonthlyReport
+	err := s.db.WithContext(ctx).
+		Where("client_id = ? AND report_month >= ? AND report_month < ?", clientID, start, start.AddDate(0, 1, 0)).
+		First(&report).Error
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// Regenerate rebuilds report id from current data, keeping its summary.
+// Unfinalized per-service reports are regenerated first.
+func (s *ClientReportService) Regenerate(ctx context.Context, id int) (*models.ClientMonthlyReport, error) {
+	var report models.ClientMonthlyReport
+	if err := s.db.WithContext(ctx).First(&report, id).Error; err != nil {
+		return nil, err
+	}
+	if report.FinalizedAt != nil {
+		return nil, ErrReportFinalized
+	}
+	if err := s.build(ctx, &report, true); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Save(&report).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// UpdateSummary replaces the summary of an unfinalized report.
+func (s *ClientReportService) UpdateSummary(ctx context.Context, id int, summary string) (*models.ClientMonthlyReport, error) {
+	var report models.ClientMonthlyReport
+	if err := s.db.WithContext(ctx).First(&report, id).Error; err != nil {
+		return nil, err
+	}
+	if report.FinalizedAt != nil {
+		return nil, ErrReportFinalized
+	}
+	report.Summary = strings.TrimSpace(summary)
+	if err := s.db.WithContext(ctx).Model(&report).Update("summary", report.Summary).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// Finalize locks report id against regeneration and edits.
+func (s *ClientReportService) Finalize(ctx context.Context, id int, now time.Time) (*models.ClientMonthlyReport, error) {
+	var report models.ClientMonthlyReport
+	if err := s.db.WithContext(ctx).First(&report, id).Error; err != nil {
+		return nil, err
+	}
+	if report.FinalizedAt != nil {
+		return &report, nil
+	}
+	report.FinalizedAt = &now
+	if err := s.db.WithContext(ctx).Model(&report).Update("finalized_at", now).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// GetForUser returns report id owned by userID (0 for unowned reports).
+func (s *ClientReportService) GetForUser(ctx context.Context, userID, id int) (*models.ClientMonthlyReport, error) {
+	var report models.ClientMonthlyReport
+	if err := s.db.WithContext(ctx).Where("user_id = ?", userID).First(&report, id).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// ListForUser returns a client's reports owned by userID, newest first.
+func (s *ClientReportService) ListForUser(ctx context.Context, userID, clientID int) ([]models.ClientMonthlyReport, error) {
+	var reports []models.ClientMonthlyReport
+	if err := s.db.WithContext(ctx).Where("client_id = ? AND user_id = ?", clientID, userID).
+		Order("report_month DESC").Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// build fills report's per-service sections and totals for its month. With
+// refresh, existing unfinalized service reports are recomputed.
+func (s *ClientReportService) build(ctx context.Context, report *models.ClientMonthlyReport, refresh bool) error {
+	db := s.db.WithContext(ctx)
+	start := report.ReportMonth
+	end := start.AddDate(0, 1, 0)
+
+	var svcs []models.Service
+	if err := db.Where("client_id = ?", report.ClientID).Order("id").Find(&svcs).Error; err != nil {
+		return err
+	}
+	report.Services = make([]models.ClientServiceSummary, 0, len(svcs))
+	report.ServiceCount = len(svcs)
+	report.AvgUptimePercent, report.TotalDowntime, report.Incidents, report.AlertsResolved = 0, 0, 0, 0
+	report.MaintenanceHours, report.SLOsMet, report.SLOsTotal = 0, 0, 0
+	withData := 0
+
+	for _, svc := range svcs {
+		line := models.ClientServiceSummary{
+			ServiceID:    svc.ID,
+			Domain:       svc.Domain,
+			ServiceType:  svc.ServiceType,
+			SSLStatus:    expiryStatus(svc.SSLExpiry, end),
+			DomainStatus: expiryStatus(svc.DomainExpiry, end),
+		}
+		if !svc.SSLExpiry.IsZero() {
+			t := svc.SSLExpiry
+			line.SSLExpiry = &t
+		}
+		if !svc.DomainExpiry.IsZero() {
+			t := svc.DomainExpiry
+			line.DomainExpiry = &t
+		}
+
+		monthly, err := s.reports.GenerateMonthlyReport(ctx, svc.ID, start)
+		if err != nil && !errors.Is(err, ErrNoReportData) {
+			return err
+		}
+		if refresh && monthly != nil && monthly.FinalizedAt == nil {
+			if monthly, err = s.reports.RegenerateMonthlyReport(ctx, monthly.ID); err != nil {
+				return err
+			}
+		}
+		if monthly != nil && monthly.UserID == 0 && report.UserID > 0 {
+			if err := s.reports.SetMonthlyReportUser(ctx, monthly.ID, report.UserID); err != nil {
+				return err
+			}
+		}
+		if monthly != nil {
+			line.MonthlyReportID = &monthly.ID
+			line.UptimePercent = monthly.AvgUptimePercent
+			line.AvgResponseMs = monthly.AvgResponseMs
+			line.TotalDowntime = monthly.TotalDowntime
+			line.AlertsResolved = monthly.AlertsResolved
+			line.MaintenanceHours = monthly.MaintenanceHours
+			line.Activities = parseReportActivities(monthly.Activities)
+			withData++
+			report.AvgUptimePercent += monthly.AvgUptimePercent
+		}
+
+		var incidents int64
+		if err := db.Model(&models.Alert{}).
+			Where("service_id = ? AND created_at >= ? AND created_at < ? AND alert_type IN (?)", svc.ID, start, end, []string{"uptime", "heartbeat_missed"}).
+			Count(&incidents).Error; err != nil {
+			return err
+		}
+		line.Incidents = int(incidents)
+
+		var hbs []models.HeartbeatJob
+		if err := db.Where("service_id = ?", svc.ID).Order("id").Find(&hbs).Error; err != nil {
+			return err
+		}
+		for _, hb := range hbs {
+			line.Heartbeats = append(line.Heartbeats, models.HeartbeatSummary{Name: hb.Name, Status: heartbeatStatus(hb, end), LastHeartbeatAt: hb.LastHeartbeatAt})
+		}
+
+		if monthly != nil {
+			var slos []models.SLOTarget
+			if err := db.Where("service_id = ? AND is_paused = ?", svc.ID, false).Order("id").Find(&slos).Error; err != nil {
+				return err
+			}
+			for _, slo := range slos {
+				a := models.SLOAttainment{Objective: slo.Objective, Target: slo.Target}
+				if slo.Objective == "latency" {
+					a.Achieved = float64(monthly.AvgResponseMs)
+					a.Met = a.Achieved <= slo.Target
+				} else {
+					a.Achieved = monthly.AvgUptimePercent
+					a.Met = a.Achieved >= slo.Target
+				}
+				line.SLOs = append(line.SLOs, a)
+				report.SLOsTotal++
+				if a.Met {
+					report.SLOsMet++
+				}
+			}
+		}
+
+		report.TotalDowntime += line.TotalDowntime
+		report.Incidents += line.Incidents
+		report.AlertsResolved += line.AlertsResolved
+		report.MaintenanceHours += line.MaintenanceHours
+		report.Services = append(report.Services, line)
+	}
+	if withData > 0 {
+		report.AvgUptimePercent /= float64(withData)
+	}
+	return nil
+}
+
+// expiryStatus classifies an SSL or domain expiry as of at.
+func expiryStatus(expiry, at time.Time) string {
+	switch {
+	case expiry.IsZero():
+		return "unknown"
+	case !expiry.After(at):
+		return "expired"
+	case expiry.Sub(at) <= expiryWarning:
+		return "expiring"
+	}
+	return "ok"
+}
+
+// heartbeatStatus classifies a heartbeat job as of at.
+func heartbeatStatus(hb models.HeartbeatJob, at time.Time) string {
+	switch {
+	case hb.IsPaused:
+		return "paused"
+	case hb.LastHeartbeatAt == nil:
+		return "never"
+	case at.Sub(*hb.LastHeartbeatAt) > time.Duration(hb.ExpectedIntervalSeconds+hb.GraceSeconds)*time.Second:
+		return "late"
+	}
+	return "healthy"
+}
+
+// RenderPDF renders report through templateID, or the owner's latest
+// "client_monthly" template, falling back to DefaultClientReportTemplate.
+func (s *ClientReportService) RenderPDF(ctx context.Context, report *models.ClientMonthlyReport, templateID int) ([]byte, error) {
+	db := s.db.WithContext(ctx)
+	data := &ClientReportData{Report: report, Client: &models.Client{}}
+	if err := db.First(data.Client, report.ClientID).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	data.Start = report.ReportMonth
+	data.End = data.Start.AddDate(0, 1, -1)
+	data.Month = fmt.Sprintf("%s %d", monthNameID(data.Start), data.Start.Year())
+	data.Summary = strings.TrimSpace(report.Summary)
+	if data.Summary == "" {
+		data.Summary = fmt.Sprintf("Selama bulan %s, %d layanan %s menunjukkan rata-rata uptime %.2f%% dengan total downtime %d menit. Tercatat %d insiden dan %d alert terselesaikan. %d dari %d SLO tercapai. Waktu maintenance %.2f jam.",
+			data.Month, report.ServiceCount, nonEmpty(data.Client.Name, fmt.Sprintf("client %d", report.ClientID)),
+			report.AvgUptimePercent, report.TotalDowntime, report.Incidents, report.AlertsResolved, report.SLOsMet, report.SLOsTotal, report.MaintenanceHours)
+	}
+	return renderStoredTemplate(db, "client_monthly", report.UserID, templateID, DefaultClientReportTemplate, data)
+}
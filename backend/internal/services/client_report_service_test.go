@@ -0,0 +1,153 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestClientReportConsolidatesServices(t *testing.T) {
+	t.Setenv("PDF_TEMPLATE_PATH", "")
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Client{}, &models.Service{}, &models.MonthlyReport{}, &models.TimeEntry{}, &models.DailyReport{}, &models.UptimeLog{},
+		&models.Alert{}, &models.HeartbeatJob{}, &models.SLOTarget{}, &models.ReportTemplate{}, &models.ClientMonthlyReport{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	ctx := context.Background()
+	feb := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	client := models.Client{Name: "Acme"}
+	db.Create(&client)
+	shop := models.Service{ClientID: client.ID, Domain: "shop.acme.test", ServiceType: "website",
+		SSLExpiry: time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC), DomainExpiry: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	blog := models.Service{ClientID: client.ID, Domain: "blog.acme.test", ServiceType: "website", SSLExpiry: time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC)}
+	db.Create(&shop)
+	db.Create(&blog)
+	db.Create(&models.Service{ClientID: client.ID + 1, Domain: "other.test", ServiceType: "website"})
+
+	for i, status := range []string{"up", "up", "up", "down"} {
+		db.Create(&models.UptimeLog{ServiceID: shop.ID, Status: status, ResponseTime: 100 * (i + 1), CheckedAt: feb.AddDate(0, 0, i+1)})
+	}
+	db.Create(&models.MonthlyReport{ServiceID: shop.ID, ReportMonth: feb, AvgUptimePercent: 75, AvgResponseMs: 250, TotalDowntime: 1,
+		MaintenanceHours: 2.5, Activities: `[{"date":"2025-02-04","description":"Plugin update"}]`})
+	db.Create(&models.Alert{ServiceID: shop.ID, AlertType: "uptime", Level: "critical", Title: "down", CreatedAt: feb.AddDate(0, 0, 4)})
+	db.Create(&models.Alert{ServiceID: shop.ID, AlertType: "heartbeat_missed", Level: "warning", Title: "missed", CreatedAt: feb.AddDate(0, 0, 5)})
+	db.Create(&models.Alert{ServiceID: shop.ID, AlertType: "uptime", Level: "critical", Title: "january", CreatedAt: feb.AddDate(0, 0, -2)})
+	last := feb.AddDate(0, 1, 0).Add(-time.Minute)
+	db.Create(&models.HeartbeatJob{ServiceID: shop.ID, Name: "backup", ExpectedIntervalSeconds: 3600, GraceSeconds: 60, LastHeartbeatAt: &last})
+	db.Create(&models.HeartbeatJob{ServiceID: shop.ID, Name: "cron", ExpectedIntervalSeconds: 60})
+	db.Create(&models.SLOTarget{ServiceID: shop.ID, Objective: "availability", Target: 99.9})
+	db.Create(&models.SLOTarget{ServiceID: shop.ID, Objective: "latency", Target: 300})
+
+	s := NewClientReportService(db)
+	report, err := s.Generate(ctx, client.ID, feb.AddDate(0, 0, 10), 4)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if report.ServiceCount != 2 || len(report.Services) != 2 || report.Incidents != 2 || report.MaintenanceHours != 2.5 {
+		t.Fatalf("totals: %+v", report)
+	}
+	if report.AvgUptimePercent != 75 || report.SLOsMet != 1 || report.SLOsTotal != 2 {
+		t.Fatalf("uptime %v, SLOs %d/%d", report.AvgUptimePercent, report.SLOsMet, report.SLOsTotal)
+	}
+	line := report.Services[0]
+	if line.Domain != "shop.acme.test" || line.SSLStatus != "expiring" || line.DomainStatus != "ok" || len(line.Activities) != 1 {
+		t.Fatalf("shop section: %+v", line)
+	}
+	if len(line.Heartbeats) != 2 || line.Heartbeats[0].Status != "healthy" || line.Heartbeats[1].Status != "never" {
+		t.Fatalf("heartbeats: %+v", line.Heartbeats)
+	}
+	if b := report.Services[1]; b.MonthlyReportID != nil || b.SSLStatus != "expired" || b.DomainStatus != "unknown" || len(b.SLOs) != 0 {
+		t.Fatalf("blog section: %+v", b)
+	}
+
+	again, err := s.Generate(ctx, client.ID, feb, 4)
+	if err != nil || again.ID != report.ID || len(again.Services) != 2 {
+		t.Fatalf("expected the stored report back: %+v %v", again, err)
+	}
+	if _, err := s.UpdateSummary(ctx, report.ID, "All good"); err != nil {
+		t.Fatalf("summary: %v", err)
+	}
+	db.Create(&models.UptimeLog{ServiceID: blog.ID, Status: "up", ResponseTime: 80, CheckedAt: feb.AddDate(0, 0, 3)})
+	regen, err := s.Regenerate(ctx, report.ID)
+	if err != nil {
+		t.Fatalf("regenerate: %v", err)
+	}
+	if regen.Summary != "All good" || regen.Services[1].MonthlyReportID == nil || regen.Services[0].UptimePercent != 75 {
+		t.Fatalf("regenerated report: %+v", regen)
+	}
+	var owner int
+	db.Model(&models.MonthlyReport{}).Where("service_id = ?", blog.ID).Select("user_id").Scan(&owner)
+	if owner != 4 {
+		t.Fatalf("expected the generated service report to be owned by the client report's user, got %d", owner)
+	}
+
+	pdf, err := s.RenderPDF(ctx, regen, 0)
+	if err != nil {
+		t.Fatalf("pdf: %v", err)
+	}
+	all := strings.Join(pdfStreams(t, pdf), "")
+	for _, want := range []string{"(Laporan Bulanan - Februari 2025)", "(shop.acme.test)", "(All good)", "(Tidak tercapai)", "(backup)"} {
+		if !strings.Contains(all, want) {
+			t.Errorf("pdf missing %s", want)
+		}
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF")) {
+		t.Fatal("not a PDF")
+	}
+
+	if _, err := s.Finalize(ctx, report.ID, time.Now()); err != nil {
+		t.Fatalf("finalize: %v", err)
+	}
+	if _, err := s.Regenerate(ctx, report.ID); !errors.Is(err, ErrReportFinalized) {
+		t.Fatalf("expected finalized report to be locked, got %v", err)
+	}
+	if _, err := s.GetForUser(ctx, 5, report.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected another user's report to be hidden, got %v", err)
+	}
+}
+
+func TestClientReportGenerateReturnsConcurrentRow(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Client{}, &models.Service{}, &models.MonthlyReport{}, &models.ClientMonthlyReport{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	ctx := context.Background()
+	feb := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	client := models.Client{Name: "Acme"}
+	db.Create(&client)
+	// Another request inserts the month's report between our lookup and insert.
+	winner := models.ClientMonthlyReport{ClientID: client.ID, ReportMonth: feb, UserID: 4, Summary: "first"}
+	db.Callback().Query().After("gorm:query").Register("test:race", func(tx *gorm.DB) {
+		if _, ok := tx.Statement.Dest.(*models.ClientMonthlyReport); ok && winner.ID == 0 {
+			db.Create(&winner)
+		}
+	})
+	s := NewClientReportService(db)
+	got, err := s.Generate(ctx, client.ID, feb, 4)
+	if err != nil || got.ID != winner.ID || got.Summary != "first" {
+		t.Fatalf("expected the concurrent report back: %+v %v", got, err)
+	}
+
+	// Another user's month is not handed out; an unowned one is adopted.
+	if _, err := s.Generate(ctx, client.ID, feb, 5); !errors.Is(err, ErrClientReportOwned) {
+		t.Fatalf("expected another user's report to be refused, got %v", err)
+	}
+	mar := models.ClientMonthlyReport{ClientID: client.ID, ReportMonth: feb.AddDate(0, 1, 0)}
+	db.Create(&mar)
+	if got, err := s.Generate(ctx, client.ID, mar.ReportMonth, 5); err != nil || got.ID != mar.ID || got.UserID != 5 {
+		t.Fatalf("expected the unowned report to be adopted: %+v %v", got, err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"freelance-monitor-system/internal/models"
 	"gorm.io/gorm"
+	"net/mail"
 	"strings"
 )
 
@@ -84,25 +85,56 @@ func (s *ClientService) GetClientByID(ctx context.Context, id int) (*models.Clie
 }
 
 func (s *ClientService) CreateClient(client *models.Client) error {
+	cc, err := normalizeReportCC(client.ReportCC)
+	if err != nil {
+		return err
+	}
+	client.ReportCC = cc
 	return s.db.Create(client).Error
 }
 
 func (s *ClientService) UpdateClient(id int, updates *models.Client) (*models.Client, error) {
+	cc, err := normalizeReportCC(updates.ReportCC)
+	if err != nil {
+		return nil, err
+	}
 	var client models.Client
 	if err := s.db.First(&client, id).Error; err != nil {
 		return nil, err
 	}
 	client.Name = updates.Name
+	client.Code = updates.Code
 	client.ContactPerson = updates.ContactPerson
 	client.Email = updates.Email
 	client.Phone = updates.Phone
 	client.Address = updates.Address
+	client.ReportOptIn = updates.ReportOptIn
+	client.ReportCC = cc
 	if err := s.db.Save(&client).Error; err != nil {
 		return nil, err
 	}
 	return &client, nil
 }
 
+// normalizeReportCC validates the report CC list (comma or semicolon
+// separated) and returns it as bare addresses joined by ", ", so nothing
+// but addresses reaches the Cc header.
+func normalizeReportCC(raw string) (string, error) {
+	parts := reportCCList(raw)
+	if len(parts) == 0 {
+		return "", nil
+	}
+	list, err := mail.ParseAddressList(strings.Join(parts, ", "))
+	if err != nil || strings.ContainsAny(raw, "\r\n") {
+		return "", FieldErrors{"report_cc": "must be a comma-separated list of email addresses"}
+	}
+	out := make([]string, len(list))
+	for i, a := range list {
+		out[i] = a.Address
+	}
+	return strings.Join(out, ", "), nil
+}
+
 func (s *ClientService) DeleteClient(id int) error {
 	res := s.db.Delete(&models.Client{}, id)
 	if res.Error != nil {
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"freelance-monitor-system/internal/models"
@@ -57,3 +58,31 @@ func TestClientServiceCRUD(t *testing.T) {
 		t.Fatalf("expected 0 clients, got %d", len(all))
 	}
 }
+
+func TestClientReportCCValidated(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Client{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	svc := NewClientService(db)
+
+	c := &models.Client{Name: "Acme", ReportCC: "Boss <boss@acme.test>; , cto@acme.test"}
+	if err := svc.CreateClient(c); err != nil {
+		t.Fatal(err)
+	}
+	if c.ReportCC != "boss@acme.test, cto@acme.test" {
+		t.Fatalf("expected normalized addresses, got %q", c.ReportCC)
+	}
+	for _, bad := range []string{"not-an-address", "ops@acme.test\r\nBcc: spy@evil.test"} {
+		var fe FieldErrors
+		if _, err := svc.UpdateClient(c.ID, &models.Client{Name: "Acme", ReportCC: bad}); !errors.As(err, &fe) || fe["report_cc"] == "" {
+			t.Fatalf("expected %q to be rejected, got %v", bad, err)
+		}
+		if err := svc.CreateClient(&models.Client{Name: "Other", ReportCC: bad}); !errors.As(err, &fe) {
+			t.Fatalf("expected %q to be rejected on create, got %v", bad, err)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrNoExchangeRate is returned when no rate is on file for a currency on or
+// before the requested date.
+var ErrNoExchangeRate = errors.New("no exchange rate")
+
+// ExchangeRateInput sets the rate of one currency from a date.
+type ExchangeRateInput struct {
+	Currency string  `json:"currency"`
+	Date     string  `json:"date"` // YYYY-MM-DD
+	Rate     float64 `json:"rate"` // base currency per unit
+}
+
+// ExchangeRateService maintains the rate table used to convert offers and
+// invoices to the base currency.
+type ExchangeRateService struct {
+	db *gorm.DB
+}
+
+func NewExchangeRateService(db *gorm.DB) *ExchangeRateService {
+	return &ExchangeRateService{db: db}
+}
+
+// List returns rates, newest first, optionally for one currency.
+func (s *ExchangeRateService) List(ctx context.Context, currency string) ([]models.ExchangeRate, error) {
+	q := s.db.WithContext(ctx).Order("date DESC, currency")
+	if currency = normalizeCurrency(currency); currency != "" {
+		q = q.Where("currency = ?", currency)
+	}
+	var rows []models.ExchangeRate
+	if err := q.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Set stores a manual rate, replacing any rate for the same currency and day.
+func (s *ExchangeRateService) Set(ctx context.Context, in ExchangeRateInput) (*models.ExchangeRate, error) {
+	rate, fe := parseExchangeRate(in, "")
+	if fe != nil {
+		return nil, fe
+	}
+	rate.Source = "manual"
+	if err := upsertExchangeRates(s.db.WithContext(ctx), []models.ExchangeRate{*rate}); err != nil {
+		return nil, err
+	}
+	var out models.ExchangeRate
+	if err := s.db.WithContext(ctx).Where("currency = ? AND date = ?", rate.Currency, rate.Date).First(&out).Error; err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Delete removes a rate.
+func (s *ExchangeRateService) Delete(ctx context.Context, id int) error {
+	res := s.db.WithContext(ctx).Delete(&models.ExchangeRate{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Import loads rates from CSV with a currency,date,rate header. Either every
+// row is valid and stored, or nothing is and the errors are returned per line.
+func (s *ExchangeRateService) Import(ctx context.Context, r io.Reader) (int, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return 0, FieldErrors{"file": "expected a CSV header: currency,date,rate"}
+	}
+	col := map[string]int{}
+	for i, h := range header {
+		col[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, h := range []string{"currency", "date", "rate"} {
+		if _, ok := col[h]; !ok {
+			return 0, FieldErrors{"file": "missing column " + h}
+		}
+	}
+	var rows []models.ExchangeRate
+	fe := FieldErrors{}
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, FieldErrors{"file": err.Error()}
+		}
+		in := ExchangeRateInput{Currency: csvField(rec, col["currency"]), Date: csvField(rec, col["date"])}
+		if v := csvField(rec, col["rate"]); v != "" {
+			if in.Rate, err = strconv.ParseFloat(v, 64); err != nil {
+				fe[fmt.Sprintf("line %d.rate", line)] = "must be a number"
+				continue
+			}
+		}
+		rate, errs := parseExchangeRate(in, fmt.Sprintf("line %d.", line))
+		for k, v := range errs {
+			fe[k] = v
+		}
+		if rate != nil {
+			rate.Source = "import"
+			rows = append(rows, *rate)
+		}
+	}
+	if len(fe) > 0 {
+		return 0, fe
+	}
+	if len(rows) == 0 {
+		return 0, FieldErrors{"file": "no rates found"}
+	}
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return upsertExchangeRates(tx, rows)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+// RateOn returns the rate of currency in effect at at: 1 for the base
+// currency, otherwise the latest rate dated on or before at.
+func (s *ExchangeRateService) RateOn(ctx context.Context, currency string, at time.Time) (float64, time.Time, error) {
+	return exchangeRateOn(s.db.WithContext(ctx), currency, at)
+}
+
+func exchangeRateOn(db *gorm.DB, currency string, at time.Time) (float64, time.Time, error) {
+	currency = normalizeCurrency(currency)
+	day := rateDay(at)
+	if currency == "" || currency == BaseCurrency() {
+		return 1, day, nil
+	}
+	var rate models.ExchangeRate
+	err := db.Session(&gorm.Session{NewDB: true}).
+		Where("currency = ? AND date <= ?", currency, day).Order("date DESC").First(&rate).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, time.Time{}, fmt.Errorf("%w for %s on or before %s", ErrNoExchangeRate, currency, day.Format("2006-01-02"))
+	}
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return rate.Rate, rate.Date, nil
+}
+
+func parseExchangeRate(in ExchangeRateInput, prefix string) (*models.ExchangeRate, FieldErrors) {
+	fe := FieldErrors{}
+	currency := normalizeCurrency(in.Currency)
+	switch {
+	case !validCurrency(currency):
+		fe[prefix+"currency"] = "must be a 3-letter ISO code"
+	case currency == BaseCurrency():
+		fe[prefix+"currency"] = "is the base currency"
+	}
+	date, err := time.Parse("2006-01-02", strings.TrimSpace(in.Date))
+	if err != nil {
+		fe[prefix+"date"] = "must be YYYY-MM-DD"
+	}
+	if in.Rate <= 0 {
+		fe[prefix+"rate"] = "must be greater than 0"
+	}
+	if len(fe) > 0 {
+		return nil, fe
+	}
+	return &models.ExchangeRate{Currency: currency, Date: rateDay(date), Rate: in.Rate}, nil
+}
+
+func upsertExchangeRates(db *gorm.DB, rows []models.ExchangeRate) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "currency"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "source", "updated_at"}),
+	}).Create(&rows).Error
+}
+
+// rateDay truncates t to its calendar day in UTC, the key rates are stored by.
+func rateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func csvField(rec []string, i int) string {
+	if i < len(rec) {
+		return strings.TrimSpace(rec[i])
+	}
+	return ""
+}
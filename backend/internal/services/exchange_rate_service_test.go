@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+)
+
+func TestExchangeRatesSnapshotAndRevenue(t *testing.T) {
+	db := newInvoiceTestDB(t)
+	if err := db.AutoMigrate(&models.ExchangeRate{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	ctx := context.Background()
+	rates := NewExchangeRateService(db)
+
+	csv := "currency,date,rate\nusd,2026-01-01,15000\nUSD,2026-02-01,16000\nSGD,2026-01-01,11500\n"
+	if n, err := rates.Import(ctx, strings.NewReader(csv)); err != nil || n != 3 {
+		t.Fatalf("import: n=%d err=%v", n, err)
+	}
+	var fe FieldErrors
+	if _, err := rates.Import(ctx, strings.NewReader("currency,date,rate\nUS,2026-01-01,x\n")); !errors.As(err, &fe) || fe["line 2.rate"] == "" {
+		t.Fatalf("expected per-line errors, got %v", err)
+	}
+	if rate, _, err := rates.RateOn(ctx, "USD", time.Date(2026, 1, 20, 15, 0, 0, 0, time.UTC)); err != nil || rate != 15000 {
+		t.Fatalf("RateOn: rate=%v err=%v", rate, err)
+	}
+
+	offers := NewOfferService(db)
+	client := models.Client{Name: "Foreign Co"}
+	db.Create(&client)
+	offer := &models.Offer{ClientID: client.ID, Subject: "Retainer", Currency: "usd",
+		Items: models.OfferItems{{Description: "Support", Qty: 1, UnitPrice: 100}}}
+	if err := offers.CreateOffer(offer); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if offer.Currency != "USD" {
+		t.Fatalf("currency not normalized: %q", offer.Currency)
+	}
+	if _, err := offers.TransitionOffer(offer.ID, models.OfferSent, nil, "", time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)); !errors.As(err, &fe) {
+		t.Fatalf("expected sending without a rate to fail, got %v", err)
+	}
+	sent, err := offers.TransitionOffer(offer.ID, models.OfferSent, nil, "", time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC))
+	if err != nil || sent.ExchangeRate != 15000 {
+		t.Fatalf("send: rate=%v err=%v", sent.ExchangeRate, err)
+	}
+	accepted, err := offers.ApproveOffer(offer.ID, time.Date(2026, 2, 3, 0, 0, 0, 0, time.UTC))
+	if err != nil || accepted.ExchangeRate != 16000 {
+		t.Fatalf("accept: rate=%v err=%v", accepted.ExchangeRate, err)
+	}
+
+	invoices := NewInvoiceService(db)
+	inv, err := invoices.CreateFromOffer(ctx, offer.ID, InvoiceOptions{IssueDate: time.Date(2026, 2, 4, 0, 0, 0, 0, time.UTC)})
+	if err != nil || inv.ExchangeRate != 16000 || inv.Currency != "USD" {
+		t.Fatalf("invoice: %+v err=%v", inv, err)
+	}
+	if _, err := invoices.AddPayment(ctx, inv.ID, PaymentInput{Amount: 40, PaidAt: time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC)}); err != nil {
+		t.Fatalf("payment: %v", err)
+	}
+	local := models.Invoice{ClientID: client.ID, IssueDate: time.Date(2026, 2, 5, 0, 0, 0, 0, time.UTC), DueDate: time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC), Currency: "IDR", Total: 500000}
+	if err := invoices.create(ctx, &local); err != nil {
+		t.Fatalf("local invoice: %v", err)
+	}
+	euro := models.Invoice{ClientID: client.ID, IssueDate: time.Date(2026, 2, 6, 0, 0, 0, 0, time.UTC), DueDate: time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC), Currency: "EUR", Total: 10}
+	if err := invoices.create(ctx, &euro); err != nil {
+		t.Fatalf("eur invoice: %v", err)
+	}
+
+	report, err := NewRevenueService(db).Revenue(ctx, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("revenue: %v", err)
+	}
+	if report.BaseCurrency != "IDR" || report.Accepted != 1600000 || report.Invoiced != 2100000 || report.Paid != 640000 {
+		t.Fatalf("unexpected totals: %+v", report)
+	}
+	if len(report.Currencies) != 3 || len(report.Unconverted) != 1 || report.Unconverted[0] != "EUR" {
+		t.Fatalf("unexpected breakdown: %+v", report)
+	}
+}
+
+func TestFormatMoney(t *testing.T) {
+	cases := []struct {
+		amount   float64
+		currency string
+		want     string
+	}{
+		{1234567.5, "IDR", "Rp 1.234.567,5"},
+		{1234567.5, "", "Rp 1.234.567,5"},
+		{1234.5, "USD", "$1,234.50"},
+		{99, "SGD", "S$99.00"},
+		{1234.5, "EUR", "EUR 1.234,50"},
+		{1234.6, "JPY", "JPY 1,235"},
+		{-12.3, "CHF", "-CHF 12.30"},
+	}
+	for _, c := range cases {
+		if got := formatMoney(c.amount, c.currency); got != c.want {
+			t.Errorf("formatMoney(%v, %q) = %q, want %q", c.amount, c.currency, got, c.want)
+		}
+	}
+}
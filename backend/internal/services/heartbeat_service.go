@@ -34,6 +34,14 @@ func (s *HeartbeatService) CountByService(ctx context.Context, serviceID int) (i
 	return total, err
 }
 
+func (s *HeartbeatService) Get(ctx context.Context, id int) (*models.HeartbeatJob, error) {
+	var hb models.HeartbeatJob
+	if err := s.db.WithContext(ctx).First(&hb, id).Error; err != nil {
+		return nil, err
+	}
+	return &hb, nil
+}
+
 func (s *HeartbeatService) Create(ctx context.Context, hb *models.HeartbeatJob) error {
 	if hb.Token == "" {
 		hb.Token = generateToken()
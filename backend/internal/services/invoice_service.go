@@ -0,0 +1,559 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOfferNotAccepted = errors.New("offer must be accepted before invoicing")
+	ErrInvoiceExists    = errors.New("offer already has an invoice")
+	ErrInvoiceState     = errors.New("invalid invoice status for this action")
+	ErrInvalidPayment   = errors.New("payment amount must be positive and not exceed the balance")
+)
+
+// InvoiceTaxInput describes a tax line applied to the subtotal.
+type InvoiceTaxInput struct {
+	Name string  `json:"name"`
+	Rate float64 `json:"rate"` // percent
+}
+
+// InvoiceOptions override defaults when generating an invoice.
+type InvoiceOptions struct {
+	IssueDate time.Time
+	DueDate   *time.Time // derived from the offer's payment terms when nil
+	Taxes     []InvoiceTaxInput
+	Notes     string
+}
+
+// InvoiceFilter narrows invoice listings. Zero values are ignored.
+type InvoiceFilter struct {
+	Status         string
+	ClientID       int
+	OfferID        int
+	SubscriptionID int
+	Limit          int
+	Offset         int
+}
+
+// PaymentInput records a payment against an invoice.
+type PaymentInput struct {
+	Amount    float64   `json:"amount"`
+	PaidAt    time.Time `json:"paid_at"`
+	Method    string    `json:"method"`
+	Reference string    `json:"reference"`
+	Note      string    `json:"note"`
+}
+
+type InvoiceService struct {
+	db     *gorm.DB
+	mailer *Mailer
+}
+
+func NewInvoiceService(db *gorm.DB) *InvoiceService {
+	return &InvoiceService{db: db, mailer: NewMailer()}
+}
+
+// CreateFromOffer generates a draft invoice from an accepted offer, copying its
+// line items and, unless opts.Taxes is set, their taxes. An offer can have at
+// most one invoice that is not void; the idx_invoice_offer_active index keeps
+// concurrent requests from creating a second one.
+func (s *InvoiceService) CreateFromOffer(ctx context.Context, offerID int, opts InvoiceOptions) (*models.Invoice, error) {
+	var offer models.Offer
+	if err := s.db.WithContext(ctx).Preload("Items", orderByPosition).First(&offer, offerID).Error; err != nil {
+		return nil, err
+	}
+	if offer.Status != models.OfferAccepted {
+		return nil, ErrOfferNotAccepted
+	}
+	exists, err := s.offerInvoiced(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrInvoiceExists
+	}
+	items := invoiceItemsFromOffer(&offer)
+	issue := opts.IssueDate
+	if issue.IsZero() {
+		issue = time.Now()
+	}
+	due := issue.AddDate(0, 0, DueDaysFromTerms(offer.PaymentTerms))
+	if opts.DueDate != nil {
+		due = *opts.DueDate
+	}
+	if due.Before(issue) {
+		return nil, errors.New("due_date must not be before issue_date")
+	}
+	inv := &models.Invoice{
+		OfferID:      &offer.ID,
+		ClientID:     offer.ClientID,
+		Status:       models.InvoiceDraft,
+		IssueDate:    issue,
+		DueDate:      due,
+		Currency:     offer.Currency,
+		ExchangeRate: offer.ExchangeRate, // as accepted
+		PaymentTerms: offer.PaymentTerms,
+		Notes:        opts.Notes,
+		Items:        items,
+	}
+	if err := applyInvoiceTotals(inv, opts.Taxes); err != nil {
+		return nil, err
+	}
+	if len(opts.Taxes) == 0 {
+		// Taxes priced on the offer lines carry over unless overridden.
+		inv.Taxes = offerItemTaxes(&offer)
+		for _, t := range inv.Taxes {
+			inv.TaxTotal += t.Amount
+		}
+		inv.TaxTotal = roundMoney(inv.TaxTotal)
+		inv.Total = roundMoney(inv.Subtotal + inv.TaxTotal)
+	}
+	if err := s.create(ctx, inv); err != nil {
+		// A concurrent request may have invoiced the offer first.
+		if exists, ferr := s.offerInvoiced(ctx, offerID); ferr == nil && exists {
+			return nil, ErrInvoiceExists
+		}
+		return nil, err
+	}
+	return inv, nil
+}
+
+// offerInvoiced reports whether the offer has an invoice that is not void.
+func (s *InvoiceService) offerInvoiced(ctx context.Context, offerID int) (bool, error) {
+	var n int64
+	err := s.db.WithContext(ctx).Model(&models.Invoice{}).
+		Where("offer_id = ? AND status <> ?", offerID, models.InvoiceVoid).Count(&n).Error
+	return n > 0, err
+}
+
+// create assigns the next INV/YYYY/NNNN number from the issue year's sequence
+// (see nextInvoiceNumber) and inserts the invoice with its lines in the same
+// transaction. Invoices without an exchange rate get the one in effect on the
+// issue date, if any.
+func (s *InvoiceService) create(ctx context.Context, inv *models.Invoice) error {
+	return s.createWith(ctx, inv, nil)
+}
+
+// createWith is create with after run in the same transaction once the
+// invoice is inserted; an error from after rolls the invoice back.
+func (s *InvoiceService) createWith(ctx context.Context, inv *models.Invoice, after func(tx *gorm.DB) error) error {
+	if inv.ExchangeRate == 0 {
+		rate, _, err := exchangeRateOn(s.db.WithContext(ctx), inv.Currency, inv.IssueDate)
+		if err != nil && !errors.Is(err, ErrNoExchangeRate) {
+			return err
+		}
+		inv.ExchangeRate = rate
+	}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		num, err := nextInvoiceNumber(tx, inv.IssueDate)
+		if err != nil {
+			return err
+		}
+		inv.InvoiceNumber = num
+		if err := tx.Create(inv).Error; err != nil {
+			return err
+		}
+		if after != nil {
+			return after(tx)
+		}
+		return nil
+	})
+}
+
+// TimeEntryInvoiceInput selects a client's uninvoiced billable time entries
+// to invoice: EntryIDs when set, otherwise all of them dated From..To
+// (inclusive; open-ended when nil).
+type TimeEntryInvoiceInput struct {
+	ClientID     int               `json:"client_id"`
+	EntryIDs     []int             `json:"entry_ids"`
+	From         *time.Time        `json:"-"`
+	To           *time.Time        `json:"-"`
+	IssueDate    time.Time         `json:"-"`
+	DueDate      *time.Time        `json:"-"` // derived from PaymentTerms when nil
+	PaymentTerms string            `json:"payment_terms"`
+	Taxes        []InvoiceTaxInput `json:"taxes"`
+	Notes        string            `json:"notes"`
+}
+
+// CreateFromTimeEntries generates a draft invoice with one line per billable
+// entry (hours at the entry's rate) and links the entries to it, so each is
+// billed once. The entries must share a currency.
+func (s *InvoiceService) CreateFromTimeEntries(ctx context.Context, in TimeEntryInvoiceInput) (*models.Invoice, error) {
+	var client models.Client
+	if err := s.db.WithContext(ctx).First(&client, in.ClientID).Error; err != nil {
+		return nil, err
+	}
+	q := s.db.WithContext(ctx).Where("client_id = ? AND billable = ? AND invoice_id IS NULL", in.ClientID, true)
+	if len(in.EntryIDs) > 0 {
+		q = q.Where("id IN ?", in.EntryIDs)
+	} else {
+		if in.From != nil {
+			q = q.Where("date >= ?", dayStart(*in.From))
+		}
+		if in.To != nil {
+			q = q.Where("date < ?", dayStart(*in.To).AddDate(0, 0, 1))
+		}
+	}
+	var entries []models.TimeEntry
+	if err := q.Order("date, id").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	if len(in.EntryIDs) > 0 && len(entries) != len(in.EntryIDs) {
+		return nil, FieldErrors{"entry_ids": "must be uninvoiced billable entries of the client"}
+	}
+	if len(entries) == 0 {
+		return nil, FieldErrors{"entries": "no uninvoiced billable time entries"}
+	}
+	currency := entries[0].Currency
+	ids := make([]int, 0, len(entries))
+	items := make([]models.InvoiceItem, 0, len(entries))
+	for i, e := range entries {
+		if e.Currency != currency {
+			return nil, FieldErrors{"currency": "entries must share one currency"}
+		}
+		qty := roundMoney(e.Hours())
+		ids = append(ids, e.ID)
+		items = append(items, models.InvoiceItem{
+			Position:    i + 1,
+			Description: fmt.Sprintf("%s - %s", formatDateID(e.Date), e.Description),
+			Qty:         qty,
+			UnitPrice:   e.Rate,
+			Total:       roundMoney(qty * e.Rate),
+		})
+	}
+	issue := in.IssueDate
+	if issue.IsZero() {
+		issue = time.Now()
+	}
+	due := issue.AddDate(0, 0, DueDaysFromTerms(in.PaymentTerms))
+	if in.DueDate != nil {
+		due = *in.DueDate
+	}
+	if due.Before(issue) {
+		return nil, errors.New("due_date must not be before issue_date")
+	}
+	inv := &models.Invoice{
+		ClientID:     in.ClientID,
+		Status:       models.InvoiceDraft,
+		IssueDate:    issue,
+		DueDate:      due,
+		Currency:     currency,
+		PaymentTerms: in.PaymentTerms,
+		Notes:        in.Notes,
+		Items:        items,
+	}
+	if err := applyInvoiceTotals(inv, in.Taxes); err != nil {
+		return nil, err
+	}
+	err := s.createWith(ctx, inv, func(tx *gorm.DB) error {
+		res := tx.Model(&models.TimeEntry{}).Where("id IN ? AND invoice_id IS NULL", ids).Update("invoice_id", inv.ID)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected != int64(len(ids)) {
+			return ErrTimeEntryInvoiced // a concurrent invoice took some entries
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// nextInvoiceNumber draws the next number from the issue year's sequence
+// (invoice/YYYY), so concurrent creates serialize on the sequence row.
+func nextInvoiceNumber(tx *gorm.DB, issued time.Time) (string, error) {
+	name := fmt.Sprintf("%s/%04d", models.SequenceInvoice, issued.Year())
+	for {
+		seq, format, err := models.NextSequence(tx, models.DefaultWorkspace, name, models.DefaultInvoiceNumberFormat, issued)
+		if err != nil {
+			return "", err
+		}
+		num := models.FormatNumber(format, seq, issued, "")
+		// Skip numbers entered by hand or imported ahead of the sequence.
+		var taken int64
+		if err := tx.Model(&models.Invoice{}).Where("invoice_number = ?", num).Count(&taken).Error; err != nil {
+			return "", err
+		}
+		if taken == 0 {
+			return num, nil
+		}
+	}
+}
+
+// invoiceItemsFromOffer copies the offer's line items at their discounted net
+// price. Offers without items are billed as a single line for the offer total,
+// and lines without a quantity as one unit at their net price.
+func invoiceItemsFromOffer(offer *models.Offer) []models.InvoiceItem {
+	if len(offer.Items) == 0 {
+		return []models.InvoiceItem{{Position: 1, Description: nonEmpty(offer.Subject, "Item"), Qty: 1, UnitPrice: offer.TotalPrice, Total: offer.TotalPrice}}
+	}
+	items := make([]models.InvoiceItem, 0, len(offer.Items))
+	for i, it := range offer.Items {
+		desc := nonEmpty(it.Description, it.Name, "Item")
+		if it.DiscountPercent > 0 {
+			desc = fmt.Sprintf("%s (diskon %s%%)", desc, formatQuantity(it.DiscountPercent))
+		}
+		net := roundMoney(it.Subtotal - it.DiscountAmount)
+		qty := it.Qty
+		if qty <= 0 {
+			qty = 1
+		}
+		items = append(items, models.InvoiceItem{
+			Position:    i + 1,
+			Description: desc,
+			Qty:         qty,
+			UnitPrice:   roundMoney(net / qty),
+			Total:       net,
+		})
+	}
+	return items
+}
+
+// offerItemTaxes groups the offer's per-line taxes by rate into invoice tax lines.
+func offerItemTaxes(offer *models.Offer) []models.InvoiceTax {
+	byRate := map[float64]float64{}
+	var rates []float64
+	for _, it := range offer.Items {
+		if it.TaxRate <= 0 {
+			continue
+		}
+		if _, ok := byRate[it.TaxRate]; !ok {
+			rates = append(rates, it.TaxRate)
+		}
+		byRate[it.TaxRate] += it.TaxAmount
+	}
+	sort.Float64s(rates)
+	taxes := make([]models.InvoiceTax, 0, len(rates))
+	for _, r := range rates {
+		taxes = append(taxes, models.InvoiceTax{Name: "Pajak " + formatQuantity(r) + "%", Rate: r, Amount: roundMoney(byRate[r])})
+	}
+	return taxes
+}
+
+// applyInvoiceTotals computes subtotal, tax lines and total from the items.
+func applyInvoiceTotals(inv *models.Invoice, taxes []InvoiceTaxInput) error {
+	subtotal := 0.0
+	for _, it := range inv.Items {
+		subtotal += it.Total
+	}
+	inv.Subtotal = roundMoney(subtotal)
+	inv.Taxes = inv.Taxes[:0]
+	taxTotal := 0.0
+	for _, t := range taxes {
+		name := strings.TrimSpace(t.Name)
+		if name == "" || t.Rate < 0 || t.Rate > 100 {
+			return fmt.Errorf("invalid tax %q: name required and rate must be between 0 and 100", t.Name)
+		}
+		amount := roundMoney(inv.Subtotal * t.Rate / 100)
+		inv.Taxes = append(inv.Taxes, models.InvoiceTax{Name: name, Rate: t.Rate, Amount: amount})
+		taxTotal += amount
+	}
+	inv.TaxTotal = roundMoney(taxTotal)
+	inv.Total = roundMoney(inv.Subtotal + inv.TaxTotal)
+	return nil
+}
+
+var (
+	dueDaysPattern        = regexp.MustCompile(`(?i)(?:net\s*(\d{1,3}))|(\d{1,3})\s*(?:days?|hari)`)
+	immediateTermsPattern = regexp.MustCompile(`(?i)\b(?:on receipt|cod|tunai)\b`)
+)
+
+// DueDaysFromTerms extracts the payment period from free-text terms such as
+// "Net 30", "14 days" or "30 hari". "Due on receipt"/"COD" mean 0. Unrecognized
+// terms fall back to INVOICE_DEFAULT_DUE_DAYS (default 14).
+func DueDaysFromTerms(terms string) int {
+	if immediateTermsPattern.MatchString(terms) {
+		return 0
+	}
+	if m := dueDaysPattern.FindStringSubmatch(terms); m != nil {
+		for _, g := range m[1:] {
+			if n, err := strconv.Atoi(g); err == nil {
+				return n
+			}
+		}
+	}
+	return envInt("INVOICE_DEFAULT_DUE_DAYS", 14)
+}
+
+func roundMoney(v float64) float64 { return math.Round(v*100) / 100 }
+
+// Get returns an invoice with its items, taxes and payments.
+func (s *InvoiceService) Get(ctx context.Context, id int) (*models.Invoice, error) {
+	var inv models.Invoice
+	err := s.db.WithContext(ctx).
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Preload("Taxes").
+		Preload("Payments", func(db *gorm.DB) *gorm.DB { return db.Order("paid_at") }).
+		First(&inv, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// List returns invoices (without lines) matching the filter, newest first.
+func (s *InvoiceService) List(ctx context.Context, f InvoiceFilter) ([]models.Invoice, int64, error) {
+	q := s.db.WithContext(ctx).Model(&models.Invoice{})
+	if f.Status != "" {
+		q = q.Where("status = ?", f.Status)
+	}
+	if f.ClientID > 0 {
+		q = q.Where("client_id = ?", f.ClientID)
+	}
+	if f.OfferID > 0 {
+		q = q.Where("offer_id = ?", f.OfferID)
+	}
+	if f.SubscriptionID > 0 {
+		q = q.Where("subscription_id = ?", f.SubscriptionID)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if f.Limit > 0 {
+		q = q.Limit(f.Limit)
+	}
+	if f.Offset > 0 {
+		q = q.Offset(f.Offset)
+	}
+	var items []models.Invoice
+	if err := q.Order("id DESC").Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// MarkSent moves a draft invoice to sent. An invoice with nothing to pay
+// becomes paid right away, since no payment could ever settle it.
+func (s *InvoiceService) MarkSent(ctx context.Context, id int, at time.Time) (*models.Invoice, error) {
+	inv, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if inv.Status != models.InvoiceDraft {
+		return nil, ErrInvoiceState
+	}
+	updates := map[string]interface{}{"status": models.InvoiceSent, "sent_at": at}
+	if roundMoney(inv.Total) <= 0 {
+		updates["status"] = models.InvoicePaid
+		updates["paid_at"] = at
+	} else if inv.DueDate.Before(at) {
+		updates["status"] = models.InvoiceOverdue
+	}
+	if err := s.db.WithContext(ctx).Model(inv).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, id)
+}
+
+// Void cancels an invoice that has not been (partially) paid. Time entries
+// billed on it become invoiceable again.
+func (s *InvoiceService) Void(ctx context.Context, id int, at time.Time) (*models.Invoice, error) {
+	inv, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if inv.Status == models.InvoiceVoid || inv.Status == models.InvoicePaid || inv.AmountPaid > 0 {
+		return nil, ErrInvoiceState
+	}
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(inv).Updates(map[string]interface{}{"status": models.InvoiceVoid, "voided_at": at}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.TimeEntry{}).Where("invoice_id = ?", id).Update("invoice_id", nil).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, id)
+}
+
+// AddPayment records a (partial) payment; the invoice becomes paid once the
+// balance reaches zero.
+func (s *InvoiceService) AddPayment(ctx context.Context, id int, p PaymentInput) (*models.Invoice, error) {
+	if p.PaidAt.IsZero() {
+		p.PaidAt = time.Now()
+	}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var inv models.Invoice
+		if err := tx.First(&inv, id).Error; err != nil {
+			return err
+		}
+		if inv.Status == models.InvoiceVoid || inv.Status == models.InvoicePaid {
+			return ErrInvoiceState
+		}
+		amount := roundMoney(p.Amount)
+		if amount <= 0 || amount > roundMoney(inv.Balance()) {
+			return ErrInvalidPayment
+		}
+		pay := models.InvoicePayment{InvoiceID: id, Amount: amount, PaidAt: p.PaidAt, Method: p.Method, Reference: p.Reference, Note: p.Note}
+		if err := tx.Create(&pay).Error; err != nil {
+			return err
+		}
+		updates := map[string]interface{}{"amount_paid": roundMoney(inv.AmountPaid + amount)}
+		if roundMoney(inv.AmountPaid+amount) >= inv.Total {
+			updates["status"] = models.InvoicePaid
+			updates["paid_at"] = p.PaidAt
+		}
+		return tx.Model(&inv).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, id)
+}
+
+// MarkOverdue flags sent invoices whose due date has passed.
+func (s *InvoiceService) MarkOverdue(ctx context.Context, now time.Time) (int, error) {
+	res := s.db.WithContext(ctx).Model(&models.Invoice{}).
+		Where("status = ? AND due_date < ?", models.InvoiceSent, now).
+		Update("status", models.InvoiceOverdue)
+	return int(res.RowsAffected), res.Error
+}
+
+// SendOverdueReminders emails clients about overdue invoices, at most once every
+// INVOICE_REMINDER_EVERY_DAYS (default 7) per invoice.
+func (s *InvoiceService) SendOverdueReminders(ctx context.Context, now time.Time) (int, error) {
+	every := time.Duration(envInt("INVOICE_REMINDER_EVERY_DAYS", 7)) * 24 * time.Hour
+	var due []models.Invoice
+	if err := s.db.WithContext(ctx).
+		Where("status = ? AND (last_reminder_at IS NULL OR last_reminder_at <= ?)", models.InvoiceOverdue, now.Add(-every)).
+		Find(&due).Error; err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, inv := range due {
+		var client models.Client
+		if err := s.db.WithContext(ctx).First(&client, inv.ClientID).Error; err != nil || client.Email == "" {
+			continue
+		}
+		daysLate := int(now.Sub(inv.DueDate).Hours() / 24)
+		subject := fmt.Sprintf("Reminder: Invoice %s telah jatuh tempo", inv.InvoiceNumber)
+		body := fmt.Sprintf("Halo %s,\n\nInvoice %s sebesar %s jatuh tempo pada %s (terlambat %d hari). Sisa tagihan: %s.\nMohon segera lakukan pembayaran.\n\nTerima kasih.\n",
+			nonEmpty(client.ContactPerson, client.Name), inv.InvoiceNumber, formatMoney(inv.Total, inv.Currency), inv.DueDate.Format("2006-01-02"), daysLate, formatMoney(inv.Balance(), inv.Currency))
+		if err := s.mailer.SendGenericEmail(client.Email, subject, body); err != nil {
+			continue
+		}
+		_ = s.db.WithContext(ctx).Model(&models.Invoice{}).Where("id = ?", inv.ID).Update("last_reminder_at", now).Error
+		sent++
+	}
+	return sent, nil
+}
+
+// SetPDFURL stores the generated PDF location.
+func (s *InvoiceService) SetPDFURL(ctx context.Context, id int, url string) error {
+	return s.db.WithContext(ctx).Model(&models.Invoice{}).Where("id = ?", id).Update("pdf_url", url).Error
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newInvoiceTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Client{}, &models.Offer{}, &models.OfferItem{}, &models.NumberSequence{}, &models.OfferStatusChange{}, &models.OfferRevision{}, &models.OfferLink{}, &models.OfferSignature{}, &models.Invoice{}, &models.InvoiceItem{}, &models.InvoiceTax{}, &models.InvoicePayment{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func TestInvoiceService_CreateFromOfferAndPayments(t *testing.T) {
+	db := newInvoiceTestDB(t)
+	ctx := context.Background()
+	client := models.Client{Name: "Acme", Email: "billing@acme.test"}
+	db.Create(&client)
+	offer := models.Offer{ClientID: client.ID, Subject: "Maintenance", Status: "draft",
+		Items:        models.OfferItems{{Description: "Setup", Qty: 2, UnitPrice: 1000}, {Description: "Support", Qty: 1, UnitPrice: 500}},
+		PaymentTerms: "Net 30"}
+	if err := PriceOffer(&offer); err != nil {
+		t.Fatalf("price: %v", err)
+	}
+	db.Create(&offer)
+	svc := NewInvoiceService(db)
+
+	if _, err := svc.CreateFromOffer(ctx, offer.ID, InvoiceOptions{}); !errors.Is(err, ErrOfferNotAccepted) {
+		t.Fatalf("expected ErrOfferNotAccepted, got %v", err)
+	}
+	db.Model(&offer).Update("status", "accepted")
+
+	issue := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	inv, err := svc.CreateFromOffer(ctx, offer.ID, InvoiceOptions{IssueDate: issue, Taxes: []InvoiceTaxInput{{Name: "PPN", Rate: 11}}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if inv.InvoiceNumber != "INV/2025/0001" || inv.Status != models.InvoiceDraft {
+		t.Fatalf("unexpected number/status %s %s", inv.InvoiceNumber, inv.Status)
+	}
+	if len(inv.Items) != 2 || inv.Subtotal != 2500 || inv.TaxTotal != 275 || inv.Total != 2775 {
+		t.Fatalf("unexpected totals %+v", inv)
+	}
+	if !inv.DueDate.Equal(issue.AddDate(0, 0, 30)) {
+		t.Fatalf("expected due date from Net 30, got %v", inv.DueDate)
+	}
+	if _, err := svc.CreateFromOffer(ctx, offer.ID, InvoiceOptions{}); !errors.Is(err, ErrInvoiceExists) {
+		t.Fatalf("expected ErrInvoiceExists, got %v", err)
+	}
+
+	if _, err := svc.MarkSent(ctx, inv.ID, issue); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if _, err := svc.AddPayment(ctx, inv.ID, PaymentInput{Amount: 3000}); !errors.Is(err, ErrInvalidPayment) {
+		t.Fatalf("expected overpayment rejected, got %v", err)
+	}
+	got, err := svc.AddPayment(ctx, inv.ID, PaymentInput{Amount: 1000, Method: "transfer"})
+	if err != nil || got.Status != models.InvoiceSent || got.Balance() != 1775 {
+		t.Fatalf("partial payment: %+v %v", got, err)
+	}
+	if _, err := svc.Void(ctx, inv.ID, time.Now()); !errors.Is(err, ErrInvoiceState) {
+		t.Fatalf("expected void of partially paid invoice rejected, got %v", err)
+	}
+	got, err = svc.AddPayment(ctx, inv.ID, PaymentInput{Amount: 1775})
+	if err != nil || got.Status != models.InvoicePaid || got.PaidAt == nil || len(got.Payments) != 2 {
+		t.Fatalf("final payment: %+v %v", got, err)
+	}
+
+	// A second invoice in the same year continues the sequence.
+	offer2 := models.Offer{ClientID: client.ID, Subject: "Hosting", Status: "accepted", TotalPrice: 100}
+	db.Create(&offer2)
+	inv2, err := svc.CreateFromOffer(ctx, offer2.ID, InvoiceOptions{IssueDate: issue})
+	if err != nil || inv2.InvoiceNumber != "INV/2025/0002" || inv2.Total != 100 || len(inv2.Items) != 1 {
+		t.Fatalf("second invoice: %+v %v", inv2, err)
+	}
+	// Numbers taken outside the sequence are skipped; each year counts from 1.
+	db.Create(&models.Invoice{ClientID: client.ID, InvoiceNumber: "INV/2025/0003", IssueDate: issue, DueDate: issue})
+	for _, want := range []string{"INV/2025/0004", "INV/2026/0001"} {
+		o := models.Offer{ClientID: client.ID, Subject: want, Status: "accepted", TotalPrice: 100}
+		db.Create(&o)
+		date := issue
+		if strings.Contains(want, "2026") {
+			date = issue.AddDate(1, 0, 0)
+		}
+		next, err := svc.CreateFromOffer(ctx, o.ID, InvoiceOptions{IssueDate: date})
+		if err != nil || next.InvoiceNumber != want {
+			t.Fatalf("expected %s: %+v %v", want, next, err)
+		}
+	}
+}
+
+func TestInvoiceService_CreateFromOfferEdgeCases(t *testing.T) {
+	db := newInvoiceTestDB(t)
+	ctx := context.Background()
+	svc := NewInvoiceService(db)
+	issue := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// Lines without a quantity are billed as one unit.
+	offer := models.Offer{ClientID: 1, Subject: "Audit", Status: "accepted", Items: models.OfferItems{{Description: "Audit", Qty: 0, UnitPrice: 500, Subtotal: 500}}}
+	db.Create(&offer)
+	inv, err := svc.CreateFromOffer(ctx, offer.ID, InvoiceOptions{IssueDate: issue})
+	if err != nil || len(inv.Items) != 1 || inv.Items[0].Qty != 1 || inv.Items[0].UnitPrice != 500 || inv.Total != 500 {
+		t.Fatalf("zero-quantity line: %+v %v", inv, err)
+	}
+
+	// Another request invoices the offer between our check and insert.
+	raced := models.Offer{ClientID: 1, Subject: "Raced", Status: "accepted", TotalPrice: 100}
+	db.Create(&raced)
+	winner := models.Invoice{OfferID: &raced.ID, ClientID: 1, InvoiceNumber: "INV/2025/0900", IssueDate: issue, DueDate: issue}
+	db.Callback().Query().After("gorm:query").Register("test:race", func(tx *gorm.DB) {
+		if _, ok := tx.Statement.Dest.(*int64); ok && tx.Statement.Table == "invoices" && winner.ID == 0 {
+			db.Create(&winner)
+		}
+	})
+	if _, err := svc.CreateFromOffer(ctx, raced.ID, InvoiceOptions{IssueDate: issue}); !errors.Is(err, ErrInvoiceExists) {
+		t.Fatalf("expected ErrInvoiceExists for the concurrent invoice, got %v", err)
+	}
+	db.Callback().Query().Remove("test:race")
+
+	// An invoice with nothing to pay is settled when sent.
+	free := models.Offer{ClientID: 1, Subject: "Free", Status: "accepted"}
+	db.Create(&free)
+	zero, err := svc.CreateFromOffer(ctx, free.ID, InvoiceOptions{IssueDate: issue})
+	if err != nil {
+		t.Fatalf("create zero-total: %v", err)
+	}
+	zero, err = svc.MarkSent(ctx, zero.ID, issue)
+	if err != nil || zero.Status != models.InvoicePaid || zero.PaidAt == nil || zero.SentAt == nil {
+		t.Fatalf("expected the zero-total invoice to be paid on send: %+v %v", zero, err)
+	}
+}
+
+func TestInvoiceService_OverdueAndReminders(t *testing.T) {
+	db := newInvoiceTestDB(t)
+	ctx := context.Background()
+	client := models.Client{Name: "Acme", Email: "billing@acme.test"}
+	db.Create(&client)
+	offer := models.Offer{ClientID: client.ID, Subject: "Work", Status: "accepted", TotalPrice: 100, PaymentTerms: "14 hari"}
+	db.Create(&offer)
+	svc := NewInvoiceService(db)
+	issue := time.Now().AddDate(0, 0, -20)
+	inv, err := svc.CreateFromOffer(ctx, offer.ID, InvoiceOptions{IssueDate: issue})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	db.Model(inv).Update("status", models.InvoiceSent)
+
+	now := time.Now()
+	if n, err := svc.MarkOverdue(ctx, now); err != nil || n != 1 {
+		t.Fatalf("mark overdue: %d %v", n, err)
+	}
+	if n, _ := svc.SendOverdueReminders(ctx, now); n != 1 {
+		t.Fatalf("expected 1 reminder, got %d", n)
+	}
+	if n, _ := svc.SendOverdueReminders(ctx, now.Add(time.Hour)); n != 0 {
+		t.Fatalf("expected reminder throttled, got %d", n)
+	}
+}
+
+func TestDueDaysFromTerms(t *testing.T) {
+	t.Setenv("INVOICE_DEFAULT_DUE_DAYS", "")
+	cases := map[string]int{"Net 30": 30, "net45": 45, "Pembayaran 14 hari setelah invoice": 14, "Due on receipt": 0, "COD": 0, "": 14, "50% upfront": 14}
+	for in, want := range cases {
+		if got := DueDaysFromTerms(in); got != want {
+			t.Errorf("%q: got %d want %d", in, got, want)
+		}
+	}
+}
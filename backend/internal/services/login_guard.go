@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ThrottleError is returned when a login or reset attempt must wait.
+type ThrottleError struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *ThrottleError) Error() string {
+	secs := int(e.RetryAfter.Round(time.Second) / time.Second)
+	if e.Locked {
+		return fmt.Sprintf("too many failed attempts; locked for %d seconds", secs)
+	}
+	return fmt.Sprintf("too many attempts; retry in %d seconds", secs)
+}
+
+// LoginGuardConfig controls backoff and lockout thresholds.
+type LoginGuardConfig struct {
+	BackoffAfter         int           // failures allowed before backoff applies
+	BackoffBase          time.Duration // first backoff delay, doubled per further failure
+	BackoffMax           time.Duration
+	AccountLockThreshold int // failures on one account before lockout
+	IPLockThreshold      int // failures from one IP before lockout
+	LockDuration         time.Duration
+	FailureWindow        time.Duration // failures older than this are forgotten
+}
+
+// LoginGuardConfigFromEnv reads LOGIN_* env vars, falling back to defaults.
+func LoginGuardConfigFromEnv() LoginGuardConfig {
+	return LoginGuardConfig{
+		BackoffAfter:         envInt("LOGIN_BACKOFF_AFTER", 3),
+		BackoffBase:          time.Duration(envInt("LOGIN_BACKOFF_BASE_SECS", 1)) * time.Second,
+		BackoffMax:           time.Duration(envInt("LOGIN_BACKOFF_MAX_SECS", 300)) * time.Second,
+		AccountLockThreshold: envInt("LOGIN_LOCKOUT_THRESHOLD", 10),
+		IPLockThreshold:      envInt("LOGIN_IP_LOCKOUT_THRESHOLD", 50),
+		LockDuration:         time.Duration(envInt("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute,
+		FailureWindow:        time.Duration(envInt("LOGIN_FAILURE_WINDOW_MINUTES", 60)) * time.Minute,
+	}
+}
+
+func envInt(key string, def int) int {
+	if v := os.Getenv(key); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			return n
+		}
+	}
+	return def
+}
+
+// LoginGuard tracks failed attempts per key and records auth audit events.
+type LoginGuard struct {
+	db     *gorm.DB
+	mailer *Mailer
+	cfg    LoginGuardConfig
+	now    func() time.Time
+}
+
+func NewLoginGuard(db *gorm.DB, cfg LoginGuardConfig) *LoginGuard {
+	return &LoginGuard{db: db, mailer: NewMailer(), cfg: cfg, now: time.Now}
+}
+
+func accountKey(email string) string { return "account:" + strings.ToLower(strings.TrimSpace(email)) }
+func resetKey(email string) string   { return "reset:" + strings.ToLower(strings.TrimSpace(email)) }
+func ipKey(ip string) string {
+	if ip == "" {
+		return ""
+	}
+	return "ip:" + ip
+}
+func resetIPKey(ip string) string {
+	if ip == "" {
+		return ""
+	}
+	return "reset-ip:" + ip
+}
+
+// Check returns a *ThrottleError if any key is locked or still inside its backoff delay.
+// Empty keys are ignored.
+func (g *LoginGuard) Check(ctx context.Context, keys ...string) error {
+	now := g.now()
+	var worst *ThrottleError
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		var t models.LoginThrottle
+		if err := g.db.WithContext(ctx).Where("key = ?", key).First(&t).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return err
+		}
+		var te *ThrottleError
+		if t.LockedUntil != nil && now.Before(*t.LockedUntil) {
+			te = &ThrottleError{RetryAfter: t.LockedUntil.Sub(now), Locked: true}
+		} else if g.cfg.FailureWindow > 0 && now.Sub(t.LastFailureAt) > g.cfg.FailureWindow {
+			continue
+		} else if wait := g.backoff(t.Failures); wait > 0 {
+			if until := t.LastFailureAt.Add(wait); now.Before(until) {
+				te = &ThrottleError{RetryAfter: until.Sub(now)}
+			}
+		}
+		if te != nil && (worst == nil || te.RetryAfter > worst.RetryAfter) {
+			worst = te
+		}
+	}
+	if worst != nil {
+		return worst
+	}
+	return nil
+}
+
+// backoff returns the delay required after the given number of consecutive failures.
+func (g *LoginGuard) backoff(failures int) time.Duration {
+	over := failures - g.cfg.BackoffAfter
+	if over <= 0 || g.cfg.BackoffBase <= 0 {
+		return 0
+	}
+	d := g.cfg.BackoffBase
+	for i := 1; i < over; i++ {
+		d *= 2
+		if g.cfg.BackoffMax > 0 && d >= g.cfg.BackoffMax {
+			return g.cfg.BackoffMax
+		}
+	}
+	return d
+}
+
+// RecordFailure increments the failure count for key. When lockThreshold is positive and
+// reached, the key is locked for LockDuration and locked=true is returned. The increment
+// is a single upsert and the lock a conditional update, so concurrent failures are all
+// counted and only one of them reports the lockout.
+func (g *LoginGuard) RecordFailure(ctx context.Context, key string, lockThreshold int) (locked bool, err error) {
+	if key == "" {
+		return false, nil
+	}
+	now := g.now()
+	failures := gorm.Expr("login_throttles.failures + 1")
+	if g.cfg.FailureWindow > 0 {
+		failures = gorm.Expr("CASE WHEN login_throttles.last_failure_at < ? THEN 1 ELSE login_throttles.failures + 1 END", now.Add(-g.cfg.FailureWindow))
+	}
+	db := g.db.WithContext(ctx)
+	err = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"failures": failures, "last_failure_at": now, "updated_at": now}),
+	}).Create(&models.LoginThrottle{Key: key, Failures: 1, LastFailureAt: now}).Error
+	if err != nil || lockThreshold <= 0 {
+		return false, err
+	}
+	res := db.Model(&models.LoginThrottle{}).Where("key = ? AND failures >= ?", key, lockThreshold).
+		Updates(map[string]interface{}{"locked_until": now.Add(g.cfg.LockDuration), "failures": 0})
+	return res.RowsAffected > 0, res.Error
+}
+
+// RecordSuccess clears failure tracking for key.
+func (g *LoginGuard) RecordSuccess(ctx context.Context, key string) error {
+	if key == "" {
+		return nil
+	}
+	return g.db.WithContext(ctx).Where("key = ?", key).Delete(&models.LoginThrottle{}).Error
+}
+
+// RecordEvent appends an auth audit event. Failures to write are ignored.
+func (g *LoginGuard) RecordEvent(ctx context.Context, userID int, email, ip, event, detail string) {
+	_ = g.db.WithContext(ctx).Create(&models.AuthEvent{
+		UserID:    userID,
+		Email:     strings.ToLower(strings.TrimSpace(email)),
+		IP:        ip,
+		Event:     event,
+		Detail:    detail,
+		CreatedAt: g.now(),
+	}).Error
+}
+
+// NotifyLockout emails the account owner that their account was temporarily locked.
+func (g *LoginGuard) NotifyLockout(to, ip string) error {
+	until := g.now().Add(g.cfg.LockDuration)
+	subject := "Account temporarily locked"
+	body := fmt.Sprintf("Hello,\n\nYour account was locked after repeated failed sign-in attempts (last attempt from IP %s).\nYou can try again after %s.\n\nIf this wasn't you, consider resetting your password.\n",
+		nonEmpty(ip, "unknown"), until.Format("2006-01-02 15:04 MST"))
+	return g.mailer.SendGenericEmail(to, subject, body)
+}
+
+// ListEvents returns auth events for a user, newest first.
+func (g *LoginGuard) ListEvents(ctx context.Context, userID, limit, offset int) ([]models.AuthEvent, error) {
+	var items []models.AuthEvent
+	q := g.db.WithContext(ctx).Where("user_id = ?", userID).Order("id DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if offset > 0 {
+		q = q.Offset(offset)
+	}
+	if err := q.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
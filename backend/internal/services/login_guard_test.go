@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newGuardedAuth(t *testing.T, cfg LoginGuardConfig) (*AuthService, *LoginGuard, *time.Time) {
+	t.Helper()
+	os.Setenv("JWT_SECRET", "test-secret")
+	db := newTestDB(t)
+	if err := db.AutoMigrate(&models.AuthEvent{}, &models.LoginThrottle{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	clock := time.Now()
+	g := NewLoginGuard(db, cfg)
+	g.now = func() time.Time { return clock }
+	return NewAuthService(db).WithLoginGuard(g), g, &clock
+}
+
+func TestLoginGuard_BackoffAndLockout(t *testing.T) {
+	svc, g, clock := newGuardedAuth(t, LoginGuardConfig{
+		BackoffAfter:         2,
+		BackoffBase:          time.Second,
+		BackoffMax:           time.Minute,
+		AccountLockThreshold: 4,
+		IPLockThreshold:      100,
+		LockDuration:         15 * time.Minute,
+		FailureWindow:        time.Hour,
+	})
+	ctx := context.Background()
+	if _, err := svc.Register(ctx, "victim@example.com", "correct"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	// Two free failures
+	for i := 0; i < 2; i++ {
+		if _, _, err := svc.LoginFrom(ctx, "victim@example.com", "nope", "10.0.0.1"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("attempt %d: expected invalid credentials, got %v", i, err)
+		}
+	}
+	// Third failure starts backoff
+	if _, _, err := svc.LoginFrom(ctx, "victim@example.com", "nope", "10.0.0.1"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("expected invalid credentials, got %v", err)
+	}
+	var te *ThrottleError
+	if _, _, err := svc.LoginFrom(ctx, "victim@example.com", "correct", "10.0.0.2"); !errors.As(err, &te) || te.Locked {
+		t.Fatalf("expected backoff throttle even with correct password, got %v", err)
+	}
+
+	// After waiting out the backoff the fourth failure locks the account
+	*clock = clock.Add(2 * time.Second)
+	if _, _, err := svc.LoginFrom(ctx, "victim@example.com", "nope", "10.0.0.1"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("expected invalid credentials, got %v", err)
+	}
+	if _, _, err := svc.LoginFrom(ctx, "victim@example.com", "correct", "10.0.0.3"); !errors.As(err, &te) || !te.Locked {
+		t.Fatalf("expected lockout, got %v", err)
+	}
+
+	// Lock expires
+	*clock = clock.Add(16 * time.Minute)
+	if _, _, err := svc.LoginFrom(ctx, "victim@example.com", "correct", "10.0.0.3"); err != nil {
+		t.Fatalf("expected login after lock expiry, got %v", err)
+	}
+
+	events, err := g.ListEvents(ctx, 1, 0, 0)
+	if err != nil {
+		t.Fatalf("list events: %v", err)
+	}
+	seen := map[string]bool{}
+	for _, e := range events {
+		seen[e.Event] = true
+	}
+	for _, want := range []string{"login_failure", "login_throttled", "account_locked", "login_success"} {
+		if !seen[want] {
+			t.Fatalf("expected %s event, got %+v", want, events)
+		}
+	}
+}
+
+func TestLoginGuard_ResetRequestBackoff(t *testing.T) {
+	svc, _, clock := newGuardedAuth(t, LoginGuardConfig{
+		BackoffAfter:  1,
+		BackoffBase:   30 * time.Second,
+		FailureWindow: time.Hour,
+	})
+	ctx := context.Background()
+	if _, err := svc.RequestPasswordResetFrom(ctx, "nobody@example.com", 5, "10.0.0.9"); err != nil {
+		t.Fatalf("first reset request: %v", err)
+	}
+	if _, err := svc.RequestPasswordResetFrom(ctx, "nobody@example.com", 5, "10.0.0.9"); err != nil {
+		t.Fatalf("second reset request records backoff but is allowed: %v", err)
+	}
+	var te *ThrottleError
+	if _, err := svc.RequestPasswordResetFrom(ctx, "nobody@example.com", 5, "10.0.0.9"); !errors.As(err, &te) {
+		t.Fatalf("expected throttled reset request, got %v", err)
+	}
+	*clock = clock.Add(2 * time.Hour)
+	if _, err := svc.RequestPasswordResetFrom(ctx, "nobody@example.com", 5, "10.0.0.9"); err != nil {
+		t.Fatalf("expected window to expire, got %v", err)
+	}
+}
+
+func TestLoginGuard_ConcurrentFailuresCountedOnce(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "guard.db") + "?_busy_timeout=5000&_txlock=immediate"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&models.LoginThrottle{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	g := NewLoginGuard(db, LoginGuardConfig{LockDuration: time.Minute, FailureWindow: time.Hour})
+	ctx := context.Background()
+
+	const n = 20
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	locks := 0
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			locked, err := g.RecordFailure(ctx, "account:a@example.com", n/2)
+			if err != nil {
+				t.Errorf("record failure: %v", err)
+			}
+			if locked {
+				mu.Lock()
+				locks++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	var row models.LoginThrottle
+	db.Where("key = ?", "account:a@example.com").First(&row)
+	// Ten failures lock the key and reset the count; the other ten count again
+	// and lock it a second time.
+	if locks != 2 || row.Failures != 0 || row.LockedUntil == nil {
+		t.Fatalf("expected every failure counted and two lockouts, got %d locks and %+v", locks, row)
+	}
+}
@@ -1,9 +1,15 @@
 package services
 
 import (
+	"bytes"
+	"encoding/base64"
 	"fmt"
+	"mime"
+	"mime/multipart"
 	"net/smtp"
+	"net/textproto"
 	"os"
+	"strings"
 )
 
 // Mailer provides minimal SMTP email sending capability.
@@ -60,3 +66,69 @@ func (m *Mailer) SendGenericEmail(to, subject, body string) error {
  	_ = smtp.SendMail(addr, auth, from, []string{to}, msg)
  	return nil
 }
+
+// MailAttachment is a file attached to an email.
+type MailAttachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// sendMail is smtp.SendMail; tests replace it to capture messages.
+var sendMail = smtp.SendMail
+
+// SendEmailWithAttachments sends a plaintext email with file attachments.
+// Unlike SendGenericEmail, delivery errors are returned to the caller.
+func (m *Mailer) SendEmailWithAttachments(to, subject, body string, attachments []MailAttachment) error {
+	return m.SendEmailWithAttachmentsCC(to, nil, subject, body, attachments)
+}
+
+// SendEmailWithAttachmentsCC is SendEmailWithAttachments with carbon-copy
+// recipients listed in a Cc header.
+func (m *Mailer) SendEmailWithAttachmentsCC(to string, cc []string, subject, body string, attachments []MailAttachment) error {
+	host := os.Getenv("SMTP_HOST")
+	port := os.Getenv("SMTP_PORT")
+	user := os.Getenv("SMTP_USER")
+	pass := os.Getenv("SMTP_PASSWORD")
+	from := os.Getenv("SMTP_FROM")
+	if host == "" || port == "" || user == "" || pass == "" || from == "" {
+		return nil // noop if SMTP not configured
+	}
+	addr := fmt.Sprintf("%s:%s", host, port)
+	auth := smtp.PlainAuth("", user, pass, host)
+	return sendMail(addr, auth, from, append([]string{to}, cc...), buildMultipartMessage(from, to, cc, subject, body, attachments))
+}
+
+// buildMultipartMessage encodes a multipart/mixed message with a text part
+// followed by base64 attachments.
+func buildMultipartMessage(from, to string, cc []string, subject, body string, attachments []MailAttachment) []byte {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	if len(cc) > 0 {
+		fmt.Fprintf(&buf, "Cc: %s\r\n", strings.Join(cc, ", "))
+	}
+	fmt.Fprintf(&buf, "From: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: multipart/mixed; boundary=%q\r\n\r\n", from, mime.QEncoding.Encode("utf-8", subject), w.Boundary())
+
+	text, _ := w.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=utf-8"}})
+	_, _ = text.Write([]byte(body + "\r\n"))
+	for _, a := range attachments {
+		ct := a.ContentType
+		if ct == "" {
+			ct = "application/octet-stream"
+		}
+		part, _ := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {ct},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+		})
+		enc := base64.StdEncoding.EncodeToString(a.Data)
+		for len(enc) > 76 {
+			_, _ = part.Write([]byte(enc[:76] + "\r\n"))
+			enc = enc[76:]
+		}
+		_, _ = part.Write([]byte(enc + "\r\n"))
+	}
+	_ = w.Close()
+	return buf.Bytes()
+}
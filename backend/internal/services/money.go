@@ -0,0 +1,77 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// BaseCurrency is the currency revenue is reported in (BASE_CURRENCY, default IDR).
+func BaseCurrency() string {
+	return nonEmpty(normalizeCurrency(os.Getenv("BASE_CURRENCY")), "IDR")
+}
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+func normalizeCurrency(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// validCurrency reports whether code looks like an ISO 4217 code.
+func validCurrency(code string) bool { return currencyCode.MatchString(code) }
+
+// moneyLocale describes how amounts in a currency are written.
+type moneyLocale struct {
+	prefix    string
+	thousands string
+	decimal   string
+	digits    int
+}
+
+// moneyLocales covers the currencies we bill in; others fall back to
+// "<CODE> 1,234.56". Amounts also go into plain-text emails, so prefixes
+// stay ASCII.
+var moneyLocales = map[string]moneyLocale{
+	"USD": {prefix: "$", thousands: ",", decimal: ".", digits: 2},
+	"SGD": {prefix: "S$", thousands: ",", decimal: ".", digits: 2},
+	"AUD": {prefix: "A$", thousands: ",", decimal: ".", digits: 2},
+	"MYR": {prefix: "RM ", thousands: ",", decimal: ".", digits: 2},
+	"EUR": {prefix: "EUR ", thousands: ".", decimal: ",", digits: 2},
+	"JPY": {prefix: "JPY ", thousands: ",", decimal: ".", digits: 0},
+}
+
+// formatMoney formats amount in currency using that currency's conventions.
+// IDR (and an empty currency) keep the Rp 1.234,5 style of formatCurrency.
+func formatMoney(amount float64, currency string) string {
+	currency = normalizeCurrency(currency)
+	if currency == "" || currency == "IDR" {
+		return formatCurrency(amount)
+	}
+	loc, ok := moneyLocales[currency]
+	if !ok {
+		loc = moneyLocale{prefix: currency + " ", thousands: ",", decimal: ".", digits: 2}
+	}
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	s := fmt.Sprintf("%.*f", loc.digits, amount)
+	intPart, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, frac = s[:i], s[i+1:]
+	}
+	var grouped strings.Builder
+	for i, r := range intPart {
+		if i != 0 && (len(intPart)-i)%3 == 0 {
+			grouped.WriteString(loc.thousands)
+		}
+		grouped.WriteRune(r)
+	}
+	out := sign + loc.prefix + grouped.String()
+	if frac != "" {
+		out += loc.decimal + frac
+	}
+	return out
+}
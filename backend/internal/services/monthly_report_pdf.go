@@ -0,0 +1,482 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"gorm.io/gorm"
+)
+
+// DefaultMonthlyReportTemplate is used when the report owner has not saved a
+// "monthly" ReportTemplate of their own.
+const DefaultMonthlyReportTemplate = `<h1>Monthly Report - {{.Month}}</h1>
+<table>
+  <tr><th width="22%">Client</th><td>{{.Client.Name}}</td></tr>
+  <tr><th>Layanan</th><td>{{.Service.Domain}} ({{.Service.ServiceType}})</td></tr>
+  <tr><th>Periode</th><td>{{date .Start}} – {{date .End}}</td></tr>
+</table>
+<h2>Ringkasan Performa</h2>
+{{range lines .Summary}}<p>{{.}}</p>{{end}}
+<table>
+  <thead><tr><th>Metrik</th><th width="30%" align="right">Nilai</th></tr></thead>
+  <tr><td>Rata-rata uptime</td><td>{{printf "%.2f" .Report.AvgUptimePercent}}%</td></tr>
+  <tr><td>Rata-rata waktu respon</td><td>{{.Report.AvgResponseMs}} ms</td></tr>
+  <tr><td>Total downtime</td><td>{{.Report.TotalDowntime}} menit</td></tr>
+  <tr><td>Alert dibuka / terselesaikan</td><td>{{.Report.AlertsOpened}} / {{.Report.AlertsResolved}}</td></tr>
+  <tr><td>Waktu maintenance</td><td>{{printf "%.2f" .Report.MaintenanceHours}} jam</td></tr>
+</table>
+{{if .Activities}}
+<h2>Aktivitas</h2>
+<table>
+  <thead><tr><th width="22%">Tanggal</th><th>Deskripsi</th></tr></thead>
+  {{range .Activities}}<tr><td>{{.Date}}</td><td>{{.Description}}</td></tr>{{end}}
+</table>
+{{end}}`
+
+// MonthlyReportData is what a monthly report template is executed against.
+// Client and Service are never nil so templates can dereference them freely.
+type MonthlyReportData struct {
+	Report     *models.MonthlyReport
+	Service    *models.Service
+	Client     *models.Client
+	Month      string // e.g. "Maret 2025"
+	Start      time.Time
+	End        time.Time
+	Summary    string // the stored summary, or one generated from the metrics
+	Activities []models.ReportActivity
+}
+
+// parseReportActivities reads MonthlyReport.Activities, which may be stored
+// either as strings or as {date, description} objects.
+func parseReportActivities(raw string) []models.ReportActivity {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	var out []models.ReportActivity
+	var objs []models.ReportActivity
+	if err := json.Unmarshal([]byte(raw), &objs); err == nil {
+		for _, a := range objs {
+			a.Date, a.Description = strings.TrimSpace(a.Date), strings.TrimSpace(a.Description)
+			if a.Date == "" && a.Description == "" {
+				continue
+			}
+			out = append(out, models.ReportActivity{Date: nonEmpty(a.Date, "-"), Description: a.Description})
+		}
+		return out
+	}
+	var strs []string
+	if err := json.Unmarshal([]byte(raw), &strs); err == nil {
+		for _, s := range strs {
+			if s = strings.TrimSpace(s); s != "" {
+				out = append(out, models.ReportActivity{Date: "-", Description: s})
+			}
+		}
+	}
+	return out
+}
+
+var reportTemplateFuncs = template.FuncMap{
+	"date": func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return formatDateID(t)
+	},
+	"money": formatMoney,
+	"upper": strings.ToUpper,
+	"lines": splitAndTrim,
+}
+
+// ValidateReportTemplate reports syntax errors in a template's content.
+func ValidateReportTemplate(content string) FieldErrors {
+	if _, err := template.New("report").Funcs(reportTemplateFuncs).Parse(content); err != nil {
+		return FieldErrors{"content": err.Error()}
+	}
+	return nil
+}
+
+// RenderPDF renders report through templateID, or when that is zero through
+// the owner's latest "monthly" template, falling back to
+// DefaultMonthlyReportTemplate (see renderStoredTemplate).
+func (s *MonthlyReportService) RenderPDF(ctx context.Context, report *models.MonthlyReport, templateID int) ([]byte, error) {
+	db := s.db.WithContext(ctx)
+
+	data := &MonthlyReportData{Report: report, Service: &models.Service{ID: report.ServiceID}, Client: &models.Client{}}
+	if err := db.First(data.Service, report.ServiceID).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if data.Service.ClientID > 0 {
+		if err := db.First(data.Client, data.Service.ClientID).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+	period := report.ReportMonth
+	if period.IsZero() {
+		period = time.Now()
+	}
+	data.Start = time.Date(period.Year(), period.Month(), 1, 0, 0, 0, 0, time.UTC)
+	data.End = data.Start.AddDate(0, 1, -1)
+	data.Month = fmt.Sprintf("%s %d", monthNameID(period), period.Year())
+	data.Activities = parseReportActivities(report.Activities)
+	data.Summary = strings.TrimSpace(report.Summary)
+	if data.Summary == "" {
+		data.Summary = fmt.Sprintf("Selama bulan %s, layanan %s menunjukkan rata-rata uptime %.2f%% dengan waktu respon rata-rata %d ms. Total downtime tercatat %d menit dengan %d alert dibuka dan %d terselesaikan. Waktu maintenance %.2f jam.",
+			data.Month, nonEmpty(data.Service.Domain, fmt.Sprintf("ID %d", report.ServiceID)),
+			report.AvgUptimePercent, report.AvgResponseMs, report.TotalDowntime, report.AlertsOpened, report.AlertsResolved, report.MaintenanceHours)
+	}
+	return renderStoredTemplate(db, "monthly", report.UserID, templateID, DefaultMonthlyReportTemplate, data)
+}
+
+// renderStoredTemplate renders data through the template picked by
+// reportTemplateContent. The owner's latest template is only used when it
+// renders: templates saved by the old editor as browser HTML or JSON fall
+// back to def instead of failing every PDF and delivery. An explicitly
+// chosen template's errors are returned as FieldErrors.
+func renderStoredTemplate(db *gorm.DB, kind string, userID, templateID int, def string, data interface{}) ([]byte, error) {
+	content, err := reportTemplateContent(db, kind, userID, templateID, def)
+	if err != nil {
+		return nil, err
+	}
+	pdf, err := renderReportPDF(content, data)
+	var fe FieldErrors
+	if err != nil && templateID == 0 && content != def && errors.As(err, &fe) {
+		log.Printf("%s template of user %d does not render, using the default: %v", kind, userID, err)
+		return renderReportPDF(def, data)
+	}
+	return pdf, err
+}
+
+// reportTemplateContent returns the content of template templateID, which
+// must be of kind and belong to userID, or when templateID is zero of the
+// user's latest template of kind, falling back to def.
+func reportTemplateContent(db *gorm.DB, kind string, userID, templateID int, def string) (string, error) {
+	var tpl models.ReportTemplate
+	if templateID > 0 {
+		if err := db.Where("kind = ? AND user_id = ?", kind, userID).First(&tpl, templateID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return "", FieldErrors{"template_id": "template not found"}
+			}
+			return "", err
+		}
+		return tpl.Content, nil
+	}
+	err := db.Where("kind = ? AND user_id = ?", kind, userID).Order("id DESC").First(&tpl).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && legacyTemplate(tpl.Content)) {
+		return def, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return tpl.Content, nil
+}
+
+// legacyTemplate reports content that is not an HTML template: empty, or the
+// JSON config the old editor stored for client-side rendering.
+func legacyTemplate(content string) bool {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return true
+	}
+	return (content[0] == '{' || content[0] == '[') && !strings.HasPrefix(content, "{{")
+}
+
+func renderReportPDF(content string, data interface{}) ([]byte, error) {
+	t, err := template.New("report").Funcs(reportTemplateFuncs).Parse(content)
+	if err != nil {
+		return nil, FieldErrors{"template": err.Error()}
+	}
+	var out bytes.Buffer
+	if err := t.Execute(&out, data); err != nil {
+		return nil, FieldErrors{"template": err.Error()}
+	}
+	return htmlToPDF(out.String())
+}
+
+// htmlToPDF lays out a small HTML subset under the PDF letterhead: h1-h3, p,
+// br, ul/ol, hr, and tables. A table whose first row is all <th> gets a
+// bordered header repeated across pages; other tables are borderless, with
+// a leading <th> cell set in bold. Column widths come from width="N%" and
+// alignment from align on the first row. Inline markup is flattened to text,
+// and page-break-before in a style attribute starts a new page.
+func htmlToPDF(src string) ([]byte, error) {
+	root, err := html.Parse(strings.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	tpl, err := loadPDFTemplate()
+	if err != nil {
+		return nil, err
+	}
+	d, err := tpl.newDoc()
+	if err != nil {
+		return nil, err
+	}
+	if err := tpl.letterhead(d); err != nil {
+		return nil, err
+	}
+	r := &htmlLayout{d: d}
+	r.blocks(root)
+	r.flush()
+	return d.render(), nil
+}
+
+type htmlLayout struct {
+	d       *pdfDoc
+	pending strings.Builder // inline text outside any block element
+}
+
+var htmlBody = pdfStyle{Size: 10}
+
+func (r *htmlLayout) flush() {
+	if text := cleanInline(r.pending.String()); text != "" {
+		r.d.paragraph(text, htmlBody)
+		r.d.space(4)
+	}
+	r.pending.Reset()
+}
+
+func (r *htmlLayout) blocks(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch c.Type {
+		case html.TextNode:
+			r.pending.WriteString(c.Data)
+			continue
+		case html.ElementNode:
+		default:
+			r.blocks(c)
+			continue
+		}
+		if isInline(c) {
+			inlineText(&r.pending, c)
+			continue
+		}
+		r.flush()
+		if style := strings.ToLower(attr(c, "style")); strings.Contains(style, "page-break-before") || strings.Contains(style, "break-before") {
+			if r.d.y > pdfMargin {
+				r.d.addPage()
+			}
+		}
+		switch c.DataAtom {
+		case atom.Head, atom.Script, atom.Style, atom.Title, atom.Img:
+		case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+			size := map[atom.Atom]float64{atom.H1: 16, atom.H2: 13, atom.H3: 11}[c.DataAtom]
+			if size == 0 {
+				size = 10
+			}
+			style := pdfStyle{Size: size, Bold: true, Align: alignOf(c)}
+			r.d.space(size / 3)
+			r.d.ensure(style.lineHeight() + 2*htmlBody.lineHeight())
+			r.d.paragraph(blockText(c), style)
+			r.d.space(4)
+		case atom.P:
+			r.d.paragraph(blockText(c), pdfStyle{Size: 10, Bold: isAllBold(c), Align: alignOf(c)})
+			r.d.space(6)
+		case atom.Ul, atom.Ol:
+			idx := 0
+			for li := c.FirstChild; li != nil; li = li.NextSibling {
+				if li.Type != html.ElementNode || li.DataAtom != atom.Li {
+					continue
+				}
+				idx++
+				marker := "•"
+				if c.DataAtom == atom.Ol {
+					marker = strconv.Itoa(idx) + "."
+				}
+				r.d.paragraph(marker+" "+blockText(li), pdfStyle{Size: 10, Indent: 12})
+			}
+			r.d.space(6)
+		case atom.Hr:
+			r.d.rule()
+		case atom.Table:
+			r.table(c)
+			r.d.space(8)
+		default:
+			r.blocks(c)
+			r.flush()
+		}
+	}
+}
+
+func (r *htmlLayout) table(n *html.Node) {
+	var rows []*html.Node
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch c.DataAtom {
+			case atom.Tr:
+				rows = append(rows, c)
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				collect(c)
+			}
+		}
+	}
+	collect(n)
+	if len(rows) == 0 {
+		return
+	}
+	cellsOf := func(tr *html.Node) []*html.Node {
+		var out []*html.Node
+		for c := tr.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && (c.DataAtom == atom.Td || c.DataAtom == atom.Th) {
+				out = append(out, c)
+			}
+		}
+		return out
+	}
+
+	first := cellsOf(rows[0])
+	header := len(first) > 0
+	for _, c := range first {
+		header = header && c.DataAtom == atom.Th
+	}
+	ncols := 0
+	for _, tr := range rows {
+		if n := len(cellsOf(tr)); n > ncols {
+			ncols = n
+		}
+	}
+	if ncols == 0 {
+		return
+	}
+
+	cols := make([]pdfColumn, ncols)
+	fixed, unset := 0.0, 0
+	for i := range cols {
+		if i < len(first) {
+			cols[i].Align = alignOf(first[i])
+			if header {
+				cols[i].Title = blockText(first[i])
+			}
+			if w, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(attr(first[i], "width")), "%"), 64); err == nil && w > 0 && w < 100 {
+				cols[i].Width = w / 100
+				fixed += cols[i].Width
+				continue
+			}
+		}
+		unset++
+	}
+	for i := range cols {
+		if cols[i].Width == 0 {
+			cols[i].Width = (1 - fixed) / float64(unset)
+		}
+	}
+
+	body := rows
+	if header {
+		body = rows[1:]
+	}
+	t := pdfTable{Columns: cols, Size: 9, Plain: !header}
+	for _, tr := range body {
+		cells := cellsOf(tr)
+		row := make([]string, ncols)
+		for i, c := range cells {
+			row[i] = blockText(c)
+		}
+		if !header && len(cells) > 0 && cells[0].DataAtom == atom.Th {
+			t.BoldFirst = true
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	if t.Plain {
+		t.Size = 10
+	}
+	r.d.table(t)
+}
+
+func isInline(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.B, atom.Strong, atom.I, atom.Em, atom.U, atom.Span, atom.A, atom.Small, atom.Code, atom.Br, atom.Sub, atom.Sup, atom.Mark:
+		return true
+	}
+	return false
+}
+
+// inlineText appends n's text, turning <br> into newlines.
+func inlineText(b *strings.Builder, n *html.Node) {
+	switch {
+	case n.Type == html.TextNode:
+		b.WriteString(n.Data)
+	case n.Type == html.ElementNode && n.DataAtom == atom.Br:
+		b.WriteString("\n")
+	default:
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			inlineText(b, c)
+		}
+	}
+}
+
+// blockText flattens an element to text with HTML whitespace rules applied.
+func blockText(n *html.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		inlineText(&b, c)
+	}
+	return cleanInline(b.String())
+}
+
+// cleanInline collapses whitespace within each line and drops blank edges.
+func cleanInline(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.Join(strings.Fields(l), " ")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// isAllBold reports whether all of n's text sits inside <b> or <strong>.
+func isAllBold(n *html.Node) bool {
+	bold := false
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c.Type == html.TextNode && strings.TrimSpace(c.Data) == "":
+		case c.Type == html.ElementNode && (c.DataAtom == atom.B || c.DataAtom == atom.Strong):
+			bold = true
+		default:
+			return false
+		}
+	}
+	return bold
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func alignOf(n *html.Node) pdfAlign {
+	align := strings.ToLower(attr(n, "align"))
+	if style := strings.ToLower(attr(n, "style")); strings.Contains(style, "text-align") {
+		switch {
+		case strings.Contains(style, "center"):
+			align = "center"
+		case strings.Contains(style, "right"):
+			align = "right"
+		}
+	}
+	switch align {
+	case "center":
+		return alignCenter
+	case "right":
+		return alignRight
+	}
+	return alignLeft
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMonthlyReportRenderPDF(t *testing.T) {
+	t.Setenv("PDF_TEMPLATE_PATH", "")
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Client{}, &models.Service{}, &models.MonthlyReport{}, &models.ReportTemplate{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	ctx := context.Background()
+	client := models.Client{Name: "Café Nusantara"}
+	db.Create(&client)
+	svc := models.Service{ClientID: client.ID, Domain: "cafe.example", ServiceType: "website"}
+	db.Create(&svc)
+	report := models.MonthlyReport{
+		ServiceID:        svc.ID,
+		UserID:           7,
+		ReportMonth:      time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+		AvgUptimePercent: 99.5,
+		AvgResponseMs:    240,
+		Activities:       `[{"date":"2025-02-03","description":"Update plugin"},{"date":"","description":"Backup <db>"}]`,
+	}
+	db.Create(&report)
+	s := NewMonthlyReportService(db)
+
+	out, err := s.RenderPDF(ctx, &report, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := strings.Join(pdfStreams(t, out), "")
+	for _, want := range []string{"(Monthly Report - Februari 2025)", `(Caf\351 Nusantara)`, "(cafe.example \\(website\\))", "(99.50%)", "(Update plugin)", "(Backup <db>)", `(01 Februari 2025 \226 28 Februari 2025)`, "(Aktivitas)"} {
+		if !strings.Contains(all, want) {
+			t.Errorf("default template: missing %s", want)
+		}
+	}
+
+	// The owner's saved template takes over from the default.
+	db.Create(&models.ReportTemplate{Name: "short", Kind: "monthly", UserID: 7, Content: `<h1>{{upper .Client.Name}}</h1><ul>{{range .Activities}}<li>{{.Description}}</li>{{end}}</ul>`})
+	out, err = s.RenderPDF(ctx, &report, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all = strings.Join(pdfStreams(t, out), "")
+	if !strings.Contains(all, `(CAF\311 NUSANTARA)`) || !strings.Contains(all, `(\225 Update plugin)`) || strings.Contains(all, "(Aktivitas)") {
+		t.Fatalf("saved template not used: %s", all)
+	}
+
+	broken := models.ReportTemplate{Name: "broken", Kind: "monthly", UserID: 7, Content: `{{.Nope}}`}
+	db.Create(&broken)
+	var fe FieldErrors
+	if _, err := s.RenderPDF(ctx, &report, broken.ID); !errors.As(err, &fe) || fe["template"] == "" {
+		t.Fatalf("expected template error, got %v", err)
+	}
+	// As the latest template it falls back to the default, as do templates
+	// the old editor saved as JSON for client-side rendering.
+	for _, legacy := range []string{"", `{"layout":"classic","showLogo":true}`} {
+		if legacy != "" {
+			db.Create(&models.ReportTemplate{Name: "legacy", Kind: "monthly", UserID: 7, Content: legacy})
+		}
+		out, err = s.RenderPDF(ctx, &report, 0)
+		if err != nil {
+			t.Fatalf("expected the default layout, got %v", err)
+		}
+		if all := strings.Join(pdfStreams(t, out), ""); !strings.Contains(all, "(Aktivitas)") || strings.Contains(all, "layout") {
+			t.Fatalf("expected the default layout: %s", all)
+		}
+	}
+	other := models.ReportTemplate{Name: "other", Kind: "monthly", UserID: 8, Content: `<p>x</p>`}
+	db.Create(&other)
+	if _, err := s.RenderPDF(ctx, &report, other.ID); !errors.As(err, &fe) || fe["template_id"] == "" {
+		t.Fatalf("another user's template must not be usable, got %v", err)
+	}
+	if fe := ValidateReportTemplate(`{{if}}`); fe == nil {
+		t.Fatal("expected a syntax error")
+	}
+}
+
+func TestHTMLToPDFTables(t *testing.T) {
+	t.Setenv("PDF_TEMPLATE_PATH", "")
+	var rows strings.Builder
+	for i := 0; i < 90; i++ {
+		rows.WriteString("<tr><td>row</td><td>1</td></tr>")
+	}
+	out, err := htmlToPDF(`<table><tr><th>Item</th><th align="right">Qty</th></tr>` + rows.String() + `</table><p style="page-break-before: always"><b>Last</b></p>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages := pdfStreams(t, out)
+	if len(pages) < 3 {
+		t.Fatalf("expected the table to span pages plus a forced break, got %d", len(pages))
+	}
+	for i, p := range pages {
+		if strings.Contains(p, "(row)") && !strings.Contains(p, "(Item)") {
+			t.Errorf("page %d lacks the repeated header", i+1)
+		}
+	}
+	last := pages[len(pages)-1]
+	if !strings.Contains(last, "/F2 10.00 Tf") || !strings.Contains(last, "(Last)") || strings.Contains(last, "(row)") {
+		t.Fatalf("page break or bold paragraph not honoured: %s", last)
+	}
+}
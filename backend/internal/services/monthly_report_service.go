@@ -3,6 +3,7 @@ package services
 import (
     "context"
     "encoding/json"
+    "errors"
     "fmt"
     "strings"
     "time"
@@ -11,6 +12,10 @@ import (
 	"gorm.io/gorm"
 )
 
+// ErrNoReportData is returned when a service has neither daily reports nor
+// uptime logs for the requested month.
+var ErrNoReportData = errors.New("no report data")
+
 type MonthlyReportService struct {
     db *gorm.DB
 }
@@ -19,127 +24,201 @@ func NewMonthlyReportService(db *gorm.DB) *MonthlyReportService {
     return &MonthlyReportService{db: db}
 }
 
-// GenerateMonthlyReport aggregates daily reports into a monthly summary
+// ErrReportFinalized is returned when changing a report that has been
+// finalized (sent to the client).
+var ErrReportFinalized = errors.New("monthly report is finalized")
+
+// GenerateMonthlyReport returns the service's report for month, aggregating
+// and saving it from daily reports (or raw uptime logs) when none exists yet.
+// Calling it again for the same service and month returns the same report.
 func (s *MonthlyReportService) GenerateMonthlyReport(ctx context.Context, serviceID int, month time.Time) (*models.MonthlyReport, error) {
-	// Calculate date range for the month
+	if existing, err := s.findMonthlyReport(ctx, serviceID, month); err != nil || existing != nil {
+		return existing, err
+	}
+	monthlyReport, err := s.aggregateMonth(ctx, serviceID, month)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Create(monthlyReport).Error; err != nil {
+		// A concurrent request may have created it first.
+		if existing, ferr := s.findMonthlyReport(ctx, serviceID, month); ferr == nil && existing != nil {
+			return existing, nil
+		}
+		return nil, fmt.Errorf("failed to save monthly report: %w", err)
+	}
+	return monthlyReport, nil
+}
+
+// RegenerateMonthlyReport recomputes the metrics of report id from current
+// data, keeping the summary, ownership and edited activities. Time-entry
+// lines missing from the activities are appended (see mergeActivities), and
+// maintenance hours are raised to the logged total when below it.
+func (s *MonthlyReportService) RegenerateMonthlyReport(ctx context.Context, id int) (*models.MonthlyReport, error) {
+	var report models.MonthlyReport
+	if err := s.db.WithContext(ctx).First(&report, id).Error; err != nil {
+		return nil, fmt.Errorf("failed to get monthly report: %w", err)
+	}
+	if report.FinalizedAt != nil {
+		return nil, ErrReportFinalized
+	}
+	fresh, err := s.aggregateMonth(ctx, report.ServiceID, report.ReportMonth)
+	if err != nil {
+		return nil, err
+	}
+	report.AvgUptimePercent = fresh.AvgUptimePercent
+	report.AvgResponseMs = fresh.AvgResponseMs
+	report.TotalDowntime = fresh.TotalDowntime
+	report.AlertsOpened = fresh.AlertsOpened
+	report.AlertsResolved = fresh.AlertsResolved
+	columns := []interface{}{"avg_response_ms", "total_downtime", "alerts_opened", "alerts_resolved"}
+	if fresh.Activities != "" {
+		report.Activities = mergeActivities(report.Activities, fresh.Activities)
+		if fresh.MaintenanceHours > report.MaintenanceHours {
+			report.MaintenanceHours = fresh.MaintenanceHours
+		}
+		columns = append(columns, "activities", "maintenance_hours")
+	}
+	if err := s.db.WithContext(ctx).Model(&report).
+		Select("avg_uptime_percent", columns...).
+		Updates(&report).Error; err != nil {
+		return nil, fmt.Errorf("failed to update monthly report: %w", err)
+	}
+	return &report, nil
+}
+
+// mergeActivities keeps the stored activities, edits and incidents included,
+// and appends the time-entry lines of fresh that are not already there.
+func mergeActivities(stored, fresh string) string {
+	merged := parseReportActivities(stored)
+	seen := make(map[models.ReportActivity]bool, len(merged))
+	for _, a := range merged {
+		seen[a] = true
+	}
+	added := false
+	for _, a := range parseReportActivities(fresh) {
+		if !seen[a] {
+			merged = append(merged, a)
+			seen[a] = true
+			added = true
+		}
+	}
+	if !added {
+		return stored
+	}
+	b, _ := json.Marshal(merged)
+	return string(b)
+}
+
+// FinalizeMonthlyReport locks report id against regeneration and edits.
+// Finalizing an already finalized report keeps the original time.
+func (s *MonthlyReportService) FinalizeMonthlyReport(ctx context.Context, id int, now time.Time) (*models.MonthlyReport, error) {
+	var report models.MonthlyReport
+	if err := s.db.WithContext(ctx).First(&report, id).Error; err != nil {
+		return nil, fmt.Errorf("failed to get monthly report: %w", err)
+	}
+	if report.FinalizedAt != nil {
+		return &report, nil
+	}
+	report.FinalizedAt = &now
+	if err := s.db.WithContext(ctx).Model(&report).Update("finalized_at", now).Error; err != nil {
+		return nil, fmt.Errorf("failed to finalize monthly report: %w", err)
+	}
+	return &report, nil
+}
+
+// findMonthlyReport returns the report for serviceID and month, or nil.
+func (s *MonthlyReportService) findMonthlyReport(ctx context.Context, serviceID int, month time.Time) (*models.MonthlyReport, error) {
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	var report models.MonthlyReport
+	err := s.db.WithContext(ctx).
+		Where("service_id = ? AND report_month >= ? AND report_month < ?", serviceID, start, start.AddDate(0, 1, 0)).
+		First(&report).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get monthly report: %w", err)
+	}
+	return &report, nil
+}
+
+// aggregateMonth computes an unsaved report for the month from daily
+// reports, falling back to raw uptime logs when there are none. Time entries
+// logged on the service become its activities and maintenance hours.
+func (s *MonthlyReportService) aggregateMonth(ctx context.Context, serviceID int, month time.Time) (*models.MonthlyReport, error) {
 	startOfMonth := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
 	endOfMonth := startOfMonth.AddDate(0, 1, 0).Add(-time.Nanosecond)
+	report := &models.MonthlyReport{ReportMonth: startOfMonth, ServiceID: serviceID}
 
-	// Query daily reports for the month
 	var dailyReports []models.DailyReport
 	if err := s.db.WithContext(ctx).
 		Where("service_id = ? AND report_date BETWEEN ? AND ?", serviceID, startOfMonth, endOfMonth).
 		Find(&dailyReports).Error; err != nil {
 		return nil, fmt.Errorf("failed to query daily reports: %w", err)
 	}
+	if len(dailyReports) > 0 {
+		var totalUptime float64
+		var totalResponse int
+		for _, d := range dailyReports {
+			totalUptime += d.UptimePercent
+			totalResponse += d.AvgResponseMs
+			report.TotalDowntime += d.DowntimeCount
+		}
+		report.AvgUptimePercent = totalUptime / float64(len(dailyReports))
+		report.AvgResponseMs = totalResponse / len(dailyReports)
+	} else {
+		var logs []models.UptimeLog
+		if err := s.db.WithContext(ctx).
+			Where("service_id = ? AND checked_at BETWEEN ? AND ?", serviceID, startOfMonth, endOfMonth).
+			Find(&logs).Error; err != nil {
+			return nil, fmt.Errorf("failed to query uptime logs: %w", err)
+		}
+		if len(logs) == 0 {
+			return nil, fmt.Errorf("%w: no data found for service %d in %s", ErrNoReportData, serviceID, month.Format("2006-01"))
+		}
+		ups, sumResp := 0, 0
+		for _, l := range logs {
+			if l.Status == "up" {
+				ups++
+			} else {
+				report.TotalDowntime++
+			}
+			sumResp += l.ResponseTime
+		}
+		report.AvgUptimePercent = float64(ups) / float64(len(logs)) * 100.0
+		report.AvgResponseMs = sumResp / len(logs)
+	}
 
-    if len(dailyReports) == 0 {
-        // Fallback: aggregate directly from uptime logs for the month
-        var logs []models.UptimeLog
-        if err := s.db.WithContext(ctx).
-            Where("service_id = ? AND checked_at BETWEEN ? AND ?", serviceID, startOfMonth, endOfMonth).
-            Find(&logs).Error; err != nil {
-            return nil, fmt.Errorf("failed to query uptime logs: %w", err)
-        }
-        if len(logs) == 0 {
-            return nil, fmt.Errorf("no data found for service %d in %s", serviceID, month.Format("2006-01"))
-        }
-
-        // Aggregate monthly metrics from logs
-        total := len(logs)
-        ups := 0
-        sumResp := 0
-        downs := 0
-        for _, l := range logs {
-            if l.Status == "up" {
-                ups++
-            } else {
-                downs++
-            }
-            sumResp += l.ResponseTime
-        }
-        avgUptime := (float64(ups) / float64(total)) * 100.0
-        avgResp := sumResp / total
-
-        // Alerts within month
-        var alerts []models.Alert
-        _ = s.db.WithContext(ctx).Where("service_id = ? AND created_at >= ? AND created_at <= ? AND alert_type IN (?)",
-            serviceID, startOfMonth, endOfMonth, []string{"uptime", "ssl_expiry", "domain_expiry"},
-        ).Find(&alerts).Error
-        var unresolved int64
-        _ = s.db.WithContext(ctx).Model(&models.Alert{}).Where("service_id = ? AND resolved_at IS NULL", serviceID).Count(&unresolved).Error
-
-        monthlyReport := &models.MonthlyReport{
-            ReportMonth:      startOfMonth,
-            ServiceID:        serviceID,
-            AvgUptimePercent: avgUptime,
-            AvgResponseMs:    avgResp,
-            TotalDowntime:    downs,
-            AlertsOpened:     len(alerts),
-            AlertsResolved:   int(unresolved),
-            MaintenanceHours: 0,
-        }
-
-        // Default activities (can be overridden by handler when provided)
-        activities := []string{"Monthly aggregation from raw logs"}
-        if b, err := json.Marshal(activities); err == nil {
-            monthlyReport.Activities = string(b)
-        }
-        if err := s.db.WithContext(ctx).Create(monthlyReport).Error; err != nil {
-            return nil, fmt.Errorf("failed to save monthly report: %w", err)
-        }
-        return monthlyReport, nil
-    }
-
-	// Aggregate metrics
-	var (
-		totalUptime       float64
-		totalResponseTime int
-		totalDowntime     int
-		alertsOpened      int
-		alertsResolved    int
-		activities        []string
-	)
-
-	for _, report := range dailyReports {
-		totalUptime += report.UptimePercent
-		totalResponseTime += report.AvgResponseMs
-		totalDowntime += report.DowntimeCount
-		alertsOpened += report.AlertsOpened
-		alertsResolved += report.AlertsUnresolved
-	}
-
-	// Calculate averages
-	avgUptime := totalUptime / float64(len(dailyReports))
-	avgResponse := totalResponseTime / len(dailyReports)
-
-	// Create monthly report
-	monthlyReport := &models.MonthlyReport{
-		ReportMonth:      startOfMonth,
-		ServiceID:        serviceID,
-		AvgUptimePercent: avgUptime,
-		AvgResponseMs:    avgResponse,
-		TotalDowntime:    totalDowntime,
-		AlertsOpened:     alertsOpened,
-		AlertsResolved:   alertsResolved,
-		MaintenanceHours: 0, // Placeholder for actual maintenance data
-	}
-
-	// Collect maintenance activities
-	activities = []string{
-		"Server maintenance: 2 hours",
-		"Security updates applied",
-		"Performance optimization",
-	}
-	activitiesJSON, _ := json.Marshal(activities)
-	monthlyReport.Activities = string(activitiesJSON)
-
-	// Save to database
-	if err := s.db.WithContext(ctx).Create(monthlyReport).Error; err != nil {
-		return nil, fmt.Errorf("failed to save monthly report: %w", err)
+	// Alerts opened during the month, and alerts resolved during the month.
+	alertTypes := []string{"uptime", "ssl_expiry", "domain_expiry"}
+	var opened, resolved int64
+	if err := s.db.WithContext(ctx).Model(&models.Alert{}).
+		Where("service_id = ? AND created_at BETWEEN ? AND ? AND alert_type IN (?)", serviceID, startOfMonth, endOfMonth, alertTypes).
+		Count(&opened).Error; err != nil {
+		return nil, fmt.Errorf("failed to count alerts: %w", err)
 	}
+	if err := s.db.WithContext(ctx).Model(&models.Alert{}).
+		Where("service_id = ? AND resolved_at BETWEEN ? AND ? AND alert_type IN (?)", serviceID, startOfMonth, endOfMonth, alertTypes).
+		Count(&resolved).Error; err != nil {
+		return nil, fmt.Errorf("failed to count alerts: %w", err)
+	}
+	report.AlertsOpened = int(opened)
+	report.AlertsResolved = int(resolved)
 
-	return monthlyReport, nil
+	entries, err := serviceMonthEntries(s.db.WithContext(ctx), serviceID, startOfMonth)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) > 0 {
+		minutes := 0
+		for _, e := range entries {
+			minutes += e.Minutes
+		}
+		report.MaintenanceHours = roundMoney(float64(minutes) / 60)
+		b, _ := json.Marshal(timeEntryActivities(entries))
+		report.Activities = string(b)
+	}
+	return report, nil
 }
 
 // GetMonthlyReports retrieves monthly reports for a service
@@ -160,6 +239,17 @@ func (s *MonthlyReportService) SetMonthlyReportUser(ctx context.Context, id int,
     return s.db.WithContext(ctx).Model(&models.MonthlyReport{}).Where("id = ?", id).Update("user_id", userID).Error
 }
 
+// ClaimMonthlyReport returns report id for userID, first assigning it to
+// userID when it has no owner. A report owned by another user is not found.
+func (s *MonthlyReportService) ClaimMonthlyReport(ctx context.Context, id int, userID int) (*models.MonthlyReport, error) {
+    if userID > 0 {
+        if err := s.db.WithContext(ctx).Model(&models.MonthlyReport{}).Where("id = ? AND user_id = 0", id).Update("user_id", userID).Error; err != nil {
+            return nil, err
+        }
+    }
+    return s.GetMonthlyReportByIDForUser(ctx, userID, id)
+}
+
 // GetMonthlyReportsForUser scopes listing to a specific user.
 func (s *MonthlyReportService) GetMonthlyReportsForUser(ctx context.Context, userID int, serviceID int) ([]models.MonthlyReport, error) {
     var reports []models.MonthlyReport
@@ -188,6 +278,9 @@ func (s *MonthlyReportService) UpdateMonthlyDetails(ctx context.Context, id int,
     if err := s.db.WithContext(ctx).First(&report, id).Error; err != nil {
         return fmt.Errorf("failed to get monthly report: %w", err)
     }
+    if report.FinalizedAt != nil {
+        return ErrReportFinalized
+    }
     if len(activities) > 0 {
         if b, err := json.Marshal(activities); err == nil {
             report.Activities = string(b)
@@ -208,6 +301,9 @@ func (s *MonthlyReportService) UpdateMonthlyDetailsRaw(ctx context.Context, id i
     if err := s.db.WithContext(ctx).First(&report, id).Error; err != nil {
         return fmt.Errorf("failed to get monthly report: %w", err)
     }
+    if report.FinalizedAt != nil {
+        return ErrReportFinalized
+    }
     if strings.TrimSpace(activitiesJSON) != "" {
         report.Activities = activitiesJSON
     }
@@ -226,6 +322,9 @@ func (s *MonthlyReportService) UpdateMonthlySummary(ctx context.Context, id int,
     if err := s.db.WithContext(ctx).First(&report, id).Error; err != nil {
         return fmt.Errorf("failed to get monthly report: %w", err)
     }
+    if report.FinalizedAt != nil {
+        return ErrReportFinalized
+    }
     report.Summary = strings.TrimSpace(summary)
     if err := s.db.WithContext(ctx).Save(&report).Error; err != nil {
         return fmt.Errorf("failed to update monthly report: %w", err)
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestGenerateMonthlyReportIsIdempotent(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	if err := db.AutoMigrate(&models.MonthlyReport{}, &models.DailyReport{}, &models.UptimeLog{}, &models.Alert{}, &models.TimeEntry{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	ctx := context.Background()
+	feb := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	for d := 1; d <= 2; d++ {
+		db.Create(&models.DailyReport{ServiceID: 1, ReportDate: feb.AddDate(0, 0, d), UptimePercent: 99, AvgResponseMs: 100, DowntimeCount: 1, AlertsOpened: 4, AlertsUnresolved: 3})
+	}
+	resolved := feb.AddDate(0, 0, 3)
+	db.Create(&models.Alert{ServiceID: 1, AlertType: "uptime", Level: "critical", Title: "down", CreatedAt: feb.AddDate(0, 0, 2), ResolvedAt: &resolved, IsResolved: true})
+	db.Create(&models.Alert{ServiceID: 1, AlertType: "uptime", Level: "critical", Title: "down again", CreatedAt: feb.AddDate(0, 0, 5)})
+	s := NewMonthlyReportService(db)
+
+	first, err := s.GenerateMonthlyReport(ctx, 1, feb.AddDate(0, 0, 14))
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if first.AlertsOpened != 2 || first.AlertsResolved != 1 {
+		t.Fatalf("alert counts: opened %d resolved %d", first.AlertsOpened, first.AlertsResolved)
+	}
+	if first.Activities != "" || first.TotalDowntime != 2 {
+		t.Fatalf("unexpected report: %+v", first)
+	}
+	if err := s.UpdateMonthlySummary(ctx, first.ID, "Edited summary"); err != nil {
+		t.Fatalf("summary: %v", err)
+	}
+	if err := s.UpdateMonthlyDetails(ctx, first.ID, []string{"Migrated DB"}, 3); err != nil {
+		t.Fatalf("details: %v", err)
+	}
+
+	again, err := s.GenerateMonthlyReport(ctx, 1, feb)
+	if err != nil || again.ID != first.ID || again.Summary != "Edited summary" {
+		t.Fatalf("expected the existing report back, got %+v %v", again, err)
+	}
+	var count int64
+	db.Model(&models.MonthlyReport{}).Count(&count)
+	if count != 1 {
+		t.Fatalf("expected one report, got %d", count)
+	}
+
+	db.Model(&models.Alert{}).Where("title = ?", "down again").Updates(map[string]interface{}{"resolved_at": feb.AddDate(0, 0, 6), "is_resolved": true})
+	db.Create(&models.DailyReport{ServiceID: 1, ReportDate: feb.AddDate(0, 0, 3), UptimePercent: 96, AvgResponseMs: 400, DowntimeCount: 5})
+	regen, err := s.RegenerateMonthlyReport(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("regenerate: %v", err)
+	}
+	if regen.AlertsResolved != 2 || regen.TotalDowntime != 7 || regen.AvgResponseMs != 200 {
+		t.Fatalf("metrics not recomputed: %+v", regen)
+	}
+	if regen.Summary != "Edited summary" || regen.Activities != `["Migrated DB"]` || regen.MaintenanceHours != 3 {
+		t.Fatalf("edits lost on regenerate: %+v", regen)
+	}
+
+	now := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+	if _, err := s.FinalizeMonthlyReport(ctx, first.ID, now); err != nil {
+		t.Fatalf("finalize: %v", err)
+	}
+	if _, err := s.RegenerateMonthlyReport(ctx, first.ID); !errors.Is(err, ErrReportFinalized) {
+		t.Fatalf("expected regenerate of a finalized report to fail, got %v", err)
+	}
+	if err := s.UpdateMonthlySummary(ctx, first.ID, "late edit"); !errors.Is(err, ErrReportFinalized) {
+		t.Fatalf("expected edits of a finalized report to fail, got %v", err)
+	}
+	again, err = s.FinalizeMonthlyReport(ctx, first.ID, now.Add(time.Hour))
+	if err != nil || !again.FinalizedAt.Equal(now) {
+		t.Fatalf("expected the original finalize time to be kept: %+v %v", again, err)
+	}
+
+	if _, err := s.GenerateMonthlyReport(ctx, 2, feb); !errors.Is(err, ErrNoReportData) {
+		t.Fatalf("expected ErrNoReportData, got %v", err)
+	}
+}
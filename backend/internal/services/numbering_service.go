@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NumberingSettings is the offer numbering configuration. Next is the value
+// the next offer will get (ignoring a pending yearly reset) and Preview the
+// number it would render to today.
+type NumberingSettings struct {
+	Format      string `json:"format"`
+	ResetYearly bool   `json:"reset_yearly"`
+	Next        int    `json:"next"`
+	Preview     string `json:"preview"`
+}
+
+// NumberingInput updates the offer numbering format and reset policy.
+type NumberingInput struct {
+	Format      string `json:"format"`
+	ResetYearly bool   `json:"reset_yearly"`
+}
+
+// NumberingService reads and changes the offer number sequence settings.
+type NumberingService struct {
+	db *gorm.DB
+}
+
+func NewNumberingService(db *gorm.DB) *NumberingService {
+	return &NumberingService{db: db}
+}
+
+// Get returns the offer numbering settings, creating the default sequence.
+func (s *NumberingService) Get(ctx context.Context, now time.Time) (*NumberingSettings, error) {
+	seq, err := s.sequence(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return numberingSettings(seq, now), nil
+}
+
+// Update changes the format and yearly reset. The counter itself is kept, so
+// numbers stay unique across a format change.
+func (s *NumberingService) Update(ctx context.Context, in NumberingInput, now time.Time) (*NumberingSettings, error) {
+	in.Format = strings.TrimSpace(in.Format)
+	if err := models.ValidateNumberFormat(in.Format); err != nil {
+		return nil, FieldErrors{"format": err.Error()}
+	}
+	// Without the year in the number, a yearly reset repeats last year's
+	// numbers, which collide with the unique offer_number.
+	if in.ResetYearly && !strings.Contains(in.Format, "{yyyy}") && !strings.Contains(in.Format, "{yy}") {
+		return nil, FieldErrors{"reset_yearly": "requires {yyyy} or {yy} in the format"}
+	}
+	seq, err := s.sequence(ctx)
+	if err != nil {
+		return nil, err
+	}
+	updates := map[string]interface{}{"format": in.Format, "reset_yearly": in.ResetYearly}
+	if in.ResetYearly && !seq.ResetYearly {
+		// Start the yearly count in the current year rather than resetting
+		// on the next offer.
+		updates["period"] = now.Year()
+	}
+	if err := s.db.WithContext(ctx).Model(seq).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	if seq, err = s.sequence(ctx); err != nil {
+		return nil, err
+	}
+	return numberingSettings(seq, now), nil
+}
+
+func (s *NumberingService) sequence(ctx context.Context) (*models.NumberSequence, error) {
+	db := s.db.WithContext(ctx)
+	seed := models.NumberSequence{Workspace: models.DefaultWorkspace, Name: models.SequenceOffer, Format: models.DefaultOfferNumberFormat}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&seed).Error; err != nil {
+		return nil, err
+	}
+	var seq models.NumberSequence
+	if err := db.Where("workspace = ? AND name = ?", models.DefaultWorkspace, models.SequenceOffer).First(&seq).Error; err != nil {
+		return nil, err
+	}
+	return &seq, nil
+}
+
+func numberingSettings(seq *models.NumberSequence, now time.Time) *NumberingSettings {
+	next := seq.Value + 1
+	if seq.ResetYearly && seq.Period != now.Year() {
+		next = 1
+	}
+	return &NumberingSettings{
+		Format:      seq.Format,
+		ResetYearly: seq.ResetYearly,
+		Next:        next,
+		Preview:     models.FormatNumber(seq.Format, next, now, "ABC"),
+	}
+}
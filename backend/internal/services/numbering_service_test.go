@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestNumberingServiceUpdate(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Offer{}, &models.OfferItem{}, &models.NumberSequence{}, &models.OfferStatusChange{}, &models.Client{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	ctx := context.Background()
+	now := time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)
+	svc := NewNumberingService(db)
+
+	got, err := svc.Get(ctx, now)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Format != models.DefaultOfferNumberFormat || got.Next != 1 || got.Preview != "001/MSI-04/05/2026" {
+		t.Fatalf("unexpected defaults: %+v", got)
+	}
+	if _, err := svc.Update(ctx, NumberingInput{Format: "{nope}"}, now); err == nil {
+		t.Fatalf("expected invalid format to be rejected")
+	}
+	var fe FieldErrors
+	if _, err := svc.Update(ctx, NumberingInput{Format: "{seq:3}/MSI", ResetYearly: true}, now); !errors.As(err, &fe) || fe["reset_yearly"] == "" {
+		t.Fatalf("expected a yearly reset without a year to be rejected, got %v", err)
+	}
+	if _, err := svc.Update(ctx, NumberingInput{Format: "{client}-{yyyy}-{seq:4}", ResetYearly: true}, now); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	client := models.Client{Name: "Acme Corp", Code: "acm"}
+	if err := db.Create(&client).Error; err != nil {
+		t.Fatalf("client: %v", err)
+	}
+	offer := &models.Offer{ClientID: client.ID, Subject: "Audit", Date: now}
+	if err := NewOfferService(db).CreateOffer(offer); err != nil {
+		t.Fatalf("create offer: %v", err)
+	}
+	if offer.OfferNumber != "ACM-2026-0001" {
+		t.Fatalf("unexpected offer number %q", offer.OfferNumber)
+	}
+}
+
+func TestOfferNumbersUniqueUnderConcurrency(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "offers.db") + "?_busy_timeout=5000&_txlock=immediate"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Offer{}, &models.OfferItem{}, &models.NumberSequence{}, &models.OfferStatusChange{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	svc := NewOfferService(db)
+
+	const n = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- svc.CreateOffer(&models.Offer{ClientID: 1, Subject: fmt.Sprintf("Offer %d", i)})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("create offer: %v", err)
+		}
+	}
+
+	var numbers []string
+	if err := db.Model(&models.Offer{}).Pluck("offer_number", &numbers).Error; err != nil {
+		t.Fatalf("pluck: %v", err)
+	}
+	seen := map[string]bool{}
+	for _, num := range numbers {
+		if seen[num] {
+			t.Fatalf("duplicate offer number %q", num)
+		}
+		seen[num] = true
+	}
+	var seq models.NumberSequence
+	if err := db.First(&seq).Error; err != nil {
+		t.Fatalf("sequence: %v", err)
+	}
+	if len(numbers) != n || seq.Value != n {
+		t.Fatalf("expected %d offers numbered 1..%d, got %d offers and sequence %d", n, n, len(numbers), seq.Value)
+	}
+}
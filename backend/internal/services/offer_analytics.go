@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/gorm"
+)
+
+// OfferAnalyticsFilter scopes the pipeline analytics. From/To bound the offer
+// date for the pipeline, win rate and time to accept, and the accept date for
+// revenue; zero values leave that side open. ExpiringDays (default 14) is the
+// look-ahead for expiring-soon offers from Now.
+type OfferAnalyticsFilter struct {
+	From         time.Time
+	To           time.Time
+	ClientID     int
+	ExpiringDays int
+	Now          time.Time
+}
+
+// StatusValue is the number and base-currency value of offers in a status.
+type StatusValue struct {
+	Status string  `json:"status"`
+	Count  int     `json:"count"`
+	Value  float64 `json:"value"`
+}
+
+// ClientRevenue is accepted value for one client.
+type ClientRevenue struct {
+	ClientID   int     `json:"client_id"`
+	ClientName string  `json:"client_name"`
+	Count      int     `json:"count"`
+	Value      float64 `json:"value"`
+}
+
+// MonthRevenue is accepted value for one month (YYYY-MM).
+type MonthRevenue struct {
+	Month string  `json:"month"`
+	Count int     `json:"count"`
+	Value float64 `json:"value"`
+}
+
+// OfferAnalytics summarizes how offers perform. Values are in BaseCurrency.
+// WinRate is accepted / (accepted + rejected + expired) and is 0 when nothing
+// was decided; AvgDaysToAccept runs from the first send to acceptance.
+type OfferAnalytics struct {
+	BaseCurrency    string          `json:"base_currency"`
+	Total           int             `json:"total"`
+	Decided         int             `json:"decided"`
+	Won             int             `json:"won"`
+	WinRate         float64         `json:"win_rate"`
+	AvgDaysToAccept float64         `json:"avg_days_to_accept"`
+	Pipeline        []StatusValue   `json:"pipeline"`
+	RevenueByClient []ClientRevenue `json:"revenue_by_client"`
+	RevenueByMonth  []MonthRevenue  `json:"revenue_by_month"`
+	ExpiringSoon    StatusValue     `json:"expiring_soon"`
+	Unconverted     []string        `json:"unconverted"`
+}
+
+// OfferAnalyticsService computes sales pipeline analytics from offers and
+// their status history.
+type OfferAnalyticsService struct {
+	db *gorm.DB
+}
+
+func NewOfferAnalyticsService(db *gorm.DB) *OfferAnalyticsService {
+	return &OfferAnalyticsService{db: db}
+}
+
+// Analytics computes the pipeline analytics for f.
+func (s *OfferAnalyticsService) Analytics(ctx context.Context, f OfferAnalyticsFilter) (*OfferAnalytics, error) {
+	db := s.db.WithContext(ctx)
+	if f.Now.IsZero() {
+		f.Now = time.Now()
+	}
+	if f.ExpiringDays <= 0 {
+		f.ExpiringDays = 14
+	}
+	conv := &revenueConverter{db: db, cache: map[string]float64{}, missing: map[string]bool{}}
+	value := func(o models.Offer, at time.Time) (float64, error) {
+		return conv.convert(o.TotalPrice, nonEmpty(normalizeCurrency(o.Currency), BaseCurrency()), o.ExchangeRate, at)
+	}
+	out := &OfferAnalytics{BaseCurrency: BaseCurrency(), Pipeline: []StatusValue{}, RevenueByClient: []ClientRevenue{},
+		RevenueByMonth: []MonthRevenue{}, ExpiringSoon: StatusValue{Status: "expiring_soon"}, Unconverted: []string{}}
+
+	// Pipeline, win rate and time to accept over offers dated in the range.
+	var cohort []models.Offer
+	if err := s.scoped(db, f, "date").Find(&cohort).Error; err != nil {
+		return nil, err
+	}
+	byStatus := map[string]*StatusValue{}
+	var acceptedIDs []int
+	for _, o := range cohort {
+		status := nonEmpty(o.Status, models.OfferDraft)
+		if byStatus[status] == nil {
+			byStatus[status] = &StatusValue{Status: status}
+		}
+		v, err := value(o, o.Date)
+		if err != nil {
+			return nil, err
+		}
+		byStatus[status].Count++
+		byStatus[status].Value += v
+		switch status {
+		case models.OfferAccepted:
+			out.Won++
+			out.Decided++
+			acceptedIDs = append(acceptedIDs, o.ID)
+		case models.OfferRejected, models.OfferExpired:
+			out.Decided++
+		}
+	}
+	out.Total = len(cohort)
+	for _, status := range []string{models.OfferDraft, models.OfferSent, models.OfferViewed, models.OfferAccepted,
+		models.OfferRejected, models.OfferExpired, models.OfferSuperseded} {
+		if sv := byStatus[status]; sv != nil {
+			sv.Value = roundMoney(sv.Value)
+			out.Pipeline = append(out.Pipeline, *sv)
+		}
+	}
+	if out.Decided > 0 {
+		out.WinRate = float64(out.Won) / float64(out.Decided)
+	}
+	avg, err := s.avgDaysToAccept(db, acceptedIDs)
+	if err != nil {
+		return nil, err
+	}
+	out.AvgDaysToAccept = avg
+
+	// Revenue by client and month over offers accepted in the range.
+	var won []models.Offer
+	q := s.scoped(db, f, "approved_at").Where("status = ? AND approved_at IS NOT NULL", models.OfferAccepted)
+	if err := q.Find(&won).Error; err != nil {
+		return nil, err
+	}
+	byClient := map[int]*ClientRevenue{}
+	byMonth := map[string]*MonthRevenue{}
+	for _, o := range won {
+		v, err := value(o, *o.ApprovedAt)
+		if err != nil {
+			return nil, err
+		}
+		if byClient[o.ClientID] == nil {
+			byClient[o.ClientID] = &ClientRevenue{ClientID: o.ClientID}
+		}
+		byClient[o.ClientID].Count++
+		byClient[o.ClientID].Value += v
+		month := o.ApprovedAt.Format("2006-01")
+		if byMonth[month] == nil {
+			byMonth[month] = &MonthRevenue{Month: month}
+		}
+		byMonth[month].Count++
+		byMonth[month].Value += v
+	}
+	if len(byClient) > 0 {
+		ids := make([]int, 0, len(byClient))
+		for id := range byClient {
+			ids = append(ids, id)
+		}
+		var clients []models.Client
+		if err := db.Where("id IN ?", ids).Find(&clients).Error; err != nil {
+			return nil, err
+		}
+		for _, c := range clients {
+			byClient[c.ID].ClientName = c.Name
+		}
+	}
+	for _, cr := range byClient {
+		cr.Value = roundMoney(cr.Value)
+		out.RevenueByClient = append(out.RevenueByClient, *cr)
+	}
+	sort.Slice(out.RevenueByClient, func(i, j int) bool {
+		a, b := out.RevenueByClient[i], out.RevenueByClient[j]
+		return a.Value > b.Value || (a.Value == b.Value && a.ClientID < b.ClientID)
+	})
+	for _, mr := range byMonth {
+		mr.Value = roundMoney(mr.Value)
+		out.RevenueByMonth = append(out.RevenueByMonth, *mr)
+	}
+	sort.Slice(out.RevenueByMonth, func(i, j int) bool { return out.RevenueByMonth[i].Month < out.RevenueByMonth[j].Month })
+
+	// Open offers whose validity ends within the look-ahead.
+	var expiring []models.Offer
+	q = db.Where("status IN ? AND valid_until IS NOT NULL AND valid_until >= ? AND valid_until < ?",
+		[]string{models.OfferSent, models.OfferViewed}, f.Now, f.Now.AddDate(0, 0, f.ExpiringDays))
+	if f.ClientID > 0 {
+		q = q.Where("client_id = ?", f.ClientID)
+	}
+	if err := q.Find(&expiring).Error; err != nil {
+		return nil, err
+	}
+	for _, o := range expiring {
+		v, err := value(o, f.Now)
+		if err != nil {
+			return nil, err
+		}
+		out.ExpiringSoon.Count++
+		out.ExpiringSoon.Value += v
+	}
+	out.ExpiringSoon.Value = roundMoney(out.ExpiringSoon.Value)
+
+	for c := range conv.missing {
+		out.Unconverted = append(out.Unconverted, c)
+	}
+	sort.Strings(out.Unconverted)
+	return out, nil
+}
+
+func (s *OfferAnalyticsService) scoped(db *gorm.DB, f OfferAnalyticsFilter, dateColumn string) *gorm.DB {
+	q := db.Model(&models.Offer{})
+	if f.ClientID > 0 {
+		q = q.Where("client_id = ?", f.ClientID)
+	}
+	if !f.From.IsZero() {
+		q = q.Where(dateColumn+" >= ?", f.From)
+	}
+	if !f.To.IsZero() {
+		q = q.Where(dateColumn+" < ?", f.To)
+	}
+	return q
+}
+
+// avgDaysToAccept averages, over the given accepted offers, the days from the
+// first move to sent to the last move to accepted. Offers without both in
+// their history are skipped.
+func (s *OfferAnalyticsService) avgDaysToAccept(db *gorm.DB, offerIDs []int) (float64, error) {
+	if len(offerIDs) == 0 {
+		return 0, nil
+	}
+	var changes []models.OfferStatusChange
+	if err := db.Where("offer_id IN ? AND to_status IN ?", offerIDs, []string{models.OfferSent, models.OfferAccepted}).
+		Order("id").Find(&changes).Error; err != nil {
+		return 0, err
+	}
+	sent := map[int]time.Time{}
+	accepted := map[int]time.Time{}
+	for _, ch := range changes {
+		switch ch.ToStatus {
+		case models.OfferSent:
+			if _, seen := sent[ch.OfferID]; !seen {
+				sent[ch.OfferID] = ch.CreatedAt
+			}
+		case models.OfferAccepted:
+			accepted[ch.OfferID] = ch.CreatedAt
+		}
+	}
+	var total float64
+	n := 0
+	for id, at := range accepted {
+		from, ok := sent[id]
+		if !ok || at.Before(from) {
+			continue
+		}
+		total += at.Sub(from).Hours() / 24
+		n++
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	return roundMoney(total / float64(n)), nil
+}
+
+// WriteCSV writes the analytics as section,key,label,count,value rows.
+func (a *OfferAnalytics) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	money := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	_ = cw.Write([]string{"section", "key", "label", "count", "value"})
+	_ = cw.Write([]string{"summary", "win_rate", "accepted / decided", fmt.Sprintf("%d/%d", a.Won, a.Decided), strconv.FormatFloat(a.WinRate, 'f', 4, 64)})
+	_ = cw.Write([]string{"summary", "avg_days_to_accept", "days from sent to accepted", strconv.Itoa(a.Won), money(a.AvgDaysToAccept)})
+	_ = cw.Write([]string{"summary", "expiring_soon", "open offers expiring soon (" + a.BaseCurrency + ")", strconv.Itoa(a.ExpiringSoon.Count), money(a.ExpiringSoon.Value)})
+	for _, p := range a.Pipeline {
+		_ = cw.Write([]string{"pipeline", p.Status, p.Status + " (" + a.BaseCurrency + ")", strconv.Itoa(p.Count), money(p.Value)})
+	}
+	for _, c := range a.RevenueByClient {
+		_ = cw.Write([]string{"revenue_by_client", strconv.Itoa(c.ClientID), c.ClientName, strconv.Itoa(c.Count), money(c.Value)})
+	}
+	for _, m := range a.RevenueByMonth {
+		_ = cw.Write([]string{"revenue_by_month", m.Month, m.Month, strconv.Itoa(m.Count), money(m.Value)})
+	}
+	for _, cur := range a.Unconverted {
+		_ = cw.Write([]string{"unconverted", cur, "no exchange rate; counted as 0", "", ""})
+	}
+	cw.Flush()
+	return cw.Error()
+}
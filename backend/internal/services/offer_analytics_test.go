@@ -0,0 +1,97 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+)
+
+func TestOfferAnalytics(t *testing.T) {
+	db := newInvoiceTestDB(t)
+	ctx := context.Background()
+	offers := NewOfferService(db)
+	acme := models.Client{Name: "Acme"}
+	globex := models.Client{Name: "Globex"}
+	db.Create(&acme)
+	db.Create(&globex)
+
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 9, 0, 0, 0, time.UTC) }
+	create := func(clientID int, price float64, date time.Time) *models.Offer {
+		o := &models.Offer{ClientID: clientID, Subject: "Work", Date: date,
+			Items: models.OfferItems{{Description: "Work", Qty: 1, UnitPrice: price}}}
+		if err := offers.CreateOffer(o); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		return o
+	}
+	move := func(o *models.Offer, to string, at time.Time) {
+		if _, err := offers.TransitionOffer(o.ID, to, nil, "", at); err != nil {
+			t.Fatalf("%s: %v", to, err)
+		}
+	}
+
+	a := create(acme.ID, 1000, day(1, 5))
+	move(a, models.OfferSent, day(1, 6))
+	move(a, models.OfferAccepted, day(1, 10)) // 4 days
+	b := create(globex.ID, 3000, day(1, 20))
+	move(b, models.OfferSent, day(1, 20))
+	move(b, models.OfferAccepted, day(2, 1)) // 12 days
+	c := create(acme.ID, 500, day(2, 2))
+	move(c, models.OfferSent, day(2, 2))
+	move(c, models.OfferRejected, day(2, 3))
+	d := create(acme.ID, 700, day(2, 4))
+	move(d, models.OfferSent, day(2, 4))
+	valid := day(2, 12)
+	db.Model(d).Update("valid_until", valid)
+	create(globex.ID, 200, day(2, 5))
+	create(globex.ID, 9999, day(3, 5)) // outside the range
+
+	svc := NewOfferAnalyticsService(db)
+	out, err := svc.Analytics(ctx, OfferAnalyticsFilter{From: day(1, 1), To: day(3, 1), Now: day(2, 6)})
+	if err != nil {
+		t.Fatalf("analytics: %v", err)
+	}
+	if out.Total != 5 || out.Won != 2 || out.Decided != 3 || out.WinRate < 0.66 || out.WinRate > 0.67 {
+		t.Fatalf("unexpected win rate: %+v", out)
+	}
+	if out.AvgDaysToAccept != 8 {
+		t.Fatalf("expected 8 days to accept, got %v", out.AvgDaysToAccept)
+	}
+	want := map[string]float64{models.OfferDraft: 200, models.OfferSent: 700, models.OfferAccepted: 4000, models.OfferRejected: 500}
+	if len(out.Pipeline) != len(want) {
+		t.Fatalf("unexpected pipeline: %+v", out.Pipeline)
+	}
+	for _, p := range out.Pipeline {
+		if want[p.Status] != p.Value {
+			t.Fatalf("pipeline %s = %v, want %v", p.Status, p.Value, want[p.Status])
+		}
+	}
+	if len(out.RevenueByClient) != 2 || out.RevenueByClient[0].ClientName != "Globex" || out.RevenueByClient[0].Value != 3000 {
+		t.Fatalf("unexpected revenue by client: %+v", out.RevenueByClient)
+	}
+	if len(out.RevenueByMonth) != 2 || out.RevenueByMonth[0].Month != "2026-01" || out.RevenueByMonth[1].Value != 3000 {
+		t.Fatalf("unexpected revenue by month: %+v", out.RevenueByMonth)
+	}
+	if out.ExpiringSoon.Count != 1 || out.ExpiringSoon.Value != 700 {
+		t.Fatalf("unexpected expiring soon: %+v", out.ExpiringSoon)
+	}
+
+	out, err = svc.Analytics(ctx, OfferAnalyticsFilter{ClientID: acme.ID, Now: day(2, 6)})
+	if err != nil {
+		t.Fatalf("analytics: %v", err)
+	}
+	if out.Total != 3 || len(out.RevenueByClient) != 1 || out.RevenueByClient[0].ClientID != acme.ID {
+		t.Fatalf("client filter not applied: %+v", out)
+	}
+	var buf bytes.Buffer
+	if err := out.WriteCSV(&buf); err != nil {
+		t.Fatalf("csv: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "section,key,label,count,value\n") || !strings.Contains(buf.String(), "revenue_by_client,1,Acme,1,1000.00") {
+		t.Fatalf("unexpected csv:\n%s", buf.String())
+	}
+}
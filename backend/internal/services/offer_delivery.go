@@ -0,0 +1,322 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrOfferLinkNotFound is returned for unknown public offer tokens.
+	ErrOfferLinkNotFound = errors.New("offer link not found")
+	// ErrOfferLinkExpired is returned once a public offer link has expired.
+	ErrOfferLinkExpired = errors.New("offer link has expired")
+	// ErrOfferLinkClosed is returned when responding through a link whose
+	// revision was replaced or whose offer is no longer awaiting a decision.
+	ErrOfferLinkClosed = errors.New("this offer can no longer be accepted or rejected through this link")
+)
+
+// SendOfferInput configures an offer email. To defaults to the client's email;
+// ExpiresInDays defaults to OFFER_LINK_TTL_DAYS (30).
+type SendOfferInput struct {
+	To            string `json:"to"`
+	Message       string `json:"message"`
+	ExpiresInDays int    `json:"expires_in_days"`
+}
+
+// OfferDelivery is the result of emailing an offer. URL holds the raw token
+// and is only available here.
+type OfferDelivery struct {
+	Offer *models.Offer    `json:"offer"`
+	Link  models.OfferLink `json:"link"`
+	URL   string           `json:"url"`
+}
+
+// PublicOffer is the read-only view behind a public link.
+type PublicOffer struct {
+	Offer      *models.Offer          `json:"offer"` // the revision as sent
+	Status     string                 `json:"status"`
+	Revision   int                    `json:"revision"`
+	ExpiresAt  time.Time              `json:"expires_at"`
+	CanRespond bool                   `json:"can_respond"`
+	Signature  *models.OfferSignature `json:"signature,omitempty"`
+}
+
+// OfferResponseInput is the client's decision. Name is required to accept,
+// Reason to reject.
+type OfferResponseInput struct {
+	Decision string
+	Name     string
+	Reason   string
+	IP       string
+	UA       string
+}
+
+// OfferDeliveryService emails offers and serves the public accept/reject links.
+type OfferDeliveryService struct {
+	db     *gorm.DB
+	offers *OfferService
+	mailer *Mailer
+}
+
+func NewOfferDeliveryService(db *gorm.DB) *OfferDeliveryService {
+	return &OfferDeliveryService{db: db, offers: NewOfferService(db), mailer: NewMailer()}
+}
+
+// SendOffer marks a draft offer as sent (creating its next revision), issues a
+// public link for that revision and emails it with the revision PDF attached.
+// Sent or viewed offers are re-sent with a fresh link to the same revision.
+func (s *OfferDeliveryService) SendOffer(ctx context.Context, offerID int, in SendOfferInput, actorID *int, now time.Time) (*OfferDelivery, error) {
+	offer, err := s.offers.GetOfferByID(offerID)
+	if err != nil {
+		return nil, err
+	}
+	var client models.Client
+	if err := s.db.WithContext(ctx).First(&client, offer.ClientID).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	to := nonEmpty(strings.TrimSpace(in.To), client.Email)
+	if to == "" {
+		return nil, FieldErrors{"to": "is required (the client has no email)"}
+	}
+	switch offer.Status {
+	case models.OfferDraft, "":
+		if offer, err = s.offers.TransitionOffer(offerID, models.OfferSent, actorID, "emailed to "+to, now); err != nil {
+			return nil, err
+		}
+	case models.OfferSent, models.OfferViewed:
+		if offer.Revision == 0 {
+			return nil, fmt.Errorf("%w: offer was sent before revisions were tracked; revise it first", ErrOfferTransition)
+		}
+	default:
+		return nil, fmt.Errorf("%w: cannot email a %s offer", ErrOfferTransition, offer.Status)
+	}
+
+	rev, err := s.offers.GetRevision(offerID, offer.Revision)
+	if err != nil {
+		return nil, err
+	}
+	if rev.PDFURL == "" {
+		if rev.PDFURL, err = s.offers.RenderRevisionPDF(offerID, rev.Revision); err != nil {
+			return nil, err
+		}
+	}
+	pdf, err := os.ReadFile(filepath.Join("static", "pdfs", filepath.Base(rev.PDFURL)))
+	if err != nil {
+		return nil, err
+	}
+
+	days := in.ExpiresInDays
+	if days <= 0 {
+		days = envInt("OFFER_LINK_TTL_DAYS", 30)
+	}
+	token, err := newOfferLinkToken()
+	if err != nil {
+		return nil, err
+	}
+	link := models.OfferLink{
+		OfferID:   offerID,
+		Revision:  rev.Revision,
+		TokenHash: hashOfferLinkToken(token),
+		Email:     to,
+		ExpiresAt: now.AddDate(0, 0, days),
+	}
+	if err := s.db.WithContext(ctx).Create(&link).Error; err != nil {
+		return nil, err
+	}
+	base := os.Getenv("OFFER_LINK_BASE")
+	if base == "" {
+		base = "http://localhost:3000/o/"
+	}
+	url := base + token
+
+	subject := fmt.Sprintf("Penawaran %s: %s", rev.OfferNumber, offer.Subject)
+	body := strings.TrimSpace(in.Message)
+	if body != "" {
+		body += "\n\n"
+	}
+	body += fmt.Sprintf("Please find offer %s attached (total %s).\n\nReview and accept or reject it online: %s\nThis link expires on %s.",
+		rev.OfferNumber, formatMoney(rev.TotalPrice, rev.Offer.Currency), url, link.ExpiresAt.Format("2006-01-02"))
+	attachment := MailAttachment{Filename: filepath.Base(rev.PDFURL), ContentType: "application/pdf", Data: pdf}
+	if err := s.mailer.SendEmailWithAttachments(to, subject, body, []MailAttachment{attachment}); err != nil {
+		return nil, err
+	}
+	return &OfferDelivery{Offer: offer, Link: link, URL: url}, nil
+}
+
+// OpenLink returns the offer revision behind token and records the view. The
+// first view of the current revision moves a sent offer to viewed.
+func (s *OfferDeliveryService) OpenLink(ctx context.Context, token string, now time.Time) (*PublicOffer, error) {
+	link, err := s.link(ctx, token, now)
+	if err != nil {
+		return nil, err
+	}
+	updates := map[string]interface{}{"views": gorm.Expr("views + 1"), "last_viewed_at": now}
+	if link.FirstViewedAt == nil {
+		updates["first_viewed_at"] = now
+	}
+	if err := s.db.WithContext(ctx).Model(&models.OfferLink{}).Where("id = ?", link.ID).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	offer, err := s.offers.GetOfferByID(link.OfferID)
+	if err != nil {
+		return nil, err
+	}
+	if offer.Status == models.OfferSent && offer.Revision == link.Revision {
+		if offer, err = s.offers.TransitionOffer(offer.ID, models.OfferViewed, nil, "opened emailed link", now); err != nil {
+			return nil, err
+		}
+	}
+	return s.publicView(ctx, link, offer)
+}
+
+// Respond records the client's decision through token and accepts or
+// rejects the offer in the same transaction as the signature.
+func (s *OfferDeliveryService) Respond(ctx context.Context, token string, in OfferResponseInput, now time.Time) (*PublicOffer, error) {
+	link, err := s.link(ctx, token, now)
+	if err != nil {
+		return nil, err
+	}
+	sig := models.OfferSignature{
+		OfferID:   link.OfferID,
+		LinkID:    link.ID,
+		Revision:  link.Revision,
+		Decision:  in.Decision,
+		IP:        in.IP,
+		UserAgent: in.UA,
+		SignedAt:  now,
+	}
+	switch in.Decision {
+	case models.OfferDecisionAccept:
+		sig.SignerName = strings.TrimSpace(in.Name)
+		if sig.SignerName == "" {
+			return nil, FieldErrors{"name": "type your full name to accept"}
+		}
+	case models.OfferDecisionReject:
+		sig.Reason = strings.TrimSpace(in.Reason)
+		if sig.Reason == "" {
+			return nil, FieldErrors{"reason": "is required"}
+		}
+	default:
+		return nil, FieldErrors{"decision": "must be accept or reject"}
+	}
+	// The decision and its signature commit together, so a failed insert
+	// can't leave an accepted offer without a signer.
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var offer models.Offer
+		if err := tx.First(&offer, link.OfferID).Error; err != nil {
+			return err
+		}
+		if !linkOpen(link, &offer) {
+			return ErrOfferLinkClosed
+		}
+		to, note := models.OfferAccepted, ""
+		if in.Decision == models.OfferDecisionReject {
+			to, note = models.OfferRejected, sig.Reason
+		}
+		if err := transitionOffer(tx, &offer, to, nil, note, now); err != nil {
+			return err
+		}
+		return tx.Create(&sig).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	offer, err := s.offers.GetOfferByID(link.OfferID)
+	if err != nil {
+		return nil, err
+	}
+	return s.publicView(ctx, link, offer)
+}
+
+// LinkPDF returns the file path of the revision PDF behind token.
+func (s *OfferDeliveryService) LinkPDF(ctx context.Context, token string, now time.Time) (string, error) {
+	link, err := s.link(ctx, token, now)
+	if err != nil {
+		return "", err
+	}
+	rev, err := s.offers.GetRevision(link.OfferID, link.Revision)
+	if err != nil {
+		return "", err
+	}
+	url := rev.PDFURL
+	if url == "" {
+		if url, err = s.offers.RenderRevisionPDF(link.OfferID, link.Revision); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join("static", "pdfs", filepath.Base(url)), nil
+}
+
+func (s *OfferDeliveryService) link(ctx context.Context, token string, now time.Time) (*models.OfferLink, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, ErrOfferLinkNotFound
+	}
+	var link models.OfferLink
+	if err := s.db.WithContext(ctx).Where("token_hash = ?", hashOfferLinkToken(token)).First(&link).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOfferLinkNotFound
+		}
+		return nil, err
+	}
+	if !now.Before(link.ExpiresAt) {
+		return nil, ErrOfferLinkExpired
+	}
+	return &link, nil
+}
+
+func (s *OfferDeliveryService) publicView(ctx context.Context, link *models.OfferLink, offer *models.Offer) (*PublicOffer, error) {
+	rev, err := s.offers.GetRevision(link.OfferID, link.Revision)
+	if err != nil {
+		return nil, err
+	}
+	view := &PublicOffer{
+		Offer:      rev.Offer,
+		Status:     offer.Status,
+		Revision:   link.Revision,
+		ExpiresAt:  link.ExpiresAt,
+		CanRespond: linkOpen(link, offer),
+	}
+	// Strip internal bookkeeping from the client-facing snapshot.
+	view.Offer.Status = offer.Status
+	view.Offer.SignedDocURL = ""
+	view.Offer.PDFURL = ""
+	var sig models.OfferSignature
+	err = s.db.WithContext(ctx).Where("offer_id = ? AND revision = ?", link.OfferID, link.Revision).Order("id DESC").First(&sig).Error
+	if err == nil {
+		view.Signature = &sig
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return view, nil
+}
+
+// linkOpen reports whether the client may still decide through link: it must
+// point at the current revision of an offer awaiting a decision.
+func linkOpen(link *models.OfferLink, offer *models.Offer) bool {
+	return link.Revision == offer.Revision && (offer.Status == models.OfferSent || offer.Status == models.OfferViewed)
+}
+
+func newOfferLinkToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashOfferLinkToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
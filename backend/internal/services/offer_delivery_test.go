@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/smtp"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestOfferDelivery_EmailViewAndAcceptOnline(t *testing.T) {
+	wd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	for k, v := range map[string]string{"SMTP_HOST": "smtp.test", "SMTP_PORT": "587", "SMTP_USER": "u", "SMTP_PASSWORD": "p", "SMTP_FROM": "me@test", "OFFER_LINK_BASE": "https://app.test/o/"} {
+		t.Setenv(k, v)
+	}
+	var sent []string
+	orig := sendMail
+	sendMail = func(_ string, _ smtp.Auth, _ string, to []string, msg []byte) error {
+		sent = append(sent, strings.Join(to, ",")+"\n"+string(msg))
+		return nil
+	}
+	t.Cleanup(func() { sendMail = orig })
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Client{}, &models.Offer{}, &models.OfferItem{}, &models.NumberSequence{}, &models.OfferStatusChange{},
+		&models.OfferRevision{}, &models.OfferLink{}, &models.OfferSignature{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	client := models.Client{Name: "Acme", Email: "buyer@acme.test"}
+	if err := db.Create(&client).Error; err != nil {
+		t.Fatalf("client: %v", err)
+	}
+	offers := NewOfferService(db)
+	offer := &models.Offer{ClientID: client.ID, Subject: "Website", Items: models.OfferItems{{Description: "Design", Qty: 1, UnitPrice: 1000}}}
+	if err := offers.CreateOffer(offer); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	svc := NewOfferDeliveryService(db)
+	ctx := context.Background()
+	now := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	out, err := svc.SendOffer(ctx, offer.ID, SendOfferInput{ExpiresInDays: 7}, nil, now)
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if out.Offer.Status != models.OfferSent || out.Offer.Revision != 1 {
+		t.Fatalf("expected sent revision 1, got %s r%d", out.Offer.Status, out.Offer.Revision)
+	}
+	if len(sent) != 1 || !strings.HasPrefix(sent[0], "buyer@acme.test\n") || !strings.Contains(sent[0], out.URL) ||
+		!strings.Contains(sent[0], `filename=offer_1_r1.pdf`) {
+		t.Fatalf("unexpected email: %v", sent)
+	}
+	token := strings.TrimPrefix(out.URL, "https://app.test/o/")
+
+	view, err := svc.OpenLink(ctx, token, now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if view.Status != models.OfferViewed || !view.CanRespond || view.Offer.Subject != "Website" {
+		t.Fatalf("unexpected view: %+v", view)
+	}
+	if _, err := svc.Respond(ctx, token, OfferResponseInput{Decision: models.OfferDecisionAccept}, now); err == nil {
+		t.Fatalf("expected typed name to be required")
+	}
+	// A signature that fails to save leaves the offer undecided.
+	db.Callback().Create().Before("gorm:create").Register("test:fail_signature", func(tx *gorm.DB) {
+		if _, ok := tx.Statement.Dest.(*models.OfferSignature); ok {
+			tx.AddError(errors.New("disk full"))
+		}
+	})
+	if _, err := svc.Respond(ctx, token, OfferResponseInput{Decision: models.OfferDecisionAccept, Name: "Jane Buyer"}, now); err == nil {
+		t.Fatalf("expected the signature error")
+	}
+	db.Callback().Create().Remove("test:fail_signature")
+	if got, _ := offers.GetOfferByID(offer.ID); got.Status != models.OfferViewed {
+		t.Fatalf("expected the acceptance to roll back, got %s", got.Status)
+	}
+	view, err = svc.Respond(ctx, token, OfferResponseInput{Decision: models.OfferDecisionAccept, Name: "Jane Buyer", IP: "203.0.113.7"}, now.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	if view.Status != models.OfferAccepted || view.CanRespond || view.Signature == nil || view.Signature.SignerName != "Jane Buyer" || view.Signature.IP != "203.0.113.7" {
+		t.Fatalf("unexpected accept view: %+v", view)
+	}
+	got, _ := offers.GetOfferByID(offer.ID)
+	if got.ApprovedAt == nil || got.AcceptedRevision == nil || *got.AcceptedRevision != 1 {
+		t.Fatalf("expected offer accepted on revision 1, got %+v", got)
+	}
+	var link models.OfferLink
+	db.First(&link)
+	if link.Views != 1 || link.FirstViewedAt == nil {
+		t.Fatalf("expected one recorded view, got %+v", link)
+	}
+	if _, err := svc.Respond(ctx, token, OfferResponseInput{Decision: models.OfferDecisionReject, Reason: "too late"}, now); !errors.Is(err, ErrOfferLinkClosed) {
+		t.Fatalf("expected closed link after accept, got %v", err)
+	}
+	if _, err := svc.OpenLink(ctx, token, now.AddDate(0, 0, 8)); !errors.Is(err, ErrOfferLinkExpired) {
+		t.Fatalf("expected expired link, got %v", err)
+	}
+	if _, err := svc.OpenLink(ctx, "nope", now); !errors.Is(err, ErrOfferLinkNotFound) {
+		t.Fatalf("expected unknown token, got %v", err)
+	}
+}
+
+func TestOfferDelivery_RejectAndStaleRevision(t *testing.T) {
+	wd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Client{}, &models.Offer{}, &models.OfferItem{}, &models.NumberSequence{}, &models.OfferStatusChange{},
+		&models.OfferRevision{}, &models.OfferLink{}, &models.OfferSignature{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	offers := NewOfferService(db)
+	offer := &models.Offer{ClientID: 1, Subject: "Audit", Items: models.OfferItems{{Description: "Audit", Qty: 1, UnitPrice: 500}}}
+	if err := offers.CreateOffer(offer); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	svc := NewOfferDeliveryService(db)
+	ctx := context.Background()
+	now := time.Now()
+	if _, err := svc.SendOffer(ctx, offer.ID, SendOfferInput{}, nil, now); err == nil {
+		t.Fatalf("expected an error without a recipient")
+	}
+	first, err := svc.SendOffer(ctx, offer.ID, SendOfferInput{To: "cfo@client.test"}, nil, now)
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	oldToken := first.URL[strings.LastIndex(first.URL, "/")+1:]
+
+	// Revise and resend: the first link shows revision 1 but can no longer decide.
+	if _, err := offers.TransitionOffer(offer.ID, models.OfferDraft, nil, "", now); err != nil {
+		t.Fatalf("revise: %v", err)
+	}
+	second, err := svc.SendOffer(ctx, offer.ID, SendOfferInput{To: "cfo@client.test"}, nil, now)
+	if err != nil {
+		t.Fatalf("resend: %v", err)
+	}
+	if _, err := svc.Respond(ctx, oldToken, OfferResponseInput{Decision: models.OfferDecisionAccept, Name: "CFO"}, now); !errors.Is(err, ErrOfferLinkClosed) {
+		t.Fatalf("expected stale revision link to be closed, got %v", err)
+	}
+	newToken := second.URL[strings.LastIndex(second.URL, "/")+1:]
+	if _, err := svc.Respond(ctx, newToken, OfferResponseInput{Decision: models.OfferDecisionReject}, now); err == nil {
+		t.Fatalf("expected reason to be required")
+	}
+	view, err := svc.Respond(ctx, newToken, OfferResponseInput{Decision: models.OfferDecisionReject, Reason: "Over budget"}, now)
+	if err != nil {
+		t.Fatalf("reject: %v", err)
+	}
+	if view.Status != models.OfferRejected || view.Signature.Reason != "Over budget" {
+		t.Fatalf("unexpected reject view: %+v", view)
+	}
+	hist, _ := offers.OfferHistory(offer.ID)
+	if last := hist[len(hist)-1]; last.ToStatus != models.OfferRejected || last.Note != "Over budget" {
+		t.Fatalf("expected rejection reason in history, got %+v", last)
+	}
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"freelance-monitor-system/internal/models"
+)
+
+// FieldErrors maps a field path such as "items[0].qty" to what is wrong with it.
+type FieldErrors map[string]string
+
+func (e FieldErrors) Error() string {
+	keys := make([]string, 0, len(e))
+	for k := range e {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+": "+e[k])
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// PriceOffer validates the offer's line items and computes per-line and offer
+// totals. Client-supplied totals are ignored. Lines are renumbered in order.
+func PriceOffer(offer *models.Offer) error {
+	errs := FieldErrors{}
+	var subtotal, discount, tax, total float64
+	for i := range offer.Items {
+		it := &offer.Items[i]
+		field := func(name string) string { return fmt.Sprintf("items[%d].%s", i, name) }
+		it.Name = strings.TrimSpace(it.Name)
+		it.Description = strings.TrimSpace(it.Description)
+		it.Unit = strings.TrimSpace(it.Unit)
+		if it.Description == "" {
+			it.Description = it.Name
+		}
+		if it.Description == "" {
+			errs[field("description")] = "is required"
+		}
+		if it.Qty <= 0 {
+			errs[field("qty")] = "must be greater than 0"
+		}
+		if it.UnitPrice < 0 {
+			errs[field("unit_price")] = "must not be negative"
+		}
+		if it.DiscountPercent < 0 || it.DiscountPercent > 100 {
+			errs[field("discount_percent")] = "must be between 0 and 100"
+		}
+		if it.TaxRate < 0 || it.TaxRate > 100 {
+			errs[field("tax_rate")] = "must be between 0 and 100"
+		}
+		it.Position = i + 1
+		it.Subtotal = roundMoney(it.Qty * it.UnitPrice)
+		it.DiscountAmount = roundMoney(it.Subtotal * it.DiscountPercent / 100)
+		it.TaxAmount = roundMoney((it.Subtotal - it.DiscountAmount) * it.TaxRate / 100)
+		it.Total = roundMoney(it.Subtotal - it.DiscountAmount + it.TaxAmount)
+		subtotal += it.Subtotal
+		discount += it.DiscountAmount
+		tax += it.TaxAmount
+		total += it.Total
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	offer.Subtotal = roundMoney(subtotal)
+	offer.DiscountTotal = roundMoney(discount)
+	offer.TaxTotal = roundMoney(tax)
+	offer.TotalPrice = roundMoney(total)
+	return nil
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"freelance-monitor-system/internal/models"
+)
+
+func TestPriceOffer_ComputesDiscountAndTax(t *testing.T) {
+	offer := &models.Offer{TotalPrice: 1, Items: models.OfferItems{
+		{Name: "Maintenance", Qty: 2, Unit: "bulan", UnitPrice: 1000, DiscountPercent: 10, TaxRate: 11},
+		{Description: "Domain", Qty: 1, UnitPrice: 150},
+	}}
+	if err := PriceOffer(offer); err != nil {
+		t.Fatalf("price: %v", err)
+	}
+	first := offer.Items[0]
+	if first.Description != "Maintenance" || first.Position != 1 || first.Subtotal != 2000 || first.DiscountAmount != 200 || first.TaxAmount != 198 || first.Total != 1998 {
+		t.Fatalf("unexpected line pricing %+v", first)
+	}
+	if offer.Subtotal != 2150 || offer.DiscountTotal != 200 || offer.TaxTotal != 198 || offer.TotalPrice != 2148 {
+		t.Fatalf("unexpected offer totals %+v", offer)
+	}
+}
+
+func TestPriceOffer_FieldErrors(t *testing.T) {
+	offer := &models.Offer{Items: models.OfferItems{
+		{Description: "OK", Qty: 1, UnitPrice: 1},
+		{Qty: -1, UnitPrice: -5, DiscountPercent: 101, TaxRate: -1},
+	}}
+	err := PriceOffer(offer)
+	var fe FieldErrors
+	if !errors.As(err, &fe) {
+		t.Fatalf("expected FieldErrors, got %v", err)
+	}
+	for _, f := range []string{"items[1].description", "items[1].qty", "items[1].unit_price", "items[1].discount_percent", "items[1].tax_rate"} {
+		if _, ok := fe[f]; !ok {
+			t.Fatalf("missing error for %s in %v", f, fe)
+		}
+	}
+	if _, ok := fe["items[0].qty"]; ok {
+		t.Fatalf("valid line reported as invalid: %v", fe)
+	}
+}
+
+func TestInvoiceFromOffer_CarriesLineDiscountsAndTaxes(t *testing.T) {
+	db := newInvoiceTestDB(t)
+	ctx := context.Background()
+	offers := NewOfferService(db)
+	offer := &models.Offer{ClientID: 1, Subject: "Retainer", Items: models.OfferItems{
+		{Description: "Maintenance", Qty: 2, UnitPrice: 1000, DiscountPercent: 10, TaxRate: 11},
+		{Description: "Domain", Qty: 1, UnitPrice: 150},
+	}}
+	if err := offers.CreateOffer(offer); err != nil {
+		t.Fatalf("create offer: %v", err)
+	}
+	if _, err := offers.TransitionOffer(offer.ID, models.OfferSent, nil, "", offer.Date); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if _, err := offers.ApproveOffer(offer.ID, offer.Date); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	inv, err := NewInvoiceService(db).CreateFromOffer(ctx, offer.ID, InvoiceOptions{})
+	if err != nil {
+		t.Fatalf("invoice: %v", err)
+	}
+	if inv.Items[0].Total != 1800 || inv.Items[0].UnitPrice != 900 || inv.Subtotal != 1950 {
+		t.Fatalf("expected discounted lines, got %+v", inv.Items)
+	}
+	if len(inv.Taxes) != 1 || inv.Taxes[0].Rate != 11 || inv.Taxes[0].Amount != 198 || inv.Total != offer.TotalPrice {
+		t.Fatalf("expected line taxes carried over, got %+v total %v", inv.Taxes, inv.Total)
+	}
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/gorm"
+)
+
+// defaultRenewEveryDays is the renewal cycle when none is set.
+const defaultRenewEveryDays = 30
+
+// renewableStatuses are the offer statuses that still auto-renew; rejected,
+// expired and superseded offers keep their setting but are never cloned.
+var renewableStatuses = []string{models.OfferDraft, models.OfferSent, models.OfferViewed, models.OfferAccepted}
+
+// errRenewalTaken rolls back a renewal whose source another run already
+// advanced.
+var errRenewalTaken = errors.New("renewal already taken")
+
+// RenewDueOffers clones every auto-renewing offer whose next_renewal has
+// passed into a new draft (see renewalOf), advances its next_renewal past
+// now, and emails a notice for each renewal. Only offers in a renewable
+// status are cloned. The advance is conditional on the next_renewal that was
+// read, so a concurrent run that got there first rolls the clone back.
+// Offers that fail to renew are skipped and reported in the joined error;
+// the created drafts are returned.
+func (s *OfferService) RenewDueOffers(ctx context.Context, now time.Time) ([]models.Offer, error) {
+	db := s.db.WithContext(ctx)
+	var due []models.Offer
+	if err := db.Preload("Items", orderByPosition).Where("auto_renew = ? AND next_renewal <= ? AND status IN ?", true, now, renewableStatuses).
+		Order("id").Find(&due).Error; err != nil {
+		return nil, err
+	}
+	created := []models.Offer{}
+	var errs []error
+	for i := range due {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		src := &due[i]
+		renewal := renewalOf(src, now)
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := NewOfferService(tx).CreateOffer(&renewal); err != nil {
+				return err
+			}
+			if err := tx.Model(&models.OfferStatusChange{}).Where("offer_id = ?", renewal.ID).
+				Update("note", "auto-renewed from "+src.OfferNumber).Error; err != nil {
+				return err
+			}
+			res := tx.Model(&models.Offer{}).Where("id = ? AND next_renewal = ?", src.ID, src.NextRenewal).
+				Update("next_renewal", nextRenewal(src.NextRenewal, src.RenewEveryDays, now))
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return errRenewalTaken
+			}
+			return nil
+		})
+		if errors.Is(err, errRenewalTaken) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("offer %s: %w", src.OfferNumber, err))
+			continue
+		}
+		created = append(created, renewal)
+		s.notifyRenewal(src, &renewal)
+	}
+	return created, errors.Join(errs...)
+}
+
+// renewalOf copies src into a new draft dated now: every content field,
+// currency, issuer, signature and client attention, with items cloned and
+// RenewedFromID pointing back at src. ValidUntil keeps the source's validity
+// period, or the renewal cycle when it had none. Numbering, status, revision,
+// approval, documents and exchange rates start over (see CreateOffer), and
+// the renewal does not renew itself.
+func renewalOf(src *models.Offer, now time.Time) models.Offer {
+	r := *src
+	r.ID = 0
+	r.OfferNumber = ""
+	r.Date = now
+	r.Items = make(models.OfferItems, len(src.Items))
+	for i, it := range src.Items {
+		it.ID, it.OfferID = 0, 0
+		it.UnitPriceSet, it.TaxRateSet = true, true
+		r.Items[i] = it
+	}
+	r.PDFURL = ""
+	r.SignedDocURL = ""
+	r.AutoRenew = false
+	r.NextRenewal = time.Time{}
+	r.LastReminderAt = nil
+	r.CreatedAt = time.Time{}
+	id := src.ID
+	r.RenewedFromID = &id
+
+	validity := time.Duration(renewEveryDays(src.RenewEveryDays)) * 24 * time.Hour
+	if src.ValidUntil != nil && src.ValidUntil.After(src.Date) {
+		validity = src.ValidUntil.Sub(src.Date)
+	}
+	until := now.Add(validity)
+	r.ValidUntil = &until
+	return r
+}
+
+// nextRenewal advances from by whole cycles until it is after now, so an
+// offer missed for several cycles renews once rather than once per cycle.
+func nextRenewal(from time.Time, days int, now time.Time) time.Time {
+	cycle := time.Duration(renewEveryDays(days)) * 24 * time.Hour
+	next := from.Add(cycle)
+	for !next.After(now) {
+		next = next.Add(cycle)
+	}
+	return next
+}
+
+func renewEveryDays(days int) int {
+	if days <= 0 {
+		return defaultRenewEveryDays
+	}
+	return days
+}
+
+// notifyRenewal emails OFFER_RENEWAL_EMAIL, or the offer's issuer, that a
+// renewal draft is ready for review. Failures are only logged.
+func (s *OfferService) notifyRenewal(src, renewal *models.Offer) {
+	to := nonEmpty(os.Getenv("OFFER_RENEWAL_EMAIL"), src.IssuerEmail)
+	if to == "" {
+		return
+	}
+	subject := fmt.Sprintf("Penawaran '%s' diperpanjang", nonEmpty(renewal.Subject, renewal.OfferTitle))
+	body := fmt.Sprintf("Halo,\n\nPenawaran %s untuk klien ID %d telah diperpanjang otomatis sebagai draft %s (total %s, berlaku sampai %s).\nMohon periksa dan kirim draft tersebut ke klien.\n\nTerima kasih.\n",
+		src.OfferNumber, renewal.ClientID, renewal.OfferNumber, formatMoney(renewal.TotalPrice, renewal.Currency),
+		renewal.ValidUntil.Format("2006-01-02"))
+	if err := NewMailer().SendGenericEmail(to, subject, body); err != nil {
+		log.Printf("offer %s: renewal notice: %v", renewal.OfferNumber, err)
+	}
+}
+
+// ToggleAutoRenew enables or disables auto-renewal. Enabling sets the cycle
+// (default 30 days) and schedules the next renewal one cycle after the offer
+// date, or one cycle from now when that has already passed.
+func (s *OfferService) ToggleAutoRenew(id int, enable bool, days int) (*models.Offer, error) {
+	if days < 0 || days > 366 {
+		return nil, FieldErrors{"renew_every_days": "must be between 1 and 366"}
+	}
+	var offer models.Offer
+	if err := s.db.First(&offer, id).Error; err != nil {
+		return nil, err
+	}
+	updates := map[string]interface{}{"auto_renew": enable}
+	if enable {
+		if days == 0 {
+			days = offer.RenewEveryDays
+		}
+		days = renewEveryDays(days)
+		cycle := time.Duration(days) * 24 * time.Hour
+		next := offer.NextRenewal
+		if next.IsZero() {
+			next = offer.Date.Add(cycle)
+		}
+		if now := time.Now(); next.Before(now) {
+			next = now.Add(cycle)
+		}
+		updates["renew_every_days"] = days
+		updates["next_renewal"] = next
+	}
+	if err := s.db.Model(&models.Offer{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return s.GetOfferByID(id)
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestRenewDueOffersClonesIntoDraft(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Offer{}, &models.OfferItem{}, &models.NumberSequence{}, &models.OfferStatusChange{}, &models.OfferRevision{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	svc := NewOfferService(db)
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	valid := date.AddDate(0, 0, 14)
+	src := &models.Offer{
+		ClientID: 1, Subject: "Maintenance", Date: date, ValidUntil: &valid, Currency: "USD",
+		IssuerName: "Budi", IssuerEmail: "budi@example.com", ClientAttention: "Ibu Sari",
+		SignatureTitle: "Director", SignatureCity: "Bandung", PaymentTerms: "Net 14",
+		Items: models.OfferItems{{Description: "Monthly care", Qty: 1, UnitPrice: 200, TaxRate: 11}},
+	}
+	if err := svc.CreateOffer(src); err != nil {
+		t.Fatal(err)
+	}
+	db.Model(&models.Offer{}).Where("id = ?", src.ID).Updates(map[string]interface{}{"status": models.OfferAccepted, "revision": 1, "exchange_rate": 16000})
+	if _, err := svc.ToggleAutoRenew(src.ID, true, 30); err != nil {
+		t.Fatal(err)
+	}
+	// Fall three cycles behind.
+	now := time.Now()
+	db.Model(&models.Offer{}).Where("id = ?", src.ID).Update("next_renewal", now.AddDate(0, 0, -75))
+
+	renewals, err := svc.RenewDueOffers(context.Background(), now)
+	if err != nil || len(renewals) != 1 {
+		t.Fatalf("expected one renewal, got %d: %v", len(renewals), err)
+	}
+	got, err := svc.GetOfferByID(renewals[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != models.OfferDraft || got.Revision != 0 || got.ExchangeRate != 0 || got.AutoRenew || got.OfferNumber == src.OfferNumber ||
+		got.RenewedFromID == nil || *got.RenewedFromID != src.ID {
+		t.Fatalf("expected an unsent draft linked to the source: %+v", got)
+	}
+	if got.Currency != "USD" || got.IssuerEmail != "budi@example.com" || got.ClientAttention != "Ibu Sari" ||
+		got.SignatureTitle != "Director" || got.SignatureCity != "Bandung" || got.PaymentTerms != "Net 14" {
+		t.Fatalf("expected every field to be cloned: %+v", got)
+	}
+	if len(got.Items) != 1 || got.Items[0].ID == src.Items[0].ID || got.TotalPrice != 222 {
+		t.Fatalf("expected cloned items and totals: %+v", got.Items)
+	}
+	if got.ValidUntil == nil || got.ValidUntil.Sub(got.Date).Round(time.Hour) != 14*24*time.Hour {
+		t.Fatalf("expected the source's 14-day validity: %v", got.ValidUntil)
+	}
+	var history models.OfferStatusChange
+	db.Where("offer_id = ?", got.ID).First(&history)
+	if history.Note != "auto-renewed from "+src.OfferNumber {
+		t.Fatalf("unexpected history note %q", history.Note)
+	}
+
+	source, _ := svc.GetOfferByID(src.ID)
+	if !source.NextRenewal.After(now) || source.NextRenewal.After(now.AddDate(0, 0, 30)) {
+		t.Fatalf("expected next_renewal to move past now by at most a cycle: %s", source.NextRenewal)
+	}
+	if again, err := svc.RenewDueOffers(context.Background(), now); err != nil || len(again) != 0 {
+		t.Fatalf("expected nothing left to renew, got %d: %v", len(again), err)
+	}
+
+	if _, err := svc.ToggleAutoRenew(src.ID, true, -1); err == nil {
+		t.Fatal("expected negative days to be rejected")
+	}
+	off, err := svc.ToggleAutoRenew(src.ID, false, 0)
+	if err != nil || off.AutoRenew {
+		t.Fatalf("expected auto-renew to be disabled: %+v %v", off, err)
+	}
+}
+
+func TestRenewDueOffersSkipsDeadAndTakenOffers(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Offer{}, &models.OfferItem{}, &models.NumberSequence{}, &models.OfferStatusChange{}, &models.OfferRevision{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	svc := NewOfferService(db)
+	now := time.Now()
+	due := func(status string) *models.Offer {
+		o := &models.Offer{ClientID: 1, Subject: status, Date: now.AddDate(0, 0, -40), Items: models.OfferItems{{Description: "Care", Qty: 1, UnitPrice: 100}}}
+		if err := svc.CreateOffer(o); err != nil {
+			t.Fatal(err)
+		}
+		db.Model(&models.Offer{}).Where("id = ?", o.ID).Updates(map[string]interface{}{
+			"status": status, "auto_renew": true, "renew_every_days": 30, "next_renewal": now.AddDate(0, 0, -1),
+		})
+		return o
+	}
+	due(models.OfferRejected)
+	due(models.OfferExpired)
+	if renewals, err := svc.RenewDueOffers(context.Background(), now); err != nil || len(renewals) != 0 {
+		t.Fatalf("expected rejected and expired offers to stay unrenewed, got %d: %v", len(renewals), err)
+	}
+
+	// Another run advances the source while this one clones it.
+	src := due(models.OfferAccepted)
+	db.Callback().Create().Before("gorm:create").Register("test:race", func(tx *gorm.DB) {
+		if o, ok := tx.Statement.Dest.(*models.Offer); ok && o.RenewedFromID != nil {
+			tx.Session(&gorm.Session{NewDB: true}).Model(&models.Offer{}).Where("id = ?", src.ID).Update("next_renewal", now.AddDate(0, 0, 29))
+		}
+	})
+	if renewals, err := svc.RenewDueOffers(context.Background(), now); err != nil || len(renewals) != 0 {
+		t.Fatalf("expected the taken renewal to be skipped, got %d: %v", len(renewals), err)
+	}
+	var count int64
+	db.Model(&models.Offer{}).Where("renewed_from_id = ?", src.ID).Count(&count)
+	if count != 0 {
+		t.Fatalf("expected the clone to be rolled back, found %d", count)
+	}
+}
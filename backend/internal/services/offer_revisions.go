@@ -0,0 +1,180 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrOfferLocked is returned when editing an offer that is no longer a draft.
+// Sent offers are revised by moving them back to draft first.
+var ErrOfferLocked = errors.New("only draft offers can be edited; revise the offer first")
+
+// revisionIgnoredFields are bookkeeping fields left out of revision diffs.
+var revisionIgnoredFields = map[string]bool{
+	"id": true, "offer_number": true, "items": true, "status": true, "revision": true,
+	"accepted_revision": true, "approved_at": true, "pdf_url": true, "signed_doc_url": true,
+	"next_renewal": true, "renewed_from_id": true, "last_reminder_at": true, "created_at": true,
+	"exchange_rate": true, "exchange_rate_date": true,
+}
+
+// revisionItemIgnoredFields are left out when comparing line items.
+var revisionItemIgnoredFields = map[string]bool{"id": true, "offer_id": true, "position": true}
+
+// OfferRevisionDiff describes what changed between two revisions. Items are
+// matched by position.
+type OfferRevisionDiff struct {
+	From    int                      `json:"from"`
+	To      int                      `json:"to"`
+	Fields  map[string]interface{}   `json:"fields"`
+	Added   []models.OfferItem       `json:"items_added"`
+	Removed []models.OfferItem       `json:"items_removed"`
+	Changed []map[string]interface{} `json:"items_changed"`
+}
+
+// snapshotRevision stores the offer as sent as its next revision and bumps
+// offers.revision. Called from transitionOffer inside its transaction.
+func snapshotRevision(tx *gorm.DB, offer *models.Offer, actorID *int, at time.Time) error {
+	var full models.Offer
+	if err := tx.Preload("Items", orderByPosition).First(&full, offer.ID).Error; err != nil {
+		return err
+	}
+	rev := full.Revision + 1
+	full.Revision = rev
+	full.Status = models.OfferSent
+	data, err := json.Marshal(full)
+	if err != nil {
+		return err
+	}
+	if err := tx.Create(&models.OfferRevision{
+		OfferID:     full.ID,
+		Revision:    rev,
+		OfferNumber: models.RevisionNumber(full.OfferNumber, rev),
+		TotalPrice:  full.TotalPrice,
+		Snapshot:    string(data),
+		ActorID:     actorID,
+		SentAt:      at,
+	}).Error; err != nil {
+		return err
+	}
+	offer.Revision = rev
+	return tx.Model(&models.Offer{}).Where("id = ?", offer.ID).Update("revision", rev).Error
+}
+
+// acceptRevision marks the latest sent revision as the accepted one.
+func acceptRevision(tx *gorm.DB, offer *models.Offer, at time.Time) error {
+	if offer.Revision == 0 {
+		return nil // sent before revisions were tracked
+	}
+	if err := tx.Model(&models.OfferRevision{}).
+		Where("offer_id = ? AND revision = ?", offer.ID, offer.Revision).
+		Update("accepted_at", at).Error; err != nil {
+		return err
+	}
+	rev := offer.Revision
+	offer.AcceptedRevision = &rev
+	return tx.Model(&models.Offer{}).Where("id = ?", offer.ID).Update("accepted_revision", rev).Error
+}
+
+// ListRevisions returns the offer's revisions, oldest first, without snapshots.
+func (s *OfferService) ListRevisions(offerID int) ([]models.OfferRevision, error) {
+	if _, err := s.GetOfferByID(offerID); err != nil {
+		return nil, err
+	}
+	var rows []models.OfferRevision
+	if err := s.db.Omit("snapshot").Where("offer_id = ?", offerID).Order("revision").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// GetRevision returns one revision with its decoded offer snapshot.
+func (s *OfferService) GetRevision(offerID, revision int) (*models.OfferRevision, error) {
+	var rev models.OfferRevision
+	if err := s.db.Where("offer_id = ? AND revision = ?", offerID, revision).First(&rev).Error; err != nil {
+		return nil, err
+	}
+	var snap models.Offer
+	if err := json.Unmarshal([]byte(rev.Snapshot), &snap); err != nil {
+		return nil, err
+	}
+	snap.OfferNumber = rev.OfferNumber
+	rev.Offer = &snap
+	return &rev, nil
+}
+
+// SetRevisionPDF records the PDF rendered for a revision.
+func (s *OfferService) SetRevisionPDF(offerID, revision int, url string) error {
+	res := s.db.Model(&models.OfferRevision{}).
+		Where("offer_id = ? AND revision = ?", offerID, revision).
+		Update("pdf_url", url)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RenderRevisionPDF writes the PDF for a revision's snapshot, records its URL
+// and returns it.
+func (s *OfferService) RenderRevisionPDF(offerID, revision int) (string, error) {
+	rev, err := s.GetRevision(offerID, revision)
+	if err != nil {
+		return "", err
+	}
+	var client *models.Client
+	var c models.Client
+	if err := s.db.First(&c, rev.Offer.ClientID).Error; err == nil {
+		client = &c
+	}
+	url, err := NewPDFService().GenerateOfferRevisionPDF(rev, client)
+	if err != nil {
+		return "", err
+	}
+	if err := s.SetRevisionPDF(offerID, revision, url); err != nil {
+		return "", err
+	}
+	return url, nil
+}
+
+// DiffRevisions compares two revisions of the same offer.
+func (s *OfferService) DiffRevisions(offerID, from, to int) (*OfferRevisionDiff, error) {
+	a, err := s.GetRevision(offerID, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.GetRevision(offerID, to)
+	if err != nil {
+		return nil, err
+	}
+	diff := &OfferRevisionDiff{
+		From:    from,
+		To:      to,
+		Fields:  fieldDiff(auditSnapshot(a.Offer), auditSnapshot(b.Offer), revisionIgnoredFields),
+		Added:   []models.OfferItem{},
+		Removed: []models.OfferItem{},
+		Changed: []map[string]interface{}{},
+	}
+	if diff.Fields == nil {
+		diff.Fields = map[string]interface{}{}
+	}
+	for i := 0; i < len(a.Offer.Items) || i < len(b.Offer.Items); i++ {
+		switch {
+		case i >= len(a.Offer.Items):
+			diff.Added = append(diff.Added, b.Offer.Items[i])
+		case i >= len(b.Offer.Items):
+			diff.Removed = append(diff.Removed, a.Offer.Items[i])
+		default:
+			ch := fieldDiff(auditSnapshot(a.Offer.Items[i]), auditSnapshot(b.Offer.Items[i]), revisionItemIgnoredFields)
+			if ch != nil {
+				diff.Changed = append(diff.Changed, map[string]interface{}{"position": i + 1, "fields": ch})
+			}
+		}
+	}
+	return diff, nil
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestOfferRevisions_SnapshotOnSendAndDiff(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Offer{}, &models.OfferItem{}, &models.NumberSequence{}, &models.OfferStatusChange{}, &models.OfferRevision{}, &models.OfferLink{}, &models.OfferSignature{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	svc := NewOfferService(db)
+	now := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	offer := &models.Offer{ClientID: 1, Subject: "Website", Items: models.OfferItems{
+		{Description: "Design", Qty: 1, UnitPrice: 1000},
+		{Description: "Hosting", Qty: 12, UnitPrice: 50},
+	}}
+	if err := svc.CreateOffer(offer); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	sent, err := svc.TransitionOffer(offer.ID, models.OfferSent, nil, "", now)
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if sent.Revision != 1 {
+		t.Fatalf("expected revision 1, got %d", sent.Revision)
+	}
+	if _, err := svc.UpdateOffer(offer.ID, &models.Offer{Subject: "Changed"}); !errors.Is(err, ErrOfferLocked) {
+		t.Fatalf("expected sent offer to be locked, got %v", err)
+	}
+
+	// Negotiate: revise, change price and scope, send again.
+	if _, err := svc.TransitionOffer(offer.ID, models.OfferDraft, nil, "client asked for discount", now); err != nil {
+		t.Fatalf("revise: %v", err)
+	}
+	if _, err := svc.UpdateOffer(offer.ID, &models.Offer{Subject: "Website v2", Items: models.OfferItems{
+		{Description: "Design", Qty: 1, UnitPrice: 800},
+	}}); err != nil {
+		t.Fatalf("update draft: %v", err)
+	}
+	if _, err := svc.TransitionOffer(offer.ID, models.OfferSent, nil, "", now.Add(time.Hour)); err != nil {
+		t.Fatalf("resend: %v", err)
+	}
+	accepted, err := svc.ApproveOffer(offer.ID, now.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	if accepted.AcceptedRevision == nil || *accepted.AcceptedRevision != 2 {
+		t.Fatalf("expected revision 2 accepted, got %v", accepted.AcceptedRevision)
+	}
+
+	revs, err := svc.ListRevisions(offer.ID)
+	if err != nil || len(revs) != 2 {
+		t.Fatalf("expected 2 revisions, got %d (%v)", len(revs), err)
+	}
+	if revs[0].OfferNumber != offer.OfferNumber || revs[1].OfferNumber != offer.OfferNumber+"/R2" {
+		t.Fatalf("unexpected revision numbers %q, %q", revs[0].OfferNumber, revs[1].OfferNumber)
+	}
+	if revs[0].AcceptedAt != nil || revs[1].AcceptedAt == nil {
+		t.Fatalf("expected only revision 2 marked accepted")
+	}
+
+	r1, err := svc.GetRevision(offer.ID, 1)
+	if err != nil {
+		t.Fatalf("get revision: %v", err)
+	}
+	if r1.Offer.Subject != "Website" || len(r1.Offer.Items) != 2 || r1.Offer.TotalPrice != 1600 {
+		t.Fatalf("revision 1 snapshot changed: %+v", r1.Offer)
+	}
+
+	diff, err := svc.DiffRevisions(offer.ID, 1, 2)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	if _, ok := diff.Fields["subject"]; !ok {
+		t.Fatalf("expected subject change, got %v", diff.Fields)
+	}
+	if _, ok := diff.Fields["total_price"]; !ok {
+		t.Fatalf("expected total change, got %v", diff.Fields)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Description != "Hosting" || len(diff.Added) != 0 {
+		t.Fatalf("expected Hosting removed, got %+v", diff)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0]["position"] != 1 {
+		t.Fatalf("expected line 1 changed, got %+v", diff.Changed)
+	}
+}
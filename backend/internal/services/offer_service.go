@@ -1,10 +1,14 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"freelance-monitor-system/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"strings"
 )
 
@@ -86,15 +90,40 @@ func (s *OfferService) ListOffersWithFilters(limit, offset int, sortBy, order, s
 	return offers, nil
 }
 
+// GetOfferByID returns the offer with its line items in order.
 func (s *OfferService) GetOfferByID(id int) (*models.Offer, error) {
 	var offer models.Offer
-	if err := s.db.First(&offer, id).Error; err != nil {
+	if err := s.db.Preload("Items", orderByPosition).First(&offer, id).Error; err != nil {
 		return nil, err
 	}
 	return &offer, nil
 }
 
+// ErrOfferTransition is returned when a status change is not allowed from the
+// offer's current status (see models.OfferTransitions).
+var ErrOfferTransition = errors.New("offer status transition not allowed")
+
+func orderByPosition(db *gorm.DB) *gorm.DB { return db.Order("position") }
+
+// CreateOffer prices the line items (see PriceOffer) and stores the offer with
+// them. New offers always start as draft.
 func (s *OfferService) CreateOffer(offer *models.Offer) error {
+	offer.Currency = nonEmpty(normalizeCurrency(offer.Currency), BaseCurrency())
+	if !validCurrency(offer.Currency) {
+		return FieldErrors{"currency": "must be a 3-letter ISO code"}
+	}
+	if err := fillFromCatalog(s.db, offer.Items, offer.Currency); err != nil {
+		return err
+	}
+	if err := PriceOffer(offer); err != nil {
+		return err
+	}
+	offer.ExchangeRate = 0
+	offer.ExchangeRateDate = nil
+	offer.Status = models.OfferDraft
+	offer.ApprovedAt = nil
+	offer.Revision = 0
+	offer.AcceptedRevision = nil
 	if offer.AutoRenew {
 		if offer.RenewEveryDays <= 0 {
 			offer.RenewEveryDays = 30
@@ -107,34 +136,54 @@ func (s *OfferService) CreateOffer(offer *models.Offer) error {
 			offer.NextRenewal = base.Add(time.Duration(offer.RenewEveryDays) * 24 * time.Hour)
 		}
 	}
-	return s.db.Create(offer).Error
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(offer).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.OfferStatusChange{OfferID: offer.ID, ToStatus: models.OfferDraft}).Error
+	})
 }
 
+// UpdateOffer applies the non-zero fields of updates. When updates.Items is
+// non-nil the line items are replaced and the totals recomputed. Status is not
+// updated here; use TransitionOffer. Content of non-draft offers is locked
+// (ErrOfferLocked) so sent revisions stay as sent; auto-renew settings can
+// still change.
 func (s *OfferService) UpdateOffer(id int, updates *models.Offer) (*models.Offer, error) {
 	var offer models.Offer
 	if err := s.db.First(&offer, id).Error; err != nil {
 		return nil, err
 	}
+	if offer.Status != models.OfferDraft && editsOfferContent(updates) {
+		return nil, ErrOfferLocked
+	}
 	// Apply updates
 	if updates.Subject != "" {
 		offer.Subject = updates.Subject
 	}
-	if updates.Items != "" {
-		offer.Items = updates.Items
+	if updates.Currency != "" {
+		if offer.Currency = normalizeCurrency(updates.Currency); !validCurrency(offer.Currency) {
+			return nil, FieldErrors{"currency": "must be a 3-letter ISO code"}
+		}
 	}
-	if updates.TotalPrice != 0 {
-		offer.TotalPrice = updates.TotalPrice
+	replaceItems := updates.Items != nil
+	if replaceItems {
+		offer.Items = updates.Items
+		for i := range offer.Items {
+			offer.Items[i].ID = 0
+			offer.Items[i].OfferID = offer.ID
+		}
+		if err := fillFromCatalog(s.db, offer.Items, offer.Currency); err != nil {
+			return nil, err
+		}
+		if err := PriceOffer(&offer); err != nil {
+			return nil, err
+		}
 	}
 	if updates.Notes != "" {
 		offer.Notes = updates.Notes
 	}
-	if updates.Status != "" {
-		offer.Status = updates.Status
-	}
 	// Additional fields
-	if updates.Currency != "" {
-		offer.Currency = updates.Currency
-	}
 	if updates.ValidUntil != nil {
 		offer.ValidUntil = updates.ValidUntil
 	}
@@ -212,106 +261,188 @@ func (s *OfferService) UpdateOffer(id int, updates *models.Offer) (*models.Offer
 	if updates.SignedDocURL != "" {
 		offer.SignedDocURL = updates.SignedDocURL
 	}
-	if err := s.db.Save(&offer).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(&offer).Error; err != nil {
+			return err
+		}
+		if !replaceItems {
+			return nil
+		}
+		if err := tx.Where("offer_id = ?", offer.ID).Delete(&models.OfferItem{}).Error; err != nil {
+			return err
+		}
+		if len(offer.Items) == 0 {
+			return nil
+		}
+		return tx.Create(&offer.Items).Error
+	})
+	if err != nil {
 		return nil, err
 	}
-	return &offer, nil
+	return s.GetOfferByID(id)
+}
+
+// editsOfferContent reports whether updates touch what the client sees.
+func editsOfferContent(u *models.Offer) bool {
+	return u.Subject != "" || u.Items != nil || u.Notes != "" || u.Currency != "" || u.ValidUntil != nil ||
+		u.IssuerName != "" || u.IssuerCompany != "" || u.IssuerAddress != "" || u.IssuerCity != "" ||
+		u.IssuerPhone != "" || u.IssuerEmail != "" || u.ClientAttention != "" || u.OfferTitle != "" ||
+		u.ProposalSummary != "" || u.ProposalDetails != "" || u.PaymentTerms != "" || u.ClosingText != "" ||
+		u.SignatureTitle != "" || u.SignatureCompany != "" || u.SignatureCity != "" || !u.Date.IsZero()
+}
+
+// SetPDFURL records the offer's working-copy PDF.
+func (s *OfferService) SetPDFURL(id int, url string) error {
+	return s.db.Model(&models.Offer{}).Where("id = ?", id).Update("pdf_url", url).Error
 }
 
+// DeleteOffer removes the offer with its items, revisions, public links,
+// signatures and status history.
 func (s *OfferService) DeleteOffer(id int) error {
-	res := s.db.Delete(&models.Offer{}, id)
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for _, child := range []interface{}{&models.OfferItem{}, &models.OfferRevision{}, &models.OfferLink{}, &models.OfferSignature{}, &models.OfferStatusChange{}} {
+			if err := tx.Where("offer_id = ?", id).Delete(child).Error; err != nil {
+				return err
+			}
+		}
+		res := tx.Delete(&models.Offer{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// TransitionOffer moves the offer to status to, enforcing models.OfferTransitions,
+// and records the change in the offer's status history. Accepting stamps ApprovedAt.
+func (s *OfferService) TransitionOffer(id int, to string, actorID *int, note string, at time.Time) (*models.Offer, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var offer models.Offer
+		if err := tx.First(&offer, id).Error; err != nil {
+			return err
+		}
+		return transitionOffer(tx, &offer, to, actorID, note, at)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetOfferByID(id)
+}
+
+func transitionOffer(tx *gorm.DB, offer *models.Offer, to string, actorID *int, note string, at time.Time) error {
+	if _, known := models.OfferTransitions[to]; !known {
+		return fmt.Errorf("unknown offer status %q", to)
+	}
+	from := nonEmpty(offer.Status, models.OfferDraft)
+	if !models.CanTransitionOffer(from, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrOfferTransition, from, to)
+	}
+	updates := map[string]interface{}{"status": to}
+	if to == models.OfferAccepted {
+		updates["approved_at"] = at
+	}
+	if to == models.OfferSent || to == models.OfferAccepted {
+		// Snapshot the exchange rate. An offer can't be sent without one;
+		// acceptance keeps the send-time rate if none is on file.
+		rate, day, err := exchangeRateOn(tx, offer.Currency, at)
+		switch {
+		case err == nil:
+			updates["exchange_rate"] = rate
+			updates["exchange_rate_date"] = day
+			offer.ExchangeRate, offer.ExchangeRateDate = rate, &day
+		case errors.Is(err, ErrNoExchangeRate) && to == models.OfferSent:
+			return FieldErrors{"currency": err.Error()}
+		case !errors.Is(err, ErrNoExchangeRate):
+			return err
+		}
+	}
+	// Guard on the status we read so a concurrent transition can't be overwritten.
+	res := tx.Model(&models.Offer{}).Where("id = ? AND status = ?", offer.ID, offer.Status).Updates(updates)
 	if res.Error != nil {
 		return res.Error
 	}
 	if res.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
-	}
-	return nil
-}
-
-// ApproveOffer sets status to 'accepted' and stamps ApprovedAt.
-func (s *OfferService) ApproveOffer(id int, approvedAt time.Time) (*models.Offer, error) {
-	var offer models.Offer
-	if err := s.db.First(&offer, id).Error; err != nil {
-		return nil, err
+		return fmt.Errorf("%w: %s changed concurrently", ErrOfferTransition, from)
 	}
-	offer.Status = "accepted"
-	offer.ApprovedAt = &approvedAt
-	if err := s.db.Save(&offer).Error; err != nil {
-		return nil, err
+	offer.Status = to
+	switch to {
+	case models.OfferSent:
+		if err := snapshotRevision(tx, offer, actorID, at); err != nil {
+			return err
+		}
+	case models.OfferAccepted:
+		if err := acceptRevision(tx, offer, at); err != nil {
+			return err
+		}
 	}
-	return &offer, nil
+	return tx.Create(&models.OfferStatusChange{
+		OfferID:    offer.ID,
+		FromStatus: from,
+		ToStatus:   to,
+		ActorID:    actorID,
+		Note:       note,
+		CreatedAt:  at,
+	}).Error
 }
 
-// SetSignedDocAndApprove saves a signed document URL and marks the offer accepted.
-func (s *OfferService) SetSignedDocAndApprove(id int, signedURL string, approvedAt time.Time) (*models.Offer, error) {
-	var offer models.Offer
-	if err := s.db.First(&offer, id).Error; err != nil {
+// OfferHistory returns the offer's status changes in the order they were recorded.
+func (s *OfferService) OfferHistory(id int) ([]models.OfferStatusChange, error) {
+	if _, err := s.GetOfferByID(id); err != nil {
 		return nil, err
 	}
-	offer.SignedDocURL = signedURL
-	offer.Status = "accepted"
-	offer.ApprovedAt = &approvedAt
-	if err := s.db.Save(&offer).Error; err != nil {
+	var rows []models.OfferStatusChange
+	if err := s.db.Where("offer_id = ?", id).Order("id").Find(&rows).Error; err != nil {
 		return nil, err
 	}
-	return &offer, nil
+	return rows, nil
 }
 
-// RenewDueOffers finds offers with auto_renew=true and next_renewal <= now,
-// creates a new offer cloned from each, and advances next_renewal.
-func (s *OfferService) RenewDueOffers(now time.Time) (int, error) {
+// ExpireOffers moves sent or viewed offers whose ValidUntil has passed to expired.
+func (s *OfferService) ExpireOffers(ctx context.Context, now time.Time) (int, error) {
+	db := s.db.WithContext(ctx)
 	var due []models.Offer
-	if err := s.db.Where("auto_renew = ? AND next_renewal <= ?", true, now).Find(&due).Error; err != nil {
+	if err := db.Where("valid_until IS NOT NULL AND valid_until < ? AND status IN ?", now, []string{models.OfferSent, models.OfferViewed}).
+		Find(&due).Error; err != nil {
 		return 0, err
 	}
-	created := 0
+	expired := 0
 	for _, of := range due {
-		newOffer := models.Offer{
-			ClientID:   of.ClientID,
-			Subject:    of.Subject,
-			Items:      of.Items,
-			TotalPrice: of.TotalPrice,
-			Notes:      of.Notes,
-			Status:     of.Status,
-			// Let hook assign OfferNumber and Date
+		if err := ctx.Err(); err != nil {
+			return expired, err
 		}
-		if err := s.db.Create(&newOffer).Error; err != nil {
+		if _, err := NewOfferService(db).TransitionOffer(of.ID, models.OfferExpired, nil, "valid_until passed", now); err != nil {
 			continue
 		}
-		days := of.RenewEveryDays
-		if days <= 0 {
-			days = 30
-		}
-		next := of.NextRenewal.Add(time.Duration(days) * 24 * time.Hour)
-		_ = s.db.Model(&models.Offer{}).Where("id = ?", of.ID).Update("next_renewal", next).Error
-		created++
+		expired++
 	}
-	return created, nil
+	return expired, nil
 }
 
-// ToggleAutoRenew enables/disables auto-renewal and sets cycle days.
-func (s *OfferService) ToggleAutoRenew(id int, enable bool, days int) (*models.Offer, error) {
-	var offer models.Offer
-	if err := s.db.First(&offer, id).Error; err != nil {
-		return nil, err
-	}
-	offer.AutoRenew = enable
-	if enable {
-		if days <= 0 {
-			days = 30
+// ApproveOffer accepts a sent or viewed offer and stamps ApprovedAt.
+func (s *OfferService) ApproveOffer(id int, approvedAt time.Time) (*models.Offer, error) {
+	return s.TransitionOffer(id, models.OfferAccepted, nil, "", approvedAt)
+}
+
+// SetSignedDocAndApprove saves a signed document URL and accepts the offer
+// unless it already is.
+func (s *OfferService) SetSignedDocAndApprove(id int, signedURL string, approvedAt time.Time, actorID *int) (*models.Offer, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var offer models.Offer
+		if err := tx.First(&offer, id).Error; err != nil {
+			return err
 		}
-		offer.RenewEveryDays = days
-		if offer.NextRenewal.IsZero() {
-			base := offer.Date
-			if base.IsZero() {
-				base = time.Now()
+		if offer.Status != models.OfferAccepted {
+			if err := transitionOffer(tx, &offer, models.OfferAccepted, actorID, "signed document uploaded", approvedAt); err != nil {
+				return err
 			}
-			offer.NextRenewal = base.Add(time.Duration(days) * 24 * time.Hour)
 		}
-	}
-	if err := s.db.Save(&offer).Error; err != nil {
+		return tx.Model(&models.Offer{}).Where("id = ?", id).Update("signed_doc_url", signedURL).Error
+	})
+	if err != nil {
 		return nil, err
 	}
-	return &offer, nil
+	return s.GetOfferByID(id)
 }
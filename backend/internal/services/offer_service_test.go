@@ -1,7 +1,10 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"freelance-monitor-system/internal/models"
 	"gorm.io/driver/sqlite"
@@ -13,7 +16,7 @@ func TestOfferServiceCreateGeneratesNumber(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to open sqlite: %v", err)
 	}
-	if err := db.AutoMigrate(&models.Offer{}); err != nil {
+	if err := db.AutoMigrate(&models.Offer{}, &models.OfferItem{}, &models.NumberSequence{}, &models.OfferStatusChange{}, &models.OfferRevision{}, &models.OfferLink{}, &models.OfferSignature{}); err != nil {
 		t.Fatalf("failed to migrate: %v", err)
 	}
 
@@ -32,29 +35,38 @@ func TestOfferServiceUpdateDelete(t *testing.T) {
 	if err != nil {
 		t.Fatalf("sqlite: %v", err)
 	}
-	if err := db.AutoMigrate(&models.Offer{}); err != nil {
+	if err := db.AutoMigrate(&models.Offer{}, &models.OfferItem{}, &models.NumberSequence{}, &models.OfferStatusChange{}, &models.OfferRevision{}, &models.OfferLink{}, &models.OfferSignature{}); err != nil {
 		t.Fatalf("migrate: %v", err)
 	}
 	svc := NewOfferService(db)
 
-	offer := &models.Offer{ClientID: 1, Subject: "S1", Items: "[]", TotalPrice: 10}
+	offer := &models.Offer{ClientID: 1, Subject: "S1", Items: models.OfferItems{{Description: "Setup", Qty: 1, UnitPrice: 10}}}
 	if err := svc.CreateOffer(offer); err != nil {
 		t.Fatalf("create: %v", err)
 	}
-	upd := &models.Offer{Subject: "S2", TotalPrice: 20}
+	upd := &models.Offer{Subject: "S2", TotalPrice: 999, Items: models.OfferItems{{Description: "Setup", Qty: 2, UnitPrice: 10}}}
 	updated, err := svc.UpdateOffer(offer.ID, upd)
 	if err != nil {
 		t.Fatalf("update: %v", err)
 	}
-	if updated.Subject != "S2" || updated.TotalPrice != 20 {
+	if updated.Subject != "S2" || updated.TotalPrice != 20 || len(updated.Items) != 1 {
 		t.Fatalf("update not applied: %+v", updated)
 	}
+	db.Create(&models.OfferLink{OfferID: offer.ID, Revision: 1, TokenHash: "h", ExpiresAt: time.Now()})
+	db.Create(&models.OfferSignature{OfferID: offer.ID, Revision: 1, Decision: models.OfferDecisionReject})
 	if err := svc.DeleteOffer(offer.ID); err != nil {
 		t.Fatalf("delete: %v", err)
 	}
 	if _, err := svc.GetOfferByID(offer.ID); err == nil {
 		t.Fatalf("expected not found after delete")
 	}
+	for _, child := range []interface{}{&models.OfferItem{}, &models.OfferLink{}, &models.OfferSignature{}, &models.OfferStatusChange{}} {
+		var n int64
+		db.Model(child).Where("offer_id = ?", offer.ID).Count(&n)
+		if n != 0 {
+			t.Fatalf("expected %T rows to be deleted, found %d", child, n)
+		}
+	}
 }
 
 func TestOfferServiceUpdateNonExistent(t *testing.T) {
@@ -62,7 +74,7 @@ func TestOfferServiceUpdateNonExistent(t *testing.T) {
 	if err != nil {
 		t.Fatalf("sqlite: %v", err)
 	}
-	if err := db.AutoMigrate(&models.Offer{}); err != nil {
+	if err := db.AutoMigrate(&models.Offer{}, &models.OfferItem{}, &models.NumberSequence{}, &models.OfferStatusChange{}, &models.OfferRevision{}, &models.OfferLink{}, &models.OfferSignature{}); err != nil {
 		t.Fatalf("migrate: %v", err)
 	}
 	svc := NewOfferService(db)
@@ -70,3 +82,105 @@ func TestOfferServiceUpdateNonExistent(t *testing.T) {
 		t.Fatalf("expected error updating non-existent offer")
 	}
 }
+
+func TestOfferServiceTransitionsAndHistory(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Offer{}, &models.OfferItem{}, &models.NumberSequence{}, &models.OfferStatusChange{}, &models.OfferRevision{}, &models.OfferLink{}, &models.OfferSignature{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	svc := NewOfferService(db)
+	now := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	offer := &models.Offer{ClientID: 1, Subject: "S", Status: models.OfferAccepted}
+	if err := svc.CreateOffer(offer); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if offer.Status != models.OfferDraft {
+		t.Fatalf("new offers must start as draft, got %q", offer.Status)
+	}
+	if _, err := svc.ApproveOffer(offer.ID, now); !errors.Is(err, ErrOfferTransition) {
+		t.Fatalf("expected draft -> accepted to be rejected, got %v", err)
+	}
+	if _, err := svc.TransitionOffer(offer.ID, "archived", nil, "", now); err == nil {
+		t.Fatalf("expected unknown status to be rejected")
+	}
+	actor := 7
+	for _, to := range []string{models.OfferSent, models.OfferViewed, models.OfferAccepted} {
+		if _, err := svc.TransitionOffer(offer.ID, to, &actor, "step", now); err != nil {
+			t.Fatalf("transition to %s: %v", to, err)
+		}
+	}
+	got, _ := svc.GetOfferByID(offer.ID)
+	if got.Status != models.OfferAccepted || got.ApprovedAt == nil {
+		t.Fatalf("expected accepted with approved_at, got %+v", got)
+	}
+	if _, err := svc.TransitionOffer(offer.ID, models.OfferRejected, nil, "", now); !errors.Is(err, ErrOfferTransition) {
+		t.Fatalf("expected accepted -> rejected to be rejected, got %v", err)
+	}
+
+	hist, err := svc.OfferHistory(offer.ID)
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	want := [][2]string{{"", "draft"}, {"draft", "sent"}, {"sent", "viewed"}, {"viewed", "accepted"}}
+	if len(hist) != len(want) {
+		t.Fatalf("expected %d history rows, got %d", len(want), len(hist))
+	}
+	for i, w := range want {
+		if hist[i].FromStatus != w[0] || hist[i].ToStatus != w[1] {
+			t.Fatalf("row %d: got %s -> %s, want %s -> %s", i, hist[i].FromStatus, hist[i].ToStatus, w[0], w[1])
+		}
+	}
+	if hist[1].ActorID == nil || *hist[1].ActorID != actor {
+		t.Fatalf("expected actor recorded, got %+v", hist[1])
+	}
+}
+
+func TestOfferServiceExpireOffers(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Offer{}, &models.OfferItem{}, &models.NumberSequence{}, &models.OfferStatusChange{}, &models.OfferRevision{}, &models.OfferLink{}, &models.OfferSignature{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	svc := NewOfferService(db)
+	now := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	past, future := now.AddDate(0, 0, -1), now.AddDate(0, 0, 5)
+
+	mk := func(validUntil time.Time, send bool) int {
+		o := &models.Offer{ClientID: 1, Subject: "S", ValidUntil: &validUntil}
+		if err := svc.CreateOffer(o); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if send {
+			if _, err := svc.TransitionOffer(o.ID, models.OfferSent, nil, "", now.AddDate(0, 0, -10)); err != nil {
+				t.Fatalf("send: %v", err)
+			}
+		}
+		return o.ID
+	}
+	stale, fresh, draft := mk(past, true), mk(future, true), mk(past, false)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if n, err := svc.ExpireOffers(canceled, now); err == nil || n != 0 {
+		t.Fatalf("expected a canceled run to stop, got %d (%v)", n, err)
+	}
+	n, err := svc.ExpireOffers(context.Background(), now)
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 expired, got %d (%v)", n, err)
+	}
+	for id, want := range map[int]string{stale: models.OfferExpired, fresh: models.OfferSent, draft: models.OfferDraft} {
+		o, _ := svc.GetOfferByID(id)
+		if o.Status != want {
+			t.Fatalf("offer %d: expected %s, got %s", id, want, o.Status)
+		}
+	}
+	if n, _ := svc.ExpireOffers(context.Background(), now); n != 0 {
+		t.Fatalf("expected expiry to be idempotent, got %d", n)
+	}
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/gorm"
+)
+
+// OfferFromTemplateInput creates an offer from a template. Items, when set,
+// replace the template's lines; AddItems are appended (e.g. lines picked from
+// the catalog by catalog_item_id). Empty fields keep the template's values.
+type OfferFromTemplateInput struct {
+	ClientID        int                `json:"client_id"`
+	Subject         string             `json:"subject"`
+	Date            *time.Time         `json:"date"`
+	ClientAttention string             `json:"client_attention"`
+	Currency        string             `json:"currency"`
+	Items           []models.OfferItem `json:"items"`
+	AddItems        []models.OfferItem `json:"add_items"`
+}
+
+// OfferTemplateService maintains offer templates and creates offers from them.
+type OfferTemplateService struct {
+	db     *gorm.DB
+	offers *OfferService
+}
+
+func NewOfferTemplateService(db *gorm.DB) *OfferTemplateService {
+	return &OfferTemplateService{db: db, offers: NewOfferService(db)}
+}
+
+// List returns templates with their lines, ordered by name.
+func (s *OfferTemplateService) List(ctx context.Context) ([]models.OfferTemplate, error) {
+	var rows []models.OfferTemplate
+	if err := s.db.WithContext(ctx).Preload("Items", orderByPosition).Order("name").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (s *OfferTemplateService) Get(ctx context.Context, id int) (*models.OfferTemplate, error) {
+	var tpl models.OfferTemplate
+	if err := s.db.WithContext(ctx).Preload("Items", orderByPosition).First(&tpl, id).Error; err != nil {
+		return nil, err
+	}
+	return &tpl, nil
+}
+
+// Create stores a template and its lines.
+func (s *OfferTemplateService) Create(ctx context.Context, tpl *models.OfferTemplate) error {
+	tpl.ID = 0
+	if err := s.validate(ctx, tpl); err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Create(tpl).Error
+}
+
+// Update replaces the template, including all of its lines.
+func (s *OfferTemplateService) Update(ctx context.Context, id int, in *models.OfferTemplate) (*models.OfferTemplate, error) {
+	existing, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	in.ID = id
+	in.CreatedAt = existing.CreatedAt
+	if err := s.validate(ctx, in); err != nil {
+		return nil, err
+	}
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Items").Save(in).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("template_id = ?", id).Delete(&models.OfferTemplateItem{}).Error; err != nil {
+			return err
+		}
+		if len(in.Items) == 0 {
+			return nil
+		}
+		return tx.Create(&in.Items).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, id)
+}
+
+func (s *OfferTemplateService) Delete(ctx context.Context, id int) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("template_id = ?", id).Delete(&models.OfferTemplateItem{}).Error; err != nil {
+			return err
+		}
+		res := tx.Delete(&models.OfferTemplate{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// CreateOffer creates a draft offer prefilled from the template. Lines that
+// reference the catalog are completed from it and all lines are priced as in
+// CreateOffer.
+func (s *OfferTemplateService) CreateOffer(ctx context.Context, id int, in OfferFromTemplateInput) (*models.Offer, error) {
+	tpl, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if in.ClientID <= 0 {
+		return nil, FieldErrors{"client_id": "is required"}
+	}
+	offer := offerFromTemplate(tpl)
+	offer.ClientID = in.ClientID
+	offer.Subject = nonEmpty(strings.TrimSpace(in.Subject), tpl.Subject, tpl.Name)
+	offer.ClientAttention = strings.TrimSpace(in.ClientAttention)
+	offer.Currency = nonEmpty(in.Currency, tpl.Currency)
+	if in.Date != nil {
+		offer.Date = *in.Date
+	} else {
+		offer.Date = time.Now()
+	}
+	if tpl.ValidDays > 0 {
+		until := offer.Date.AddDate(0, 0, tpl.ValidDays)
+		offer.ValidUntil = &until
+	}
+	if in.Items != nil {
+		offer.Items = in.Items
+	}
+	offer.Items = append(offer.Items, in.AddItems...)
+	for i := range offer.Items {
+		offer.Items[i].ID = 0
+		offer.Items[i].OfferID = 0
+	}
+	if err := s.offers.CreateOffer(offer); err != nil {
+		return nil, err
+	}
+	return s.offers.GetOfferByID(offer.ID)
+}
+
+// validate normalizes the template and checks its lines by pricing them as an
+// offer would be, so errors carry the same items[i].field paths.
+func (s *OfferTemplateService) validate(ctx context.Context, tpl *models.OfferTemplate) error {
+	tpl.Name = strings.TrimSpace(tpl.Name)
+	if tpl.Name == "" {
+		return FieldErrors{"name": "is required"}
+	}
+	if tpl.Currency = normalizeCurrency(tpl.Currency); tpl.Currency != "" && !validCurrency(tpl.Currency) {
+		return FieldErrors{"currency": "must be a 3-letter ISO code"}
+	}
+	if tpl.ValidDays < 0 {
+		return FieldErrors{"valid_days": "must not be negative"}
+	}
+	probe := offerFromTemplate(tpl)
+	if err := fillFromCatalog(s.db.WithContext(ctx), probe.Items, probe.Currency); err != nil {
+		return err
+	}
+	if err := PriceOffer(probe); err != nil {
+		return err
+	}
+	for i := range tpl.Items {
+		tpl.Items[i].ID = 0
+		tpl.Items[i].TemplateID = tpl.ID
+		tpl.Items[i].Position = i + 1
+	}
+	return nil
+}
+
+// offerFromTemplate copies the template's fields and lines into a new offer.
+func offerFromTemplate(tpl *models.OfferTemplate) *models.Offer {
+	offer := &models.Offer{
+		OfferTitle:       tpl.OfferTitle,
+		Currency:         tpl.Currency,
+		IssuerName:       tpl.IssuerName,
+		IssuerCompany:    tpl.IssuerCompany,
+		IssuerAddress:    tpl.IssuerAddress,
+		IssuerCity:       tpl.IssuerCity,
+		IssuerPhone:      tpl.IssuerPhone,
+		IssuerEmail:      tpl.IssuerEmail,
+		ProposalSummary:  tpl.ProposalSummary,
+		ProposalDetails:  tpl.ProposalDetails,
+		PaymentTerms:     tpl.PaymentTerms,
+		ClosingText:      tpl.ClosingText,
+		Notes:            tpl.Notes,
+		SignatureTitle:   tpl.SignatureTitle,
+		SignatureCompany: tpl.SignatureCompany,
+		SignatureCity:    tpl.SignatureCity,
+		Items:            make(models.OfferItems, 0, len(tpl.Items)),
+	}
+	for _, it := range tpl.Items {
+		offer.Items = append(offer.Items, models.OfferItem{
+			CatalogItemID:   it.CatalogItemID,
+			Name:            it.Name,
+			Description:     it.Description,
+			Qty:             it.Qty,
+			Unit:            it.Unit,
+			UnitPrice:       it.UnitPrice,
+			DiscountPercent: it.DiscountPercent,
+			TaxRate:         it.TaxRate,
+		})
+	}
+	return offer
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+)
+
+func TestOfferTemplateCreatesOfferFromCatalog(t *testing.T) {
+	db := newInvoiceTestDB(t)
+	if err := db.AutoMigrate(&models.CatalogItem{}, &models.OfferTemplate{}, &models.OfferTemplateItem{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	ctx := context.Background()
+	catalog := NewCatalogService(db)
+	hosting, err := catalog.Create(ctx, CatalogItemInput{Name: "Managed hosting", Unit: "month", UnitPrice: 500000, TaxRate: 11})
+	if err != nil {
+		t.Fatalf("catalog: %v", err)
+	}
+	audit, err := catalog.Create(ctx, CatalogItemInput{Name: "Security audit", Description: "One-off audit", UnitPrice: 2000000})
+	if err != nil {
+		t.Fatalf("catalog: %v", err)
+	}
+	usd, err := catalog.Create(ctx, CatalogItemInput{Name: "Consulting", Unit: "hour", UnitPrice: 80, Currency: "usd"})
+	if err != nil || usd.Currency != "USD" {
+		t.Fatalf("catalog usd: %+v %v", usd, err)
+	}
+	var fe FieldErrors
+	if _, err := catalog.Create(ctx, CatalogItemInput{UnitPrice: -1}); !errors.As(err, &fe) || fe["name"] == "" || fe["unit_price"] == "" {
+		t.Fatalf("expected validation errors, got %v", err)
+	}
+
+	templates := NewOfferTemplateService(db)
+	tpl := &models.OfferTemplate{
+		Name: "Hosting", Subject: "Hosting proposal", ValidDays: 30,
+		IssuerName: "Budi", IssuerCompany: "MSI", PaymentTerms: "Net 14", SignatureTitle: "Director",
+		ProposalSummary: "Managed hosting for your site",
+		Items:           []models.OfferTemplateItem{{CatalogItemID: &hosting.ID, Qty: 12}, {Description: "Setup", Qty: 1, UnitPrice: 250000}},
+	}
+	if err := templates.Create(ctx, tpl); err != nil {
+		t.Fatalf("template: %v", err)
+	}
+	bad := &models.OfferTemplate{Name: "Bad", Items: []models.OfferTemplateItem{{Description: "x", Qty: 0}}}
+	if err := templates.Create(ctx, bad); !errors.As(err, &fe) || fe["items[0].qty"] == "" {
+		t.Fatalf("expected item validation error, got %v", err)
+	}
+
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	offer, err := templates.CreateOffer(ctx, tpl.ID, OfferFromTemplateInput{
+		ClientID: 7, Date: &date,
+		AddItems: []models.OfferItem{{CatalogItemID: &audit.ID}},
+	})
+	if err != nil {
+		t.Fatalf("create offer: %v", err)
+	}
+	if offer.Subject != "Hosting proposal" || offer.IssuerCompany != "MSI" || offer.PaymentTerms != "Net 14" || offer.SignatureTitle != "Director" || offer.Status != models.OfferDraft {
+		t.Fatalf("template fields not copied: %+v", offer)
+	}
+	if offer.ValidUntil == nil || !offer.ValidUntil.Equal(date.AddDate(0, 0, 30)) {
+		t.Fatalf("unexpected valid_until: %v", offer.ValidUntil)
+	}
+	if len(offer.Items) != 3 || offer.Items[0].Name != "Managed hosting" || offer.Items[0].Unit != "month" || offer.Items[0].TaxRate != 11 ||
+		offer.Items[2].Description != "One-off audit" || offer.Items[2].Qty != 1 {
+		t.Fatalf("unexpected items: %+v", offer.Items)
+	}
+	// 12 x 500000 + 11% tax, setup, audit
+	if offer.TotalPrice != 6660000+250000+2000000 {
+		t.Fatalf("unexpected total %v", offer.TotalPrice)
+	}
+
+	if _, err := templates.CreateOffer(ctx, tpl.ID, OfferFromTemplateInput{ClientID: 7, AddItems: []models.OfferItem{{CatalogItemID: &usd.ID}}}); !errors.As(err, &fe) || fe["items[2].catalog_item_id"] == "" {
+		t.Fatalf("expected currency mismatch error, got %v", err)
+	}
+	usdOffer, err := templates.CreateOffer(ctx, tpl.ID, OfferFromTemplateInput{ClientID: 7, Currency: "USD",
+		Items: []models.OfferItem{{CatalogItemID: &usd.ID, Qty: 10}}})
+	if err != nil || len(usdOffer.Items) != 1 || usdOffer.TotalPrice != 800 {
+		t.Fatalf("usd offer: %+v %v", usdOffer, err)
+	}
+
+	// An explicit zero price or tax rate is kept rather than filled.
+	var free models.OfferItems
+	if err := json.Unmarshal([]byte(fmt.Sprintf(`[{"catalog_item_id":%d,"qty":12,"tax_rate":0},{"catalog_item_id":%d,"unit_price":0}]`, hosting.ID, audit.ID)), &free); err != nil {
+		t.Fatal(err)
+	}
+	freeOffer, err := templates.CreateOffer(ctx, tpl.ID, OfferFromTemplateInput{ClientID: 7, Items: free})
+	if err != nil || freeOffer.Items[0].TaxRate != 0 || freeOffer.Items[0].UnitPrice != 500000 || freeOffer.Items[1].UnitPrice != 0 || freeOffer.TotalPrice != 6000000 {
+		t.Fatalf("expected explicit zeros to be kept: %+v %v", freeOffer, err)
+	}
+
+	if err := catalog.Delete(ctx, hosting.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	got, err := templates.Get(ctx, tpl.ID)
+	if err != nil || got.Items[0].CatalogItemID != nil || got.Items[0].Name != "Managed hosting" || got.Items[0].UnitPrice != 500000 {
+		t.Fatalf("expected template line to keep the catalog values after delete: %+v %v", got, err)
+	}
+	inactive := false
+	if _, err := catalog.Update(ctx, audit.ID, CatalogItemInput{Name: "Security audit", UnitPrice: 2500000, Active: &inactive}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if items, _ := catalog.List(ctx, CatalogFilter{}); len(items) != 1 {
+		t.Fatalf("expected inactive item hidden, got %+v", items)
+	}
+}
@@ -0,0 +1,515 @@
+package services
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+// pdfFont is a font the layout engine can measure text with and embed.
+type pdfFont interface {
+	// advance returns the width of r in thousandths of the font size.
+	advance(r rune) float64
+	// encode returns s as a PDF string operand for the Tj operator.
+	encode(s string) string
+	// write emits the font's objects and returns the font dictionary's number.
+	write(w *pdfWriter) int
+}
+
+// textWidth measures s set in f at the given size, in points.
+func textWidth(f pdfFont, s string, size float64) float64 {
+	var total float64
+	for _, r := range s {
+		total += f.advance(r)
+	}
+	return total * size / 1000
+}
+
+// standardFont is one of the PDF base-14 Helvetica faces. It needs no
+// embedding but can only show characters in WinAnsiEncoding (Latin-1 plus a
+// few typographic marks); anything else is drawn as "?".
+type standardFont struct {
+	name   string
+	ascii  *[95]int16 // widths for U+0020..U+007E
+	latin1 *[96]int16 // widths for U+00A0..U+00FF
+	extra  map[rune]int16
+}
+
+func newHelvetica(bold bool) *standardFont {
+	if bold {
+		return &standardFont{name: "Helvetica-Bold", ascii: &helveticaBoldASCII, latin1: &helveticaBoldLatin1, extra: helveticaBoldExtra}
+	}
+	return &standardFont{name: "Helvetica", ascii: &helveticaASCII, latin1: &helveticaLatin1, extra: helveticaExtra}
+}
+
+// winAnsiExtra maps the non-Latin-1 characters WinAnsiEncoding carries in
+// 0x80..0x9F to their byte values.
+var winAnsiExtra = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+func (f *standardFont) winAnsi(r rune) (byte, bool) {
+	switch {
+	case r >= 0x20 && r <= 0x7e, r >= 0xa0 && r <= 0xff:
+		return byte(r), true
+	}
+	b, ok := winAnsiExtra[r]
+	return b, ok
+}
+
+func (f *standardFont) advance(r rune) float64 {
+	switch {
+	case r >= 0x20 && r <= 0x7e:
+		return float64(f.ascii[r-0x20])
+	case r >= 0xa0 && r <= 0xff:
+		return float64(f.latin1[r-0xa0])
+	}
+	if w, ok := f.extra[r]; ok {
+		return float64(w)
+	}
+	return float64(f.ascii['?'-0x20])
+}
+
+func (f *standardFont) encode(s string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range s {
+		c, ok := f.winAnsi(r)
+		if !ok {
+			c = '?'
+		}
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c > 0x7e:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+func (f *standardFont) write(w *pdfWriter) int {
+	n := w.alloc()
+	w.obj(n, fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", f.name))
+	return n
+}
+
+// trueTypeFont is a TrueType font embedded whole as a CID font. Text is
+// written as glyph IDs (Identity-H), so any character the font covers can be
+// shown; a ToUnicode map keeps the text searchable and copyable.
+type trueTypeFont struct {
+	data       []byte
+	name       string
+	unitsPerEm float64
+	bbox       [4]int16
+	ascent     int16
+	descent    int16
+	capHeight  int16
+	italic     float64
+	widths     []uint16 // advance per glyph, font units
+	cmap       map[rune]uint16
+	used       map[uint16]rune
+}
+
+// loadTrueTypeFont reads a .ttf file. CFF-flavoured OpenType and font
+// collections are rejected, as are fonts whose licence forbids embedding.
+func loadTrueTypeFont(path string) (*trueTypeFont, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := parseTrueType(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
+
+func parseTrueType(data []byte) (*trueTypeFont, error) {
+	if len(data) < 12 {
+		return nil, fmt.Errorf("not a TrueType font")
+	}
+	switch string(data[:4]) {
+	case "\x00\x01\x00\x00", "true":
+	case "OTTO":
+		return nil, fmt.Errorf("OpenType fonts with CFF outlines are not supported")
+	case "ttcf":
+		return nil, fmt.Errorf("font collections are not supported")
+	default:
+		return nil, fmt.Errorf("not a TrueType font")
+	}
+	tables := map[string][]byte{}
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	for i := 0; i < numTables; i++ {
+		rec := 12 + 16*i
+		if rec+16 > len(data) {
+			return nil, fmt.Errorf("truncated table directory")
+		}
+		tag := string(data[rec : rec+4])
+		off := int(binary.BigEndian.Uint32(data[rec+8:]))
+		length := int(binary.BigEndian.Uint32(data[rec+12:]))
+		if off < 0 || length < 0 || off+length > len(data) {
+			return nil, fmt.Errorf("table %q out of range", tag)
+		}
+		tables[tag] = data[off : off+length]
+	}
+	for _, tag := range []string{"head", "hhea", "hmtx", "maxp", "cmap"} {
+		if tables[tag] == nil {
+			return nil, fmt.Errorf("missing %s table", tag)
+		}
+	}
+
+	f := &trueTypeFont{data: data, used: map[uint16]rune{}}
+	head, hhea, maxp := tables["head"], tables["hhea"], tables["maxp"]
+	if len(head) < 54 || len(hhea) < 36 || len(maxp) < 6 {
+		return nil, fmt.Errorf("truncated header tables")
+	}
+	f.unitsPerEm = float64(binary.BigEndian.Uint16(head[18:]))
+	if f.unitsPerEm == 0 {
+		return nil, fmt.Errorf("invalid unitsPerEm")
+	}
+	for i := range f.bbox {
+		f.bbox[i] = int16(binary.BigEndian.Uint16(head[36+2*i:]))
+	}
+	f.ascent = int16(binary.BigEndian.Uint16(hhea[4:]))
+	f.descent = int16(binary.BigEndian.Uint16(hhea[6:]))
+	f.capHeight = f.ascent
+
+	numGlyphs := int(binary.BigEndian.Uint16(maxp[4:]))
+	numMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	hmtx := tables["hmtx"]
+	if numMetrics == 0 || numMetrics > numGlyphs || len(hmtx) < 4*numMetrics {
+		return nil, fmt.Errorf("invalid hmtx table")
+	}
+	f.widths = make([]uint16, numGlyphs)
+	for g := range f.widths {
+		m := g
+		if m >= numMetrics {
+			m = numMetrics - 1
+		}
+		f.widths[g] = binary.BigEndian.Uint16(hmtx[4*m:])
+	}
+
+	if os2 := tables["OS/2"]; len(os2) >= 10 {
+		// fsType bit 1 alone means "restricted licence embedding".
+		if binary.BigEndian.Uint16(os2[8:])&0x000f == 0x0002 {
+			return nil, fmt.Errorf("font licence does not permit embedding")
+		}
+		if binary.BigEndian.Uint16(os2) >= 2 && len(os2) >= 90 {
+			f.capHeight = int16(binary.BigEndian.Uint16(os2[88:]))
+		}
+	}
+	if post := tables["post"]; len(post) >= 8 {
+		f.italic = float64(int32(binary.BigEndian.Uint32(post[4:]))) / 65536
+	}
+
+	cmap, err := parseCmap(tables["cmap"])
+	if err != nil {
+		return nil, err
+	}
+	f.cmap = cmap
+	f.name = postScriptName(tables["name"])
+	return f, nil
+}
+
+// parseCmap picks the best Unicode subtable: format 12 (full Unicode) when
+// present, otherwise format 4 (BMP).
+func parseCmap(t []byte) (map[rune]uint16, error) {
+	if len(t) < 4 {
+		return nil, fmt.Errorf("invalid cmap table")
+	}
+	var best []byte
+	bestRank := 0
+	n := int(binary.BigEndian.Uint16(t[2:]))
+	for i := 0; i < n; i++ {
+		rec := 4 + 8*i
+		if rec+8 > len(t) {
+			break
+		}
+		platform := binary.BigEndian.Uint16(t[rec:])
+		encoding := binary.BigEndian.Uint16(t[rec+2:])
+		off := int(binary.BigEndian.Uint32(t[rec+4:]))
+		if off+2 > len(t) {
+			continue
+		}
+		unicode := platform == 0 || (platform == 3 && (encoding == 1 || encoding == 10))
+		if !unicode {
+			continue
+		}
+		rank := 0
+		switch binary.BigEndian.Uint16(t[off:]) {
+		case 12:
+			rank = 2
+		case 4:
+			rank = 1
+		}
+		if rank > bestRank {
+			best, bestRank = t[off:], rank
+		}
+	}
+	switch bestRank {
+	case 2:
+		return parseCmap12(best)
+	case 1:
+		return parseCmap4(best)
+	}
+	return nil, fmt.Errorf("no Unicode cmap subtable")
+}
+
+func parseCmap4(t []byte) (map[rune]uint16, error) {
+	if len(t) < 14 {
+		return nil, fmt.Errorf("invalid cmap format 4")
+	}
+	segs := int(binary.BigEndian.Uint16(t[6:])) / 2
+	ends := 14
+	starts := ends + 2*segs + 2
+	deltas := starts + 2*segs
+	ranges := deltas + 2*segs
+	if ranges+2*segs > len(t) {
+		return nil, fmt.Errorf("invalid cmap format 4")
+	}
+	out := map[rune]uint16{}
+	for s := 0; s < segs; s++ {
+		end := int(binary.BigEndian.Uint16(t[ends+2*s:]))
+		start := int(binary.BigEndian.Uint16(t[starts+2*s:]))
+		delta := int(binary.BigEndian.Uint16(t[deltas+2*s:]))
+		rangeOff := int(binary.BigEndian.Uint16(t[ranges+2*s:]))
+		for c := start; c <= end && c != 0xffff; c++ {
+			var g int
+			if rangeOff == 0 {
+				g = (c + delta) & 0xffff
+			} else {
+				at := ranges + 2*s + rangeOff + 2*(c-start)
+				if at+2 > len(t) {
+					continue
+				}
+				g = int(binary.BigEndian.Uint16(t[at:]))
+				if g != 0 {
+					g = (g + delta) & 0xffff
+				}
+			}
+			if g != 0 {
+				out[rune(c)] = uint16(g)
+			}
+		}
+	}
+	return out, nil
+}
+
+func parseCmap12(t []byte) (map[rune]uint16, error) {
+	if len(t) < 16 {
+		return nil, fmt.Errorf("invalid cmap format 12")
+	}
+	n := int(binary.BigEndian.Uint32(t[12:]))
+	if 16+12*n > len(t) {
+		return nil, fmt.Errorf("invalid cmap format 12")
+	}
+	out := map[rune]uint16{}
+	for i := 0; i < n; i++ {
+		g := t[16+12*i:]
+		start := binary.BigEndian.Uint32(g)
+		end := binary.BigEndian.Uint32(g[4:])
+		glyph := binary.BigEndian.Uint32(g[8:])
+		if end < start || end-start > 0x10ffff {
+			continue
+		}
+		for c := start; c <= end; c++ {
+			out[rune(c)] = uint16(glyph + c - start)
+		}
+	}
+	return out, nil
+}
+
+// postScriptName reads name ID 6, keeping only characters PDF names allow
+// unescaped.
+func postScriptName(t []byte) string {
+	clean := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if r > 0x20 && r < 0x7f && !strings.ContainsRune("()<>[]{}/%#", r) {
+				return r
+			}
+			return -1
+		}, s)
+	}
+	if len(t) >= 6 {
+		count := int(binary.BigEndian.Uint16(t[2:]))
+		strOff := int(binary.BigEndian.Uint16(t[4:]))
+		for i := 0; i < count; i++ {
+			rec := 6 + 12*i
+			if rec+12 > len(t) {
+				break
+			}
+			platform := binary.BigEndian.Uint16(t[rec:])
+			if binary.BigEndian.Uint16(t[rec+6:]) != 6 {
+				continue
+			}
+			length := int(binary.BigEndian.Uint16(t[rec+8:]))
+			off := strOff + int(binary.BigEndian.Uint16(t[rec+10:]))
+			if off+length > len(t) {
+				continue
+			}
+			raw := t[off : off+length]
+			var s string
+			if platform == 1 {
+				s = string(raw)
+			} else {
+				units := make([]uint16, len(raw)/2)
+				for j := range units {
+					units[j] = binary.BigEndian.Uint16(raw[2*j:])
+				}
+				s = string(utf16.Decode(units))
+			}
+			if s = clean(s); s != "" {
+				return s
+			}
+		}
+	}
+	return "EmbeddedFont"
+}
+
+// glyph maps r to a glyph, substituting "?" for characters the font lacks.
+func (f *trueTypeFont) glyph(r rune) uint16 {
+	if g, ok := f.cmap[r]; ok {
+		return g
+	}
+	return f.cmap['?']
+}
+
+func (f *trueTypeFont) scale(v float64) float64 { return v * 1000 / f.unitsPerEm }
+
+func (f *trueTypeFont) advance(r rune) float64 {
+	g := int(f.glyph(r))
+	if g >= len(f.widths) {
+		return 0
+	}
+	return f.scale(float64(f.widths[g]))
+}
+
+func (f *trueTypeFont) encode(s string) string {
+	var b strings.Builder
+	b.WriteByte('<')
+	for _, r := range s {
+		g, ok := f.cmap[r]
+		if !ok {
+			g, r = f.cmap['?'], '?'
+		}
+		if _, seen := f.used[g]; !seen {
+			f.used[g] = r
+		}
+		fmt.Fprintf(&b, "%04X", g)
+	}
+	b.WriteByte('>')
+	return b.String()
+}
+
+func (f *trueTypeFont) write(w *pdfWriter) int {
+	fontFile, descriptor, cid, toUnicode, font := w.alloc(), w.alloc(), w.alloc(), w.alloc(), w.alloc()
+
+	w.stream(fontFile, fmt.Sprintf("/Length1 %d", len(f.data)), f.data)
+
+	flags := 32 // nonsymbolic
+	if f.italic != 0 {
+		flags |= 64
+	}
+	w.obj(descriptor, fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags %d /FontBBox [%.0f %.0f %.0f %.0f] /ItalicAngle %.2f /Ascent %.0f /Descent %.0f /CapHeight %.0f /StemV 80 /FontFile2 %d 0 R >>",
+		f.name, flags,
+		f.scale(float64(f.bbox[0])), f.scale(float64(f.bbox[1])), f.scale(float64(f.bbox[2])), f.scale(float64(f.bbox[3])),
+		f.italic, f.scale(float64(f.ascent)), f.scale(float64(f.descent)), f.scale(float64(f.capHeight)), fontFile))
+
+	glyphs := make([]int, 0, len(f.used))
+	for g := range f.used {
+		glyphs = append(glyphs, int(g))
+	}
+	sort.Ints(glyphs)
+
+	var widths strings.Builder
+	for _, g := range glyphs {
+		if g < len(f.widths) {
+			fmt.Fprintf(&widths, "%d [%.0f] ", g, f.scale(float64(f.widths[g])))
+		}
+	}
+	w.obj(cid, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /W [%s] /CIDToGIDMap /Identity >>",
+		f.name, descriptor, strings.TrimSpace(widths.String())))
+
+	var cmap strings.Builder
+	cmap.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	cmap.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	cmap.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	cmap.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	for start := 0; start < len(glyphs); start += 100 {
+		end := start + 100
+		if end > len(glyphs) {
+			end = len(glyphs)
+		}
+		fmt.Fprintf(&cmap, "%d beginbfchar\n", end-start)
+		for _, g := range glyphs[start:end] {
+			fmt.Fprintf(&cmap, "<%04X> <", g)
+			for _, u := range utf16.Encode([]rune{f.used[uint16(g)]}) {
+				fmt.Fprintf(&cmap, "%04X", u)
+			}
+			cmap.WriteString(">\n")
+		}
+		cmap.WriteString("endbfchar\n")
+	}
+	cmap.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	w.stream(toUnicode, "", []byte(cmap.String()))
+
+	w.obj(font, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		f.name, cid, toUnicode))
+	return font
+}
+
+// Helvetica metrics from the Adobe AFM files, in thousandths of an em.
+var helveticaASCII = [95]int16{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space../
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556, // 0..?
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778, // @..O
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556, // P.._
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556, // `..o
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584, // p..~
+}
+
+var helveticaBoldASCII = [95]int16{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
+
+var helveticaLatin1 = [96]int16{
+	278, 333, 556, 556, 556, 556, 260, 556, 333, 737, 370, 556, 584, 333, 737, 333, // nbsp..macron
+	400, 584, 333, 333, 333, 556, 537, 278, 333, 333, 365, 556, 834, 834, 834, 611, // degree..questiondown
+	667, 667, 667, 667, 667, 667, 1000, 722, 667, 667, 667, 667, 278, 278, 278, 278, // Agrave..Idieresis
+	722, 722, 778, 778, 778, 778, 778, 584, 778, 722, 722, 722, 722, 667, 667, 611, // Eth..germandbls
+	556, 556, 556, 556, 556, 556, 889, 500, 556, 556, 556, 556, 278, 278, 278, 278, // agrave..idieresis
+	556, 556, 556, 556, 556, 556, 556, 584, 611, 556, 556, 556, 556, 500, 556, 500, // eth..ydieresis
+}
+
+var helveticaBoldLatin1 = [96]int16{
+	278, 333, 556, 556, 556, 556, 280, 556, 333, 737, 370, 556, 584, 333, 737, 333,
+	400, 584, 333, 333, 333, 611, 556, 278, 333, 333, 365, 556, 834, 834, 834, 611,
+	722, 722, 722, 722, 722, 722, 1000, 722, 667, 667, 667, 667, 278, 278, 278, 278,
+	722, 722, 778, 778, 778, 778, 778, 584, 778, 722, 722, 722, 722, 667, 667, 611,
+	556, 556, 556, 556, 556, 556, 889, 556, 556, 556, 556, 556, 278, 278, 278, 278,
+	611, 611, 611, 611, 611, 611, 611, 584, 611, 611, 611, 611, 611, 556, 611, 556,
+}
+
+var helveticaExtra = map[rune]int16{
+	'€': 556, '‚': 222, '„': 333, '…': 1000, '‘': 222, '’': 222,
+	'“': 333, '”': 333, '•': 350, '–': 556, '—': 1000, '™': 1000,
+}
+
+var helveticaBoldExtra = map[rune]int16{
+	'€': 556, '‚': 278, '„': 500, '…': 1000, '‘': 278, '’': 278,
+	'“': 500, '”': 500, '•': 350, '–': 556, '—': 1000, '™': 1000,
+}
@@ -0,0 +1,507 @@
+package services
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// A4 in points.
+const (
+	pdfPageWidth  = 595.28
+	pdfPageHeight = 841.89
+	pdfMargin     = 50.0
+	pdfFooterGap  = 40.0
+	pdfLineFactor = 1.35
+	pdfCellPad    = 4.0
+)
+
+// pdfAlign positions text within its box.
+type pdfAlign int
+
+const (
+	alignLeft pdfAlign = iota
+	alignCenter
+	alignRight
+)
+
+// pdfStyle describes how a run of text is set.
+type pdfStyle struct {
+	Size  float64
+	Bold  bool
+	Align pdfAlign
+	Gray  float64 // 0 is black
+	// Indent shifts paragraphs right, narrowing the wrap width.
+	Indent float64
+}
+
+func (s pdfStyle) lineHeight() float64 { return s.Size * pdfLineFactor }
+
+// pdfWriter assembles numbered PDF objects and the cross-reference table.
+type pdfWriter struct {
+	buf     bytes.Buffer
+	offsets []int // by object number; 0 is the free-list head
+}
+
+func newPDFWriter() *pdfWriter {
+	w := &pdfWriter{offsets: []int{0}}
+	w.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	return w
+}
+
+func (w *pdfWriter) alloc() int {
+	w.offsets = append(w.offsets, 0)
+	return len(w.offsets) - 1
+}
+
+func (w *pdfWriter) obj(n int, body string) {
+	w.offsets[n] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", n, body)
+}
+
+// stream writes a Flate-compressed stream; dict holds any extra entries.
+func (w *pdfWriter) stream(n int, dict string, data []byte) {
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	_, _ = zw.Write(data)
+	_ = zw.Close()
+	w.rawStream(n, "/Filter /FlateDecode "+dict, z.Bytes())
+}
+
+// rawStream writes data as-is, for content that is already encoded.
+func (w *pdfWriter) rawStream(n int, dict string, data []byte) {
+	w.offsets[n] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n<< %s /Length %d >>\nstream\n", n, strings.TrimSpace(dict), len(data))
+	w.buf.Write(data)
+	w.buf.WriteString("\nendstream\nendobj\n")
+}
+
+func (w *pdfWriter) finish(root int) []byte {
+	xref := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets))
+	for _, off := range w.offsets[1:] {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(w.offsets), root, xref)
+	return w.buf.Bytes()
+}
+
+// pdfImage is a decoded raster ready to embed as an image XObject.
+type pdfImage struct {
+	width, height int
+	colorSpace    string
+	filter        string // DCTDecode for JPEG pass-through, empty for raw samples
+	data          []byte
+	alpha         []byte // 8-bit soft mask, nil when opaque
+}
+
+// loadPDFImage reads a JPEG or PNG file. JPEGs are embedded unchanged; other
+// formats are decoded to RGB with an alpha mask when they have transparency.
+func loadPDFImage(path string) (*pdfImage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(data, []byte{0xff, 0xd8}) {
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		cs := "DeviceRGB"
+		switch cfg.ColorModel {
+		case color.GrayModel:
+			cs = "DeviceGray"
+		case color.CMYKModel:
+			cs = "DeviceCMYK"
+		}
+		return &pdfImage{width: cfg.Width, height: cfg.Height, colorSpace: cs, filter: "DCTDecode", data: data}, nil
+	}
+	src, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: only JPEG and PNG images are supported: %w", path, err)
+	}
+	b := src.Bounds()
+	img := &pdfImage{width: b.Dx(), height: b.Dy(), colorSpace: "DeviceRGB"}
+	rgb := make([]byte, 0, 3*b.Dx()*b.Dy())
+	alpha := make([]byte, 0, b.Dx()*b.Dy())
+	opaque := true
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, a := src.At(x, y).RGBA()
+			// Undo alpha premultiplication so the soft mask applies cleanly.
+			if a > 0 && a < 0xffff {
+				r, g, bl = r*0xffff/a, g*0xffff/a, bl*0xffff/a
+			}
+			rgb = append(rgb, byte(r>>8), byte(g>>8), byte(bl>>8))
+			alpha = append(alpha, byte(a>>8))
+			if a != 0xffff {
+				opaque = false
+			}
+		}
+	}
+	img.data = rgb
+	if !opaque {
+		img.alpha = alpha
+	}
+	return img, nil
+}
+
+func (img *pdfImage) write(w *pdfWriter) int {
+	n := w.alloc()
+	dict := fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /%s /BitsPerComponent 8", img.width, img.height, img.colorSpace)
+	if img.alpha != nil {
+		mask := w.alloc()
+		w.stream(mask, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray /BitsPerComponent 8", img.width, img.height), img.alpha)
+		dict += fmt.Sprintf(" /SMask %d 0 R", mask)
+	}
+	if img.filter != "" {
+		w.rawStream(n, dict+" /Filter /"+img.filter, img.data)
+	} else {
+		w.stream(n, dict, img.data)
+	}
+	return n
+}
+
+// pdfDoc lays out flowing content over A4 pages. The cursor y is measured
+// from the top of the page; when content would cross the bottom margin a new
+// page is started and onPage, if set, draws any running header.
+type pdfDoc struct {
+	regular pdfFont
+	bold    pdfFont
+	images  []*pdfImage
+	pages   []*bytes.Buffer
+	cur     int // page being drawn on
+	y       float64
+	footer  string
+	onPage  func()
+}
+
+func newPDFDoc(regular, bold pdfFont) *pdfDoc {
+	if regular == nil {
+		regular = newHelvetica(false)
+	}
+	if bold == nil {
+		bold = newHelvetica(true)
+	}
+	d := &pdfDoc{regular: regular, bold: bold}
+	d.addPage()
+	return d
+}
+
+func (d *pdfDoc) contentWidth() float64 { return pdfPageWidth - 2*pdfMargin }
+
+// bottom is the lowest y content may reach; below it sits the footer.
+func (d *pdfDoc) bottom() float64 { return pdfPageHeight - pdfMargin - pdfFooterGap/2 }
+
+func (d *pdfDoc) page() *bytes.Buffer { return d.pages[d.cur] }
+
+func (d *pdfDoc) font(bold bool) pdfFont {
+	if bold {
+		return d.bold
+	}
+	return d.regular
+}
+
+func (d *pdfDoc) addPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.cur = len(d.pages) - 1
+	d.y = pdfMargin
+	if d.onPage != nil {
+		d.onPage()
+	}
+}
+
+// ensure starts a new page unless h points still fit on this one.
+func (d *pdfDoc) ensure(h float64) {
+	if d.y+h > d.bottom() && d.y > pdfMargin {
+		d.addPage()
+	}
+}
+
+func (d *pdfDoc) space(h float64) {
+	d.y += h
+	if d.y > d.bottom() {
+		d.addPage()
+	}
+}
+
+// text draws one line with its top edge at top, aligned inside [x, x+width].
+func (d *pdfDoc) text(x, top, width float64, style pdfStyle, s string) {
+	if s == "" {
+		return
+	}
+	f := d.font(style.Bold)
+	switch style.Align {
+	case alignCenter:
+		x += (width - textWidth(f, s, style.Size)) / 2
+	case alignRight:
+		x += width - textWidth(f, s, style.Size)
+	}
+	name := "F1"
+	if style.Bold {
+		name = "F2"
+	}
+	baseline := pdfPageHeight - top - style.Size
+	fmt.Fprintf(d.page(), "BT %.3g g /%s %.2f Tf %.2f %.2f Td %s Tj ET\n", style.Gray, name, style.Size, x, baseline, f.encode(s))
+}
+
+// paragraph wraps text to the content width and flows it across pages.
+func (d *pdfDoc) paragraph(text string, style pdfStyle) {
+	lh := style.lineHeight()
+	width := d.contentWidth() - style.Indent
+	for _, line := range wrapText(d.font(style.Bold), style.Size, text, width) {
+		d.ensure(lh)
+		d.text(pdfMargin+style.Indent, d.y, width, style, line)
+		d.y += lh
+	}
+}
+
+// rule draws a thin horizontal line across the content width.
+func (d *pdfDoc) rule() {
+	d.ensure(6)
+	y := pdfPageHeight - d.y - 3
+	fmt.Fprintf(d.page(), "0.6 G 0.5 w %.2f %.2f m %.2f %.2f l S 0 G\n", pdfMargin, y, pdfPageWidth-pdfMargin, y)
+	d.y += 6
+}
+
+// image places img with its top-left corner at (x, top), scaled to w x h.
+func (d *pdfDoc) image(img *pdfImage, x, top, w, h float64) {
+	idx := -1
+	for i, existing := range d.images {
+		if existing == img {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		d.images = append(d.images, img)
+		idx = len(d.images) - 1
+	}
+	fmt.Fprintf(d.page(), "q %.2f 0 0 %.2f %.2f %.2f cm /Im%d Do Q\n", w, h, x, pdfPageHeight-top-h, idx+1)
+}
+
+// pdfColumn is one column of a pdfTable; Width is a fraction of the table.
+type pdfColumn struct {
+	Title string
+	Width float64
+	Align pdfAlign
+}
+
+// pdfTable is a grid of wrapped cells. With a header, the header row is
+// repeated at the top of every page the table continues onto. Plain tables
+// have no borders or header and suit label/value blocks.
+type pdfTable struct {
+	Columns []pdfColumn
+	Rows    [][]string
+	Size    float64
+	Plain   bool
+	// BoldFirst sets the first column in bold, for label/value blocks.
+	BoldFirst bool
+}
+
+func (d *pdfDoc) table(t pdfTable) {
+	if t.Size == 0 {
+		t.Size = 9
+	}
+	widths := make([]float64, len(t.Columns))
+	for i, c := range t.Columns {
+		widths[i] = c.Width * d.contentWidth()
+	}
+	lh := t.Size * pdfLineFactor
+	pad := pdfCellPad
+	if t.Plain {
+		pad = 1
+	}
+
+	wrapRow := func(cells []string, header bool) [][]string {
+		out := make([][]string, len(t.Columns))
+		for i := range t.Columns {
+			cell := ""
+			if i < len(cells) {
+				cell = cells[i]
+			}
+			bold := header || (t.BoldFirst && i == 0)
+			out[i] = wrapText(d.font(bold), t.Size, cell, widths[i]-2*pad)
+		}
+		return out
+	}
+	rowLines := func(cells [][]string) int {
+		n := 1
+		for _, c := range cells {
+			if len(c) > n {
+				n = len(c)
+			}
+		}
+		return n
+	}
+
+	// drawRow draws lines [from, to) of every cell as one band.
+	drawRow := func(cells [][]string, from, to int, header bool) {
+		h := float64(to-from)*lh + 2*pad
+		top := d.y
+		if header {
+			fmt.Fprintf(d.page(), "0.92 g %.2f %.2f %.2f %.2f re f 0 g\n", pdfMargin, pdfPageHeight-top-h, d.contentWidth(), h)
+		}
+		x := pdfMargin
+		for i, c := range t.Columns {
+			style := pdfStyle{Size: t.Size, Bold: header || (t.BoldFirst && i == 0), Align: c.Align}
+			for l := from; l < to && l < len(cells[i]); l++ {
+				d.text(x+pad, top+pad+float64(l-from)*lh, widths[i]-2*pad, style, cells[i][l])
+			}
+			if !t.Plain {
+				fmt.Fprintf(d.page(), "0.7 G 0.5 w %.2f %.2f %.2f %.2f re S 0 G\n", x, pdfPageHeight-top-h, widths[i], h)
+			}
+			x += widths[i]
+		}
+		d.y += h
+	}
+
+	var header [][]string
+	if !t.Plain {
+		titles := make([]string, len(t.Columns))
+		for i, c := range t.Columns {
+			titles[i] = c.Title
+		}
+		header = wrapRow(titles, true)
+	}
+	drawHeader := func() {
+		if header != nil {
+			drawRow(header, 0, rowLines(header), true)
+		}
+	}
+	headerH := 0.0
+	if header != nil {
+		headerH = float64(rowLines(header))*lh + 2*pad
+	}
+
+	// Keep the header with at least the first row.
+	firstH := lh + 2*pad
+	if len(t.Rows) > 0 {
+		firstH = float64(rowLines(wrapRow(t.Rows[0], false)))*lh + 2*pad
+	}
+	d.ensure(headerH + math.Min(firstH, d.bottom()-pdfMargin-headerH))
+	drawHeader()
+
+	for _, row := range t.Rows {
+		cells := wrapRow(row, false)
+		total := rowLines(cells)
+		// Move a row that does not fit to the next page whole; split it only
+		// when it would not fit on an empty page either.
+		fresh := d.bottom() - pdfMargin - headerH
+		if h := float64(total)*lh + 2*pad; d.y+h > d.bottom() && h <= fresh {
+			d.addPage()
+			drawHeader()
+		}
+		for from := 0; from < total; {
+			fit := int((d.bottom() - d.y - 2*pad) / lh)
+			if fit < 1 && d.y > pdfMargin+headerH {
+				d.addPage()
+				drawHeader()
+				continue
+			}
+			if fit < 1 {
+				fit = 1
+			}
+			to := from + fit
+			if to > total {
+				to = total
+			}
+			drawRow(cells, from, to, false)
+			from = to
+		}
+	}
+}
+
+// render writes the document, stamping the footer and "Halaman X dari Y" on
+// every page.
+func (d *pdfDoc) render() []byte {
+	w := newPDFWriter()
+	catalog, pages := w.alloc(), w.alloc()
+
+	total := len(d.pages)
+	footerTop := pdfPageHeight - pdfMargin + 4
+	footerStyle := pdfStyle{Size: 8, Gray: 0.4}
+	d.onPage = nil
+	contents := make([]int, total)
+	for i, buf := range d.pages {
+		d.cur = i
+		d.text(pdfMargin, footerTop, d.contentWidth(), footerStyle, d.footer)
+		right := footerStyle
+		right.Align = alignRight
+		d.text(pdfMargin, footerTop, d.contentWidth(), right, fmt.Sprintf("Halaman %d dari %d", i+1, total))
+		contents[i] = w.alloc()
+		w.stream(contents[i], "", buf.Bytes())
+	}
+
+	regular := d.regular.write(w)
+	bold := regular
+	if d.bold != d.regular {
+		bold = d.bold.write(w)
+	}
+	var xobjects strings.Builder
+	for i, img := range d.images {
+		fmt.Fprintf(&xobjects, "/Im%d %d 0 R ", i+1, img.write(w))
+	}
+	resources := w.alloc()
+	w.obj(resources, fmt.Sprintf("<< /Font << /F1 %d 0 R /F2 %d 0 R >> /XObject << %s>> >>", regular, bold, xobjects.String()))
+
+	kids := make([]string, total)
+	for i, content := range contents {
+		page := w.alloc()
+		w.obj(page, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] /Resources %d 0 R /Contents %d 0 R >>",
+			pages, pdfPageWidth, pdfPageHeight, resources, content))
+		kids[i] = fmt.Sprintf("%d 0 R", page)
+	}
+	w.obj(pages, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), total))
+	w.obj(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pages))
+	return w.finish(catalog)
+}
+
+// wrapText breaks text into lines no wider than width. Explicit newlines are
+// kept (blank lines included) and words longer than a line are split.
+func wrapText(f pdfFont, size float64, text string, width float64) []string {
+	var lines []string
+	space := textWidth(f, " ", size)
+	for _, para := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		words := strings.Fields(para)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+		line, lineW := "", 0.0
+		for _, word := range words {
+			ww := textWidth(f, word, size)
+			if line != "" && lineW+space+ww <= width {
+				line += " " + word
+				lineW += space + ww
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+				line, lineW = "", 0
+			}
+			for ww > width && utf8.RuneCountInString(word) > 1 {
+				cut, cutW := 0, 0.0
+				for i, r := range word {
+					rw := f.advance(r) * size / 1000
+					if cutW+rw > width && i > 0 {
+						break
+					}
+					cut, cutW = i+utf8.RuneLen(r), cutW+rw
+				}
+				lines = append(lines, word[:cut])
+				word = word[cut:]
+				ww = textWidth(f, word, size)
+			}
+			line, lineW = word, ww
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
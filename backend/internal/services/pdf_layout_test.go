@@ -0,0 +1,263 @@
+package services
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+)
+
+// pdfStreams inflates every Flate stream in a rendered PDF.
+func pdfStreams(t *testing.T, pdf []byte) []string {
+	t.Helper()
+	var out []string
+	re := regexp.MustCompile(`(?s)/FlateDecode[^>]*>>\nstream\n(.*?)\nendstream`)
+	for _, m := range re.FindAllSubmatch(pdf, -1) {
+		r, err := zlib.NewReader(bytes.NewReader(m[1]))
+		if err != nil {
+			t.Fatalf("inflate: %v", err)
+		}
+		b, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("inflate: %v", err)
+		}
+		out = append(out, string(b))
+	}
+	return out
+}
+
+func TestWrapText(t *testing.T) {
+	f := newHelvetica(false)
+	lines := wrapText(f, 10, "the quick brown fox jumps over the lazy dog", 60)
+	if len(lines) < 3 {
+		t.Fatalf("expected wrapping, got %q", lines)
+	}
+	for _, l := range lines {
+		if w := textWidth(f, l, 10); w > 60 {
+			t.Fatalf("line %q is %.1fpt wide", l, w)
+		}
+	}
+	if got := strings.Join(lines, " "); got != "the quick brown fox jumps over the lazy dog" {
+		t.Fatalf("words lost: %q", got)
+	}
+
+	long := wrapText(f, 10, strings.Repeat("W", 40), 50)
+	if len(long) < 2 || strings.Join(long, "") != strings.Repeat("W", 40) {
+		t.Fatalf("long word not split: %q", long)
+	}
+
+	if got := wrapText(f, 10, "a\n\nb", 100); len(got) != 3 || got[1] != "" {
+		t.Fatalf("blank line not kept: %q", got)
+	}
+}
+
+func TestTableRepeatsHeaderAcrossPages(t *testing.T) {
+	d := newPDFDoc(nil, nil)
+	rows := make([][]string, 120)
+	for i := range rows {
+		rows[i] = []string{fmt.Sprintf("Row %d", i+1), "1"}
+	}
+	d.table(pdfTable{Columns: []pdfColumn{{Title: "Deskripsi", Width: 0.8}, {Title: "Qty", Width: 0.2, Align: alignRight}}, Rows: rows})
+	if len(d.pages) < 3 {
+		t.Fatalf("expected the table to span pages, got %d", len(d.pages))
+	}
+	for i, p := range d.pages {
+		if !strings.Contains(p.String(), "(Deskripsi)") {
+			t.Fatalf("page %d has no header row", i+1)
+		}
+	}
+	if !strings.Contains(d.pages[len(d.pages)-1].String(), "(Row 120)") {
+		t.Fatal("last row missing")
+	}
+
+	streams := pdfStreams(t, d.render())
+	if !strings.Contains(strings.Join(streams, ""), fmt.Sprintf("(Halaman %d dari %d)", len(d.pages), len(d.pages))) {
+		t.Fatal("page numbers missing")
+	}
+}
+
+func TestTableSplitsRowTallerThanPage(t *testing.T) {
+	d := newPDFDoc(nil, nil)
+	d.table(pdfTable{Columns: []pdfColumn{{Title: "Deskripsi", Width: 1}}, Rows: [][]string{{strings.Repeat("baris\n", 80)}}})
+	if len(d.pages) != 2 {
+		t.Fatalf("expected the row to continue on a second page, got %d pages", len(d.pages))
+	}
+	if !strings.Contains(d.pages[1].String(), "(Deskripsi)") {
+		t.Fatal("header not repeated on continuation page")
+	}
+}
+
+func TestOfferPDFFlowsAcrossPages(t *testing.T) {
+	t.Setenv("PDF_TEMPLATE_PATH", "")
+	offer := &models.Offer{
+		OfferNumber:     "001/MSI/2025",
+		Date:            time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC),
+		Subject:         "Pemeliharaan",
+		Currency:        "IDR",
+		ProposalDetails: strings.Repeat("Detail pekerjaan yang cukup panjang untuk dibungkus. ", 60),
+		TotalPrice:      1000,
+	}
+	for i := 0; i < 50; i++ {
+		offer.Items = append(offer.Items, models.OfferItem{Description: fmt.Sprintf("Layanan %d", i+1), Qty: 1, UnitPrice: 20, Total: 20})
+	}
+	client := &models.Client{Name: "José Müller", Address: "Jl. Merdeka 1"}
+
+	out, err := buildStyledOfferPDF(offer, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4")) || !bytes.Contains(out, []byte("/WinAnsiEncoding")) {
+		t.Fatal("not a WinAnsi PDF")
+	}
+	pages := pdfStreams(t, out)
+	if len(pages) < 2 {
+		t.Fatalf("expected several pages, got %d", len(pages))
+	}
+	all := strings.Join(pages, "")
+	for _, want := range []string{`(Jos\351 M\374ller)`, "(04 Maret 2025)", "(Layanan 50)", fmt.Sprintf("(Halaman 1 dari %d)", len(pages))} {
+		if !strings.Contains(all, want) {
+			t.Errorf("missing %s", want)
+		}
+	}
+	for i, p := range pages[1:] {
+		if strings.Contains(p, "(Layanan") && !strings.Contains(p, "(Deskripsi)") {
+			t.Errorf("page %d continues the item table without its header", i+2)
+		}
+	}
+}
+
+func TestPDFTemplateLogoAndFont(t *testing.T) {
+	dir := t.TempDir()
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	img.Set(0, 0, color.NRGBA{R: 255, A: 128})
+	var logo bytes.Buffer
+	if err := png.Encode(&logo, img); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "logo.png"), logo.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "font.ttf"), testTrueTypeFont(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tplPath := filepath.Join(dir, "layout.json")
+	if err := os.WriteFile(tplPath, []byte(`{"company":"Acme","logo":"logo.png","font":"font.ttf","footer":"Acme Ltd"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PDF_TEMPLATE_PATH", tplPath)
+
+	out, err := renderSimplePDF([]string{"Aễ"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"/FontFile2", "/Identity-H", "/SMask", "/Im1 Do"} {
+		if !bytes.Contains(out, []byte(want)) && !strings.Contains(strings.Join(pdfStreams(t, out), ""), want) {
+			t.Errorf("missing %s", want)
+		}
+	}
+	all := strings.Join(pdfStreams(t, out), "")
+	if !strings.Contains(all, "<00020003>") {
+		t.Error("text not written as glyph IDs")
+	}
+	if !strings.Contains(all, "<0003> <1EC5>") {
+		t.Error("ToUnicode map lacks the non-Latin character")
+	}
+
+	t.Setenv("PDF_TEMPLATE_PATH", filepath.Join(dir, "logo.png"))
+	if _, err := renderSimplePDF([]string{"x"}); err == nil {
+		t.Fatal("expected an error for a template that is not JSON")
+	}
+}
+
+func TestParseTrueTypeMetrics(t *testing.T) {
+	f, err := parseTrueType(testTrueTypeFont())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.advance('A') != 700 || f.advance('ễ') != 800 {
+		t.Fatalf("advances: %v %v", f.advance('A'), f.advance('ễ'))
+	}
+	if f.advance('z') != 600 || f.encode("z") != "<0001>" {
+		t.Fatal("missing characters should fall back to ?")
+	}
+	if _, err := parseTrueType([]byte("OTTO0000000000")); err == nil {
+		t.Fatal("expected CFF fonts to be rejected")
+	}
+}
+
+// testTrueTypeFont builds a minimal TrueType file with glyphs for .notdef,
+// "?", "A" and "ễ" (U+1EC5), 500/600/700/800 units wide on a 1000 em.
+func testTrueTypeFont() []byte {
+	be := binary.BigEndian
+	head := make([]byte, 54)
+	be.PutUint32(head, 0x00010000)
+	be.PutUint16(head[18:], 1000)
+	be.PutUint16(head[40:], 1000)
+	be.PutUint16(head[42:], 900)
+
+	hhea := make([]byte, 36)
+	be.PutUint32(hhea, 0x00010000)
+	be.PutUint16(hhea[4:], 800)
+	be.PutUint16(hhea[6:], uint16(0x10000-200))
+	be.PutUint16(hhea[34:], 4)
+
+	maxp := make([]byte, 6)
+	be.PutUint32(maxp, 0x00005000)
+	be.PutUint16(maxp[4:], 4)
+
+	hmtx := make([]byte, 16)
+	for g := 0; g < 4; g++ {
+		be.PutUint16(hmtx[4*g:], uint16(500+100*g))
+	}
+
+	chars := []uint16{'?', 'A', 0x1ec5, 0xffff}
+	glyphs := []uint16{1, 2, 3, 0}
+	segs := len(chars)
+	sub := make([]byte, 16+8*segs)
+	be.PutUint16(sub, 4)
+	be.PutUint16(sub[2:], uint16(len(sub)))
+	be.PutUint16(sub[6:], uint16(2*segs))
+	for i, c := range chars {
+		be.PutUint16(sub[14+2*i:], c)
+		be.PutUint16(sub[16+2*segs+2*i:], c)
+		delta := uint16(1)
+		if c != 0xffff {
+			delta = glyphs[i] - c
+		}
+		be.PutUint16(sub[16+4*segs+2*i:], delta)
+	}
+	cmap := make([]byte, 12)
+	be.PutUint16(cmap[2:], 1)
+	be.PutUint16(cmap[4:], 3)
+	be.PutUint16(cmap[6:], 1)
+	be.PutUint32(cmap[8:], 12)
+	cmap = append(cmap, sub...)
+
+	tables := []struct {
+		tag  string
+		data []byte
+	}{{"cmap", cmap}, {"head", head}, {"hhea", hhea}, {"hmtx", hmtx}, {"maxp", maxp}}
+	out := make([]byte, 12+16*len(tables))
+	be.PutUint32(out, 0x00010000)
+	be.PutUint16(out[4:], uint16(len(tables)))
+	for i, tb := range tables {
+		rec := out[12+16*i:]
+		copy(rec, tb.tag)
+		be.PutUint32(rec[8:], uint32(len(out)))
+		be.PutUint32(rec[12:], uint32(len(tb.data)))
+		out = append(out, tb.data...)
+	}
+	return out
+}
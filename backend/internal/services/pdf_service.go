@@ -1,9 +1,9 @@
 package services
 
 import (
-    "bytes"
     "encoding/json"
     "fmt"
+    "math"
     "os"
     "path/filepath"
     "strings"
@@ -12,8 +12,7 @@ import (
     "freelance-monitor-system/internal/models"
 )
 
-// PDFService provides minimal offer PDF generation.
-// For now, it writes a simple placeholder file summarizing the offer.
+// PDFService renders offers and invoices to PDF files under static/pdfs.
 type PDFService struct{}
 
 func NewPDFService() *PDFService { return &PDFService{} }
@@ -36,185 +35,391 @@ func (s *PDFService) GenerateOfferPDF(offer *models.Offer, client *models.Client
 	return "/static/pdfs/" + filename, nil
 }
 
+// GenerateOfferRevisionPDF renders a sent revision's snapshot to its own file so
+// later edits never overwrite what the client received.
+func (s *PDFService) GenerateOfferRevisionPDF(rev *models.OfferRevision, client *models.Client) (string, error) {
+	if rev == nil || rev.Offer == nil {
+		return "", fmt.Errorf("revision snapshot is required")
+	}
+	outDir := filepath.Join("static", "pdfs")
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return "", err
+	}
+	filename := fmt.Sprintf("offer_%d_r%d.pdf", rev.OfferID, rev.Revision)
+	pdfBytes, err := buildStyledOfferPDF(rev.Offer, client)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(outDir, filename), pdfBytes, 0o644); err != nil {
+		return "", err
+	}
+	return "/static/pdfs/" + filename, nil
+}
+
 // GenerateOfferPDFForOffer is a convenience used by the CLI helper to build a PDF when only an offer is available.
 func (s *PDFService) GenerateOfferPDFForOffer(offer *models.Offer) (string, error) {
 	return s.GenerateOfferPDF(offer, nil)
 }
 
-// Backend monthly PDF generation removed; using client-side React PDF.
+// GenerateInvoicePDF writes the invoice PDF and returns its public URL.
+func (s *PDFService) GenerateInvoicePDF(inv *models.Invoice, client *models.Client) (string, error) {
+	outDir := filepath.Join("static", "pdfs")
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return "", err
+	}
+	filename := fmt.Sprintf("invoice_%d.pdf", inv.ID)
+	pdfBytes, err := buildInvoicePDF(inv, client)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(outDir, filename), pdfBytes, 0o644); err != nil {
+		return "", err
+	}
+	return "/static/pdfs/" + filename, nil
+}
 
-// buildStyledOfferPDF creates a valid PDF (A4) approximating the reference layout.
-func buildStyledOfferPDF(offer *models.Offer, client *models.Client) ([]byte, error) {
-	if offer == nil {
-		return nil, fmt.Errorf("offer is required")
+// pdfTemplate is the letterhead and typography read from the JSON file named
+// by PDF_TEMPLATE_PATH. Relative logo and font paths resolve against the
+// template's directory. Without fonts the base-14 Helvetica faces are used,
+// which cover Latin-1 only.
+type pdfTemplate struct {
+	Company  string   `json:"company"`
+	Tagline  string   `json:"tagline"`
+	Address  []string `json:"address"`
+	Logo     string   `json:"logo"`
+	Footer   string   `json:"footer"`
+	Font     string   `json:"font"`
+	BoldFont string   `json:"bold_font"`
+}
+
+func defaultPDFTemplate() *pdfTemplate {
+	return &pdfTemplate{Company: "Monitoring Solusi Indonesia"}
+}
+
+// loadPDFTemplate reads PDF_TEMPLATE_PATH, falling back to the built-in
+// letterhead when it is unset.
+func loadPDFTemplate() (*pdfTemplate, error) {
+	path := strings.TrimSpace(os.Getenv("PDF_TEMPLATE_PATH"))
+	if path == "" {
+		return defaultPDFTemplate(), nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("PDF_TEMPLATE_PATH: %w", err)
+	}
+	tpl := defaultPDFTemplate()
+	if err := json.Unmarshal(raw, tpl); err != nil {
+		return nil, fmt.Errorf("PDF_TEMPLATE_PATH: %s is not a JSON layout file: %w", path, err)
+	}
+	dir := filepath.Dir(path)
+	for _, p := range []*string{&tpl.Logo, &tpl.Font, &tpl.BoldFont} {
+		if *p != "" && !filepath.IsAbs(*p) {
+			*p = filepath.Join(dir, *p)
+		}
 	}
+	return tpl, nil
+}
 
-	var items []offerItem
-	rawItems := strings.TrimSpace(offer.Items)
-	if rawItems != "" {
-		_ = json.Unmarshal([]byte(rawItems), &items)
+// newDoc starts a document with the template's fonts and footer.
+func (t *pdfTemplate) newDoc() (*pdfDoc, error) {
+	var regular, bold pdfFont
+	if t.Font != "" {
+		f, err := loadTrueTypeFont(t.Font)
+		if err != nil {
+			return nil, err
+		}
+		regular, bold = f, f
+	}
+	if t.BoldFont != "" {
+		f, err := loadTrueTypeFont(t.BoldFont)
+		if err != nil {
+			return nil, err
+		}
+		bold = f
 	}
+	d := newPDFDoc(regular, bold)
+	d.footer = t.Footer
+	return d, nil
+}
 
-	lines := make([]string, 0, 32)
-	lines = append(lines, "Monitoring Solusi Indonesia")
-	lines = append(lines, "Penawaran Layanan")
-	lines = append(lines, "")
+// letterhead draws the logo and company block at the top of the first page.
+func (t *pdfTemplate) letterhead(d *pdfDoc) error {
+	top := d.y
+	x := float64(pdfMargin)
+	height := 0.0
+	if t.Logo != "" {
+		img, err := loadPDFImage(t.Logo)
+		if err != nil {
+			return err
+		}
+		h := 48.0
+		w := h * float64(img.width) / float64(img.height)
+		if w > 160 {
+			w, h = 160, 160*float64(img.height)/float64(img.width)
+		}
+		d.image(img, x, top, w, h)
+		x += w + 12
+		height = h
+	}
+	width := pdfPageWidth - pdfMargin - x
+	y := top
+	name := pdfStyle{Size: 16, Bold: true}
+	d.text(x, y, width, name, t.Company)
+	y += name.lineHeight()
+	small := pdfStyle{Size: 8.5, Gray: 0.35}
+	for _, line := range append([]string{t.Tagline}, t.Address...) {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		d.text(x, y, width, small, line)
+		y += small.lineHeight()
+	}
+	d.y = top + math.Max(height, y-top) + 6
+	d.rule()
+	d.space(8)
+	return nil
+}
 
-	if strings.TrimSpace(offer.OfferNumber) != "" {
-		lines = append(lines, fmt.Sprintf("Nomor Penawaran: %s", offer.OfferNumber))
+// formatDateID formats t as "02 Januari 2006".
+func formatDateID(t time.Time) string {
+	return fmt.Sprintf("%02d %s %d", t.Day(), monthNameID(t), t.Year())
+}
+
+// buildStyledOfferPDF lays out an offer on A4: letterhead, addressee block,
+// proposal text, a line-item table whose header repeats on every page,
+// totals, terms and a signature block.
+func buildStyledOfferPDF(offer *models.Offer, client *models.Client) ([]byte, error) {
+	if offer == nil {
+		return nil, fmt.Errorf("offer is required")
+	}
+	tpl, err := loadPDFTemplate()
+	if err != nil {
+		return nil, err
 	}
+	d, err := tpl.newDoc()
+	if err != nil {
+		return nil, err
+	}
+	if err := tpl.letterhead(d); err != nil {
+		return nil, err
+	}
+
+	body := pdfStyle{Size: 10}
+	heading := pdfStyle{Size: 11, Bold: true}
+	money := func(v float64) string { return formatMoney(v, offer.Currency) }
 
 	offerDate := offer.Date
 	if offerDate.IsZero() {
 		offerDate = time.Now()
 	}
-	lines = append(lines, fmt.Sprintf("Tanggal: %s", offerDate.Format("02 January 2006")))
 
+	d.paragraph(strings.ToUpper(nonEmpty(strings.TrimSpace(offer.OfferTitle), "Penawaran Layanan")), pdfStyle{Size: 14, Bold: true, Align: alignCenter})
+	d.space(8)
+
+	meta := [][]string{}
+	if strings.TrimSpace(offer.OfferNumber) != "" {
+		meta = append(meta, []string{"Nomor", offer.OfferNumber})
+	}
+	meta = append(meta, []string{"Tanggal", formatDateID(offerDate)})
+	if offer.ValidUntil != nil {
+		meta = append(meta, []string{"Berlaku hingga", formatDateID(*offer.ValidUntil)})
+	}
 	if client != nil {
-		name := strings.TrimSpace(client.Name)
-		if name == "" {
-			name = "Client"
+		meta = append(meta, []string{"Kepada", nonEmpty(strings.TrimSpace(client.Name), "Client")})
+		if attn := nonEmpty(strings.TrimSpace(offer.ClientAttention), strings.TrimSpace(client.ContactPerson)); attn != "" {
+			meta = append(meta, []string{"Up.", attn})
 		}
-		if strings.TrimSpace(client.ContactPerson) != "" {
-			name = fmt.Sprintf("%s (Attn: %s)", name, client.ContactPerson)
-		}
-		lines = append(lines, "Kepada: "+name)
 		if addr := compressWhitespace(client.Address); addr != "" {
-			lines = append(lines, "Alamat: "+addr)
+			meta = append(meta, []string{"Alamat", addr})
 		}
 		if strings.TrimSpace(client.Email) != "" {
-			lines = append(lines, "Email: "+strings.TrimSpace(client.Email))
+			meta = append(meta, []string{"Email", strings.TrimSpace(client.Email)})
 		}
 		if strings.TrimSpace(client.Phone) != "" {
-			lines = append(lines, "Telepon: "+strings.TrimSpace(client.Phone))
+			meta = append(meta, []string{"Telepon", strings.TrimSpace(client.Phone)})
 		}
 	} else {
-		lines = append(lines, fmt.Sprintf("Client ID: %d", offer.ClientID))
+		meta = append(meta, []string{"Client ID", fmt.Sprintf("%d", offer.ClientID)})
 	}
-
 	if subject := strings.TrimSpace(offer.Subject); subject != "" {
-		lines = append(lines, "")
-		lines = append(lines, subject)
+		meta = append(meta, []string{"Perihal", subject})
 	}
-
-	if offer.ProposalSummary != "" {
-		lines = append(lines, "")
-		lines = append(lines, splitAndTrim(offer.ProposalSummary)...)
+	d.table(pdfTable{
+		Columns:   []pdfColumn{{Width: 0.2}, {Width: 0.8}},
+		Rows:      meta,
+		Size:      10,
+		Plain:     true,
+		BoldFirst: true,
+	})
+	d.space(10)
+
+	for _, text := range []string{offer.ProposalSummary, offer.ProposalDetails} {
+		if text = strings.TrimSpace(text); text != "" {
+			d.paragraph(text, body)
+			d.space(8)
+		}
 	}
 
-	if len(items) > 0 {
-		lines = append(lines, "")
-		lines = append(lines, "Rincian Layanan:")
-		for idx, item := range items {
+	if len(offer.Items) > 0 {
+		var hasDiscount, hasTax bool
+		for _, item := range offer.Items {
+			hasDiscount = hasDiscount || item.DiscountPercent > 0
+			hasTax = hasTax || item.TaxRate > 0
+		}
+		cols := []pdfColumn{{Title: "No", Width: 0.06, Align: alignCenter}, {Title: "Deskripsi", Width: 0.38}, {Title: "Qty", Width: 0.1, Align: alignRight}, {Title: "Harga Satuan", Width: 0.16, Align: alignRight}}
+		if hasDiscount {
+			cols = append(cols, pdfColumn{Title: "Diskon", Width: 0.08, Align: alignRight})
+		}
+		if hasTax {
+			cols = append(cols, pdfColumn{Title: "Pajak", Width: 0.08, Align: alignRight})
+		}
+		cols = append(cols, pdfColumn{Title: "Total", Width: 0.16, Align: alignRight})
+		// Give the description whatever the optional columns leave over.
+		used := 0.0
+		for _, c := range cols {
+			used += c.Width
+		}
+		cols[1].Width += 1 - used
+
+		rows := make([][]string, 0, len(offer.Items))
+		for idx, item := range offer.Items {
 			desc := strings.TrimSpace(item.Description)
-			if desc == "" {
-				desc = "Item"
+			if name := strings.TrimSpace(item.Name); name != "" && name != desc {
+				desc = strings.TrimSpace(name + "\n" + desc)
 			}
-			lines = append(lines, fmt.Sprintf("%d. %s (Qty: %s, Harga: %s, Total: %s)",
-				idx+1,
-				desc,
-				formatQuantity(item.Qty),
-				formatCurrency(item.UnitPrice),
-				formatCurrency(item.Total),
-			))
+			qty := formatQuantity(item.Qty)
+			if item.Unit != "" {
+				qty += " " + item.Unit
+			}
+			row := []string{fmt.Sprintf("%d", idx+1), nonEmpty(desc, "Item"), qty, money(item.UnitPrice)}
+			if hasDiscount {
+				row = append(row, formatQuantity(item.DiscountPercent)+"%")
+			}
+			if hasTax {
+				row = append(row, formatQuantity(item.TaxRate)+"%")
+			}
+			rows = append(rows, append(row, money(item.Total)))
+		}
+		d.paragraph("Rincian Layanan", heading)
+		d.space(2)
+		d.table(pdfTable{Columns: cols, Rows: rows})
+		d.space(6)
+
+		totals := [][]string{{"Subtotal", money(offer.Subtotal)}}
+		if offer.DiscountTotal > 0 {
+			totals = append(totals, []string{"Diskon", "-" + money(offer.DiscountTotal)})
+		}
+		if offer.TaxTotal > 0 {
+			totals = append(totals, []string{"Pajak", money(offer.TaxTotal)})
 		}
+		d.table(pdfTable{
+			Columns: []pdfColumn{{Width: 0.8, Align: alignRight}, {Width: 0.2, Align: alignRight}},
+			Rows:    totals,
+			Size:    10,
+			Plain:   true,
+		})
 	}
-
 	if offer.TotalPrice > 0 {
-		lines = append(lines, "")
-		lines = append(lines, "Total Penawaran: "+formatCurrency(offer.TotalPrice))
+		d.table(pdfTable{
+			Columns:   []pdfColumn{{Width: 0.7, Align: alignRight}, {Width: 0.3, Align: alignRight}},
+			Rows:      [][]string{{"Total Penawaran", money(offer.TotalPrice)}},
+			Size:      11,
+			Plain:     true,
+			BoldFirst: true,
+		})
 	}
 
-	if offer.PaymentTerms != "" {
-		lines = append(lines, "")
-		lines = append(lines, "Syarat Pembayaran:")
-		lines = append(lines, splitAndTrim(offer.PaymentTerms)...)
+	for _, section := range []struct{ title, text string }{
+		{"Syarat Pembayaran", offer.PaymentTerms},
+		{"Catatan", offer.Notes},
+	} {
+		if text := strings.TrimSpace(section.text); text != "" {
+			d.space(10)
+			d.ensure(heading.lineHeight() + 2*body.lineHeight())
+			d.paragraph(section.title, heading)
+			d.paragraph(text, body)
+		}
 	}
 
-	if offer.Notes != "" {
-		lines = append(lines, "")
-		lines = append(lines, "Catatan:")
-		lines = append(lines, splitAndTrim(offer.Notes)...)
-	}
+	d.space(14)
+	d.paragraph(nonEmpty(strings.TrimSpace(offer.ClosingText), "Terima kasih atas kepercayaannya."), body)
 
-	lines = append(lines, "")
-	lines = append(lines, "Terima kasih atas kepercayaannya.")
-	if strings.TrimSpace(offer.IssuerName) != "" {
-		lines = append(lines, fmt.Sprintf("Hormat kami, %s", strings.TrimSpace(offer.IssuerName)))
-		if strings.TrimSpace(offer.IssuerCompany) != "" {
-			lines = append(lines, strings.TrimSpace(offer.IssuerCompany))
-		}
+	// Keep the signature block together.
+	signature := []string{}
+	if city := strings.TrimSpace(offer.SignatureCity); city != "" {
+		signature = append(signature, city+", "+formatDateID(offerDate))
+	}
+	signature = append(signature, "Hormat kami,")
+	if company := nonEmpty(strings.TrimSpace(offer.SignatureCompany), strings.TrimSpace(offer.IssuerCompany)); company != "" {
+		signature = append(signature, company)
+	}
+	signature = append(signature, "", "", "")
+	if name := strings.TrimSpace(offer.IssuerName); name != "" {
+		signature = append(signature, name)
+	}
+	if title := strings.TrimSpace(offer.SignatureTitle); title != "" {
+		signature = append(signature, title)
 	}
+	d.space(14)
+	d.ensure(float64(len(signature)) * body.lineHeight())
+	d.paragraph(strings.Join(signature, "\n"), body)
 
-	return renderSimplePDF(lines)
+	return d.render(), nil
 }
 
-func buildStyledMonthlyReportPDF(report *models.MonthlyReport, client *models.Client, service *models.Service) ([]byte, error) {
-	if report == nil {
-		return nil, fmt.Errorf("monthly report is required")
+// buildInvoicePDF renders an invoice with its lines, taxes and payments.
+func buildInvoicePDF(inv *models.Invoice, client *models.Client) ([]byte, error) {
+	if inv == nil {
+		return nil, fmt.Errorf("invoice is required")
 	}
-
-    lines := make([]string, 0, 28)
-    // Title header matching requested format
-    period := report.ReportMonth
-    if period.IsZero() {
-        period = time.Now()
-    }
-    lines = append(lines, fmt.Sprintf("Monthly Report - %s %d", monthNameID(period), period.Year()))
-    lines = append(lines, "")
-    // Company line (optional branding)
-    lines = append(lines, "Monitoring Solusi Indonesia")
-    lines = append(lines, "")
-
+	lines := make([]string, 0, 32)
+	lines = append(lines, "INVOICE")
+	lines = append(lines, "")
+	lines = append(lines, "Nomor Invoice: "+inv.InvoiceNumber)
+	lines = append(lines, "Tanggal: "+inv.IssueDate.Format("02 January 2006"))
+	lines = append(lines, "Jatuh Tempo: "+inv.DueDate.Format("02 January 2006"))
+	lines = append(lines, "Status: "+strings.ToUpper(inv.Status))
 	if client != nil {
-		lines = append(lines, "Client: "+strings.TrimSpace(client.Name))
-	}
-	if service != nil {
-		lines = append(lines, fmt.Sprintf("Layanan: %s (%s)", service.Domain, service.ServiceType))
+		lines = append(lines, "Kepada: "+strings.TrimSpace(client.Name))
+		if addr := compressWhitespace(client.Address); addr != "" {
+			lines = append(lines, "Alamat: "+addr)
+		}
 	} else {
-		lines = append(lines, fmt.Sprintf("Service ID: %d", report.ServiceID))
-	}
-
-    lines = append(lines, "Ringkasan Performa:")
-    // A concise paragraph summary approximating the React PDF 'summary' field
-    summary := fmt.Sprintf("Selama bulan %s %d, layanan %s menunjukkan rata-rata uptime %.2f%% dengan waktu respon rata-rata %d ms. Total downtime tercatat %d menit dengan %d alert dibuka dan %d terselesaikan. Waktu maintenance %.2f jam.",
-        monthNameID(period), period.Year(),
-        func() string { if service != nil { return service.Domain } ; return fmt.Sprintf("ID %d", report.ServiceID) }(),
-        report.AvgUptimePercent, report.AvgResponseMs, report.TotalDowntime, report.AlertsOpened, report.AlertsResolved, report.MaintenanceHours,
-    )
-    if strings.TrimSpace(report.Summary) != "" {
-        lines = append(lines, strings.TrimSpace(report.Summary))
-    } else {
-        lines = append(lines, summary)
-    }
+		lines = append(lines, fmt.Sprintf("Client ID: %d", inv.ClientID))
+	}
 
-    if acts := strings.TrimSpace(report.Activities); acts != "" {
-        // Activities may be array of strings or array of {date, description}
-        lines = append(lines, "")
-        lines = append(lines, "Aktivitas:")
-        type actObj struct{ Date, Description string }
-        var objItems []actObj
-        if err := json.Unmarshal([]byte(acts), &objItems); err == nil && len(objItems) > 0 {
-            for _, a := range objItems {
-                date := strings.TrimSpace(a.Date)
-                desc := strings.TrimSpace(a.Description)
-                if date == "" && desc == "" { continue }
-                if date == "" { date = "-" }
-                lines = append(lines, fmt.Sprintf("%s - %s", date, desc))
-            }
-        } else {
-            var strItems []string
-            if err := json.Unmarshal([]byte(acts), &strItems); err == nil && len(strItems) > 0 {
-                for idx, s := range strItems {
-                    s = strings.TrimSpace(s)
-                    if s == "" { continue }
-                    lines = append(lines, fmt.Sprintf("%d. %s", idx+1, s))
-                }
-            }
-        }
-    }
+	lines = append(lines, "")
+	lines = append(lines, "Rincian:")
+	for idx, item := range inv.Items {
+		lines = append(lines, fmt.Sprintf("%d. %s (Qty: %s, Harga: %s, Total: %s)",
+			idx+1, item.Description, formatQuantity(item.Qty), formatMoney(item.UnitPrice, inv.Currency), formatMoney(item.Total, inv.Currency)))
+	}
+	lines = append(lines, "")
+	lines = append(lines, "Subtotal: "+formatMoney(inv.Subtotal, inv.Currency))
+	for _, t := range inv.Taxes {
+		lines = append(lines, fmt.Sprintf("%s (%s%%): %s", t.Name, formatQuantity(t.Rate), formatMoney(t.Amount, inv.Currency)))
+	}
+	lines = append(lines, "Total: "+formatMoney(inv.Total, inv.Currency))
+	if len(inv.Payments) > 0 {
+		lines = append(lines, "")
+		lines = append(lines, "Pembayaran:")
+		for _, p := range inv.Payments {
+			lines = append(lines, fmt.Sprintf("%s - %s %s", p.PaidAt.Format("2006-01-02"), formatMoney(p.Amount, inv.Currency), strings.TrimSpace(p.Method)))
+		}
+	}
+	lines = append(lines, "Sisa Tagihan: "+formatMoney(inv.Balance(), inv.Currency))
 
-    return renderSimplePDF(lines)
+	if inv.PaymentTerms != "" {
+		lines = append(lines, "")
+		lines = append(lines, "Syarat Pembayaran:")
+		lines = append(lines, splitAndTrim(inv.PaymentTerms)...)
+	}
+	if inv.Notes != "" {
+		lines = append(lines, "")
+		lines = append(lines, "Catatan:")
+		lines = append(lines, splitAndTrim(inv.Notes)...)
+	}
+	return renderSimplePDF(lines)
 }
 
 // monthNameID returns the Indonesian month name for the given time.
@@ -270,61 +475,24 @@ func ensureUniquePDFName(dir, base string) string {
     return fmt.Sprintf("%s (%d).pdf", base, ts)
 }
 
-type offerItem struct {
-	Description string  `json:"description"`
-	Qty         float64 `json:"qty"`
-	UnitPrice   float64 `json:"unit_price"`
-	Total       float64 `json:"total"`
-}
-
+// renderSimplePDF sets lines as wrapped paragraphs under the letterhead.
 func renderSimplePDF(lines []string) ([]byte, error) {
 	if len(lines) == 0 {
 		lines = []string{"Dokumen"}
 	}
-
-	var stream strings.Builder
-	stream.WriteString("BT\n/F1 12 Tf\n72 812 Td\n14 TL\n")
-	for idx, line := range lines {
-		if idx > 0 {
-			stream.WriteString("T*\n")
-		}
-		stream.WriteString(fmt.Sprintf("(%s) Tj\n", escapePDFString(line)))
+	tpl, err := loadPDFTemplate()
+	if err != nil {
+		return nil, err
 	}
-	stream.WriteString("ET\n")
-
-	content := stream.String()
-
-	var buf bytes.Buffer
-	buf.WriteString("%PDF-1.4\n")
-
-	offsets := []int{0}
-	writeObj := func(format string, args ...any) {
-		offsets = append(offsets, buf.Len())
-		fmt.Fprintf(&buf, format, args...)
+	d, err := tpl.newDoc()
+	if err != nil {
+		return nil, err
 	}
-
-	writeObj("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
-	writeObj("2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 >>\nendobj\n")
-	writeObj("3 0 obj\n<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>\nendobj\n")
-	writeObj("4 0 obj\n<< /Length %d >>\nstream\n%sendstream\nendobj\n", len(content), content)
-	writeObj("5 0 obj\n<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>\nendobj\n")
-
-	startxref := buf.Len()
-	buf.WriteString("xref\n")
-	fmt.Fprintf(&buf, "0 %d\n", len(offsets))
-	buf.WriteString("0000000000 65535 f \n")
-	for _, off := range offsets[1:] {
-		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	if err := tpl.letterhead(d); err != nil {
+		return nil, err
 	}
-	buf.WriteString("trailer\n<< /Size ")
-	fmt.Fprintf(&buf, "%d /Root 1 0 R >>\nstartxref\n%d\n%%EOF\n", len(offsets), startxref)
-
-	return buf.Bytes(), nil
-}
-
-func escapePDFString(in string) string {
-	replacer := strings.NewReplacer("\\", "\\\\", "(", "\\(", ")", "\\)")
-	return replacer.Replace(in)
+	d.paragraph(strings.Join(lines, "\n"), pdfStyle{Size: 10})
+	return d.render(), nil
 }
 
 func formatQuantity(qty float64) string {
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RateLimitStore keeps fixed-window counters in the database so that limits hold
+// across replicas. Windows are aligned to the window size; expired rows are
+// pruned at most once a minute.
+type RateLimitStore struct {
+	db        *gorm.DB
+	now       func() time.Time
+	mu        sync.Mutex
+	nextPrune time.Time
+}
+
+func NewRateLimitStore(db *gorm.DB) *RateLimitStore {
+	return &RateLimitStore{db: db, now: time.Now}
+}
+
+// Incr atomically counts one hit for key and returns the window total and reset time.
+func (s *RateLimitStore) Incr(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	now := s.now()
+	start := now.Truncate(window)
+	resetAt := start.Add(window)
+	s.prune(ctx, now)
+
+	row := models.RateLimitCounter{Key: key, WindowStart: start, Count: 1, ExpiresAt: resetAt}
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}, {Name: "window_start"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"count": gorm.Expr("rate_limit_counters.count + 1")}),
+	}).Create(&row).Error
+	if err != nil {
+		return 0, resetAt, err
+	}
+	var cur models.RateLimitCounter
+	if err := s.db.WithContext(ctx).Where("key = ? AND window_start = ?", key, start).First(&cur).Error; err != nil {
+		return 0, resetAt, err
+	}
+	return cur.Count, resetAt, nil
+}
+
+func (s *RateLimitStore) prune(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if now.Before(s.nextPrune) {
+		s.mu.Unlock()
+		return
+	}
+	s.nextPrune = now.Add(time.Minute)
+	s.mu.Unlock()
+	_ = s.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&models.RateLimitCounter{}).Error
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestRateLimitStore_CountsPerWindowAndPrunes(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	if err := db.AutoMigrate(&models.RateLimitCounter{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	now := time.Date(2025, 1, 1, 10, 0, 5, 0, time.UTC)
+	s := NewRateLimitStore(db)
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 1; i <= 3; i++ {
+		n, reset, err := s.Incr(ctx, "default:ip:1.1.1.1", time.Minute)
+		if err != nil || n != i {
+			t.Fatalf("hit %d: got %d (%v)", i, n, err)
+		}
+		if !reset.Equal(time.Date(2025, 1, 1, 10, 1, 0, 0, time.UTC)) {
+			t.Fatalf("unexpected reset %v", reset)
+		}
+	}
+	if n, _, _ := s.Incr(ctx, "default:ip:2.2.2.2", time.Minute); n != 1 {
+		t.Fatalf("keys must be independent, got %d", n)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if n, _, _ := s.Incr(ctx, "default:ip:1.1.1.1", time.Minute); n != 1 {
+		t.Fatalf("expected new window to restart count, got %d", n)
+	}
+	var rows int64
+	db.Model(&models.RateLimitCounter{}).Count(&rows)
+	if rows != 1 {
+		t.Fatalf("expected expired counters pruned, %d rows left", rows)
+	}
+}
@@ -29,9 +29,9 @@ func (s *ReminderService) SendOfferExpiryReminders(ctx context.Context, within t
 	now := time.Now()
 	cutoff := now.Add(within)
 	var offers []models.Offer
-	// Only draft/sent offers; skip accepted/rejected
+	// Only open offers; skip accepted/rejected/expired/superseded
 	if err := s.db.WithContext(ctx).
-		Where("valid_until IS NOT NULL AND valid_until <= ? AND (status = ? OR status = ?)", cutoff, "draft", "sent").
+		Where("valid_until IS NOT NULL AND valid_until <= ? AND status IN ?", cutoff, []string{models.OfferDraft, models.OfferSent, models.OfferViewed}).
 		Find(&offers).Error; err != nil {
 		return 0, err
 	}
@@ -15,7 +15,7 @@ func TestSendOfferExpiryReminders(t *testing.T) {
     if err != nil {
         t.Fatalf("sqlite open: %v", err)
     }
-    if err := db.AutoMigrate(&models.Client{}, &models.Offer{}); err != nil {
+    if err := db.AutoMigrate(&models.Client{}, &models.Offer{}, &models.OfferItem{}, &models.NumberSequence{}, &models.OfferStatusChange{}, &models.OfferRevision{}, &models.OfferLink{}, &models.OfferSignature{}); err != nil {
         t.Fatalf("migrate: %v", err)
     }
 
@@ -25,7 +25,7 @@ func TestSendOfferExpiryReminders(t *testing.T) {
         t.Fatalf("create client: %v", err)
     }
     vu := time.Now().Add(72 * time.Hour)
-    of := &models.Offer{ClientID: c.ID, Subject: "Test Offer", Items: "[]", TotalPrice: 100, Status: "sent", ValidUntil: &vu}
+    of := &models.Offer{ClientID: c.ID, Subject: "Test Offer", TotalPrice: 100, Status: "sent", ValidUntil: &vu}
     if err := db.Create(of).Error; err != nil {
         t.Fatalf("create offer: %v", err)
     }
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/gorm"
+)
+
+// ReportDeliveryService generates last month's report for every active
+// service and emails the PDF to clients who opted in.
+type ReportDeliveryService struct {
+	db      *gorm.DB
+	reports *MonthlyReportService
+	mailer  *Mailer
+}
+
+func NewReportDeliveryService(db *gorm.DB) *ReportDeliveryService {
+	return &ReportDeliveryService{db: db, reports: NewMonthlyReportService(db), mailer: NewMailer()}
+}
+
+// ReportDeliveryFilter narrows ListDeliveries. Month is "2006-01".
+type ReportDeliveryFilter struct {
+	Month    string
+	Status   string
+	ClientID int
+	Limit    int
+	Offset   int
+}
+
+// DeliverMonthly delivers the reports for the month before now. Services
+// that already have a sent delivery for that month are left alone; failed
+// ones are retried until MONTHLY_REPORT_MAX_ATTEMPTS (default 5) is reached,
+// and skipped ones are re-evaluated on every run until their report is
+// finalized, so a client who opts in later still gets it. It returns the
+// deliveries attempted in this run.
+func (s *ReportDeliveryService) DeliverMonthly(ctx context.Context, now time.Time) ([]models.ReportDelivery, error) {
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+	maxAttempts := envInt("MONTHLY_REPORT_MAX_ATTEMPTS", 5)
+
+	var svcs []models.Service
+	if err := s.db.WithContext(ctx).Where("status = ?", "active").Order("id").Find(&svcs).Error; err != nil {
+		return nil, err
+	}
+	var existing []models.ReportDelivery
+	if err := s.db.WithContext(ctx).Where("report_month = ?", month).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	done := make(map[int]models.ReportDelivery, len(existing))
+	var reportIDs []int
+	for _, d := range existing {
+		done[d.ServiceID] = d
+		if d.Status == models.ReportDeliverySkipped && d.MonthlyReportID != nil {
+			reportIDs = append(reportIDs, *d.MonthlyReportID)
+		}
+	}
+	finalized := map[int]bool{}
+	if len(reportIDs) > 0 {
+		var ids []int
+		if err := s.db.WithContext(ctx).Model(&models.MonthlyReport{}).
+			Where("id IN ? AND finalized_at IS NOT NULL", reportIDs).Pluck("id", &ids).Error; err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			finalized[id] = true
+		}
+	}
+
+	var out []models.ReportDelivery
+	for _, svc := range svcs {
+		d, ok := done[svc.ID]
+		if ok {
+			switch d.Status {
+			case models.ReportDeliverySent:
+				continue
+			case models.ReportDeliveryFailed:
+				if d.Attempts >= maxAttempts {
+					continue
+				}
+			case models.ReportDeliverySkipped:
+				if d.MonthlyReportID != nil && finalized[*d.MonthlyReportID] {
+					continue
+				}
+			}
+		}
+		if !ok {
+			d = models.ReportDelivery{ServiceID: svc.ID, ReportMonth: month, ClientID: svc.ClientID}
+		}
+		if err := s.deliver(ctx, &svc, &d, now); err != nil {
+			return out, err
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+// RetryDelivery attempts a failed or skipped delivery again right away,
+// regardless of the attempt limit.
+func (s *ReportDeliveryService) RetryDelivery(ctx context.Context, id int, now time.Time) (*models.ReportDelivery, error) {
+	var d models.ReportDelivery
+	if err := s.db.WithContext(ctx).First(&d, id).Error; err != nil {
+		return nil, err
+	}
+	if d.Status == models.ReportDeliverySent {
+		return nil, FieldErrors{"status": "report was already sent"}
+	}
+	var svc models.Service
+	if err := s.db.WithContext(ctx).First(&svc, d.ServiceID).Error; err != nil {
+		return nil, err
+	}
+	if err := s.deliver(ctx, &svc, &d, now); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// ListDeliveries returns deliveries newest month first.
+func (s *ReportDeliveryService) ListDeliveries(ctx context.Context, f ReportDeliveryFilter) ([]models.ReportDelivery, int64, error) {
+	q := s.db.WithContext(ctx).Model(&models.ReportDelivery{})
+	if f.Month != "" {
+		m, err := time.Parse("2006-01", f.Month)
+		if err != nil {
+			return nil, 0, FieldErrors{"month": "must be YYYY-MM"}
+		}
+		q = q.Where("report_month >= ? AND report_month < ?", m, m.AddDate(0, 1, 0))
+	}
+	if f.Status != "" {
+		q = q.Where("status = ?", f.Status)
+	}
+	if f.ClientID > 0 {
+		q = q.Where("client_id = ?", f.ClientID)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if f.Limit > 0 {
+		q = q.Limit(f.Limit).Offset(f.Offset)
+	}
+	var items []models.ReportDelivery
+	if err := q.Order("report_month DESC, id DESC").Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// deliver runs one attempt for d and saves its outcome. Only database
+// errors are returned; generation, rendering and mail errors mark d failed.
+// Skipped runs send nothing, so they do not count towards the attempt limit.
+func (s *ReportDeliveryService) deliver(ctx context.Context, svc *models.Service, d *models.ReportDelivery, now time.Time) error {
+	d.LastAttemptAt = &now
+	d.LastError = ""
+	d.ClientID = svc.ClientID
+
+	status, reason := s.send(ctx, svc, d)
+	d.Status = status
+	if status != models.ReportDeliverySkipped {
+		d.Attempts++
+	}
+	if status != models.ReportDeliverySent {
+		d.LastError = reason
+	} else {
+		d.SentAt = &now
+	}
+	if err := s.db.WithContext(ctx).Save(d).Error; err != nil {
+		return err
+	}
+	// The email is out and recorded; failing to finalize must not resend it.
+	if status == models.ReportDeliverySent {
+		if _, err := s.reports.FinalizeMonthlyReport(ctx, *d.MonthlyReportID, now); err != nil {
+			log.Printf("report delivery %d: finalizing report %d: %v", d.ID, *d.MonthlyReportID, err)
+		}
+	}
+	return nil
+}
+
+// send prepares and emails the report, returning the resulting status and,
+// unless sent, the reason. The report is generated for every service, even
+// when the client is not emailed, so it is there for the dashboard and for
+// a later opt-in.
+func (s *ReportDeliveryService) send(ctx context.Context, svc *models.Service, d *models.ReportDelivery) (string, string) {
+	report, err := s.monthlyReport(ctx, svc, d.ReportMonth)
+	if errors.Is(err, ErrNoReportData) {
+		return models.ReportDeliverySkipped, err.Error()
+	}
+	if err != nil {
+		return models.ReportDeliveryFailed, err.Error()
+	}
+	d.MonthlyReportID = &report.ID
+
+	var client models.Client
+	if err := s.db.WithContext(ctx).First(&client, svc.ClientID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ReportDeliverySkipped, "client not found"
+		}
+		return models.ReportDeliveryFailed, err.Error()
+	}
+	if !client.ReportOptIn {
+		return models.ReportDeliverySkipped, "client has not opted in to report emails"
+	}
+	to := strings.TrimSpace(client.Email)
+	if to == "" {
+		return models.ReportDeliverySkipped, "client has no email"
+	}
+	cc := reportCCList(client.ReportCC)
+	d.Recipient, d.CC = to, strings.Join(cc, ", ")
+
+	pdf, err := s.reports.RenderPDF(ctx, report, 0)
+	if err != nil {
+		return models.ReportDeliveryFailed, err.Error()
+	}
+	period := monthNameID(d.ReportMonth) + " " + d.ReportMonth.Format("2006")
+	subject := fmt.Sprintf("Laporan Bulanan %s - %s", svc.Domain, period)
+	body := fmt.Sprintf("Dear %s,\n\nPlease find attached the monthly monitoring report for %s covering %s.",
+		nonEmpty(client.ContactPerson, client.Name), svc.Domain, period)
+	attachment := MailAttachment{
+		Filename:    fmt.Sprintf("monthly-report-%s-%s.pdf", svc.Domain, d.ReportMonth.Format("2006-01")),
+		ContentType: "application/pdf",
+		Data:        pdf,
+	}
+	if err := s.mailer.SendEmailWithAttachmentsCC(to, cc, subject, body, []MailAttachment{attachment}); err != nil {
+		return models.ReportDeliveryFailed, err.Error()
+	}
+	return models.ReportDeliverySent, ""
+}
+
+// monthlyReport returns the service's report for month, generating it when
+// none exists. Reports generated here are owned by the service's user and
+// list the month's incidents and maintenance before the logged work as
+// activities. An existing report is regenerated first unless finalized, so
+// one built mid-month is not sent with partial metrics; regeneration keeps
+// manual edits.
+func (s *ReportDeliveryService) monthlyReport(ctx context.Context, svc *models.Service, month time.Time) (*models.MonthlyReport, error) {
+	existing, err := s.reports.findMonthlyReport(ctx, svc.ID, month)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if existing.FinalizedAt != nil {
+			return existing, nil
+		}
+		return s.reports.RegenerateMonthlyReport(ctx, existing.ID)
+	}
+	generated, err := s.reports.GenerateMonthlyReport(ctx, svc.ID, month)
+	if err != nil {
+		return nil, err
+	}
+	activities, err := s.alertActivities(ctx, svc.ID, month)
+	if err != nil {
+		return nil, err
+	}
+	generated.UserID = svc.UserID
+	if len(activities) > 0 {
+		// Keep the logged work aggregated from time entries.
+		activities = append(activities, parseReportActivities(generated.Activities)...)
+		b, _ := json.Marshal(activities)
+		generated.Activities = string(b)
+	}
+	if err := s.db.WithContext(ctx).Model(generated).Updates(map[string]interface{}{
+		"user_id":    generated.UserID,
+		"activities": generated.Activities,
+	}).Error; err != nil {
+		return nil, err
+	}
+	return generated, nil
+}
+
+// alertActivities describes the month's alerts as report activities: uptime
+// alerts are incidents, resolved SSL and domain expiry alerts are renewals.
+func (s *ReportDeliveryService) alertActivities(ctx context.Context, serviceID int, month time.Time) ([]models.ReportActivity, error) {
+	var alerts []models.Alert
+	if err := s.db.WithContext(ctx).
+		Where("service_id = ? AND created_at >= ? AND created_at < ?", serviceID, month, month.AddDate(0, 1, 0)).
+		Order("created_at").Find(&alerts).Error; err != nil {
+		return nil, err
+	}
+	var out []models.ReportActivity
+	for _, a := range alerts {
+		var desc string
+		switch a.AlertType {
+		case "uptime":
+			desc = "Insiden: " + a.Title
+			if a.ResolvedAt != nil {
+				desc += fmt.Sprintf(" (pulih %s, %s)", a.ResolvedAt.Format("02/01 15:04"), formatIncidentDuration(a.ResolvedAt.Sub(a.CreatedAt)))
+			} else {
+				desc += " (belum terselesaikan)"
+			}
+		case "ssl_expiry", "domain_expiry":
+			if a.ResolvedAt == nil {
+				continue
+			}
+			desc = "Pemeliharaan: " + a.Title + " ditangani"
+		default:
+			continue
+		}
+		out = append(out, models.ReportActivity{Date: formatDateID(a.CreatedAt), Description: desc})
+	}
+	return out, nil
+}
+
+func formatIncidentDuration(d time.Duration) string {
+	if d < time.Hour {
+		return fmt.Sprintf("%d menit", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%d jam %d menit", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// reportCCList splits a comma or semicolon separated CC list.
+func reportCCList(raw string) []string {
+	var out []string
+	for _, p := range strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == ';' }) {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestDeliverMonthlyReports(t *testing.T) {
+	t.Setenv("PDF_TEMPLATE_PATH", "")
+	for k, v := range map[string]string{"SMTP_HOST": "smtp.test", "SMTP_PORT": "587", "SMTP_USER": "u", "SMTP_PASSWORD": "p", "SMTP_FROM": "me@test"} {
+		t.Setenv(k, v)
+	}
+	var sent []string
+	fail := true
+	orig := sendMail
+	sendMail = func(_ string, _ smtp.Auth, _ string, to []string, msg []byte) error {
+		if fail {
+			fail = false
+			return errors.New("421 try again later")
+		}
+		sent = append(sent, strings.Join(to, ",")+"\n"+string(msg))
+		return nil
+	}
+	t.Cleanup(func() { sendMail = orig })
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Client{}, &models.Service{}, &models.MonthlyReport{}, &models.TimeEntry{}, &models.DailyReport{}, &models.UptimeLog{},
+		&models.Alert{}, &models.ReportTemplate{}, &models.ReportDelivery{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	optedIn := models.Client{Name: "Acme", Email: "ops@acme.test", ReportOptIn: true, ReportCC: "boss@acme.test; , cto@acme.test"}
+	quiet := models.Client{Name: "Quiet", Email: "quiet@test"}
+	later := models.Client{Name: "Later", Email: "later@test"}
+	db.Create(&optedIn)
+	db.Create(&quiet)
+	db.Create(&later)
+	withData := models.Service{UserID: 3, ClientID: optedIn.ID, Domain: "acme.test", ServiceType: "website", Status: "active"}
+	noData := models.Service{UserID: 3, ClientID: optedIn.ID, Domain: "empty.test", ServiceType: "website", Status: "active"}
+	notOpted := models.Service{UserID: 3, ClientID: quiet.ID, Domain: "quiet.test", ServiceType: "website", Status: "active"}
+	optsInLater := models.Service{UserID: 3, ClientID: later.ID, Domain: "later.test", ServiceType: "website", Status: "active"}
+	paused := models.Service{UserID: 3, ClientID: optedIn.ID, Domain: "paused.test", ServiceType: "website", Status: "paused"}
+	for _, s := range []*models.Service{&withData, &noData, &notOpted, &optsInLater, &paused} {
+		db.Create(s)
+	}
+	feb := time.Date(2025, 2, 10, 10, 0, 0, 0, time.UTC)
+	for _, s := range []models.Service{withData, notOpted, optsInLater, paused} {
+		db.Create(&models.UptimeLog{ServiceID: s.ID, Status: "up", ResponseTime: 200, CheckedAt: feb})
+	}
+	resolved := feb.Add(45 * time.Minute)
+	db.Create(&models.Alert{ServiceID: withData.ID, AlertType: "uptime", Level: "critical", Title: "acme.test down", CreatedAt: feb, ResolvedAt: &resolved, IsResolved: true})
+
+	svc := NewReportDeliveryService(db)
+	ctx := context.Background()
+	now := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	run, err := svc.DeliverMonthly(ctx, now)
+	if err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if len(run) != 4 {
+		t.Fatalf("expected 4 deliveries (paused service excluded), got %d", len(run))
+	}
+	status := map[int]models.ReportDelivery{}
+	for _, d := range run {
+		status[d.ServiceID] = d
+	}
+	if d := status[withData.ID]; d.Status != models.ReportDeliveryFailed || d.Attempts != 1 || !strings.Contains(d.LastError, "421") {
+		t.Fatalf("expected the first send to fail, got %+v", d)
+	}
+	if d := status[noData.ID]; d.Status != models.ReportDeliverySkipped || !strings.Contains(d.LastError, "no data") {
+		t.Fatalf("expected no-data skip, got %+v", d)
+	}
+	if d := status[notOpted.ID]; d.Status != models.ReportDeliverySkipped || d.MonthlyReportID == nil || d.Attempts != 0 {
+		t.Fatalf("expected opt-out skip with the report still generated, got %+v", d)
+	}
+	// A finalized report settles its skipped delivery.
+	if _, err := svc.reports.FinalizeMonthlyReport(ctx, *status[notOpted.ID].MonthlyReportID, now); err != nil {
+		t.Fatalf("finalize: %v", err)
+	}
+	db.Model(&models.Client{}).Where("id = ?", later.ID).Update("report_opt_in", true)
+
+	// Data arriving after the report was built is picked up before sending.
+	db.Create(&models.UptimeLog{ServiceID: withData.ID, Status: "up", ResponseTime: 400, CheckedAt: feb.AddDate(0, 0, 1)})
+
+	run, err = svc.DeliverMonthly(ctx, now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("retry run: %v", err)
+	}
+	if len(run) != 3 {
+		t.Fatalf("expected the failed and unfinalized skipped deliveries to run again, got %+v", run)
+	}
+	for _, d := range run {
+		status[d.ServiceID] = d
+	}
+	if d := status[withData.ID]; d.Status != models.ReportDeliverySent || d.Attempts != 2 || d.SentAt == nil {
+		t.Fatalf("expected the failed delivery to be retried and sent, got %+v", d)
+	}
+	if d := status[optsInLater.ID]; d.Status != models.ReportDeliverySent || d.Recipient != "later@test" {
+		t.Fatalf("expected a client who opted in later to be sent the report, got %+v", d)
+	}
+	if d := status[noData.ID]; d.Status != models.ReportDeliverySkipped {
+		t.Fatalf("expected no-data to stay skipped, got %+v", d)
+	}
+	if len(sent) != 2 {
+		t.Fatalf("expected two emails, got %d", len(sent))
+	}
+	msg := sent[0]
+	for _, want := range []string{"ops@acme.test,boss@acme.test,cto@acme.test\n", "Cc: boss@acme.test, cto@acme.test\r\n", "monthly-report-acme.test-2025-02.pdf", "Februari 2025"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("email missing %q", want)
+		}
+	}
+
+	var reports []models.MonthlyReport
+	db.Where("service_id = ?", withData.ID).Find(&reports)
+	if len(reports) != 1 {
+		t.Fatalf("expected the report to be generated once, got %d", len(reports))
+	}
+	if reports[0].UserID != 3 || !strings.Contains(reports[0].Activities, "Insiden: acme.test down") || !strings.Contains(reports[0].Activities, "45 menit") {
+		t.Fatalf("generated report not filled in: %+v", reports[0])
+	}
+	if reports[0].AvgResponseMs != 300 {
+		t.Fatalf("expected the report to be regenerated before sending: %+v", reports[0])
+	}
+	if reports[0].FinalizedAt == nil {
+		t.Fatal("expected the sent report to be finalized")
+	}
+
+	if run, _ := svc.DeliverMonthly(ctx, now.Add(2*time.Hour)); len(run) != 1 || run[0].ServiceID != noData.ID {
+		t.Fatalf("expected only the no-data delivery to be re-evaluated, got %+v", run)
+	}
+	items, total, err := svc.ListDeliveries(ctx, ReportDeliveryFilter{Month: "2025-02", Status: models.ReportDeliverySkipped})
+	if err != nil || total != 2 || len(items) != 2 {
+		t.Fatalf("list skipped: %d %v", total, err)
+	}
+	var fe FieldErrors
+	if _, err := svc.RetryDelivery(ctx, status[withData.ID].ID, now); !errors.As(err, &fe) {
+		t.Fatalf("expected retrying a sent delivery to be rejected, got %v", err)
+	}
+
+	db.Model(&models.Client{}).Where("id = ?", quiet.ID).Update("report_opt_in", true)
+	d, err := svc.RetryDelivery(ctx, status[notOpted.ID].ID, now)
+	if err != nil || d.Status != models.ReportDeliverySent || d.Recipient != "quiet@test" {
+		t.Fatalf("manual retry after opting in: %+v %v", d, err)
+	}
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/gorm"
+)
+
+// CurrencyRevenue is one currency's share of a revenue report, in that
+// currency and converted to the base currency.
+type CurrencyRevenue struct {
+	Currency     string  `json:"currency"`
+	Accepted     float64 `json:"accepted"`
+	Invoiced     float64 `json:"invoiced"`
+	Paid         float64 `json:"paid"`
+	AcceptedBase float64 `json:"accepted_base"`
+	InvoicedBase float64 `json:"invoiced_base"`
+	PaidBase     float64 `json:"paid_base"`
+}
+
+// RevenueReport totals accepted offers (by accept date), invoices (by issue
+// date, excluding void) and payments (by payment date) in [From, To), in the
+// base currency. Amounts in a currency with no rate on file are listed under
+// Unconverted and left out of the base totals.
+type RevenueReport struct {
+	BaseCurrency string            `json:"base_currency"`
+	From         time.Time         `json:"from"`
+	To           time.Time         `json:"to"`
+	Accepted     float64           `json:"accepted"`
+	Invoiced     float64           `json:"invoiced"`
+	Paid         float64           `json:"paid"`
+	Currencies   []CurrencyRevenue `json:"currencies"`
+	Unconverted  []string          `json:"unconverted"`
+}
+
+// RevenueService reports revenue across currencies.
+type RevenueService struct {
+	db *gorm.DB
+}
+
+func NewRevenueService(db *gorm.DB) *RevenueService {
+	return &RevenueService{db: db}
+}
+
+// Revenue builds the report for [from, to). Offers and invoices convert at
+// their snapshotted rate; ones without a snapshot use the rate in effect on
+// the accept or issue date. Payments convert at their invoice's rate.
+func (s *RevenueService) Revenue(ctx context.Context, from, to time.Time) (*RevenueReport, error) {
+	db := s.db.WithContext(ctx)
+	conv := &revenueConverter{db: db, cache: map[string]float64{}, missing: map[string]bool{}}
+	byCur := map[string]*CurrencyRevenue{}
+	line := func(currency string) *CurrencyRevenue {
+		currency = nonEmpty(normalizeCurrency(currency), BaseCurrency())
+		if byCur[currency] == nil {
+			byCur[currency] = &CurrencyRevenue{Currency: currency}
+		}
+		return byCur[currency]
+	}
+
+	var offers []models.Offer
+	if err := db.Where("status = ? AND approved_at >= ? AND approved_at < ?", models.OfferAccepted, from, to).
+		Find(&offers).Error; err != nil {
+		return nil, err
+	}
+	for _, o := range offers {
+		l := line(o.Currency)
+		l.Accepted += o.TotalPrice
+		base, err := conv.convert(o.TotalPrice, l.Currency, o.ExchangeRate, *o.ApprovedAt)
+		if err != nil {
+			return nil, err
+		}
+		l.AcceptedBase += base
+	}
+
+	var invoices []models.Invoice
+	if err := db.Where("status <> ? AND issue_date >= ? AND issue_date < ?", models.InvoiceVoid, from, to).
+		Find(&invoices).Error; err != nil {
+		return nil, err
+	}
+	for _, inv := range invoices {
+		l := line(inv.Currency)
+		l.Invoiced += inv.Total
+		base, err := conv.convert(inv.Total, l.Currency, inv.ExchangeRate, inv.IssueDate)
+		if err != nil {
+			return nil, err
+		}
+		l.InvoicedBase += base
+	}
+
+	var payments []struct {
+		Amount       float64
+		PaidAt       time.Time
+		Currency     string
+		ExchangeRate float64
+	}
+	if err := db.Table("invoice_payments").
+		Select("invoice_payments.amount, invoice_payments.paid_at, invoices.currency, invoices.exchange_rate").
+		Joins("JOIN invoices ON invoices.id = invoice_payments.invoice_id").
+		Where("invoices.status <> ? AND invoice_payments.paid_at >= ? AND invoice_payments.paid_at < ?", models.InvoiceVoid, from, to).
+		Scan(&payments).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range payments {
+		l := line(p.Currency)
+		l.Paid += p.Amount
+		base, err := conv.convert(p.Amount, l.Currency, p.ExchangeRate, p.PaidAt)
+		if err != nil {
+			return nil, err
+		}
+		l.PaidBase += base
+	}
+
+	report := &RevenueReport{BaseCurrency: BaseCurrency(), From: from, To: to, Currencies: []CurrencyRevenue{}, Unconverted: []string{}}
+	for _, l := range byCur {
+		l.Accepted, l.Invoiced, l.Paid = roundMoney(l.Accepted), roundMoney(l.Invoiced), roundMoney(l.Paid)
+		l.AcceptedBase, l.InvoicedBase, l.PaidBase = roundMoney(l.AcceptedBase), roundMoney(l.InvoicedBase), roundMoney(l.PaidBase)
+		report.Accepted += l.AcceptedBase
+		report.Invoiced += l.InvoicedBase
+		report.Paid += l.PaidBase
+		report.Currencies = append(report.Currencies, *l)
+	}
+	report.Accepted, report.Invoiced, report.Paid = roundMoney(report.Accepted), roundMoney(report.Invoiced), roundMoney(report.Paid)
+	sort.Slice(report.Currencies, func(i, j int) bool { return report.Currencies[i].Currency < report.Currencies[j].Currency })
+	for c := range conv.missing {
+		report.Unconverted = append(report.Unconverted, c)
+	}
+	sort.Strings(report.Unconverted)
+	return report, nil
+}
+
+// revenueConverter converts amounts to the base currency, caching rate lookups.
+type revenueConverter struct {
+	db      *gorm.DB
+	cache   map[string]float64
+	missing map[string]bool
+}
+
+func (c *revenueConverter) convert(amount float64, currency string, snapshot float64, at time.Time) (float64, error) {
+	if snapshot > 0 {
+		return amount * snapshot, nil
+	}
+	key := currency + "@" + rateDay(at).Format("2006-01-02")
+	rate, ok := c.cache[key]
+	if !ok {
+		var err error
+		rate, _, err = exchangeRateOn(c.db, currency, at)
+		if errors.Is(err, ErrNoExchangeRate) {
+			c.missing[currency] = true
+		} else if err != nil {
+			return 0, err
+		}
+		c.cache[key] = rate
+	}
+	return amount * rate, nil
+}
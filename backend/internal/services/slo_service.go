@@ -21,6 +21,14 @@ func (s *SLOService) ListByService(ctx context.Context, serviceID int) ([]models
 	return items, nil
 }
 
+func (s *SLOService) Get(ctx context.Context, id int) (*models.SLOTarget, error) {
+	var t models.SLOTarget
+	if err := s.db.WithContext(ctx).First(&t, id).Error; err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
 func (s *SLOService) Create(ctx context.Context, t *models.SLOTarget) error {
 	return s.db.WithContext(ctx).Create(t).Error
 }
@@ -0,0 +1,358 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/gorm"
+)
+
+var ErrSubscriptionState = errors.New("subscription is cancelled")
+
+// SubscriptionInput creates a retainer. StartDate defaults to today (UTC).
+type SubscriptionInput struct {
+	ClientID     int       `json:"client_id"`
+	ServiceID    *int      `json:"service_id"`
+	Name         string    `json:"name"`
+	Amount       float64   `json:"amount"`
+	Currency     string    `json:"currency"`
+	Cycle        string    `json:"cycle"`
+	StartDate    time.Time `json:"start_date"`
+	PaymentTerms string    `json:"payment_terms"`
+	TaxName      string    `json:"tax_name"`
+	TaxRate      float64   `json:"tax_rate"`
+}
+
+// SubscriptionUpdate changes a retainer. Nil fields are left untouched.
+type SubscriptionUpdate struct {
+	Name         *string  `json:"name"`
+	Amount       *float64 `json:"amount"`
+	PaymentTerms *string  `json:"payment_terms"`
+	TaxName      *string  `json:"tax_name"`
+	TaxRate      *float64 `json:"tax_rate"`
+}
+
+// SubscriptionFilter narrows subscription listings. Zero values are ignored.
+type SubscriptionFilter struct {
+	ClientID  int
+	ServiceID int
+	Status    string
+	Limit     int
+	Offset    int
+}
+
+type SubscriptionService struct {
+	db       *gorm.DB
+	invoices *InvoiceService
+	reports  *MonthlyReportService
+}
+
+func NewSubscriptionService(db *gorm.DB) *SubscriptionService {
+	return &SubscriptionService{db: db, invoices: NewInvoiceService(db), reports: NewMonthlyReportService(db)}
+}
+
+// Create validates and stores a new active subscription. Billing starts at
+// StartDate; the first invoice is generated once the first cycle has ended.
+func (s *SubscriptionService) Create(ctx context.Context, in SubscriptionInput) (*models.Subscription, error) {
+	in.Name = strings.TrimSpace(in.Name)
+	in.Cycle = strings.ToLower(strings.TrimSpace(in.Cycle))
+	if in.Cycle == "" {
+		in.Cycle = models.CycleMonthly
+	}
+	if in.ClientID <= 0 {
+		return nil, errors.New("client_id is required")
+	}
+	if in.Name == "" {
+		return nil, errors.New("name is required")
+	}
+	if in.Amount <= 0 {
+		return nil, errors.New("amount must be positive")
+	}
+	if in.Cycle != models.CycleMonthly && in.Cycle != models.CycleQuarterly && in.Cycle != models.CycleYearly {
+		return nil, fmt.Errorf("invalid cycle %q: use monthly, quarterly or yearly", in.Cycle)
+	}
+	if in.TaxRate < 0 || in.TaxRate > 100 {
+		return nil, errors.New("tax_rate must be between 0 and 100")
+	}
+	var client models.Client
+	if err := s.db.WithContext(ctx).First(&client, in.ClientID).Error; err != nil {
+		return nil, err
+	}
+	if in.ServiceID != nil {
+		var svc models.Service
+		if err := s.db.WithContext(ctx).First(&svc, *in.ServiceID).Error; err != nil {
+			return nil, err
+		}
+		if svc.ClientID != in.ClientID {
+			return nil, errors.New("service does not belong to the client")
+		}
+	}
+	start := in.StartDate
+	if start.IsZero() {
+		start = time.Now()
+	}
+	start = dayStart(start)
+	sub := &models.Subscription{
+		ClientID:        in.ClientID,
+		ServiceID:       in.ServiceID,
+		Name:            in.Name,
+		Amount:          roundMoney(in.Amount),
+		Currency:        nonEmpty(normalizeCurrency(in.Currency), BaseCurrency()),
+		Cycle:           in.Cycle,
+		StartDate:       start,
+		NextPeriodStart: start,
+		Status:          models.SubscriptionActive,
+		PaymentTerms:    in.PaymentTerms,
+		TaxName:         strings.TrimSpace(in.TaxName),
+		TaxRate:         in.TaxRate,
+	}
+	if err := s.db.WithContext(ctx).Create(sub).Error; err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *SubscriptionService) Get(ctx context.Context, id int) (*models.Subscription, error) {
+	var sub models.Subscription
+	if err := s.db.WithContext(ctx).First(&sub, id).Error; err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// List returns subscriptions matching the filter, newest first.
+func (s *SubscriptionService) List(ctx context.Context, f SubscriptionFilter) ([]models.Subscription, int64, error) {
+	q := s.db.WithContext(ctx).Model(&models.Subscription{})
+	if f.ClientID > 0 {
+		q = q.Where("client_id = ?", f.ClientID)
+	}
+	if f.ServiceID > 0 {
+		q = q.Where("service_id = ?", f.ServiceID)
+	}
+	if f.Status != "" {
+		q = q.Where("status = ?", f.Status)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if f.Limit > 0 {
+		q = q.Limit(f.Limit)
+	}
+	if f.Offset > 0 {
+		q = q.Offset(f.Offset)
+	}
+	var items []models.Subscription
+	if err := q.Order("id DESC").Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// Update applies changes effective at the given time. Cycles that ended before
+// then are invoiced first at the old terms. An amount change inside the open
+// cycle is prorated by day: the cycle is billed at the new amount plus an
+// adjustment for the days already served at the old amount.
+func (s *SubscriptionService) Update(ctx context.Context, id int, u SubscriptionUpdate, at time.Time) (*models.Subscription, error) {
+	sub, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sub.Status == models.SubscriptionCancelled {
+		return nil, ErrSubscriptionState
+	}
+	if u.Amount != nil && *u.Amount <= 0 {
+		return nil, errors.New("amount must be positive")
+	}
+	if u.TaxRate != nil && (*u.TaxRate < 0 || *u.TaxRate > 100) {
+		return nil, errors.New("tax_rate must be between 0 and 100")
+	}
+	if u.Name != nil && strings.TrimSpace(*u.Name) == "" {
+		return nil, errors.New("name must not be empty")
+	}
+	at = dayStart(at)
+	if _, err := s.bill(ctx, sub, at); err != nil {
+		return nil, err
+	}
+	updates := map[string]interface{}{}
+	if u.Name != nil {
+		updates["name"] = strings.TrimSpace(*u.Name)
+	}
+	if u.PaymentTerms != nil {
+		updates["payment_terms"] = *u.PaymentTerms
+	}
+	if u.TaxName != nil {
+		updates["tax_name"] = strings.TrimSpace(*u.TaxName)
+	}
+	if u.TaxRate != nil {
+		updates["tax_rate"] = *u.TaxRate
+	}
+	if u.Amount != nil && roundMoney(*u.Amount) != sub.Amount {
+		newAmount := roundMoney(*u.Amount)
+		ps := sub.NextPeriodStart
+		if at.After(ps) {
+			served := prorate(sub.Amount-newAmount, ps, at, sub.PeriodEnd(ps))
+			updates["proration_adjustment"] = roundMoney(sub.ProrationAdjustment + served)
+		}
+		updates["amount"] = newAmount
+	}
+	if len(updates) > 0 {
+		if err := s.db.WithContext(ctx).Model(sub).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+	return s.Get(ctx, id)
+}
+
+// Cancel ends the subscription at the given time. The final, partial cycle is
+// prorated and invoiced by the billing job once that time has passed.
+func (s *SubscriptionService) Cancel(ctx context.Context, id int, at time.Time) (*models.Subscription, error) {
+	sub, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sub.Status == models.SubscriptionCancelled {
+		return nil, ErrSubscriptionState
+	}
+	at = dayStart(at)
+	if at.Before(sub.StartDate) {
+		at = sub.StartDate
+	}
+	if _, err := s.bill(ctx, sub, at); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Model(sub).Updates(map[string]interface{}{
+		"status":   models.SubscriptionCancelled,
+		"end_date": at,
+	}).Error; err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, id)
+}
+
+// GenerateDueInvoices invoices every cycle that has ended by now, catching up
+// on missed cycles. It returns the invoices created.
+func (s *SubscriptionService) GenerateDueInvoices(ctx context.Context, now time.Time) ([]models.Invoice, error) {
+	var subs []models.Subscription
+	if err := s.db.WithContext(ctx).
+		Where("status = ? OR (status = ? AND end_date > next_period_start)", models.SubscriptionActive, models.SubscriptionCancelled).
+		Where("next_period_start < ?", now).
+		Order("id").Find(&subs).Error; err != nil {
+		return nil, err
+	}
+	var out []models.Invoice
+	for i := range subs {
+		invs, err := s.bill(ctx, &subs[i], now)
+		out = append(out, invs...)
+		if err != nil {
+			return out, fmt.Errorf("subscription %d: %w", subs[i].ID, err)
+		}
+	}
+	return out, nil
+}
+
+// bill invoices the subscription's cycles that ended on or before now and
+// advances NextPeriodStart past them. sub is updated in place.
+func (s *SubscriptionService) bill(ctx context.Context, sub *models.Subscription, now time.Time) ([]models.Invoice, error) {
+	var out []models.Invoice
+	for {
+		ps := sub.NextPeriodStart
+		pe := sub.PeriodEnd(ps)
+		end := pe
+		if sub.EndDate != nil && sub.EndDate.Before(pe) {
+			end = *sub.EndDate
+		}
+		if !end.After(ps) || end.After(now) {
+			return out, nil
+		}
+		var existing int64
+		if err := s.db.WithContext(ctx).Model(&models.Invoice{}).
+			Where("subscription_id = ? AND period_start = ?", sub.ID, ps).Count(&existing).Error; err != nil {
+			return out, err
+		}
+		if existing == 0 {
+			inv, err := s.cycleInvoice(ctx, sub, ps, end, pe, now)
+			if err != nil {
+				return out, err
+			}
+			if err := s.invoices.create(ctx, inv); err != nil {
+				return out, err
+			}
+			out = append(out, *inv)
+		}
+		if err := s.db.WithContext(ctx).Model(sub).Updates(map[string]interface{}{
+			"next_period_start":    pe,
+			"proration_adjustment": 0,
+		}).Error; err != nil {
+			return out, err
+		}
+		sub.NextPeriodStart = pe
+		sub.ProrationAdjustment = 0
+	}
+}
+
+// cycleInvoice builds the draft invoice for [ps, end) of the cycle [ps, pe).
+func (s *SubscriptionService) cycleInvoice(ctx context.Context, sub *models.Subscription, ps, end, pe, issue time.Time) (*models.Invoice, error) {
+	label := fmt.Sprintf("%s (%s – %s)", sub.Name, ps.Format("2006-01-02"), end.AddDate(0, 0, -1).Format("2006-01-02"))
+	charge := sub.Amount
+	if end.Before(pe) {
+		charge = prorate(sub.Amount, ps, end, pe)
+		label += fmt.Sprintf(", prorated %d/%d days", days(ps, end), days(ps, pe))
+	}
+	items := []models.InvoiceItem{{Position: 1, Description: label, Qty: 1, UnitPrice: roundMoney(charge), Total: roundMoney(charge)}}
+	if adj := roundMoney(sub.ProrationAdjustment); adj != 0 {
+		items = append(items, models.InvoiceItem{Position: 2, Description: "Proration adjustment for mid-cycle changes", Qty: 1, UnitPrice: adj, Total: adj})
+	}
+	periodEnd := end
+	inv := &models.Invoice{
+		SubscriptionID: &sub.ID,
+		PeriodStart:    &ps,
+		PeriodEnd:      &periodEnd,
+		ClientID:       sub.ClientID,
+		Status:         models.InvoiceDraft,
+		IssueDate:      issue,
+		DueDate:        issue.AddDate(0, 0, DueDaysFromTerms(sub.PaymentTerms)),
+		Currency:       sub.Currency,
+		PaymentTerms:   sub.PaymentTerms,
+		Items:          items,
+	}
+	var taxes []InvoiceTaxInput
+	if sub.TaxName != "" {
+		taxes = append(taxes, InvoiceTaxInput{Name: sub.TaxName, Rate: sub.TaxRate})
+	}
+	if err := applyInvoiceTotals(inv, taxes); err != nil {
+		return nil, err
+	}
+	if sub.ServiceID != nil {
+		inv.MonthlyReportID = s.monthlyReportFor(ctx, *sub.ServiceID, ps)
+	}
+	return inv, nil
+}
+
+// monthlyReportFor returns the service's report for the month the cycle starts
+// in, generating it when missing. Nil when there is no monitoring data.
+func (s *SubscriptionService) monthlyReportFor(ctx context.Context, serviceID int, periodStart time.Time) *int {
+	report, err := s.reports.GenerateMonthlyReport(ctx, serviceID, periodStart)
+	if err != nil {
+		return nil
+	}
+	return &report.ID
+}
+
+// prorate returns the share of amount for [ps, to) within the cycle [ps, pe), by day.
+func prorate(amount float64, ps, to, pe time.Time) float64 {
+	return amount * float64(days(ps, to)) / float64(days(ps, pe))
+}
+
+func days(from, to time.Time) int {
+	return int(to.Sub(from).Hours()/24 + 0.5)
+}
+
+func dayStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newSubscriptionTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite open: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Client{}, &models.Service{}, &models.UptimeLog{}, &models.Alert{}, &models.DailyReport{},
+		&models.MonthlyReport{}, &models.TimeEntry{}, &models.Subscription{}, &models.Invoice{}, &models.InvoiceItem{}, &models.InvoiceTax{}, &models.InvoicePayment{},
+		&models.NumberSequence{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func day(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+func TestSubscriptionService_BillsCyclesAndLinksMonthlyReport(t *testing.T) {
+	db := newSubscriptionTestDB(t)
+	ctx := context.Background()
+	client := models.Client{Name: "Acme"}
+	db.Create(&client)
+	svc := models.Service{ClientID: client.ID, Domain: "acme.test", URL: "https://acme.test", ServiceType: "website"}
+	db.Create(&svc)
+	db.Create(&models.UptimeLog{ServiceID: svc.ID, Status: "up", ResponseTime: 120, CheckedAt: day(2025, 1, 10)})
+
+	subs := NewSubscriptionService(db)
+	if _, err := subs.Create(ctx, SubscriptionInput{ClientID: client.ID, ServiceID: &svc.ID, Name: "Retainer", Amount: 0}); err == nil {
+		t.Fatalf("expected validation error for zero amount")
+	}
+	other := models.Client{Name: "Other"}
+	db.Create(&other)
+	if _, err := subs.Create(ctx, SubscriptionInput{ClientID: other.ID, ServiceID: &svc.ID, Name: "Retainer", Amount: 1}); err == nil {
+		t.Fatalf("expected error for service of another client")
+	}
+	sub, err := subs.Create(ctx, SubscriptionInput{ClientID: client.ID, ServiceID: &svc.ID, Name: "Retainer", Amount: 3000,
+		StartDate: day(2025, 1, 1), PaymentTerms: "Net 7", TaxName: "PPN", TaxRate: 11})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if invs, err := subs.GenerateDueInvoices(ctx, day(2025, 1, 31)); err != nil || len(invs) != 0 {
+		t.Fatalf("expected nothing billed before the cycle ends, got %d (%v)", len(invs), err)
+	}
+	invs, err := subs.GenerateDueInvoices(ctx, day(2025, 3, 2))
+	if err != nil {
+		t.Fatalf("bill: %v", err)
+	}
+	if len(invs) != 2 {
+		t.Fatalf("expected January and February invoices, got %d", len(invs))
+	}
+	jan := invs[0]
+	if !jan.PeriodStart.Equal(day(2025, 1, 1)) || !jan.PeriodEnd.Equal(day(2025, 2, 1)) || *jan.SubscriptionID != sub.ID {
+		t.Fatalf("unexpected period %v-%v", jan.PeriodStart, jan.PeriodEnd)
+	}
+	if jan.Subtotal != 3000 || jan.TaxTotal != 330 || jan.Total != 3330 || jan.Status != models.InvoiceDraft {
+		t.Fatalf("unexpected totals %+v", jan)
+	}
+	if !jan.DueDate.Equal(day(2025, 3, 9)) {
+		t.Fatalf("expected due date from Net 7, got %v", jan.DueDate)
+	}
+	if jan.MonthlyReportID == nil {
+		t.Fatalf("expected January invoice to link a monthly report")
+	}
+	var report models.MonthlyReport
+	db.First(&report, *jan.MonthlyReportID)
+	if report.ServiceID != svc.ID || report.ReportMonth.Month() != time.January {
+		t.Fatalf("linked wrong report %+v", report)
+	}
+	if invs[1].MonthlyReportID != nil {
+		t.Fatalf("February has no monitoring data, expected no report link")
+	}
+
+	if again, err := subs.GenerateDueInvoices(ctx, day(2025, 3, 2)); err != nil || len(again) != 0 {
+		t.Fatalf("expected rerun to be a no-op, got %d (%v)", len(again), err)
+	}
+	got, _ := subs.Get(ctx, sub.ID)
+	if !got.NextPeriodStart.Equal(day(2025, 3, 1)) {
+		t.Fatalf("expected next period 2025-03-01, got %v", got.NextPeriodStart)
+	}
+}
+
+func TestSubscriptionService_ProratesAmountChangeAndCancellation(t *testing.T) {
+	db := newSubscriptionTestDB(t)
+	ctx := context.Background()
+	client := models.Client{Name: "Acme"}
+	db.Create(&client)
+	subs := NewSubscriptionService(db)
+	sub, err := subs.Create(ctx, SubscriptionInput{ClientID: client.ID, Name: "Support", Amount: 3000, StartDate: day(2025, 4, 1)})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// 10 of April's 30 days at 3000, the remaining 20 at 6000.
+	amount := 6000.0
+	sub, err = subs.Update(ctx, sub.ID, SubscriptionUpdate{Amount: &amount}, day(2025, 4, 11))
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if sub.Amount != 6000 || sub.ProrationAdjustment != -1000 {
+		t.Fatalf("unexpected proration %+v", sub)
+	}
+	invs, err := subs.GenerateDueInvoices(ctx, day(2025, 5, 1))
+	if err != nil || len(invs) != 1 {
+		t.Fatalf("expected April invoice, got %d (%v)", len(invs), err)
+	}
+	if len(invs[0].Items) != 2 || invs[0].Total != 5000 {
+		t.Fatalf("expected prorated total 5000, got %+v", invs[0])
+	}
+
+	// Cancelled on May 16: 15 of 31 days.
+	sub, err = subs.Cancel(ctx, sub.ID, day(2025, 5, 16))
+	if err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if sub.Status != models.SubscriptionCancelled {
+		t.Fatalf("expected cancelled, got %s", sub.Status)
+	}
+	if _, err := subs.Cancel(ctx, sub.ID, day(2025, 5, 20)); !errors.Is(err, ErrSubscriptionState) {
+		t.Fatalf("expected ErrSubscriptionState, got %v", err)
+	}
+	invs, err = subs.GenerateDueInvoices(ctx, day(2025, 7, 1))
+	if err != nil || len(invs) != 1 {
+		t.Fatalf("expected only the final partial invoice, got %d (%v)", len(invs), err)
+	}
+	final := invs[0]
+	if !final.PeriodEnd.Equal(day(2025, 5, 16)) || final.Total != roundMoney(6000*15.0/31) {
+		t.Fatalf("unexpected final invoice %+v", final)
+	}
+	if invs, _ := subs.GenerateDueInvoices(ctx, day(2025, 9, 1)); len(invs) != 0 {
+		t.Fatalf("expected no invoices after cancellation, got %d", len(invs))
+	}
+}
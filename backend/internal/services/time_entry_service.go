@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrTimeEntryInvoiced is returned when changing an entry that is on an
+// invoice; void the invoice first.
+var ErrTimeEntryInvoiced = errors.New("time entry is already invoiced")
+
+// TimeEntryInput creates or updates a time entry. The duration is Minutes,
+// or Hours when Minutes is zero. Currency defaults to the base currency.
+type TimeEntryInput struct {
+	ClientID    int     `json:"client_id"`
+	ServiceID   *int    `json:"service_id"`
+	Date        string  `json:"date"` // YYYY-MM-DD
+	Minutes     int     `json:"minutes"`
+	Hours       float64 `json:"hours"`
+	Description string  `json:"description"`
+	Billable    bool    `json:"billable"`
+	Rate        float64 `json:"rate"`
+	Currency    string  `json:"currency"`
+}
+
+// TimeEntryFilter narrows time entry listings. Zero values are ignored.
+type TimeEntryFilter struct {
+	ClientID   int
+	ServiceID  int
+	From       *time.Time
+	To         *time.Time // inclusive
+	Billable   *bool
+	Uninvoiced bool
+	Limit      int
+	Offset     int
+}
+
+// TimeEntryService records work logged against clients and services.
+type TimeEntryService struct {
+	db *gorm.DB
+}
+
+func NewTimeEntryService(db *gorm.DB) *TimeEntryService {
+	return &TimeEntryService{db: db}
+}
+
+func (s *TimeEntryService) Get(ctx context.Context, id int) (*models.TimeEntry, error) {
+	var entry models.TimeEntry
+	if err := s.db.WithContext(ctx).First(&entry, id).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// List returns entries newest first, with the total before paging.
+func (s *TimeEntryService) List(ctx context.Context, f TimeEntryFilter) ([]models.TimeEntry, int64, error) {
+	q := s.db.WithContext(ctx).Model(&models.TimeEntry{})
+	if f.ClientID > 0 {
+		q = q.Where("client_id = ?", f.ClientID)
+	}
+	if f.ServiceID > 0 {
+		q = q.Where("service_id = ?", f.ServiceID)
+	}
+	if f.From != nil {
+		q = q.Where("date >= ?", dayStart(*f.From))
+	}
+	if f.To != nil {
+		q = q.Where("date < ?", dayStart(*f.To).AddDate(0, 0, 1))
+	}
+	if f.Billable != nil {
+		q = q.Where("billable = ?", *f.Billable)
+	}
+	if f.Uninvoiced {
+		q = q.Where("invoice_id IS NULL")
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if f.Limit > 0 {
+		q = q.Limit(f.Limit).Offset(f.Offset)
+	}
+	var entries []models.TimeEntry
+	if err := q.Order("date DESC, id DESC").Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// Create logs a time entry for userID.
+func (s *TimeEntryService) Create(ctx context.Context, in TimeEntryInput, userID int) (*models.TimeEntry, error) {
+	entry := &models.TimeEntry{UserID: userID}
+	if err := s.apply(ctx, entry, in); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Create(entry).Error; err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Update replaces an entry's fields. Invoiced entries cannot be changed.
+func (s *TimeEntryService) Update(ctx context.Context, id int, in TimeEntryInput) (*models.TimeEntry, error) {
+	entry, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if entry.InvoiceID != nil {
+		return nil, ErrTimeEntryInvoiced
+	}
+	if err := s.apply(ctx, entry, in); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Save(entry).Error; err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Delete removes an entry that is not on an invoice.
+func (s *TimeEntryService) Delete(ctx context.Context, id int) error {
+	entry, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if entry.InvoiceID != nil {
+		return ErrTimeEntryInvoiced
+	}
+	return s.db.WithContext(ctx).Delete(&models.TimeEntry{}, id).Error
+}
+
+// apply validates in and copies it onto entry.
+func (s *TimeEntryService) apply(ctx context.Context, entry *models.TimeEntry, in TimeEntryInput) error {
+	errs := FieldErrors{}
+	if in.ClientID <= 0 {
+		errs["client_id"] = "is required"
+	} else {
+		var n int64
+		if err := s.db.WithContext(ctx).Model(&models.Client{}).Where("id = ?", in.ClientID).Count(&n).Error; err != nil {
+			return err
+		}
+		if n == 0 {
+			errs["client_id"] = "client not found"
+		}
+	}
+	if in.ServiceID != nil && *in.ServiceID > 0 {
+		var svc models.Service
+		err := s.db.WithContext(ctx).Select("id", "client_id").First(&svc, *in.ServiceID).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			errs["service_id"] = "service not found"
+		case err != nil:
+			return err
+		case svc.ClientID != in.ClientID:
+			errs["service_id"] = "must belong to the client"
+		}
+	} else {
+		in.ServiceID = nil
+	}
+	date, err := time.Parse("2006-01-02", strings.TrimSpace(in.Date))
+	if err != nil {
+		errs["date"] = "must be a date (YYYY-MM-DD)"
+	}
+	minutes := in.Minutes
+	if minutes == 0 {
+		minutes = int(math.Round(in.Hours * 60))
+	}
+	if minutes <= 0 || minutes > 24*60 {
+		errs["minutes"] = "must be between 1 minute and 24 hours"
+	}
+	desc := strings.TrimSpace(in.Description)
+	if desc == "" {
+		errs["description"] = "is required"
+	}
+	if in.Rate < 0 {
+		errs["rate"] = "must not be negative"
+	}
+	currency := nonEmpty(normalizeCurrency(in.Currency), BaseCurrency())
+	if !validCurrency(currency) {
+		errs["currency"] = "must be a 3-letter ISO code"
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	entry.ClientID = in.ClientID
+	entry.ServiceID = in.ServiceID
+	entry.Date = date
+	entry.Minutes = minutes
+	entry.Description = desc
+	entry.Billable = in.Billable
+	entry.Rate = in.Rate
+	entry.Currency = currency
+	return nil
+}
+
+// serviceMonthEntries returns a service's entries for the month, oldest first.
+func serviceMonthEntries(db *gorm.DB, serviceID int, month time.Time) ([]models.TimeEntry, error) {
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	var entries []models.TimeEntry
+	if err := db.Where("service_id = ? AND date >= ? AND date < ?", serviceID, start, start.AddDate(0, 1, 0)).
+		Order("date, id").Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to query time entries: %w", err)
+	}
+	return entries, nil
+}
+
+// timeEntryActivities lists entries as report activities with their hours.
+func timeEntryActivities(entries []models.TimeEntry) []models.ReportActivity {
+	out := make([]models.ReportActivity, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, models.ReportActivity{
+			Date:        formatDateID(e.Date),
+			Description: fmt.Sprintf("%s (%s jam)", e.Description, formatQuantity(roundMoney(e.Hours()))),
+		})
+	}
+	return out
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"freelance-monitor-system/internal/models"
+)
+
+func TestTimeEntryService_Validation(t *testing.T) {
+	db := newInvoiceTestDB(t)
+	if err := db.AutoMigrate(&models.Service{}, &models.TimeEntry{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	ctx := context.Background()
+	client := models.Client{Name: "Acme"}
+	db.Create(&client)
+	other := models.Service{ClientID: client.ID + 1, Domain: "other.test", ServiceType: "website"}
+	db.Create(&other)
+	s := NewTimeEntryService(db)
+
+	_, err := s.Create(ctx, TimeEntryInput{ClientID: client.ID, ServiceID: &other.ID, Date: "2025-02-30", Hours: 25, Rate: -1}, 1)
+	var fe FieldErrors
+	if !errors.As(err, &fe) {
+		t.Fatalf("expected field errors, got %v", err)
+	}
+	for _, key := range []string{"service_id", "date", "minutes", "description", "rate"} {
+		if fe[key] == "" {
+			t.Errorf("expected an error for %s: %v", key, fe)
+		}
+	}
+
+	entry, err := s.Create(ctx, TimeEntryInput{ClientID: client.ID, Date: "2025-02-03", Hours: 1.5, Description: " Backups ", Billable: true, Rate: 100, Currency: "usd"}, 1)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if entry.Minutes != 90 || entry.Description != "Backups" || entry.Currency != "USD" || entry.UserID != 1 {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+}
+
+func TestTimeEntryService_InvoiceAndVoid(t *testing.T) {
+	db := newInvoiceTestDB(t)
+	if err := db.AutoMigrate(&models.Service{}, &models.TimeEntry{}, &models.ExchangeRate{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	ctx := context.Background()
+	client := models.Client{Name: "Acme"}
+	db.Create(&client)
+	entries := NewTimeEntryService(db)
+	for _, in := range []TimeEntryInput{
+		{Date: "2025-02-03", Minutes: 90, Description: "Plugin updates", Billable: true, Rate: 200000},
+		{Date: "2025-02-10", Minutes: 30, Description: "Backup check", Billable: true, Rate: 200000},
+		{Date: "2025-02-11", Minutes: 60, Description: "Internal notes"},
+		{Date: "2025-03-02", Minutes: 60, Description: "March work", Billable: true, Rate: 200000},
+	} {
+		in.ClientID = client.ID
+		if _, err := entries.Create(ctx, in, 1); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	invoices := NewInvoiceService(db)
+	from, to := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC)
+	inv, err := invoices.CreateFromTimeEntries(ctx, TimeEntryInvoiceInput{ClientID: client.ID, From: &from, To: &to,
+		IssueDate: from, PaymentTerms: "Net 14"})
+	if err != nil {
+		t.Fatalf("invoice: %v", err)
+	}
+	if len(inv.Items) != 2 || inv.Items[0].Qty != 1.5 || inv.Total != 400000 || inv.Currency != "IDR" {
+		t.Fatalf("unexpected invoice: %+v", inv)
+	}
+	if !strings.Contains(inv.Items[0].Description, "Plugin updates") || !inv.DueDate.Equal(from.AddDate(0, 0, 14)) {
+		t.Fatalf("unexpected line or due date: %+v", inv)
+	}
+
+	billed, total, _ := entries.List(ctx, TimeEntryFilter{ClientID: client.ID, Uninvoiced: true})
+	if total != 2 || billed[0].Description != "March work" {
+		t.Fatalf("expected only March and the non-billable entry to stay uninvoiced, got %+v", billed)
+	}
+	var first models.TimeEntry
+	db.Where("description = ?", "Plugin updates").First(&first)
+	if _, err := entries.Update(ctx, first.ID, TimeEntryInput{ClientID: client.ID, Date: "2025-02-03", Minutes: 10, Description: "x"}); !errors.Is(err, ErrTimeEntryInvoiced) {
+		t.Fatalf("expected invoiced entries to be locked, got %v", err)
+	}
+	if _, err := invoices.CreateFromTimeEntries(ctx, TimeEntryInvoiceInput{ClientID: client.ID, EntryIDs: []int{first.ID}}); err == nil {
+		t.Fatal("expected an entry to be invoiced only once")
+	}
+
+	if _, err := invoices.Void(ctx, inv.ID, time.Now()); err != nil {
+		t.Fatalf("void: %v", err)
+	}
+	if err := entries.Delete(ctx, first.ID); err != nil {
+		t.Fatalf("expected voiding to release the entries, got %v", err)
+	}
+
+	if _, err := entries.Create(ctx, TimeEntryInput{ClientID: client.ID, Date: "2025-03-03", Minutes: 60, Description: "USD work",
+		Billable: true, Rate: 50, Currency: "USD"}, 1); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	var fe FieldErrors
+	if _, err := invoices.CreateFromTimeEntries(ctx, TimeEntryInvoiceInput{ClientID: client.ID}); !errors.As(err, &fe) || fe["currency"] == "" {
+		t.Fatalf("expected mixed currencies to be rejected, got %v", err)
+	}
+}
+
+func TestMonthlyReportUsesTimeEntries(t *testing.T) {
+	db := newInvoiceTestDB(t)
+	if err := db.AutoMigrate(&models.Service{}, &models.TimeEntry{}, &models.MonthlyReport{}, &models.DailyReport{}, &models.UptimeLog{}, &models.Alert{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	ctx := context.Background()
+	client := models.Client{Name: "Acme"}
+	db.Create(&client)
+	svc := models.Service{ClientID: client.ID, Domain: "shop.acme.test", ServiceType: "website"}
+	db.Create(&svc)
+	feb := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	db.Create(&models.UptimeLog{ServiceID: svc.ID, Status: "up", ResponseTime: 100, CheckedAt: feb.AddDate(0, 0, 2)})
+	entries := NewTimeEntryService(db)
+	for _, in := range []TimeEntryInput{
+		{Date: "2025-02-05", Minutes: 90, Description: "Plugin updates"},
+		{Date: "2025-02-01", Minutes: 45, Description: "Backup restore test"},
+		{Date: "2025-03-01", Minutes: 60, Description: "Next month"},
+	} {
+		in.ClientID, in.ServiceID = client.ID, &svc.ID
+		if _, err := entries.Create(ctx, in, 1); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	reports := NewMonthlyReportService(db)
+	report, err := reports.GenerateMonthlyReport(ctx, svc.ID, feb)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	activities := parseReportActivities(report.Activities)
+	if report.MaintenanceHours != 2.25 || len(activities) != 2 || activities[0].Description != "Backup restore test (0.75 jam)" {
+		t.Fatalf("unexpected report: hours %v, activities %+v", report.MaintenanceHours, activities)
+	}
+
+	// Hand edits and incident lines survive regeneration.
+	edited := `[{"date":"03/02/2025","description":"Insiden: site down"},{"date":"05/02/2025","description":"Plugin updates, reviewed with client"}]`
+	if err := db.Model(report).Update("activities", edited).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := entries.Create(ctx, TimeEntryInput{ClientID: client.ID, ServiceID: &svc.ID, Date: "2025-02-20", Minutes: 15, Description: "DNS change"}, 1); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	regen, err := reports.RegenerateMonthlyReport(ctx, report.ID)
+	if err != nil {
+		t.Fatalf("regenerate: %v", err)
+	}
+	merged := parseReportActivities(regen.Activities)
+	if regen.MaintenanceHours != 2.5 || len(merged) != 5 || merged[0].Description != "Insiden: site down" ||
+		merged[1].Description != "Plugin updates, reviewed with client" || merged[4].Description != "DNS change (0.25 jam)" {
+		t.Fatalf("expected regenerate to keep edits and add the new entry: %+v", merged)
+	}
+}
@@ -253,6 +253,17 @@ else
   fi
 fi
 
+echo "Applying database migrations..."
+docker run --rm \
+  "${net_args[@]}" \
+  "${env_arg[@]}" \
+  $( if [[ "${ENV_DB_HOST}" == "db" || "${ENV_DB_HOST}" == "freelance_monitor_db" ]]; then \
+       if [[ -n "${ENV_DB_PORT}" && "${ENV_DB_PORT}" != "5432" ]]; then \
+         echo -n "-e DB_PORT=5432"; \
+       fi; \
+     fi ) \
+  "${IMAGE_NAME_API}" /srv/migrate up
+
 docker run -d \
   -p "${BIND_ADDRESS}:${PORT_HOST}:${PORT_CONTAINER}" \
   --name "${CONTAINER_NAME_API}" \
@@ -4,9 +4,19 @@ import { useEffect, useState } from "react"
 import { Sidebar } from "@/components/sidebar"
 import { Card } from "@/components/ui/card"
 import { Button } from "@/components/ui/button"
-import { apiFetchJson } from "@/lib/api"
+import { apiFetch, apiFetchJson } from "@/lib/api"
 
-type TaskItem = { name: string; interval_seconds: number; enabled: boolean; last_run_at?: string }
+type TaskItem = {
+  name: string
+  schedule: string
+  timezone?: string
+  enabled: boolean
+  last_run_at?: string
+  last_status?: string
+  last_error?: string
+  next_run_at?: string
+  consecutive_failures: number
+}
 
 export default function AutomationPage() {
   const [tasks, setTasks] = useState<TaskItem[]>([])
@@ -19,9 +29,14 @@ export default function AutomationPage() {
       const items = Array.isArray((res.data as any)?.items) ? (res.data as any).items : []
       const normalized = items.map((t: any) => ({
         name: t.name as string,
-        interval_seconds: Number(t.interval_seconds || t.interval || t.intervalSeconds || 0),
+        schedule: t.schedule ? String(t.schedule) : `Every ${Number(t.interval_seconds || 0)}s`,
+        timezone: t.timezone ? String(t.timezone) : undefined,
         enabled: Boolean(t.enabled),
-        last_run_at: t.last_run_at ? String(t.last_run_at) : undefined,
+        last_run_at: t.last_run_at && !String(t.last_run_at).startsWith("0001") ? String(t.last_run_at) : undefined,
+        last_status: t.last_status ? String(t.last_status) : undefined,
+        last_error: t.last_error ? String(t.last_error) : undefined,
+        next_run_at: t.next_run_at ? String(t.next_run_at) : undefined,
+        consecutive_failures: Number(t.consecutive_failures || 0),
       }))
       setTasks(normalized)
     }
@@ -33,7 +48,15 @@ export default function AutomationPage() {
   }, [])
 
   async function run(name: string) {
-    const res = await fetch(`/api/automation/tasks/${encodeURIComponent(name)}/run`, { method: 'POST' })
+    const res = await apiFetch(`/api/automation/tasks/${encodeURIComponent(name)}/run`, { method: 'POST' })
+    if (res.ok) {
+      await load()
+    }
+  }
+
+  async function toggle(t: TaskItem) {
+    const action = t.enabled ? "pause" : "resume"
+    const res = await apiFetch(`/api/automation/tasks/${encodeURIComponent(t.name)}/${action}`, { method: 'POST' })
     if (res.ok) {
       await load()
     }
@@ -55,12 +78,31 @@ export default function AutomationPage() {
                   <div className="flex items-center justify-between">
                     <div>
                       <div className="font-semibold">{t.name}</div>
-                      <div className="text-sm text-muted-foreground">Every {t.interval_seconds}s</div>
+                      <div className="text-sm text-muted-foreground">
+                        {t.schedule}
+                        {t.timezone ? ` (${t.timezone})` : ""}
+                      </div>
                       {t.last_run_at && (
-                        <div className="text-xs text-muted-foreground mt-1">Last run: {new Date(t.last_run_at).toLocaleString()}</div>
+                        <div className="text-xs text-muted-foreground mt-1">
+                          Last run: {new Date(t.last_run_at).toLocaleString()}
+                          {t.last_status ? ` — ${t.last_status}` : ""}
+                        </div>
+                      )}
+                      {t.last_status === "failed" && t.last_error && (
+                        <div className="text-xs text-destructive mt-1">{t.last_error}</div>
+                      )}
+                      {t.consecutive_failures > 1 && (
+                        <div className="text-xs text-destructive mt-1">Failed {t.consecutive_failures} times in a row</div>
                       )}
+                      {!t.enabled && <div className="text-xs text-muted-foreground mt-1">Paused</div>}
+                      {t.next_run_at && (
+                        <div className="text-xs text-muted-foreground mt-1">Next run: {new Date(t.next_run_at).toLocaleString()}</div>
+                      )}
+                    </div>
+                    <div className="flex gap-2">
+                      <Button variant="outline" onClick={() => toggle(t)} disabled={loading}>{t.enabled ? "Pause" : "Resume"}</Button>
+                      <Button variant="outline" onClick={() => run(t.name)} disabled={loading}>Run</Button>
                     </div>
-                    <Button variant="outline" onClick={() => run(t.name)} disabled={loading}>Run</Button>
                   </div>
                 </div>
               ))}
@@ -15,10 +15,13 @@ import { ArrowLeft } from "lucide-react"
 interface Client {
   id: number
   name: string
+  code?: string
   contact_person?: string
   email?: string
   phone?: string
   address?: string
+  report_opt_in?: boolean
+  report_cc?: string
 }
 
 export default function EditClientPage() {
@@ -56,10 +59,13 @@ export default function EditClientPage() {
         headers: { 'Content-Type': 'application/json', Accept: 'application/json' },
         body: JSON.stringify({
           name: client.name,
+          code: client.code || '',
           contact_person: client.contact_person || '',
           email: client.email || '',
           phone: client.phone || '',
           address: client.address || '',
+          report_opt_in: !!client.report_opt_in,
+          report_cc: client.report_cc || '',
         }),
       })
       if (!res.ok) throw new Error(`status ${res.status}`)
@@ -115,6 +121,10 @@ export default function EditClientPage() {
                     <Label>Name</Label>
                     <Input value={client.name} onChange={(e) => setClient({ ...client, name: e.target.value })} required />
                   </div>
+                  <div className="space-y-2">
+                    <Label>Code</Label>
+                    <Input value={client.code || ''} maxLength={10} placeholder="Used in offer numbers, e.g. ACM" onChange={(e) => setClient({ ...client, code: e.target.value })} />
+                  </div>
                   <div className="space-y-2">
                     <Label>Contact Person</Label>
                     <Input value={client.contact_person || ''} onChange={(e) => setClient({ ...client, contact_person: e.target.value })} />
@@ -132,6 +142,22 @@ export default function EditClientPage() {
                   <Label>Address</Label>
                   <Input value={client.address || ''} onChange={(e) => setClient({ ...client, address: e.target.value })} />
                 </div>
+                <div className="flex items-center gap-4">
+                  <input
+                    type="checkbox"
+                    id="reportOptIn"
+                    checked={!!client.report_opt_in}
+                    onChange={(e) => setClient({ ...client, report_opt_in: e.target.checked })}
+                    className="w-4 h-4"
+                  />
+                  <Label htmlFor="reportOptIn">Email monthly reports to this client</Label>
+                </div>
+                {client.report_opt_in && (
+                  <div className="space-y-2">
+                    <Label>Report CC</Label>
+                    <Input value={client.report_cc || ''} placeholder="Comma-separated, e.g. cto@acme.com, ops@acme.com" onChange={(e) => setClient({ ...client, report_cc: e.target.value })} />
+                  </div>
+                )}
 
                 <div className="flex gap-2 pt-4">
                   <Link href={`/clients/${client.id}`}>
@@ -0,0 +1,167 @@
+"use client"
+
+import { useEffect, useState } from "react"
+import { useParams } from "next/navigation"
+import { Card } from "@/components/ui/card"
+import { Button } from "@/components/ui/button"
+import { Input } from "@/components/ui/input"
+import { Label } from "@/components/ui/label"
+import { apiFetch, apiPostJson } from "@/lib/api"
+import { parseOfferItems } from "@/lib/offer-items"
+
+interface PublicOffer {
+  offer: {
+    offer_number: string
+    subject: string
+    date: string
+    valid_until?: string | null
+    items: unknown
+    subtotal: number
+    discount_total: number
+    tax_total: number
+    total_price: number
+    notes?: string
+    payment_terms?: string
+  }
+  status: string
+  revision: number
+  expires_at: string
+  can_respond: boolean
+  signature?: { decision: string; signer_name?: string; reason?: string; signed_at: string }
+}
+
+const rupiah = (n: number) => `Rp ${Number(n || 0).toLocaleString("id-ID")}`
+
+export default function PublicOfferPage() {
+  const params = useParams()
+  const token = String(params.token || "")
+  const [view, setView] = useState<PublicOffer | null>(null)
+  const [error, setError] = useState<string | null>(null)
+  const [name, setName] = useState("")
+  const [reason, setReason] = useState("")
+  const [mode, setMode] = useState<"accept" | "reject" | null>(null)
+  const [busy, setBusy] = useState(false)
+
+  useEffect(() => {
+    if (!token) return
+    ;(async () => {
+      try {
+        const res = await apiFetch(`/api/public/offers/${token}`)
+        const data = await res.json()
+        if (!res.ok) { setError(data?.error || "This link is not valid"); return }
+        setView(data)
+      } catch {
+        setError("Could not load the offer")
+      }
+    })()
+  }, [token])
+
+  const respond = async () => {
+    if (!mode) return
+    setBusy(true); setError(null)
+    const body = mode === "accept" ? { name } : { reason }
+    const res = await apiPostJson<any>(`/api/public/offers/${token}/${mode}`, body, { retries: 0 })
+    setBusy(false)
+    if (!res.ok) {
+      const data = res.data as any
+      const fields = data?.fields ? Object.values(data.fields).join(", ") : ""
+      setError(fields || data?.error || res.errorText || "Could not record your response")
+      return
+    }
+    setView(res.data as PublicOffer)
+    setMode(null)
+  }
+
+  if (error && !view) {
+    return <Card className="p-6"><p className="text-red-600">{error}</p></Card>
+  }
+  if (!view) {
+    return <p className="text-muted-foreground">Loading…</p>
+  }
+
+  const { offer } = view
+  const items = parseOfferItems(offer.items)
+  return (
+    <div className="space-y-4">
+      <Card className="p-6 space-y-1">
+        <h1 className="text-2xl font-bold">{offer.subject}</h1>
+        <p className="text-sm text-muted-foreground">{offer.offer_number} • {new Date(offer.date).toLocaleDateString()}</p>
+        {offer.valid_until && <p className="text-sm">Valid until {new Date(offer.valid_until).toLocaleDateString()}</p>}
+        <p className="text-sm">Status: <span className="font-medium">{view.status}</span></p>
+      </Card>
+
+      <Card className="p-6">
+        <div className="divide-y">
+          {items.map((it, idx) => (
+            <div key={idx} className="py-3 flex justify-between gap-4">
+              <div>
+                <div className="font-medium">{it.name || it.description}</div>
+                {it.name && it.description && it.description !== it.name && <div className="text-sm text-muted-foreground">{it.description}</div>}
+              </div>
+              <div className="text-sm text-right min-w-[180px]">
+                <div>{it.qty}{it.unit ? ` ${it.unit}` : ""} × {rupiah(it.unit_price)}</div>
+                {Number(it.discount_percent) > 0 && <div>Discount {it.discount_percent}%</div>}
+                {Number(it.tax_rate) > 0 && <div>Tax {it.tax_rate}%</div>}
+                <div className="font-medium">{rupiah(it.total)}</div>
+              </div>
+            </div>
+          ))}
+        </div>
+        <div className="mt-4 text-right space-y-1 text-sm">
+          <div>Subtotal: {rupiah(offer.subtotal)}</div>
+          {offer.discount_total > 0 && <div>Discount: −{rupiah(offer.discount_total)}</div>}
+          {offer.tax_total > 0 && <div>Tax: {rupiah(offer.tax_total)}</div>}
+          <div className="text-lg font-semibold">Total: {rupiah(offer.total_price)}</div>
+        </div>
+        {offer.payment_terms && <p className="mt-4 text-sm">Payment terms: {offer.payment_terms}</p>}
+        {offer.notes && <p className="mt-2 text-sm whitespace-pre-line">{offer.notes}</p>}
+        <a className="mt-4 inline-block text-sm underline" href={resolvePdfHref(token)} target="_blank" rel="noreferrer">Download PDF</a>
+      </Card>
+
+      <Card className="p-6 space-y-4">
+        {error && <p className="text-red-600">{error}</p>}
+        {view.signature ? (
+          <p className={view.signature.decision === "accept" ? "text-green-700" : "text-red-700"}>
+            {view.signature.decision === "accept"
+              ? `Accepted by ${view.signature.signer_name} on ${new Date(view.signature.signed_at).toLocaleString()}.`
+              : `Rejected on ${new Date(view.signature.signed_at).toLocaleString()}: ${view.signature.reason}`}
+          </p>
+        ) : !view.can_respond ? (
+          <p className="text-muted-foreground">This offer can no longer be accepted or rejected through this link.</p>
+        ) : mode === "accept" ? (
+          <div className="space-y-2">
+            <Label>Type your full name to accept this offer</Label>
+            <Input value={name} onChange={(e) => setName(e.target.value)} placeholder="Full name" />
+            <p className="text-xs text-muted-foreground">Your name, the time and your IP address are recorded as your signature.</p>
+            <div className="flex gap-2">
+              <Button onClick={respond} disabled={busy || !name.trim()}>Accept offer</Button>
+              <Button variant="outline" onClick={() => setMode(null)}>Cancel</Button>
+            </div>
+          </div>
+        ) : mode === "reject" ? (
+          <div className="space-y-2">
+            <Label>Reason for rejecting</Label>
+            <Input value={reason} onChange={(e) => setReason(e.target.value)} placeholder="e.g. over budget" />
+            <div className="flex gap-2">
+              <Button variant="destructive" onClick={respond} disabled={busy || !reason.trim()}>Reject offer</Button>
+              <Button variant="outline" onClick={() => setMode(null)}>Cancel</Button>
+            </div>
+          </div>
+        ) : (
+          <div className="flex gap-2">
+            <Button onClick={() => setMode("accept")}>Accept</Button>
+            <Button variant="outline" onClick={() => setMode("reject")}>Reject</Button>
+          </div>
+        )}
+        <p className="text-xs text-muted-foreground">This link expires on {new Date(view.expires_at).toLocaleDateString()}.</p>
+      </Card>
+    </div>
+  )
+}
+
+function resolvePdfHref(token: string) {
+  let base = process.env.NEXT_PUBLIC_BACKEND_URL || ""
+  if (!base && typeof window !== "undefined") base = "http://localhost:8080"
+  if (base.endsWith("/api")) base = base.slice(0, -4)
+  return `${base}/api/public/offers/${token}/pdf`
+}
@@ -0,0 +1,9 @@
+export default function PublicOfferLayout({ children }: { children: React.ReactNode }) {
+  return (
+    <div className="min-h-screen flex items-center justify-center bg-background p-4">
+      <div className="w-full max-w-3xl">
+        {children}
+      </div>
+    </div>
+  )
+}
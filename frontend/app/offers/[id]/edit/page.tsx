@@ -7,6 +7,7 @@ import { OfferForm, OfferFormValues } from "@/components/offers/offer-form"
 import { Card } from "@/components/ui/card"
 import { Button } from "@/components/ui/button"
 import { apiFetch, apiFetchJson } from "@/lib/api"
+import { parseOfferItems, toOfferItems } from "@/lib/offer-items"
 
 interface ClientInfo {
   id: string
@@ -53,15 +54,7 @@ export default function EditOfferPage() {
         }
         setClient(clientInfo)
 
-        let servicesRaw: any[] = []
-        try {
-          const parsed = JSON.parse(off.items || "[]")
-          if (Array.isArray(parsed)) {
-            servicesRaw = parsed
-          }
-        } catch {
-          servicesRaw = []
-        }
+        const servicesRaw = parseOfferItems(off.items)
 
         const services = servicesRaw.map((service, index) => {
           const quantityValue = Number(service.quantity ?? service.qty ?? 1)
@@ -70,7 +63,7 @@ export default function EditOfferPage() {
             id: String(index + 1),
             name: service.name || "",
             description: service.description || "",
-            duration: service.duration || "",
+            duration: service.duration || service.unit || "",
             quantity: Number.isFinite(quantityValue) ? quantityValue : 0,
             unitPrice: Number.isFinite(unitPriceValue) ? unitPriceValue : 0,
           }
@@ -117,8 +110,7 @@ export default function EditOfferPage() {
     const payload = {
       subject: formData.title,
       offer_title: formData.offerTitle,
-      items: JSON.stringify(formData.services || []),
-      total_price: formData.totalAmount,
+      items: toOfferItems(formData.services || []),
       notes: formData.note || "",
       proposal_summary: formData.proposalSummary || "",
       proposal_details: formData.proposalDetails || "",
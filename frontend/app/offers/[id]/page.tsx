@@ -7,8 +7,9 @@ import { Sidebar } from "@/components/sidebar"
 import { RequireAuth } from "@/components/auth/require-auth"
 import { Card } from "@/components/ui/card"
 import { Button } from "@/components/ui/button"
-import { ArrowLeft, Download, CheckCircle, Upload, Trash2 } from "lucide-react"
+import { ArrowLeft, Download, CheckCircle, Upload, Trash2, Send, XCircle, Mail } from "lucide-react"
 import { apiFetch } from "@/lib/api"
+import { parseOfferItems } from "@/lib/offer-items"
 
 interface BackendOffer {
   id: number
@@ -16,13 +17,24 @@ interface BackendOffer {
   client_id: number
   date: string
   subject: string
-  items: string
+  items: unknown
   total_price: number
   notes?: string
   status: string
   pdf_url?: string
   signed_doc_url?: string
   approved_at?: string | null
+  revision?: number
+  accepted_revision?: number | null
+}
+
+interface OfferRevision {
+  revision: number
+  offer_number: string
+  total_price: number
+  pdf_url?: string
+  sent_at: string
+  accepted_at?: string | null
 }
 
 export default function OfferDetailPage() {
@@ -31,6 +43,7 @@ export default function OfferDetailPage() {
   const [offer, setOffer] = useState<BackendOffer | null>(null)
   const [loading, setLoading] = useState(true)
   const [client, setClient] = useState<{ id: number; name: string; email?: string } | null>(null)
+  const [revisions, setRevisions] = useState<OfferRevision[]>([])
 
   useEffect(() => {
     if (!offerId) return
@@ -43,6 +56,7 @@ export default function OfferDetailPage() {
       if (!res.ok) throw new Error(`status ${res.status}`)
       const data = await res.json()
       setOffer(data)
+      fetchRevisions()
       // fetch client info in parallel
       if (data?.client_id) {
         try {
@@ -61,6 +75,18 @@ export default function OfferDetailPage() {
     }
   }
 
+  async function fetchRevisions() {
+    try {
+      const res = await apiFetch(`/api/offers/${offerId}/revisions`)
+      if (res.ok) {
+        const data = await res.json()
+        setRevisions(Array.isArray(data?.items) ? data.items : [])
+      }
+    } catch (e) {
+      console.error("Failed to fetch revisions:", e)
+    }
+  }
+
   function openURL(url?: string) {
     if (!url) return
     let base = process.env.NEXT_PUBLIC_BACKEND_URL || ""
@@ -82,16 +108,40 @@ export default function OfferDetailPage() {
     window.open(`/offers/${offer.id}/pdf-preview?auto=open`, "_blank", "noopener,noreferrer")
   }
 
-  async function approveOffer() {
+  // transitionOffer moves the offer along its lifecycle (send, accept, reject).
+  async function transitionOffer(action: "send" | "accept" | "reject" | "revise") {
     if (!offer) return
     try {
-      const res = await apiFetch(`/api/offers/${offer.id}/approve`, { method: "POST" })
+      const res = await apiFetch(`/api/offers/${offer.id}/${action}`, { method: "POST" })
       const data = await res.json()
       if (res.ok) {
-        setOffer({ ...offer, status: data.status, approved_at: data.approved_at })
+        setOffer({ ...offer, status: data.status, approved_at: data.approved_at, revision: data.revision, accepted_revision: data.accepted_revision })
+        if (action === "send" || action === "accept") fetchRevisions()
+      }
+    } catch (e) {
+      console.error(`Failed to ${action} offer:`, e)
+    }
+  }
+
+  async function emailOffer() {
+    if (!offer) return
+    const to = typeof window !== "undefined" ? window.prompt("Send offer to", client?.email || "") : client?.email
+    if (!to) return
+    try {
+      const res = await apiFetch(`/api/offers/${offer.id}/email`, {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ to }),
+      })
+      const data = await res.json()
+      if (!res.ok) {
+        window.alert(data?.fields?.to || data?.error || "Failed to email offer")
+        return
       }
+      setOffer({ ...offer, status: data.offer.status, revision: data.offer.revision })
+      fetchRevisions()
     } catch (e) {
-      console.error("Failed to approve offer:", e)
+      console.error("Failed to email offer:", e)
     }
   }
 
@@ -124,15 +174,9 @@ export default function OfferDetailPage() {
     }
   }
 
-  const items = useMemo(() => {
-    if (!offer?.items) return [] as Array<{ name?: string; quantity?: number; unit_price?: number; total?: number }>
-    try {
-      const parsed = JSON.parse(offer.items)
-      return Array.isArray(parsed) ? parsed : []
-    } catch {
-      return []
-    }
-  }, [offer?.items])
+  const items = useMemo(() => parseOfferItems(offer?.items), [offer?.items])
+  const awaitingDecision = offer?.status === "sent" || offer?.status === "viewed"
+  const canRevise = awaitingDecision || offer?.status === "rejected"
 
   if (loading) {
     return (
@@ -183,9 +227,16 @@ export default function OfferDetailPage() {
                   <div className="text-lg font-semibold mt-2">Total: Rp {Number(offer.total_price || 0).toLocaleString("id-ID")}</div>
                 </div>
                 <div className="flex items-center gap-2">
-                  <Link href={`/offers/${offer.id}/edit`}>
-                    <Button variant="outline" size="sm" className="gap-2 bg-transparent">Edit</Button>
-                  </Link>
+                  {offer.status === 'draft' && (
+                    <Link href={`/offers/${offer.id}/edit`}>
+                      <Button variant="outline" size="sm" className="gap-2 bg-transparent">Edit</Button>
+                    </Link>
+                  )}
+                  {canRevise && (
+                    <Button variant="outline" size="sm" className="gap-2 bg-transparent" onClick={() => transitionOffer('revise')}>
+                      Revise
+                    </Button>
+                  )}
                   <Button variant="outline" size="sm" className="gap-2 bg-transparent" onClick={openPDFInline}>
                     <Download className="w-4 h-4" /> Open PDF
                   </Button>
@@ -199,9 +250,22 @@ export default function OfferDetailPage() {
                     <span>Upload Signed</span>
                     <input type="file" className="hidden" onChange={(e) => e.target.files?.[0] && uploadSigned(e.target.files![0])} />
                   </label>
-                  <Button variant="outline" size="sm" className="gap-2 bg-transparent" onClick={approveOffer} disabled={offer.status === 'accepted'}>
+                  {(offer.status === 'draft' || awaitingDecision) && (
+                    <Button variant="outline" size="sm" className="gap-2 bg-transparent" onClick={emailOffer}>
+                      <Mail className="w-4 h-4" /> Email to Client
+                    </Button>
+                  )}
+                  {offer.status === 'draft' && (
+                    <Button variant="outline" size="sm" className="gap-2 bg-transparent" onClick={() => transitionOffer('send')}>
+                      <Send className="w-4 h-4" /> Mark as Sent
+                    </Button>
+                  )}
+                  <Button variant="outline" size="sm" className="gap-2 bg-transparent" onClick={() => transitionOffer('accept')} disabled={!awaitingDecision}>
                     <CheckCircle className="w-4 h-4" /> Approve
                   </Button>
+                  <Button variant="outline" size="sm" className="gap-2 bg-transparent" onClick={() => transitionOffer('reject')} disabled={!awaitingDecision}>
+                    <XCircle className="w-4 h-4" /> Reject
+                  </Button>
                   <Button variant="outline" size="sm" className="gap-2 bg-transparent" onClick={deleteOffer}>
                     <Trash2 className="w-4 h-4" /> Delete
                   </Button>
@@ -218,18 +282,45 @@ export default function OfferDetailPage() {
                   {items.map((it, idx) => (
                     <div key={idx} className="py-3 flex items-center justify-between">
                       <div>
-                        <div className="font-medium">{(it as any).name || `Item ${idx+1}`}</div>
+                        <div className="font-medium">{it.name || it.description || `Item ${idx+1}`}</div>
                       </div>
                       <div className="text-sm text-right min-w-[200px]">
-                        <div>Qty: {(it as any).quantity ?? '-'}</div>
-                        <div>Unit: Rp {Number((it as any).unit_price || 0).toLocaleString("id-ID")}</div>
-                        <div>Total: Rp {Number((it as any).total || 0).toLocaleString("id-ID")}</div>
+                        <div>Qty: {it.qty ?? it.quantity ?? '-'}{it.unit ? ` ${it.unit}` : ''}</div>
+                        <div>Unit: Rp {Number(it.unit_price || 0).toLocaleString("id-ID")}</div>
+                        <div>Total: Rp {Number(it.total || 0).toLocaleString("id-ID")}</div>
                       </div>
                     </div>
                   ))}
                 </div>
               )}
             </Card>
+
+            {revisions.length > 0 && (
+              <Card className="p-6">
+                <h2 className="text-lg font-semibold mb-4">Revisions</h2>
+                <div className="divide-y">
+                  {revisions.map((rev) => (
+                    <div key={rev.revision} className="py-3 flex items-center justify-between">
+                      <div>
+                        <div className="font-medium">{rev.offer_number}</div>
+                        <div className="text-sm text-muted-foreground">Sent {new Date(rev.sent_at).toLocaleString()}</div>
+                        {rev.accepted_at && (
+                          <div className="text-sm text-green-700">Accepted {new Date(rev.accepted_at).toLocaleString()}</div>
+                        )}
+                      </div>
+                      <div className="flex items-center gap-3 text-sm">
+                        <span>Rp {Number(rev.total_price || 0).toLocaleString("id-ID")}</span>
+                        {rev.pdf_url && (
+                          <Button variant="outline" size="sm" className="gap-2 bg-transparent" onClick={() => openURL(rev.pdf_url)}>
+                            <Download className="w-4 h-4" /> PDF
+                          </Button>
+                        )}
+                      </div>
+                    </div>
+                  ))}
+                </div>
+              </Card>
+            )}
           </div>
         </RequireAuth>
       </main>
@@ -10,6 +10,7 @@ import { Sidebar } from "@/components/sidebar"
 import { RequireAuth } from "@/components/auth/require-auth"
 import { ArrowLeft, Download } from "lucide-react"
 import { apiFetch } from "@/lib/api"
+import { OfferItem, parseOfferItems } from "@/lib/offer-items"
 
 type BackendOffer = {
   id: number
@@ -17,7 +18,7 @@ type BackendOffer = {
   client_id: number
   date: string
   subject: string
-  items: string
+  items: OfferItem[] | string
   total_price: number
   notes?: string
   status: string
@@ -537,14 +538,7 @@ const defaultTemplateData: TemplateData = {
   signCompany: "PT Emico Mitra Samudera",
 }
 
-const parseItems = (offer: BackendOffer): any[] => {
-  try {
-    const parsed = JSON.parse(offer.items || "[]")
-    return Array.isArray(parsed) ? parsed : []
-  } catch {
-    return []
-  }
-}
+const parseItems = (offer: BackendOffer): any[] => parseOfferItems(offer.items)
 
 function buildTemplateData(offer: BackendOffer | null, client: BackendClient | null): TemplateData {
   if (!offer) {
@@ -568,7 +562,7 @@ function buildTemplateData(offer: BackendOffer | null, client: BackendClient | n
       item.name || item.title || item.description || "Layanan"
     )
     const description = escapeHtml(item.description || item.detail || "-")
-    const duration = escapeHtml(item.duration || item.timeframe || "-")
+    const duration = escapeHtml(item.duration || item.unit || item.timeframe || "-")
     const quantity = Number(item.quantity ?? item.qty ?? 0)
     const unitPrice = Number(item.unitPrice ?? item.unit_price ?? item.price ?? 0)
     const total = Number(item.total ?? quantity * unitPrice)
@@ -2,6 +2,8 @@
 
 import { useState, useEffect } from "react"
 import { apiFetchJson, apiPostJson } from "@/lib/api"
+import { toOfferItems } from "@/lib/offer-items"
+import { CatalogItem, OfferTemplate, templateToFormInitial } from "@/lib/offer-templates"
 import { useRouter } from "next/navigation"
 import { Sidebar } from "@/components/sidebar"
 import { OfferForm, OfferFormValues } from "@/components/offers/offer-form"
@@ -24,30 +26,30 @@ export default function NewOfferPage() {
   const [clients, setClients] = useState<Client[]>([])
   const [selectedClient, setSelectedClient] = useState<Client | null>(null)
   const [loading, setLoading] = useState(false)
+  const [templates, setTemplates] = useState<OfferTemplate[]>([])
+  const [catalog, setCatalog] = useState<CatalogItem[]>([])
+  const [initial, setInitial] = useState<ReturnType<typeof templateToFormInitial> | undefined>(undefined)
 
   useEffect(() => {
     fetchClients()
+    fetchTemplates()
   }, [])
 
-  async function fetchClients() {
+  async function fetchTemplates() {
     try {
-      const { ok, data } = await apiFetchJson("/api/clients")
-      const items = Array.isArray(data) ? data : Array.isArray(data?.items) ? data.items : []
-      setClients(
-        items.map((c: any) => ({
-          id: String(c.id),
-          name: c.name,
-          contactPerson: c.contact_person ?? null,
-          email: c.email ?? null,
-          phone: c.phone ?? null,
-          address: c.address ?? null,
-        }))
-      )
+      const [tpl, cat] = await Promise.all([apiFetchJson("/api/offer-templates"), apiFetchJson("/api/catalog")])
+      setTemplates(Array.isArray(tpl.data?.items) ? tpl.data.items : [])
+      setCatalog(Array.isArray(cat.data?.items) ? cat.data.items : [])
     } catch (error) {
-      console.error("Failed to fetch clients:", error)
+      console.error("Failed to fetch templates:", error)
     }
   }
 
+  function applyTemplate(id: string) {
+    const tpl = templates.find((t) => String(t.id) === id)
+    setInitial(tpl ? templateToFormInitial(tpl, catalog) : undefined)
+  }
+
   async function handleSubmit(formData: OfferFormValues) {
     if (!selectedClient) return
     setLoading(true)
@@ -56,8 +58,7 @@ export default function NewOfferPage() {
         client_id: Number(selectedClient.id),
         subject: formData.title,
         offer_title: formData.offerTitle,
-        items: JSON.stringify(formData.services || []),
-        total_price: formData.totalAmount,
+        items: toOfferItems(formData.services || []),
         notes: formData.note || "",
         proposal_summary: formData.proposalSummary || "",
         proposal_details: formData.proposalDetails || "",
@@ -135,7 +136,23 @@ export default function NewOfferPage() {
               <Button variant="outline" size="sm" onClick={() => setSelectedClient(null)} className="mb-6">
                 Change Client
               </Button>
-              <OfferForm client={selectedClient} onSubmit={handleSubmit} isLoading={loading} />
+              {templates.length > 0 && (
+                <div className="mb-6">
+                  <select
+                    className="border border-border rounded-md px-3 py-2 bg-background text-sm"
+                    defaultValue=""
+                    onChange={(e) => applyTemplate(e.target.value)}
+                  >
+                    <option value="">Start from template…</option>
+                    {templates.map((t) => (
+                      <option key={t.id} value={t.id}>
+                        {t.name}
+                      </option>
+                    ))}
+                  </select>
+                </div>
+              )}
+              <OfferForm client={selectedClient} onSubmit={handleSubmit} isLoading={loading} initial={initial} catalog={catalog} />
             </div>
           )}
         </div>
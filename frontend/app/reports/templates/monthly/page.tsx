@@ -61,7 +61,7 @@ export default function MonthlyTemplateEditorPage() {
         <div className="p-8 space-y-6">
           <div>
             <h1 className="text-3xl font-bold">Monthly Template Editor</h1>
-            <p className="text-muted-foreground">Edit HTML content stored as template and preview PDF client-side.</p>
+            <p className="text-muted-foreground">Edit the HTML template the server uses to render monthly report PDFs.</p>
           </div>
 
           <Card className="p-6 space-y-4">
@@ -97,13 +97,13 @@ export default function MonthlyTemplateEditorPage() {
               </div>
             </div>
             <div className="space-y-2">
-              <Label htmlFor="tpl-content">Template HTML/JSON (stored for reference)</Label>
+              <Label htmlFor="tpl-content">Template HTML (Go html/template, e.g. {"{{.Client.Name}}"})</Label>
               <textarea
                 id="tpl-content"
                 value={content}
                 onChange={(e) => setContent(e.target.value)}
                 className="w-full rounded-md border border-input bg-background px-3 py-2 text-sm text-foreground h-40"
-                placeholder="Paste HTML or JSON config used in client rendering"
+                placeholder="<h1>{{.Month}}</h1>"
               />
             </div>
             <Button onClick={save}>Save Template</Button>
@@ -13,7 +13,9 @@ export function AuthGate({ children }: { children: React.ReactNode }) {
 
   useEffect(() => {
     const isAuthRoute = pathname?.startsWith("/auth")
-    if (devBypass || isAuthRoute) { setOk(true); return }
+    // Offer links emailed to clients are public; the token is the credential.
+    const isPublicOffer = pathname?.startsWith("/o/")
+    if (devBypass || isAuthRoute || isPublicOffer) { setOk(true); return }
     // Prefer cookie presence if backend set AUTH_COOKIE=true
     const cookieToken = typeof document !== 'undefined' ? document.cookie.includes('auth_token=') : false
     const token = typeof window !== "undefined" ? localStorage.getItem("auth_token") : null
@@ -8,6 +8,7 @@ import { DatePicker } from "@/components/ui/date-picker"
 import { Label } from "@/components/ui/label"
 import { Card } from "@/components/ui/card"
 import { Plus, Trash2 } from "lucide-react"
+import { CatalogItem, catalogLine } from "@/lib/offer-templates"
 
 interface ServiceItem {
   id: string
@@ -88,6 +89,7 @@ interface OfferFormProps {
   isLoading?: boolean
   initial?: OfferFormInitial
   submitLabel?: string
+  catalog?: CatalogItem[]
 }
 
 const defaultService = (): ServiceItem => ({
@@ -99,7 +101,7 @@ const defaultService = (): ServiceItem => ({
   unitPrice: 0,
 })
 
-export function OfferForm({ client, onSubmit, isLoading, initial, submitLabel }: OfferFormProps) {
+export function OfferForm({ client, onSubmit, isLoading, initial, submitLabel, catalog }: OfferFormProps) {
    const [title, setTitle] = useState(initial?.title || "")
    const [offerTitle, setOfferTitle] = useState(initial?.offerTitle || "Rincian Produk & Jasa")
    const [proposalSummary, setProposalSummary] = useState(initial?.proposalSummary || "")
@@ -223,6 +225,12 @@ export function OfferForm({ client, onSubmit, isLoading, initial, submitLabel }:
      setServices((prev) => [...prev, defaultService()])
    }
 
+   const addCatalogItem = (id: string) => {
+     const item = catalog?.find((c) => String(c.id) === id)
+     if (!item) return
+     setServices((prev) => [...prev, { ...defaultService(), ...catalogLine({}, item) }])
+   }
+
    const removeService = (id: string) => {
      setServices((prev) => prev.filter((service) => service.id !== id))
    }
@@ -451,10 +459,26 @@ export function OfferForm({ client, onSubmit, isLoading, initial, submitLabel }:
        <Card className="p-6">
          <div className="flex justify-between items-center mb-4">
            <h2 className="text-xl font-semibold">Services</h2>
-           <Button type="button" onClick={addService} variant="outline" size="sm" className="gap-2 bg-transparent">
-             <Plus className="w-4 h-4" />
-             Add Service
-           </Button>
+           <div className="flex gap-2">
+             {catalog && catalog.length > 0 && (
+               <select
+                 className="border border-border rounded-md px-2 py-1 bg-background text-sm"
+                 value=""
+                 onChange={(e) => addCatalogItem(e.target.value)}
+               >
+                 <option value="">Add from catalog…</option>
+                 {catalog.map((c) => (
+                   <option key={c.id} value={c.id}>
+                     {c.name}
+                   </option>
+                 ))}
+               </select>
+             )}
+             <Button type="button" onClick={addService} variant="outline" size="sm" className="gap-2 bg-transparent">
+               <Plus className="w-4 h-4" />
+               Add Service
+             </Button>
+           </div>
          </div>
 
          <div className="space-y-4">
@@ -75,7 +75,7 @@ export function OfferList() {
 
   async function approveOffer(id: number) {
     try {
-      const res = await apiFetch(`/api/offers/${id}/approve`, { method: 'POST' })
+      const res = await apiFetch(`/api/offers/${id}/accept`, { method: 'POST' })
       const data = await res.json()
       if (res.ok) {
         setOffers((prev) => prev.map((o) => (o.id === id ? { ...o, status: data.status } : o)))
@@ -106,10 +106,14 @@ export function OfferList() {
         return "bg-gray-100 text-gray-800"
       case "sent":
         return "bg-blue-100 text-blue-800"
+      case "viewed":
+        return "bg-indigo-100 text-indigo-800"
       case "accepted":
         return "bg-green-100 text-green-800"
       case "rejected":
         return "bg-red-100 text-red-800"
+      case "expired":
+        return "bg-yellow-100 text-yellow-800"
       default:
         return "bg-gray-100 text-gray-800"
     }
@@ -175,7 +179,7 @@ export function OfferList() {
                       <span>Upload Signed</span>
                       <input type="file" className="hidden" onChange={(e) => e.target.files?.[0] && uploadSigned(offer.id, e.target.files![0])} />
                     </label>
-                    <Button variant="outline" size="sm" className="gap-2 bg-transparent" onClick={() => approveOffer(offer.id)} disabled={offer.status === 'accepted'}>
+                    <Button variant="outline" size="sm" className="gap-2 bg-transparent" onClick={() => approveOffer(offer.id)} disabled={offer.status !== 'sent' && offer.status !== 'viewed'}>
                       <CheckCircle className="w-4 h-4" />
                       Approve
                     </Button>
@@ -0,0 +1,49 @@
+// Offer line items as stored by the API (`offer_items`). Totals are computed
+// server-side; the client only sends qty, unit, unit price, discount and tax.
+
+export interface OfferItem {
+  id?: number
+  position?: number
+  name: string
+  description: string
+  qty: number
+  unit: string
+  unit_price: number
+  discount_percent?: number
+  tax_rate?: number
+  subtotal?: number
+  discount_amount?: number
+  tax_amount?: number
+  total?: number
+}
+
+interface FormService {
+  name?: string
+  description?: string
+  duration?: string
+  quantity?: number
+  unitPrice?: number
+}
+
+export function toOfferItems(services: FormService[]): OfferItem[] {
+  return services.map((service) => ({
+    name: service.name || "",
+    description: service.description || service.name || "",
+    qty: Number(service.quantity ?? 0),
+    unit: service.duration || "",
+    unit_price: Number(service.unitPrice ?? 0),
+  }))
+}
+
+// parseOfferItems accepts the items array returned by the API, or the JSON
+// string older responses used.
+export function parseOfferItems(raw: unknown): any[] {
+  if (Array.isArray(raw)) return raw
+  if (typeof raw !== "string" || raw.trim() === "") return []
+  try {
+    const parsed = JSON.parse(raw)
+    return Array.isArray(parsed) ? parsed : []
+  } catch {
+    return []
+  }
+}
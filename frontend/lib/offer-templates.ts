@@ -0,0 +1,90 @@
+// Offer templates and catalog items as returned by /api/offer-templates and
+// /api/catalog. Template lines that point at a catalog item take the item's
+// name, unit and price when their own are empty (the server does the same
+// when an offer is created from a template).
+
+export interface CatalogItem {
+  id: number
+  name: string
+  description?: string
+  unit?: string
+  unit_price: number
+  currency?: string
+  tax_rate?: number
+}
+
+export interface OfferTemplateItem {
+  catalog_item_id?: number | null
+  name?: string
+  description?: string
+  qty?: number
+  unit?: string
+  unit_price?: number
+}
+
+export interface OfferTemplate {
+  id: number
+  name: string
+  subject?: string
+  offer_title?: string
+  currency?: string
+  valid_days?: number
+  issuer_name?: string
+  issuer_company?: string
+  issuer_address?: string
+  issuer_city?: string
+  issuer_phone?: string
+  issuer_email?: string
+  proposal_summary?: string
+  proposal_details?: string
+  payment_terms?: string
+  closing_text?: string
+  notes?: string
+  signature_title?: string
+  signature_company?: string
+  signature_city?: string
+  items?: OfferTemplateItem[]
+}
+
+// templateToFormInitial maps a template onto the OfferForm's initial values.
+export function templateToFormInitial(tpl: OfferTemplate, catalog: CatalogItem[]) {
+  const byId = new Map(catalog.map((c) => [c.id, c]))
+  let validUntil = ""
+  if (tpl.valid_days && tpl.valid_days > 0) {
+    const d = new Date()
+    d.setDate(d.getDate() + tpl.valid_days)
+    validUntil = d.toISOString().slice(0, 10)
+  }
+  return {
+    title: tpl.subject || "",
+    offerTitle: tpl.offer_title || undefined,
+    proposalSummary: tpl.proposal_summary || "",
+    proposalDetails: tpl.proposal_details || "",
+    note: tpl.notes || "",
+    paymentTerms: tpl.payment_terms || "",
+    closingText: tpl.closing_text || "",
+    validUntil,
+    currency: tpl.currency || undefined,
+    issuerName: tpl.issuer_name || "",
+    issuerCompany: tpl.issuer_company || "",
+    issuerAddress: tpl.issuer_address || "",
+    issuerCity: tpl.issuer_city || "",
+    issuerPhone: tpl.issuer_phone || "",
+    issuerEmail: tpl.issuer_email || "",
+    signatureTitle: tpl.signature_title || "",
+    signatureCompany: tpl.signature_company || "",
+    signatureCity: tpl.signature_city || "",
+    services: (tpl.items || []).map((it) => catalogLine(it, it.catalog_item_id ? byId.get(it.catalog_item_id) : undefined)),
+  }
+}
+
+// catalogLine builds an OfferForm service line, filling gaps from a catalog item.
+export function catalogLine(it: OfferTemplateItem, item?: CatalogItem) {
+  return {
+    name: it.name || item?.name || "",
+    description: it.description || item?.description || item?.name || "",
+    duration: it.unit || item?.unit || "",
+    quantity: it.qty || 1,
+    unitPrice: it.unit_price || item?.unit_price || 0,
+  }
+}